@@ -0,0 +1,56 @@
+// Command i18nlint checks the translation files: every message must be valid ICU MessageFormat
+// and use the same placeholders as its English version.
+//
+//	go run ./cmd/i18nlint [-dir static/i18n] [-base en]
+//
+// Translations edited in the admin are checked when saved; the admin's
+// /admin/api/v1/translations/validate endpoint reports the same problems for the database.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hekigan/couples/internal/services"
+)
+
+func main() {
+	dir := flag.String("dir", "static/i18n", "directory of the <lang>.json translation files")
+	base := flag.String("base", services.DefaultLanguage, "language whose placeholders the others must use")
+	flag.Parse()
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		log.Fatalf("❌ Failed to list translation files: %v", err)
+	}
+	if len(files) == 0 {
+		log.Fatalf("❌ No translation files in %s", *dir)
+	}
+
+	translations := make(map[string]map[string]string)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Fatalf("❌ Failed to read %s: %v", file, err)
+		}
+		values, err := services.ParseTranslations(data)
+		if err != nil {
+			log.Fatalf("❌ %s: %v", file, err)
+		}
+		translations[strings.TrimSuffix(filepath.Base(file), ".json")] = values
+	}
+
+	problems := services.LintTranslations(translations, *base)
+	for _, problem := range problems {
+		fmt.Printf("%s %s: %s\n", problem.Lang, problem.Key, problem.Problem)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("❌ %d problems in %d languages\n", len(problems), len(translations))
+		os.Exit(1)
+	}
+	fmt.Printf("✅ %d languages OK\n", len(translations))
+}
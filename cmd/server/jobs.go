@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hekigan/couples/internal/config"
+	"github.com/hekigan/couples/internal/services"
+)
+
+// Maintenance job thresholds
+const (
+	// inactiveAnonymousUserHours is how long an anonymous user without rooms is kept
+	inactiveAnonymousUserHours = 4
+	// staleRequestAge is how long join requests and invitations may stay pending
+	staleRequestAge = 7 * 24 * time.Hour
+)
+
+// schedulerLockName identifies the advisory lock the instances compete for
+const schedulerLockName = "couples:job-scheduler"
+
+// newScheduler creates the maintenance job scheduler
+// With a Postgres database (the store's or the realtime broker's), instances elect the one
+// that runs the jobs through an advisory lock, held on a connection of its own; otherwise this
+// instance always runs them, which config refuses for more than one replica (REPLICAS needs
+// REALTIME_BROKER=postgres).
+func newScheduler(
+	cfg *config.Config,
+	store services.Store,
+	pgStore *services.PostgresStore,
+	userService *services.UserService,
+	roomService *services.RoomService,
+	gameService *services.GameService,
+	notificationService *services.NotificationService,
+	accountService *services.AccountService,
+	sessionService *services.SessionService,
+) (*services.Scheduler, error) {
+	var leader services.LeaderElector = services.LocalLeader{}
+	if pgStore != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		if leader, err = services.OpenPostgresLeader(ctx, cfg.DatabaseURL, schedulerLockName); err != nil {
+			return nil, err
+		}
+		log.Printf("⏰ Electing the job scheduler leader through a Postgres advisory lock")
+	} else if cfg.Replicas > 1 {
+		return nil, fmt.Errorf("%d replicas need a Postgres pool to elect the job scheduler leader", cfg.Replicas)
+	}
+	scheduler := services.NewScheduler(store, leader)
+
+	jobs := []struct {
+		name, spec string
+		run        services.JobFunc
+	}{
+		{"anonymous-users-expired", "15 * * * *", func(ctx context.Context) (string, error) {
+			n, err := userService.CleanupExpiredAnonymousUsers(ctx, services.DefaultAnonymousUserTTLHours)
+			return fmt.Sprintf("deleted %d users", n), err
+		}},
+		{"user-merges", "*/10 * * * *", func(ctx context.Context) (string, error) {
+			n, err := userService.ResumeUserMerges(ctx)
+			return fmt.Sprintf("resumed %d merges", n), err
+		}},
+		{"anonymous-users-inactive", "45 * * * *", func(ctx context.Context) (string, error) {
+			n, err := userService.CleanupInactiveAnonymousUsers(ctx, inactiveAnonymousUserHours)
+			return fmt.Sprintf("deleted %d users", n), err
+		}},
+		{"reconnection-timeouts", "* * * * *", func(ctx context.Context) (string, error) {
+			n, err := gameService.EndTimedOutGames(ctx, cfg.ReconnectTimeoutMinutes)
+			return fmt.Sprintf("ended %d games", n), err
+		}},
+		{"stale-join-requests", "0 3 * * *", func(ctx context.Context) (string, error) {
+			n, err := roomService.DeleteStaleJoinRequests(ctx, staleRequestAge)
+			return fmt.Sprintf("deleted %d requests", n), err
+		}},
+		{"stale-invitations", "30 3 * * *", func(ctx context.Context) (string, error) {
+			n, err := notificationService.DeleteStaleInvitations(ctx, staleRequestAge)
+			return fmt.Sprintf("deleted %d invitations", n), err
+		}},
+		{"expired-account-tokens", "0 4 * * *", func(ctx context.Context) (string, error) {
+			n, err := accountService.DeleteExpiredTokens(ctx)
+			return fmt.Sprintf("deleted %d tokens", n), err
+		}},
+		{"expired-sessions", "30 4 * * *", func(ctx context.Context) (string, error) {
+			n, err := sessionService.DeleteExpiredSessions(ctx)
+			return fmt.Sprintf("deleted %d sessions", n), err
+		}},
+	}
+	for _, job := range jobs {
+		if err := scheduler.Register(job.name, job.spec, job.run); err != nil {
+			return nil, err
+		}
+	}
+	return scheduler, nil
+}
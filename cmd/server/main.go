@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hekigan/couples/internal/config"
+)
+
+func main() {
+	// "serve" is the default so `./server` keeps working without arguments
+	command := "serve"
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	switch command {
+	case "serve":
+		if err := runServer(); err != nil {
+			log.Fatalf("❌ Server error: %v", err)
+		}
+
+	case "migrate":
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatalf("❌ Migration error: %v", err)
+		}
+
+	case "help", "-h", "--help":
+		printUsage()
+
+	default:
+		fmt.Printf("❌ Unknown command: %s\n\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+// runServer loads configuration, starts the HTTP server and blocks until
+// SIGINT/SIGTERM, then shuts down gracefully
+func runServer() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	store, err := newStore(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Refuse to serve against a schema older than the code expects, before any
+	// background worker touches it
+	checkCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	err = checkSchemaVersion(checkCtx, store)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	app, err := newApplication(cfg, store)
+	if err != nil {
+		return err
+	}
+
+	// Languages and translations edited by admins live in the database; the built-in languages
+	// and the files cover anything missing
+	loadCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := app.languages.Load(loadCtx); err != nil {
+		log.Printf("⚠️ Failed to load languages, using the defaults: %v", err)
+	}
+	if err := app.i18n.Load(loadCtx); err != nil {
+		log.Printf("⚠️ Failed to load translations, using the files only: %v", err)
+	}
+	cancel()
+	app.languages.Start()
+	app.i18n.Start()
+
+	// Uncomment to print the full route inventory on startup
+	// PrintRouteRegistry(app.echo)
+	if cfg.IsDevelopment() {
+		PrintRouteStats(app.echo)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("🚀 Server starting on %s (env: %s)", cfg.Addr(), cfg.Env)
+		if err := app.echo.Start(cfg.Addr()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err := <-serverErr:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Restore default signal handling so a second Ctrl+C forces exit
+	stop()
+	log.Println("🛑 Shutting down gracefully...")
+
+	if err := app.Shutdown(cfg.ShutdownTimeout); err != nil {
+		return err
+	}
+
+	log.Println("👋 Server stopped")
+	return nil
+}
+
+func printUsage() {
+	fmt.Println("server - Couple Card Game HTTP server")
+	fmt.Println("")
+	fmt.Println("Usage:")
+	fmt.Println("  go run ./cmd/server [command]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  serve   - Start the HTTP server (default)")
+	fmt.Println("  migrate - Apply or revert database migrations (up, down, status, redo)")
+	fmt.Println("  help    - Show this message")
+	fmt.Println("")
+	fmt.Println("Configuration is read from the environment and an optional .env file.")
+	fmt.Println("See .env.example for the supported variables.")
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hekigan/couples/internal/config"
+	"github.com/hekigan/couples/internal/migrations"
+	"github.com/hekigan/couples/internal/services"
+)
+
+// runMigrate implements `server migrate <up|down|status|redo>`
+// Migrations always run over a direct connection (DATABASE_URL), whatever DATABASE_BACKEND says.
+func runMigrate(args []string) error {
+	if len(args) != 1 {
+		printMigrateUsage()
+		return errors.New("expected exactly one migrate command")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if cfg.DatabaseURL == "" {
+		return errors.New("DATABASE_URL is required to run migrations")
+	}
+
+	ctx := context.Background()
+	store, err := services.NewPostgresStore(ctx, cfg.DatabaseURL, services.PostgresPoolConfig{MaxConns: 1})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	runner, err := migrations.NewRunner(store.DB())
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := runner.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("✅ Applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("Database is up to date")
+		}
+
+	case "down":
+		m, err := runner.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("No migration to revert")
+		} else {
+			fmt.Printf("↩️  Reverted %04d_%s\n", m.Version, m.Name)
+		}
+
+	case "redo":
+		m, err := runner.Redo(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("🔁 Redid %04d_%s\n", m.Version, m.Name)
+
+	case "status":
+		statuses, err := runner.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		printMigrateUsage()
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+
+	return nil
+}
+
+// checkSchemaVersion refuses to start when the database is behind the embedded migrations
+// It reads schema_migrations through the configured store, so it works for every backend.
+func checkSchemaVersion(ctx context.Context, store services.Store) error {
+	data, err := store.Select(ctx, "schema_migrations", services.NewQuery().
+		Select("version").
+		OrderBy("version", false).
+		Page(1, 0))
+	if err != nil {
+		return fmt.Errorf("failed to read schema version (has `server migrate up` been run?): %w", err)
+	}
+
+	var rows []struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return fmt.Errorf("failed to parse schema version: %w", err)
+	}
+
+	applied := 0
+	if len(rows) > 0 {
+		applied = rows[0].Version
+	}
+	return migrations.CheckVersion(applied)
+}
+
+func printMigrateUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run ./cmd/server migrate <command>")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  up      - Apply all pending migrations")
+	fmt.Println("  down    - Revert the last applied migration")
+	fmt.Println("  redo    - Revert and re-apply the last applied migration")
+	fmt.Println("  status  - List migrations and whether they are applied")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"github.com/hekigan/couples/internal/handlers"
+	adminHandlers "github.com/hekigan/couples/internal/handlers/admin"
+	adminAPI "github.com/hekigan/couples/internal/handlers/admin/api"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// registerAdminRoutes registers the admin UI pages and everything under /admin/api/v1
+// Non-admins get a 404 (see EchoRequireAdmin)
+func registerAdminRoutes(
+	e *echo.Echo,
+	h *handlers.Handler,
+	adminAPIHandler *adminHandlers.AdminAPIHandler,
+	csvHandler *adminAPI.CSVHandler,
+	translationHandler *adminHandlers.TranslationHandler,
+) {
+	admin := e.Group("/admin", middleware.EchoRequireAuth(), middleware.EchoRequireAdmin())
+
+	// Admin UI pages (unversioned)
+	admin.GET("", h.AdminDashboardHandler)
+	admin.GET("/users", h.AdminUsersHandler)
+	admin.GET("/questions", h.AdminQuestionsHandler)
+	admin.GET("/categories", h.AdminCategoriesHandler)
+	admin.GET("/rooms", h.AdminRoomsHandler)
+	admin.GET("/translations", h.AdminTranslationsHandler)
+	admin.GET("/routes", h.AdminRoutesHandler)
+	admin.GET("/jobs", h.AdminJobsHandler)
+
+	api := admin.Group("/api/v1")
+
+	// Users
+	users := api.Group("/users")
+	users.GET("/list", adminAPIHandler.ListUsersHandler)
+	users.GET("/new", adminAPIHandler.GetUserCreateFormHandler)
+	users.POST("", adminAPIHandler.CreateUserHandler)
+	users.GET("/:id/edit-form", adminAPIHandler.GetUserEditFormHandler)
+	users.PUT("/:id", adminAPIHandler.UpdateUserHandler)
+	users.POST("/:id/toggle-admin", adminAPIHandler.ToggleUserAdminHandler)
+	users.DELETE("/:id", adminAPIHandler.DeleteUserHandler)
+	users.POST("/bulk-delete", adminAPIHandler.BulkDeleteUsersHandler)
+	users.GET("/expired-anonymous", adminAPIHandler.ExpiredAnonymousUsersHandler)
+
+	// Questions
+	questions := api.Group("/questions")
+	questions.GET("/list", adminAPIHandler.ListQuestionsHandler)
+	questions.GET("/new", adminAPIHandler.GetQuestionCreateFormHandler)
+	questions.POST("", adminAPIHandler.CreateQuestionHandler)
+	questions.GET("/:id/edit-form", adminAPIHandler.GetQuestionEditFormHandler)
+	questions.PUT("/:id", adminAPIHandler.UpdateQuestionHandler)
+	questions.DELETE("/:id", adminAPIHandler.DeleteQuestionHandler)
+	questions.POST("/bulk-delete", adminAPIHandler.BulkDeleteQuestionsHandler)
+
+	// Categories
+	categories := api.Group("/categories")
+	categories.GET("/list", adminAPIHandler.ListCategoriesHandler)
+	categories.GET("/new", adminAPIHandler.GetCategoryCreateFormHandler)
+	categories.POST("", adminAPIHandler.CreateCategoryHandler)
+	categories.GET("/:id/edit-form", adminAPIHandler.GetCategoryEditFormHandler)
+	categories.PUT("/:id", adminAPIHandler.UpdateCategoryHandler)
+	categories.DELETE("/:id", adminAPIHandler.DeleteCategoryHandler)
+	categories.POST("/bulk-delete", adminAPIHandler.BulkDeleteCategoriesHandler)
+
+	// Rooms
+	rooms := api.Group("/rooms")
+	rooms.GET("/list", adminAPIHandler.ListRoomsHandler)
+	rooms.GET("/:id/details", adminAPIHandler.GetRoomDetailsHandler)
+	rooms.POST("/:id/close", adminAPIHandler.CloseRoomHandler)
+	rooms.POST("/:id/reset-question-history", adminAPIHandler.ResetRoomQuestionHistoryHandler)
+	rooms.DELETE("/:id", adminAPIHandler.DeleteRoomHandler)
+	rooms.POST("/bulk-close", adminAPIHandler.BulkCloseRoomsHandler)
+
+	// Dashboard
+	api.GET("/dashboard/stats", adminAPIHandler.GetDashboardStatsHandler)
+
+	// Scheduled jobs
+	jobs := api.Group("/jobs")
+	jobs.GET("/list", adminAPIHandler.ListJobsHandler)
+	jobs.POST("/:name/run", adminAPIHandler.RunJobHandler)
+
+	// CSV import/export
+	csv := api.Group("/csv")
+	csv.GET("/questions/export", csvHandler.ExportQuestionsCSV)
+	csv.POST("/questions/import", csvHandler.ImportQuestionsCSV)
+	csv.GET("/questions/template", csvHandler.GetImportTemplate)
+	csv.GET("/categories/export", csvHandler.ExportCategoriesCSV)
+
+	// Translations
+	translations := api.Group("/translations")
+	translations.GET("/languages", translationHandler.ListLanguagesHandler)
+	translations.GET("/list", translationHandler.ListTranslationsHandler)
+	translations.GET("/validate", translationHandler.ValidateMissingKeysHandler)
+	translations.POST("/language/add", translationHandler.AddLanguageHandler)
+	translations.PUT("/language/:code", translationHandler.UpdateLanguageHandler)
+	translations.POST("", translationHandler.CreateTranslationHandler)
+	translations.GET("/:lang_code", translationHandler.GetTranslationsHandler)
+	translations.PUT("/:lang_code", translationHandler.UpdateTranslationHandler)
+	translations.DELETE("/:lang_code", translationHandler.DeleteTranslationHandler)
+	translations.GET("/:lang_code/export", translationHandler.ExportTranslationsHandler)
+	translations.POST("/:lang_code/import", translationHandler.ImportTranslationsHandler)
+}
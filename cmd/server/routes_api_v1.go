@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+
+	"github.com/hekigan/couples/internal/handlers"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// registerAPIv1Routes registers everything under /api/v1 (see docs/API_VERSIONING.md)
+// Requests authenticated by an API token need the scope of each group (session requests don't)
+func registerAPIv1Routes(e *echo.Echo, h *handlers.Handler, rt *handlers.RealtimeHandler, streamsCtx context.Context) {
+	api := e.Group("/api/v1", middleware.EchoRequireAuth())
+
+	// Rooms
+	roomsScope := middleware.EchoRequireReadWriteScope(models.ScopeRoomsRead, models.ScopeRoomsWrite)
+	rooms := api.Group("/rooms", roomsScope)
+
+	// Invitations sent from the room page friends list
+	rooms.POST("/invitations", h.SendRoomInvitationHandler)
+	rooms.DELETE("/invitations/:room_id/:invitee_id", h.CancelRoomInvitationHandler)
+
+	// Game state management
+	rooms.DELETE("/:id", h.DeleteRoomAPIHandler)
+	rooms.POST("/:id/leave", h.LeaveRoomHandler)
+	rooms.POST("/:id/start", h.StartGameAPIHandler)
+	rooms.POST("/:id/guest-ready", h.SetGuestReadyAPIHandler)
+	rooms.POST("/:id/typing", h.PlayerTypingAPIHandler)
+	rooms.POST("/:id/draw", h.DrawQuestionAPIHandler)
+	rooms.POST("/:id/answer", h.SubmitAnswerAPIHandler)
+	rooms.POST("/:id/finish", h.FinishGameAPIHandler)
+	rooms.POST("/:id/reset-history", h.ResetQuestionHistoryAPIHandler)
+	rooms.POST("/:id/next-question", h.NextQuestionHTMLHandler)
+
+	// UI fragments (HTMX)
+	rooms.GET("/:id/start-button", h.GetStartGameButtonHTMLHandler)
+	rooms.GET("/:id/ready-button", h.GetGuestReadyButtonHTMLHandler)
+	rooms.GET("/:id/status-badge", h.RoomStatusBadgeAPIHandler)
+	rooms.GET("/:id/turn-indicator", h.GetTurnIndicatorHandler)
+	rooms.GET("/:id/question-card", h.GetQuestionCardHandler)
+	rooms.GET("/:id/game-forms", h.GetGameFormsHandler)
+	rooms.GET("/:id/game-content", h.GetGameContentHandler)
+	rooms.GET("/:id/progress-counter", h.GetProgressCounterHandler)
+	rooms.GET("/:id/presence-indicator", h.GetPresenceIndicatorHandler)
+
+	// Room categories
+	rooms.GET("/:id/categories", h.GetRoomCategoriesHTMLHandler)
+	rooms.POST("/:id/categories", h.UpdateCategoriesAPIHandler)
+	rooms.POST("/:id/categories/toggle", h.ToggleCategoryAPIHandler)
+
+	// Room join requests
+	rooms.GET("/:id/join-requests", h.ListJoinRequestsHandler)
+	rooms.GET("/:id/join-requests-json", h.GetJoinRequestsJSONHandler)
+	rooms.GET("/:id/join-requests-count", h.GetJoinRequestsCountHandler)
+	rooms.GET("/:id/my-join-request", h.CheckMyJoinRequestHandler)
+	rooms.POST("/:id/cancel-my-request", h.CancelMyJoinRequestHTMLHandler)
+
+	// Categories
+	api.GET("/categories", h.GetCategoriesAPIHandler, roomsScope)
+
+	// Friends
+	friends := api.Group("/friends", middleware.EchoRequireScope(models.ScopeFriends))
+	friends.GET("/list", h.GetFriendsAPIHandler)
+	friends.GET("/list-html", h.GetFriendsHTMLHandler)
+
+	// Join requests
+	joinRequests := api.Group("/join-requests", roomsScope)
+	joinRequests.POST("", h.CreateJoinRequestHandler)
+	joinRequests.GET("/my-requests", h.GetMyJoinRequestsHTMLHandler)
+	joinRequests.GET("/my-accepted", h.GetMyAcceptedRequestsHandler)
+	joinRequests.POST("/:request_id/accept", h.AcceptJoinRequestHandler)
+	joinRequests.POST("/:request_id/reject", h.RejectJoinRequestHandler)
+
+	// Invitations
+	invitations := api.Group("/invitations", middleware.EchoRequireScope(models.ScopeFriends))
+	invitations.POST("", h.SendRoomInvitationHandler)
+	invitations.DELETE("/:room_id/:invitee_id", h.CancelRoomInvitationHandler)
+
+	// Notifications
+	notifications := api.Group("/notifications", roomsScope)
+	notifications.GET("", h.GetNotificationsHandler)
+	notifications.GET("/unread-count", h.GetUnreadCountHandler)
+	notifications.POST("/:id/read", h.MarkNotificationReadHandler)
+	notifications.POST("/read-all", h.MarkAllNotificationsReadHandler)
+
+	// Real-time streams (SSE) - rate limiting, CSRF and gzip are skipped for this prefix
+	// in registerMiddleware; EchoShutdownContext ends the streams on graceful shutdown
+	stream := api.Group("/stream", middleware.EchoRequireScope(models.ScopeRoomsRead), middleware.EchoShutdownContext(streamsCtx))
+	stream.GET("/rooms/:id/events", rt.StreamRoomEvents)
+	stream.GET("/rooms/:id/ws", rt.StreamRoomSocket)
+	stream.GET("/rooms/:id/players", rt.GetRoomPlayers)
+	stream.GET("/rooms/:id/state", rt.GetRoomState)
+	stream.GET("/user/events", rt.StreamUserNotifications)
+	stream.GET("/notifications", rt.StreamUserNotifications)
+}
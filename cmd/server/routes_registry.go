@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Route versions as reported by determineVersion
+const (
+	versionAPIv1      = "v1"
+	versionAdminAPIv1 = "admin-v1"
+	versionNone       = "unversioned"
+)
+
+// validMethods lists the HTTP methods shown in the registry
+// (Echo also registers internal "echo_route_not_found" entries)
+var validMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "DELETE": true,
+	"PATCH": true, "OPTIONS": true,
+}
+
+// registeredRoutes returns the user-facing routes sorted by path, then method
+func registeredRoutes(e *echo.Echo) []*echo.Route {
+	var routes []*echo.Route
+	for _, route := range e.Routes() {
+		if !validMethods[route.Method] {
+			continue
+		}
+		routes = append(routes, route)
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// PrintRouteStats prints route counts per API version (development mode only)
+func PrintRouteStats(e *echo.Echo) {
+	counts := map[string]int{}
+	routes := registeredRoutes(e)
+	for _, route := range routes {
+		counts[determineVersion(route.Path)]++
+	}
+
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Println("ROUTE STATISTICS")
+	fmt.Println(strings.Repeat("=", 80))
+	fmt.Printf("Total Routes:         %d\n", len(routes))
+	fmt.Printf("API v1 Routes:        %d\n", counts[versionAPIv1])
+	fmt.Printf("Admin API v1 Routes:  %d\n", counts[versionAdminAPIv1])
+	fmt.Printf("Unversioned Routes:   %d (UI pages)\n", counts[versionNone])
+	fmt.Println(strings.Repeat("=", 80))
+}
+
+// PrintRouteRegistry prints the full route inventory grouped by API version
+func PrintRouteRegistry(e *echo.Echo) {
+	groups := map[string][]*echo.Route{}
+	for _, route := range registeredRoutes(e) {
+		version := determineVersion(route.Path)
+		groups[version] = append(groups[version], route)
+	}
+
+	sections := []struct {
+		version string
+		title   string
+	}{
+		{versionAPIv1, "API v1 (/api/v1)"},
+		{versionAdminAPIv1, "Admin API v1 (/admin/api/v1)"},
+		{versionNone, "Unversioned (UI pages)"},
+	}
+
+	for _, section := range sections {
+		fmt.Println(strings.Repeat("=", 80))
+		fmt.Printf("%s - %d routes\n", section.title, len(groups[section.version]))
+		fmt.Println(strings.Repeat("=", 80))
+		for _, route := range groups[section.version] {
+			marker := ""
+			if isHTMXFragment(route.Path) {
+				marker = " [HTMX]"
+			}
+			fmt.Printf("  %-7s %s%s\n", route.Method, route.Path, marker)
+		}
+	}
+	fmt.Println(strings.Repeat("=", 80))
+}
+
+// determineVersion identifies the route version from its path
+func determineVersion(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/api/v1/"):
+		return versionAPIv1
+	case strings.HasPrefix(path, "/admin/api/v1/"):
+		return versionAdminAPIv1
+	default:
+		return versionNone
+	}
+}
+
+// isHTMXFragment checks if a route path represents an HTMX fragment endpoint
+func isHTMXFragment(path string) bool {
+	patterns := []string{
+		"-button", "-badge", "-indicator", "-card", "-form",
+		"-content", "-counter", "/list", "/edit-form", "/new",
+	}
+
+	for _, pattern := range patterns {
+		if strings.Contains(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
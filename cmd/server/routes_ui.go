@@ -0,0 +1,88 @@
+package main
+
+import (
+	"github.com/hekigan/couples/internal/config"
+	"github.com/hekigan/couples/internal/handlers"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// registerUIRoutes registers the unversioned, full-page routes
+func registerUIRoutes(e *echo.Echo, h *handlers.Handler, cfg *config.Config) {
+	requireAuth := middleware.EchoRequireAuth()
+
+	// Public pages
+	e.GET("/", h.HomeHandler)
+	e.GET("/health", h.HealthHandler)
+
+	// Authentication
+	e.GET("/login", h.LoginHandler)
+	e.POST("/login", h.LoginPostHandler)
+	e.GET("/signup", h.SignupHandler)
+	e.POST("/signup", h.SignupPostHandler)
+	e.GET("/forgot-password", h.ForgotPasswordHandler)
+	e.POST("/forgot-password", h.ForgotPasswordPostHandler)
+	e.GET("/reset-password", h.ResetPasswordHandler)
+	e.POST("/reset-password", h.ResetPasswordPostHandler)
+	e.GET("/verify-email", h.VerifyEmailHandler)
+	e.GET("/login/two-factor", h.TwoFactorLoginHandler)
+	e.POST("/login/two-factor", h.TwoFactorLoginPostHandler)
+
+	auth := e.Group("/auth")
+	auth.POST("/logout", h.LogoutHandler)
+	auth.POST("/anonymous", h.CreateAnonymousHandler)
+	auth.GET("/oauth/google", h.OAuthGoogleHandler)
+	auth.GET("/oauth/facebook", h.OAuthFacebookHandler)
+	auth.GET("/oauth/github", h.OAuthGithubHandler)
+	auth.GET("/oauth/callback", h.OAuthCallbackHandler)
+	auth.POST("/oauth/token", h.OAuthTokenHandler)
+	if cfg.IsDevelopment() {
+		// Development only: log in as the seeded admin user (see sql/seed.sql)
+		auth.GET("/dev-login-admin", h.DevLoginAsAdminHandler)
+	}
+
+	// Username setup (after anonymous/OAuth sign-in)
+	e.GET("/setup-username", h.SetupUsernameHandler, requireAuth)
+	e.POST("/setup-username", h.SetupUsernamePostHandler, requireAuth)
+
+	// Profile
+	profile := e.Group("/profile", requireAuth)
+	profile.GET("", h.ProfileHandler)
+	profile.POST("", h.UpdateProfileHandler)
+	profile.POST("/email", h.ChangeEmailHandler)
+	profile.POST("/verify-email", h.ResendVerificationHandler)
+	profile.GET("/two-factor", h.TwoFactorSettingsHandler)
+	profile.POST("/two-factor/setup", h.TwoFactorSetupHandler)
+	profile.POST("/two-factor/confirm", h.TwoFactorConfirmHandler)
+	profile.POST("/two-factor/verify", h.TwoFactorVerifyHandler)
+	profile.POST("/two-factor/disable", h.TwoFactorDisableHandler)
+	profile.POST("/two-factor/recovery-codes", h.TwoFactorRecoveryCodesHandler)
+	profile.GET("/sessions", h.SessionsHandler)
+	profile.POST("/sessions/revoke-others", h.RevokeOtherSessionsHandler)
+	profile.POST("/sessions/:id/revoke", h.RevokeSessionHandler)
+	profile.GET("/api-tokens", h.APITokensHandler)
+	profile.POST("/api-tokens", h.CreateAPITokenHandler)
+	profile.POST("/api-tokens/:id/revoke", h.RevokeAPITokenHandler)
+
+	// Friends
+	friends := e.Group("/friends", requireAuth)
+	friends.GET("", h.FriendsHandler)
+	friends.GET("/add", h.AddFriendHandler)
+	friends.POST("/add", h.AddFriendHandler)
+	friends.POST("/accept/:id", h.AcceptFriendHandler)
+	friends.POST("/decline/:id", h.DeclineFriendHandler)
+	friends.DELETE("/:id", h.RemoveFriendHandler)
+
+	// Game pages
+	game := e.Group("/game", requireAuth)
+	game.GET("/rooms", h.ListRoomsHandler)
+	game.GET("/partials/empty-rooms-state", h.EmptyRoomsStateHandler)
+	game.GET("/create-room", h.CreateRoomHandler)
+	game.POST("/create-room", h.CreateRoomHandler)
+	game.GET("/join-room", h.JoinRoomHandler)
+	game.POST("/join-room", h.JoinRoomHandler)
+	game.GET("/room/:id", h.RoomHandler)
+	game.POST("/room/:id/delete", h.DeleteRoomHandler)
+	game.GET("/play/:id", h.PlayHandler)
+	game.GET("/finished/:id", h.GameFinishedHandler)
+}
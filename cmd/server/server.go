@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hekigan/couples/internal/config"
+	"github.com/hekigan/couples/internal/handlers"
+	adminHandlers "github.com/hekigan/couples/internal/handlers/admin"
+	adminAPI "github.com/hekigan/couples/internal/handlers/admin/api"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/rendering"
+	"github.com/hekigan/couples/internal/services"
+	"github.com/labstack/echo/v4"
+	echoMiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// streamPrefix groups the long-lived SSE endpoints (no rate limit, no CSRF, no gzip)
+const streamPrefix = "/api/v1/stream"
+
+// application holds the wired server: Echo instance, services and handlers
+type application struct {
+	cfg       *config.Config
+	echo      *echo.Echo
+	store     services.Store
+	realtime  *services.RealtimeService
+	presence  *services.PresenceService
+	scheduler *services.Scheduler
+	i18n      *services.I18nService
+	languages *services.LanguageService
+	// brokerPool is the realtime broker's own connection pool, when the store is not Postgres
+	// (the job scheduler's leader election shares it)
+	brokerPool io.Closer
+
+	// streamsCtx is cancelled on shutdown so SSE handlers return on their own
+	streamsCtx    context.Context
+	cancelStreams context.CancelFunc
+}
+
+// newApplication builds every service and handler on store and registers all routes
+// It starts the background workers (broker, presence, scheduler), so check the schema first.
+func newApplication(cfg *config.Config, store services.Store) (*application, error) {
+	e := echo.New()
+	e.HideBanner = true
+	e.Debug = cfg.IsDevelopment()
+
+	sessionService := services.NewSessionService(store)
+	middleware.InitSessionStore(sessionService, append([]string{cfg.SessionSecret}, cfg.SessionPreviousSecrets...)...)
+
+	// Services
+	realtimeService := services.NewRealtimeService()
+	var eventStore services.Store
+	if cfg.PersistRoomEvents {
+		eventStore = store
+	}
+	realtimeService.SetEventLog(services.NewRoomEventLog(cfg.EventReplaySize, eventStore))
+	pgStore, _ := store.(*services.PostgresStore)
+	var brokerPool io.Closer
+	if cfg.RealtimeBroker == config.BrokerPostgres {
+		broker, brokerStore, err := newPostgresBroker(cfg, pgStore)
+		if err != nil {
+			return nil, err
+		}
+		if err := realtimeService.SetBroker(broker); err != nil {
+			return nil, fmt.Errorf("failed to start realtime broker: %w", err)
+		}
+		if brokerStore != nil {
+			pgStore = brokerStore
+			brokerPool = brokerStore
+		}
+	}
+	roomService := services.NewRoomService(store, realtimeService)
+	questionService := services.NewQuestionService(store)
+	questionService.SetHistoryScope(services.HistoryScope(cfg.QuestionHistoryScope))
+	categoryService := services.NewCategoryService(store)
+	answerService := services.NewAnswerService(store)
+	userService := services.NewUserService(store)
+	friendService := services.NewFriendService(store)
+	notificationService := services.NewNotificationService(store, realtimeService)
+	adminService := services.NewAdminService(store)
+	languageService := services.NewLanguageService(store)
+	i18nService := services.NewI18nService(store, cfg.TranslationDir, languageService)
+	gameService := services.NewGameService(
+		store,
+		roomService,
+		questionService,
+		categoryService,
+		answerService,
+		realtimeService,
+		rendering.NewTemplService(),
+	)
+	authService, err := services.NewAuthService(store)
+	if err != nil {
+		return nil, err
+	}
+	accountService := services.NewAccountService(store, authService, newMailer(cfg), i18nService, cfg.AppBaseURL)
+	twoFactorService := services.NewTwoFactorService(store, "Couple Card Game")
+	apiTokenService := services.NewAPITokenService(store)
+	presenceService := services.NewPresenceService(store, roomService, gameService, realtimeService, cfg.DisconnectGracePeriod)
+	realtimeService.SetPresenceObserver(presenceService)
+	presenceService.Start()
+	scheduler, err := newScheduler(cfg, store, pgStore, userService, roomService, gameService, notificationService, accountService, sessionService)
+	if err != nil {
+		return nil, err
+	}
+	scheduler.Start()
+
+	// Handlers
+	h := handlers.NewHandler(
+		userService,
+		roomService,
+		gameService,
+		questionService,
+		categoryService,
+		answerService,
+		friendService,
+		i18nService,
+		languageService,
+		notificationService,
+		presenceService,
+		scheduler,
+		accountService,
+		twoFactorService,
+		sessionService,
+		apiTokenService,
+		adminService,
+		e,
+	)
+	realtimeHandler := handlers.NewRealtimeHandler(h, realtimeService)
+	adminAPIHandler := adminHandlers.NewAdminAPIHandler(h, adminService, questionService, categoryService)
+	translationHandler := adminHandlers.NewTranslationHandler(h)
+	csvHandler := adminAPI.NewCSVHandler(questionService, categoryService)
+
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+
+	app := &application{
+		cfg:           cfg,
+		echo:          e,
+		store:         store,
+		realtime:      realtimeService,
+		presence:      presenceService,
+		scheduler:     scheduler,
+		i18n:          i18nService,
+		languages:     languageService,
+		brokerPool:    brokerPool,
+		streamsCtx:    streamsCtx,
+		cancelStreams: cancelStreams,
+	}
+
+	app.registerMiddleware(userService, apiTokenService, languageService)
+	e.Static("/static", cfg.StaticDir)
+
+	registerUIRoutes(e, h, cfg)
+	registerAPIv1Routes(e, h, realtimeHandler, app.streamsCtx)
+	registerAdminRoutes(e, h, adminAPIHandler, csvHandler, translationHandler)
+
+	return app, nil
+}
+
+// newStore opens the persistence backend selected by DATABASE_BACKEND
+func newStore(cfg *config.Config) (services.Store, error) {
+	switch cfg.DatabaseBackend {
+	case config.BackendPostgres:
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		store, err := services.NewPostgresStore(ctx, cfg.DatabaseURL, services.PostgresPoolConfig{
+			MaxConns:        cfg.DatabaseMaxConns,
+			ConnMaxIdleTime: 5 * time.Minute,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Postgres: %w", err)
+		}
+		log.Printf("🐘 Using Postgres backend (pool of %d connections)", cfg.DatabaseMaxConns)
+		return store, nil
+	default:
+		supabaseClient, err := services.NewSupabaseClient()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Supabase: %w", err)
+		}
+		return services.NewSupabaseStore(supabaseClient), nil
+	}
+}
+
+// newPostgresBroker creates the LISTEN/NOTIFY realtime broker on pgStore, the store's pool
+// when the backend is Postgres; when pgStore is nil it opens a small pool of its own, which
+// it returns to be shared and closed
+func newPostgresBroker(cfg *config.Config, pgStore *services.PostgresStore) (*services.PostgresBroker, *services.PostgresStore, error) {
+	var own *services.PostgresStore
+	if pgStore == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		own, err = services.NewPostgresStore(ctx, cfg.DatabaseURL, services.PostgresPoolConfig{MaxConns: 2})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to initialize realtime broker: %w", err)
+		}
+		pgStore = own
+	}
+
+	broker := services.NewPostgresBroker(pgStore)
+	log.Printf("📡 Sharing realtime events between instances through Postgres LISTEN/NOTIFY")
+	return broker, own, nil
+}
+
+// newMailer creates the mailer selected by MAILER
+func newMailer(cfg *config.Config) services.Mailer {
+	if cfg.Mailer == config.MailerSMTP {
+		log.Printf("📧 Sending mail through %s:%d", cfg.SMTPHost, cfg.SMTPPort)
+		return services.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.MailFrom)
+	}
+	if cfg.MailDir != "" {
+		log.Printf("📧 Writing mail to %s instead of sending it", cfg.MailDir)
+	}
+	return services.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+}
+
+// registerMiddleware installs the global middleware chain
+// Order matters: session- or token-derived auth must run before i18n and CSRF
+func (a *application) registerMiddleware(userService *services.UserService, apiTokenService *services.APITokenService, languageService *services.LanguageService) {
+	a.echo.Use(echoMiddleware.Recover())
+	a.echo.Use(middleware.EchoSecurityHeaders())
+	a.echo.Use(middleware.EchoCORS())
+	a.echo.Use(echoMiddleware.GzipWithConfig(echoMiddleware.GzipConfig{
+		// Compression buffers output, which breaks SSE flushing
+		Skipper: isStreamRequest,
+	}))
+	a.echo.Use(middleware.EchoAuth(apiTokenService))
+	a.echo.Use(middleware.EchoAnonymousSession())
+	a.echo.Use(middleware.EchoTrackLastSeen(userService))
+	a.echo.Use(middleware.EchoI18n(languageService, userService))
+	a.echo.Use(skipForStreams(middleware.EchoRateLimit()))
+	a.echo.Use(skipForStreams(middleware.EchoCSRF()))
+}
+
+// Shutdown stops accepting connections, closes every open SSE stream and waits up
+// to timeout for in-flight requests to finish
+func (a *application) Shutdown(timeout time.Duration) error {
+	// Streams closed by the shutdown are not disconnections: stop presence tracking first
+	a.presence.Stop()
+	a.scheduler.Stop()
+	a.i18n.Stop()
+	a.languages.Stop()
+
+	// Closing the realtime channels makes StreamRoomEvents/StreamUserNotifications return;
+	// cancelling streamsCtx covers any stream still waiting on its request context
+	closed := a.realtime.Shutdown()
+	a.cancelStreams()
+	log.Printf("📴 Closed %d realtime stream(s)", closed)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := a.echo.Shutdown(ctx); err != nil {
+		log.Printf("⚠️  Graceful shutdown did not complete within %s: %v", timeout, err)
+		if closeErr := a.echo.Close(); closeErr != nil && !errors.Is(closeErr, http.ErrServerClosed) {
+			return fmt.Errorf("failed to close server: %w", closeErr)
+		}
+	}
+
+	// Release pooled database connections once no request can use them
+	if closer, ok := a.store.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			return fmt.Errorf("failed to close database pool: %w", err)
+		}
+	}
+	if a.brokerPool != nil {
+		if err := a.brokerPool.Close(); err != nil {
+			return fmt.Errorf("failed to close realtime broker pool: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// isStreamRequest reports whether the request targets an SSE endpoint
+func isStreamRequest(c echo.Context) bool {
+	return strings.HasPrefix(c.Request().URL.Path, streamPrefix+"/")
+}
+
+// skipForStreams wraps a middleware so it is bypassed for SSE endpoints
+func skipForStreams(mw echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		wrapped := mw(next)
+		return func(c echo.Context) error {
+			if isStreamRequest(c) {
+				return next(c)
+			}
+			return wrapped(c)
+		}
+	}
+}
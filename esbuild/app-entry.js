@@ -7,6 +7,7 @@
  * Bundle includes:
  * - HTMX core library
  * - HTMX SSE extension
+ * - Room WebSocket transport (falls back to SSE)
  * - UI utilities (Toast, Loading, MobileMenu, etc.)
  * - Modal system
  * - Real-time notifications
@@ -15,6 +16,7 @@
 // Core HTMX and extensions (must be loaded first)
 import '../static/js/htmx.min.js';
 import '../static/js/sse.js';
+import '../static/js/room-socket.js';
 
 // Shared utilities
 import '../static/js/ui-utils.js';
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Environments supported by the server
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+// Persistence backends selectable with DATABASE_BACKEND
+const (
+	BackendSupabase = "supabase"
+	BackendPostgres = "postgres"
+)
+
+// minPostgresConns is the smallest pool the postgres backend runs on: the realtime listener
+// holds one connection, and requests must still get one while another is busy
+const minPostgresConns = 3
+
+// Question history scopes selectable with QUESTION_HISTORY_SCOPE
+const (
+	HistoryScopePair = "pair"
+	HistoryScopeUser = "user"
+)
+
+// Realtime brokers selectable with REALTIME_BROKER
+const (
+	BrokerLocal    = "local"
+	BrokerPostgres = "postgres"
+)
+
+// Mailers selectable with MAILER
+const (
+	MailerFile = "file"
+	MailerSMTP = "smtp"
+)
+
+// minSessionSecretLength is the minimum secret length accepted in production
+const minSessionSecretLength = 32
+
+// Config holds the typed server configuration loaded from the environment
+type Config struct {
+	Port int
+	Env  string
+
+	SupabaseURL            string
+	SupabaseAnonKey        string
+	SupabaseServiceRoleKey string
+
+	// DatabaseBackend selects the Store: supabase (PostgREST) or postgres (direct, transactional)
+	DatabaseBackend string
+	// DatabaseURL is the PostgreSQL connection string used by the postgres backend
+	DatabaseURL string
+	// DatabaseMaxConns caps the postgres connection pool (at least minPostgresConns)
+	DatabaseMaxConns int
+
+	// QuestionHistoryScope decides which earlier sessions exclude a question from the draw:
+	// pair (questions the two players saw together) or user (questions either saw with anyone)
+	QuestionHistoryScope string
+
+	// EventReplaySize is how many realtime events per room are kept for Last-Event-ID replay
+	EventReplaySize int
+	// PersistRoomEvents also writes the replay buffer to room_events so it survives restarts
+	PersistRoomEvents bool
+	// RealtimeBroker carries realtime events between instances: local (single instance) or
+	// postgres (LISTEN/NOTIFY over DATABASE_URL, for several replicas)
+	RealtimeBroker string
+	// Replicas is how many instances serve the app; more than one needs the postgres broker,
+	// whose pool also elects the instance running the scheduled jobs
+	Replicas int
+	// DisconnectGracePeriod is how long a player may have no live connection before the game
+	// pauses; ReconnectTimeoutMinutes is how long a paused game waits before being ended
+	DisconnectGracePeriod   time.Duration
+	ReconnectTimeoutMinutes int
+
+	SessionSecret string
+	// SessionPreviousSecrets are former session secrets still accepted while rotating
+	SessionPreviousSecrets []string
+	AllowedOrigins         []string
+	LogLevel               string
+
+	// AppBaseURL is the public URL of the app, used for the links in outgoing emails
+	AppBaseURL string
+
+	// Mailer sends account emails: file (written to MailDir, or logged when empty) or smtp
+	Mailer       string
+	MailDir      string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUser     string
+	SMTPPassword string
+
+	// TranslationDir is the directory holding the static/i18n/*.json files
+	TranslationDir string
+	// StaticDir is served under /static
+	StaticDir string
+
+	// ShutdownTimeout bounds how long the server waits for in-flight requests on SIGTERM
+	ShutdownTimeout time.Duration
+}
+
+// Load reads .env (if present) and the process environment into a validated Config
+// Example usage:
+//
+//	cfg, err := config.Load()
+//	if err != nil {
+//	    log.Fatalf("❌ Invalid configuration: %v", err)
+//	}
+func Load() (*Config, error) {
+	// .env is optional - real environment variables always take precedence
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	return FromEnv(os.Getenv)
+}
+
+// FromEnv builds a Config from a lookup function (os.Getenv in production, a map in tests)
+func FromEnv(getenv func(string) string) (*Config, error) {
+	var errs []error
+
+	cfg := &Config{
+		Env:                    strings.ToLower(valueOr(getenv("ENV"), EnvDevelopment)),
+		SupabaseURL:            strings.TrimSpace(getenv("SUPABASE_URL")),
+		SupabaseAnonKey:        strings.TrimSpace(getenv("SUPABASE_ANON_KEY")),
+		SupabaseServiceRoleKey: strings.TrimSpace(getenv("SUPABASE_SERVICE_ROLE_KEY")),
+		DatabaseBackend:        strings.ToLower(valueOr(getenv("DATABASE_BACKEND"), BackendSupabase)),
+		DatabaseURL:            strings.TrimSpace(getenv("DATABASE_URL")),
+		QuestionHistoryScope:   strings.ToLower(valueOr(getenv("QUESTION_HISTORY_SCOPE"), HistoryScopePair)),
+		RealtimeBroker:         strings.ToLower(valueOr(getenv("REALTIME_BROKER"), BrokerLocal)),
+		SessionSecret:          getenv("SESSION_SECRET"),
+		SessionPreviousSecrets: splitList(getenv("SESSION_PREVIOUS_SECRETS")),
+		AllowedOrigins:         splitList(getenv("ALLOWED_ORIGINS")),
+		LogLevel:               strings.ToLower(valueOr(getenv("LOG_LEVEL"), "info")),
+		Mailer:                 strings.ToLower(valueOr(getenv("MAILER"), MailerFile)),
+		MailDir:                strings.TrimSpace(getenv("MAIL_DIR")),
+		MailFrom:               valueOr(getenv("SMTP_FROM"), "noreply@localhost"),
+		SMTPHost:               strings.TrimSpace(getenv("SMTP_HOST")),
+		SMTPUser:               strings.TrimSpace(getenv("SMTP_USER")),
+		SMTPPassword:           getenv("SMTP_PASSWORD"),
+		TranslationDir:         valueOr(getenv("TRANSLATION_DIR"), "./static/i18n"),
+		StaticDir:              valueOr(getenv("STATIC_DIR"), "static"),
+	}
+
+	port, err := strconv.Atoi(valueOr(getenv("PORT"), "8080"))
+	if err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("PORT must be a number between 1 and 65535, got %q", getenv("PORT")))
+	}
+	cfg.Port = port
+	cfg.AppBaseURL = strings.TrimRight(valueOr(getenv("APP_BASE_URL"), "http://localhost:"+strconv.Itoa(port)), "/")
+
+	cfg.SMTPPort, err = strconv.Atoi(valueOr(getenv("SMTP_PORT"), "587"))
+	if err != nil || cfg.SMTPPort < 1 || cfg.SMTPPort > 65535 {
+		errs = append(errs, fmt.Errorf("SMTP_PORT must be a number between 1 and 65535, got %q", getenv("SMTP_PORT")))
+	}
+
+	cfg.DatabaseMaxConns, err = strconv.Atoi(valueOr(getenv("DATABASE_MAX_CONNS"), "10"))
+	if err != nil || cfg.DatabaseMaxConns < 1 {
+		errs = append(errs, fmt.Errorf("DATABASE_MAX_CONNS must be a positive number, got %q", getenv("DATABASE_MAX_CONNS")))
+	}
+
+	cfg.EventReplaySize, err = strconv.Atoi(valueOr(getenv("EVENT_REPLAY_SIZE"), "200"))
+	if err != nil || cfg.EventReplaySize < 1 {
+		errs = append(errs, fmt.Errorf("EVENT_REPLAY_SIZE must be a positive number, got %q", getenv("EVENT_REPLAY_SIZE")))
+	}
+
+	cfg.PersistRoomEvents, err = strconv.ParseBool(valueOr(getenv("PERSIST_ROOM_EVENTS"), "false"))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("PERSIST_ROOM_EVENTS must be true or false, got %q", getenv("PERSIST_ROOM_EVENTS")))
+	}
+
+	cfg.Replicas, err = strconv.Atoi(valueOr(getenv("REPLICAS"), "1"))
+	if err != nil || cfg.Replicas < 1 {
+		errs = append(errs, fmt.Errorf("REPLICAS must be a positive number, got %q", getenv("REPLICAS")))
+	}
+
+	cfg.DisconnectGracePeriod, err = time.ParseDuration(valueOr(getenv("DISCONNECT_GRACE_PERIOD"), "15s"))
+	if err != nil || cfg.DisconnectGracePeriod <= 0 {
+		errs = append(errs, fmt.Errorf("DISCONNECT_GRACE_PERIOD must be a positive duration (e.g. 15s), got %q", getenv("DISCONNECT_GRACE_PERIOD")))
+	}
+
+	cfg.ReconnectTimeoutMinutes, err = strconv.Atoi(valueOr(getenv("RECONNECT_TIMEOUT_MINUTES"), "5"))
+	if err != nil || cfg.ReconnectTimeoutMinutes < 1 {
+		errs = append(errs, fmt.Errorf("RECONNECT_TIMEOUT_MINUTES must be a positive number, got %q", getenv("RECONNECT_TIMEOUT_MINUTES")))
+	}
+
+	cfg.ShutdownTimeout, err = time.ParseDuration(valueOr(getenv("SHUTDOWN_TIMEOUT"), "15s"))
+	if err != nil || cfg.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_TIMEOUT must be a positive duration (e.g. 15s), got %q", getenv("SHUTDOWN_TIMEOUT")))
+	}
+
+	if err := cfg.validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return cfg, nil
+}
+
+// validate checks the cross-field rules that don't depend on parsing
+func (c *Config) validate() error {
+	var errs []error
+
+	switch c.Env {
+	case EnvDevelopment, EnvProduction, EnvTest:
+	default:
+		errs = append(errs, fmt.Errorf("ENV must be one of %s, %s or %s, got %q", EnvDevelopment, EnvProduction, EnvTest, c.Env))
+	}
+
+	if c.SupabaseURL == "" {
+		errs = append(errs, errors.New("SUPABASE_URL is required"))
+	} else if u, err := url.Parse(c.SupabaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("SUPABASE_URL must be an absolute URL, got %q", c.SupabaseURL))
+	}
+
+	if c.SupabaseServiceRoleKey == "" {
+		errs = append(errs, errors.New("SUPABASE_SERVICE_ROLE_KEY is required"))
+	}
+
+	switch c.DatabaseBackend {
+	case BackendSupabase:
+	case BackendPostgres:
+		if c.DatabaseURL == "" {
+			errs = append(errs, errors.New("DATABASE_URL is required when DATABASE_BACKEND is postgres"))
+		}
+		// The realtime listener keeps one pooled connection for good; requests need the rest
+		if c.DatabaseMaxConns < minPostgresConns {
+			errs = append(errs, fmt.Errorf("DATABASE_MAX_CONNS must be at least %d when DATABASE_BACKEND is postgres, got %d", minPostgresConns, c.DatabaseMaxConns))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("DATABASE_BACKEND must be %s or %s, got %q", BackendSupabase, BackendPostgres, c.DatabaseBackend))
+	}
+
+	switch c.QuestionHistoryScope {
+	case HistoryScopePair, HistoryScopeUser:
+	default:
+		errs = append(errs, fmt.Errorf("QUESTION_HISTORY_SCOPE must be %s or %s, got %q", HistoryScopePair, HistoryScopeUser, c.QuestionHistoryScope))
+	}
+
+	switch c.RealtimeBroker {
+	case BrokerLocal:
+	case BrokerPostgres:
+		if c.DatabaseURL == "" {
+			errs = append(errs, errors.New("DATABASE_URL is required when REALTIME_BROKER is postgres"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("REALTIME_BROKER must be %s or %s, got %q", BrokerLocal, BrokerPostgres, c.RealtimeBroker))
+	}
+	if c.Replicas > 1 && c.RealtimeBroker != BrokerPostgres {
+		errs = append(errs, fmt.Errorf("REALTIME_BROKER must be %s when REPLICAS is %d: events and the job scheduler lock are shared through Postgres", BrokerPostgres, c.Replicas))
+	}
+
+	if u, err := url.Parse(c.AppBaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("APP_BASE_URL must be an absolute URL, got %q", c.AppBaseURL))
+	}
+
+	switch c.Mailer {
+	case MailerFile:
+	case MailerSMTP:
+		if c.SMTPHost == "" {
+			errs = append(errs, errors.New("SMTP_HOST is required when MAILER is smtp"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("MAILER must be %s or %s, got %q", MailerFile, MailerSMTP, c.Mailer))
+	}
+
+	if c.IsProduction() {
+		if len(c.SessionSecret) < minSessionSecretLength {
+			errs = append(errs, fmt.Errorf("SESSION_SECRET must be at least %d characters in production", minSessionSecretLength))
+		}
+		for _, origin := range c.AllowedOrigins {
+			if origin == "*" {
+				errs = append(errs, errors.New("ALLOWED_ORIGINS must not contain * in production"))
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsDevelopment reports whether the server runs in development mode
+func (c *Config) IsDevelopment() bool {
+	return c.Env == EnvDevelopment
+}
+
+// IsProduction reports whether the server runs in production mode
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// Addr returns the listen address for the HTTP server
+func (c *Config) Addr() string {
+	return ":" + strconv.Itoa(c.Port)
+}
+
+// valueOr returns value unless it is blank, in which case fallback is returned
+func valueOr(value, fallback string) string {
+	if strings.TrimSpace(value) == "" {
+		return fallback
+	}
+	return strings.TrimSpace(value)
+}
+
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func envFrom(values map[string]string) func(string) string {
+	return func(key string) string { return values[key] }
+}
+
+func validEnv() map[string]string {
+	return map[string]string{
+		"SUPABASE_URL":              "https://example.supabase.co",
+		"SUPABASE_SERVICE_ROLE_KEY": "service-role-key",
+	}
+}
+
+func TestFromEnv_Defaults(t *testing.T) {
+	cfg, err := FromEnv(envFrom(validEnv()))
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Errorf("expected default port 8080, got %d", cfg.Port)
+	}
+	if !cfg.IsDevelopment() {
+		t.Errorf("expected development env by default, got %q", cfg.Env)
+	}
+	if cfg.ShutdownTimeout != 15*time.Second {
+		t.Errorf("expected 15s shutdown timeout, got %s", cfg.ShutdownTimeout)
+	}
+	if cfg.Addr() != ":8080" {
+		t.Errorf("expected :8080, got %s", cfg.Addr())
+	}
+	if cfg.DatabaseBackend != BackendSupabase || cfg.DatabaseMaxConns != 10 {
+		t.Errorf("expected supabase backend with 10 conns, got %s/%d", cfg.DatabaseBackend, cfg.DatabaseMaxConns)
+	}
+	if cfg.QuestionHistoryScope != HistoryScopePair {
+		t.Errorf("expected pair question history by default, got %q", cfg.QuestionHistoryScope)
+	}
+	if cfg.RealtimeBroker != BrokerLocal || cfg.Replicas != 1 {
+		t.Errorf("expected one instance with the local realtime broker by default, got %q/%d", cfg.RealtimeBroker, cfg.Replicas)
+	}
+	if cfg.EventReplaySize != 200 || cfg.PersistRoomEvents {
+		t.Errorf("expected 200 in-memory replay events by default, got %d (persist %v)", cfg.EventReplaySize, cfg.PersistRoomEvents)
+	}
+	if cfg.DisconnectGracePeriod != 15*time.Second || cfg.ReconnectTimeoutMinutes != 5 {
+		t.Errorf("expected 15s grace and 5 minute reconnect timeout, got %s/%d", cfg.DisconnectGracePeriod, cfg.ReconnectTimeoutMinutes)
+	}
+	if cfg.Mailer != MailerFile || cfg.MailDir != "" || cfg.SMTPPort != 587 {
+		t.Errorf("expected file mailer logging to stdout, got %q/%q (smtp port %d)", cfg.Mailer, cfg.MailDir, cfg.SMTPPort)
+	}
+	if cfg.AppBaseURL != "http://localhost:8080" {
+		t.Errorf("expected app base URL on the listen port, got %q", cfg.AppBaseURL)
+	}
+}
+
+func TestFromEnv_ParsesValues(t *testing.T) {
+	env := validEnv()
+	env["PORT"] = "9090"
+	env["ENV"] = "Production"
+	env["SESSION_SECRET"] = strings.Repeat("s", 32)
+	env["SESSION_PREVIOUS_SECRETS"] = "old-secret-1, old-secret-2"
+	env["ALLOWED_ORIGINS"] = "https://a.example, https://b.example,"
+	env["SHUTDOWN_TIMEOUT"] = "3s"
+	env["APP_BASE_URL"] = "https://couples.example/"
+	env["MAILER"] = "SMTP"
+	env["SMTP_HOST"] = "smtp.example"
+
+	cfg, err := FromEnv(envFrom(env))
+	if err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	if cfg.Port != 9090 || !cfg.IsProduction() || cfg.ShutdownTimeout != 3*time.Second {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if len(cfg.AllowedOrigins) != 2 || cfg.AllowedOrigins[1] != "https://b.example" {
+		t.Errorf("unexpected origins: %v", cfg.AllowedOrigins)
+	}
+	if len(cfg.SessionPreviousSecrets) != 2 || cfg.SessionPreviousSecrets[1] != "old-secret-2" {
+		t.Errorf("unexpected previous session secrets: %v", cfg.SessionPreviousSecrets)
+	}
+	if cfg.AppBaseURL != "https://couples.example" || cfg.Mailer != MailerSMTP {
+		t.Errorf("unexpected mail config: %q/%q", cfg.AppBaseURL, cfg.Mailer)
+	}
+}
+
+func TestFromEnv_ValidationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(map[string]string)
+		wantErr string
+	}{
+		{"missing supabase url", func(e map[string]string) { delete(e, "SUPABASE_URL") }, "SUPABASE_URL is required"},
+		{"relative supabase url", func(e map[string]string) { e["SUPABASE_URL"] = "localhost" }, "SUPABASE_URL must be an absolute URL"},
+		{"missing service key", func(e map[string]string) { delete(e, "SUPABASE_SERVICE_ROLE_KEY") }, "SUPABASE_SERVICE_ROLE_KEY is required"},
+		{"bad port", func(e map[string]string) { e["PORT"] = "http" }, "PORT must be a number"},
+		{"port out of range", func(e map[string]string) { e["PORT"] = "70000" }, "PORT must be a number"},
+		{"unknown env", func(e map[string]string) { e["ENV"] = "staging" }, "ENV must be one of"},
+		{"bad shutdown timeout", func(e map[string]string) { e["SHUTDOWN_TIMEOUT"] = "soon" }, "SHUTDOWN_TIMEOUT"},
+		{"unknown backend", func(e map[string]string) { e["DATABASE_BACKEND"] = "mysql" }, "DATABASE_BACKEND must be"},
+		{"postgres without url", func(e map[string]string) { e["DATABASE_BACKEND"] = "postgres" }, "DATABASE_URL is required"},
+		{"bad pool size", func(e map[string]string) { e["DATABASE_MAX_CONNS"] = "0" }, "DATABASE_MAX_CONNS"},
+		{"postgres pool too small", func(e map[string]string) {
+			e["DATABASE_BACKEND"] = "postgres"
+			e["DATABASE_URL"] = "postgres://localhost/couples"
+			e["DATABASE_MAX_CONNS"] = "2"
+		}, "DATABASE_MAX_CONNS must be at least 3"},
+		{"unknown history scope", func(e map[string]string) { e["QUESTION_HISTORY_SCOPE"] = "room" }, "QUESTION_HISTORY_SCOPE must be"},
+		{"unknown realtime broker", func(e map[string]string) { e["REALTIME_BROKER"] = "redis" }, "REALTIME_BROKER must be"},
+		{"postgres broker without url", func(e map[string]string) { e["REALTIME_BROKER"] = "postgres" }, "DATABASE_URL is required when REALTIME_BROKER"},
+		{"bad replica count", func(e map[string]string) { e["REPLICAS"] = "0" }, "REPLICAS must be"},
+		{"replicas without postgres broker", func(e map[string]string) { e["REPLICAS"] = "3" }, "REALTIME_BROKER must be postgres when REPLICAS is 3"},
+		{"bad replay size", func(e map[string]string) { e["EVENT_REPLAY_SIZE"] = "-1" }, "EVENT_REPLAY_SIZE"},
+		{"bad persist flag", func(e map[string]string) { e["PERSIST_ROOM_EVENTS"] = "maybe" }, "PERSIST_ROOM_EVENTS"},
+		{"bad grace period", func(e map[string]string) { e["DISCONNECT_GRACE_PERIOD"] = "0s" }, "DISCONNECT_GRACE_PERIOD"},
+		{"bad reconnect timeout", func(e map[string]string) { e["RECONNECT_TIMEOUT_MINUTES"] = "never" }, "RECONNECT_TIMEOUT_MINUTES"},
+		{"relative app base url", func(e map[string]string) { e["APP_BASE_URL"] = "couples.example" }, "APP_BASE_URL must be an absolute URL"},
+		{"unknown mailer", func(e map[string]string) { e["MAILER"] = "sendgrid" }, "MAILER must be"},
+		{"smtp without host", func(e map[string]string) { e["MAILER"] = "smtp" }, "SMTP_HOST is required"},
+		{"bad smtp port", func(e map[string]string) { e["SMTP_PORT"] = "mail" }, "SMTP_PORT"},
+		{"short production secret", func(e map[string]string) {
+			e["ENV"] = "production"
+			e["SESSION_SECRET"] = "short"
+		}, "SESSION_SECRET must be at least"},
+		{"wildcard origin in production", func(e map[string]string) {
+			e["ENV"] = "production"
+			e["SESSION_SECRET"] = strings.Repeat("s", 32)
+			e["ALLOWED_ORIGINS"] = "*"
+		}, "ALLOWED_ORIGINS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := validEnv()
+			tt.mutate(env)
+
+			_, err := FromEnv(envFrom(env))
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFromEnv_ReportsAllErrors(t *testing.T) {
+	_, err := FromEnv(envFrom(map[string]string{"PORT": "nope"}))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	for _, want := range []string{"PORT", "SUPABASE_URL", "SUPABASE_SERVICE_ROLE_KEY"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in joined error, got %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/viewmodels"
+	"github.com/hekigan/couples/internal/views/pages"
+	authPages "github.com/hekigan/couples/internal/views/pages/auth"
+	"github.com/labstack/echo/v4"
+)
+
+// ForgotPasswordHandler displays the form asking for a password reset link
+func (h *Handler) ForgotPasswordHandler(c echo.Context) error {
+	data := NewTemplateData(c)
+	data.Title = "Forgot Password - Couple Card Game"
+	return h.RenderTemplComponent(c, authPages.ForgotPasswordPage(data))
+}
+
+// ForgotPasswordPostHandler mails a password reset link
+// The answer is the same whether or not the email has an account.
+func (h *Handler) ForgotPasswordPostHandler(c echo.Context) error {
+	data := NewTemplateData(c)
+	data.Title = "Forgot Password - Couple Card Game"
+
+	email := c.FormValue("email")
+	if email == "" {
+		data.Error = "Email is required"
+		return h.RenderTemplComponent(c, authPages.ForgotPasswordPage(data))
+	}
+
+	lang, _ := middleware.GetLanguage(c)
+	if err := h.AccountService.RequestPasswordReset(c.Request().Context(), email, lang); err != nil {
+		log.Printf("Failed to send password reset email: %v", err)
+		data.Error = "We could not send the email. Please try again later."
+		return h.RenderTemplComponent(c, authPages.ForgotPasswordPage(data))
+	}
+
+	data.Success = "If an account uses this email, a link to reset the password is on its way."
+	return h.RenderTemplComponent(c, authPages.ForgotPasswordPage(data))
+}
+
+// ResetPasswordHandler displays the new password form for a reset link
+func (h *Handler) ResetPasswordHandler(c echo.Context) error {
+	token := c.QueryParam("token")
+
+	data := NewTemplateData(c)
+	data.Title = "Reset Password - Couple Card Game"
+	if err := h.AccountService.CheckPasswordResetToken(c.Request().Context(), token); err != nil {
+		data.Error = linkError(err)
+	} else {
+		data.Data = token
+	}
+	return h.RenderTemplComponent(c, authPages.ResetPasswordPage(data))
+}
+
+// ResetPasswordPostHandler sets the new password
+func (h *Handler) ResetPasswordPostHandler(c echo.Context) error {
+	token := c.FormValue("token")
+	password := c.FormValue("password")
+
+	data := NewTemplateData(c)
+	data.Title = "Reset Password - Couple Card Game"
+	data.Data = token
+
+	// Same rules as signup
+	if password != c.FormValue("password_confirm") {
+		data.Error = "Passwords do not match"
+		return h.RenderTemplComponent(c, authPages.ResetPasswordPage(data))
+	}
+	if len(password) < 6 {
+		data.Error = "Password must be at least 6 characters long"
+		return h.RenderTemplComponent(c, authPages.ResetPasswordPage(data))
+	}
+
+	if err := h.AccountService.ResetPassword(c.Request().Context(), token, password); err != nil {
+		log.Printf("Password reset failed: %v", err)
+		data.Error = linkError(err)
+		data.Data = nil
+		return h.RenderTemplComponent(c, authPages.ResetPasswordPage(data))
+	}
+
+	data.Data = nil
+	data.Success = "Your password has been changed. You can now log in with it."
+	return h.RenderTemplComponent(c, authPages.ResetPasswordPage(data))
+}
+
+// VerifyEmailHandler confirms an email address (or an email change) from a mailed link
+func (h *Handler) VerifyEmailHandler(c echo.Context) error {
+	data := NewTemplateData(c)
+	data.Title = "Verify Email - Couple Card Game"
+
+	user, err := h.AccountService.VerifyEmail(c.Request().Context(), c.QueryParam("token"))
+	if err != nil {
+		log.Printf("Email verification failed: %v", err)
+		data.Error = linkError(err)
+		return h.RenderTemplComponent(c, authPages.VerifyEmailPage(data))
+	}
+
+	// Keep the session in step with a changed email
+	if sess, err := middleware.GetSession(c); err == nil && sess.Values["user_id"] == user.ID.String() && user.Email != nil {
+		sess.Values["email"] = *user.Email
+		if err := middleware.SaveSession(c, sess); err != nil {
+			log.Printf("Failed to save session: %v", err)
+		}
+	}
+
+	data.Success = "Your email address is confirmed."
+	return h.RenderTemplComponent(c, authPages.VerifyEmailPage(data))
+}
+
+// ResendVerificationHandler mails a new verification link for the current user's email
+func (h *Handler) ResendVerificationHandler(c echo.Context) error {
+	return h.renderProfileAction(c, func(user *models.User, lang string) (string, error) {
+		err := h.AccountService.SendVerificationEmail(c.Request().Context(), user, lang)
+		return "A verification link has been sent to your email address.", err
+	})
+}
+
+// ChangeEmailHandler mails a confirmation link to the new address the user entered
+func (h *Handler) ChangeEmailHandler(c echo.Context) error {
+	return h.renderProfileAction(c, func(user *models.User, lang string) (string, error) {
+		err := h.AccountService.RequestEmailChange(c.Request().Context(), user.ID, c.FormValue("email"), lang)
+		return "Follow the link we sent to the new address to confirm the change.", err
+	})
+}
+
+// renderProfileAction runs an account action for the current user and renders the profile
+// page with its outcome
+func (h *Handler) renderProfileAction(c echo.Context, action func(user *models.User, lang string) (string, error)) error {
+	ctx := c.Request().Context()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	user, err := h.UserService.GetUserByID(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load profile")
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "My Profile"
+	data.User = user
+	data.Data = &viewmodels.ProfileData{User: user, Languages: h.LanguageService.Enabled()}
+
+	lang, _ := middleware.GetLanguage(c)
+	message, err := action(user, lang)
+	switch {
+	case errors.Is(err, models.ErrEmailInUse):
+		data.Error = "This email is already used by another account"
+	case errors.Is(err, models.ErrEmailRequired):
+		data.Error = "Please enter an email address"
+	case err != nil:
+		log.Printf("Account email for user %s failed: %v", userID, err)
+		data.Error = "We could not send the email. Please try again later."
+	default:
+		data.Success = message
+	}
+	return h.RenderTemplComponent(c, pages.ProfilePage(data))
+}
+
+// linkError is the message shown when a mailed link cannot be used
+func linkError(err error) string {
+	if errors.Is(err, models.ErrInvalidToken) {
+		return "This link is invalid or has expired. Please request a new one."
+	}
+	return "Something went wrong. Please try again later."
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 
@@ -235,10 +236,11 @@ func (h *Handler) AdminQuestionsHandler(c echo.Context) error {
 			allIDs[i] = q.ID
 		}
 		allTranslationStatus, _ := h.QuestionService.GetQuestionTranslationStatus(ctx, allIDs)
+		languageCount := len(h.LanguageService.Enabled())
 		missingTranslationsCount := 0
 		for _, count := range allTranslationStatus {
-			if count < 3 {
-				missingTranslationsCount += (3 - count)
+			if count < languageCount {
+				missingTranslationsCount += languageCount - count
 			}
 		}
 
@@ -302,6 +304,7 @@ func (h *Handler) AdminQuestionsHandler(c echo.Context) error {
 			TotalPages:               totalPages,
 			ItemsPerPage:             perPage,
 			MissingTranslationsCount: missingTranslationsCount,
+			LanguageCount:            languageCount,
 			// Pagination template fields
 			BaseURL:         "/admin/api/questions/list",
 			PageURL:         "/admin/questions",
@@ -408,7 +411,7 @@ func (h *Handler) AdminRoomsHandler(c echo.Context) error {
 	offset := (page - 1) * perPage
 
 	// Fetch rooms list for SSR with default filters (all statuses, no search, sort by created_at desc)
-	defaultStatuses := []string{"waiting", "ready", "playing", "finished"}
+	defaultStatuses := models.RoomStateNames()
 	rooms, err := h.AdminService.ListAllRooms(ctx, perPage, offset, "", defaultStatuses, "created_at", "desc")
 	if err != nil {
 		log.Printf("⚠️ Failed to fetch rooms: %v", err)
@@ -464,7 +467,7 @@ func (h *Handler) AdminRoomsHandler(c echo.Context) error {
 				Name:          room.Name,
 				Owner:         owner,
 				Guest:         guest,
-				Status:        room.Status,
+				Status:        string(room.Status),
 				CreatedAt:     room.CreatedAt.Format("2006-01-02 15:04"),
 				CategoryNames: categoryNames,
 			}
@@ -508,12 +511,28 @@ func (h *Handler) AdminTranslationsHandler(c echo.Context) error {
 		Title:     "Translation Management",
 		User:      GetTemplateUser(c), // Use helper to avoid nil interface gotcha
 		IsAdmin:   true,
+		Data:      h.TranslationsListData(c.QueryParam("lang")),
 		Env:       os.Getenv("ENV"),
 		CSRFToken: GetCSRFToken(c),
 	}
 	return h.RenderTemplComponent(c, adminPages.TranslationsPage(data))
 }
 
+// TranslationsListData builds the admin translations list for a language (English when lang is unknown)
+func (h *Handler) TranslationsListData(lang string) *services.TranslationsListData {
+	languages := h.I18nService.Languages()
+	if !slices.Contains(languages, lang) {
+		lang = services.DefaultLanguage
+	}
+	return &services.TranslationsListData{
+		Languages:    languages,
+		Registry:     h.LanguageService.All(),
+		SelectedLang: lang,
+		Entries:      h.I18nService.Entries(lang),
+		MissingKeys:  h.I18nService.MissingKeys(),
+	}
+}
+
 // AdminRoutesHandler displays all application routes
 func (h *Handler) AdminRoutesHandler(c echo.Context) error {
 	currentUser := GetTemplateUser(c)
@@ -610,14 +629,63 @@ func (h *Handler) AdminRoutesHandler(c echo.Context) error {
 	return h.RenderTemplComponent(c, adminPages.RoutesPage(data))
 }
 
+// AdminJobsHandler displays the scheduled maintenance jobs and their latest runs
+func (h *Handler) AdminJobsHandler(c echo.Context) error {
+	jobsData, err := h.JobsListData(c.Request().Context())
+	if err != nil {
+		log.Printf("⚠️ Failed to fetch job runs: %v", err)
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "Scheduled Jobs"
+	data.User = GetTemplateUser(c)
+	data.Data = jobsData
+
+	return h.RenderTemplComponent(c, adminPages.JobsPage(data))
+}
+
+// jobTimeFormat is how job times are shown to admins
+const jobTimeFormat = "2006-01-02 15:04:05"
+
+// JobsListData builds the jobs list for the admin page and its fragment
+// A failure to read the run history still lists every job, without its runs.
+func (h *Handler) JobsListData(ctx context.Context) (*services.JobsListData, error) {
+	statuses, err := h.Scheduler.Jobs(ctx)
+
+	jobs := make([]services.AdminJobInfo, len(statuses))
+	for i, status := range statuses {
+		job := services.AdminJobInfo{
+			Name:    status.Name,
+			Spec:    status.Spec,
+			Running: status.Running,
+		}
+		if !status.NextRun.IsZero() {
+			job.NextRun = status.NextRun.Format(jobTimeFormat)
+		}
+		if run := status.LastRun; run != nil {
+			job.LastRun = run.StartedAt.Format(jobTimeFormat)
+			job.Duration = run.Duration().String()
+			job.LastStatus = run.Status
+			job.LastResult = run.Result
+		}
+		if run := status.LastFailure; run != nil {
+			job.LastError = run.Error
+			job.LastErrorAt = run.StartedAt.Format(jobTimeFormat)
+		}
+		jobs[i] = job
+	}
+
+	return &services.JobsListData{Jobs: jobs}, err
+}
+
 // Helper functions (copied from cmd/server/routes_registry.go to avoid import issues)
 
 // determineVersion identifies the route version from its path
 func determineVersion(path string) string {
-	if len(path) >= 8 && path[:8] == "/api/v1/" {
+	if strings.HasPrefix(path, "/api/v1/") {
 		return "v1"
 	}
-	if len(path) >= 15 && path[:15] == "/admin/api/v1/" {
+	if strings.HasPrefix(path, "/admin/api/v1/") {
 		return "admin-v1"
 	}
 	return "unversioned"
@@ -13,6 +13,7 @@ import (
 // - admin_rooms.go: Room management (3 handlers)
 // - admin_stats.go: Dashboard statistics (1 handler)
 // - admin_bulk.go: Bulk operations (4 handlers)
+// - admin_jobs.go: Scheduled jobs (2 handlers)
 type AdminAPIHandler struct {
 	handler         *handlers.Handler
 	adminService    *services.AdminService
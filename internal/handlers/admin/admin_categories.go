@@ -2,8 +2,10 @@ package admin
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/hekigan/couples/internal/handlers"
 	"github.com/hekigan/couples/internal/models"
@@ -81,9 +83,10 @@ func (ah *AdminAPIHandler) ListCategoriesHandler(c echo.Context) error {
 func (ah *AdminAPIHandler) GetCategoryCreateFormHandler(c echo.Context) error {
 	// Create empty form data for create mode
 	data := services.CategoryFormData{
-		ID:    "", // Empty ID indicates create mode
-		Key:   "",
-		Label: "",
+		ID:     "", // Empty ID indicates create mode
+		Key:    "",
+		Label:  "",
+		Weight: 1,
 	}
 
 	html, err := ah.handler.RenderTemplFragment(c, adminFragments.CategoryForm(&data))
@@ -111,9 +114,10 @@ func (ah *AdminAPIHandler) GetCategoryEditFormHandler(c echo.Context) error {
 	}
 
 	data := services.CategoryFormData{
-		ID:    category.ID.String(),
-		Key:   category.Key,
-		Label: category.Label,
+		ID:     category.ID.String(),
+		Key:    category.Key,
+		Label:  category.Label,
+		Weight: category.Weight,
 	}
 
 	html, err := ah.handler.RenderTemplFragment(c, adminFragments.CategoryForm(&data))
@@ -135,10 +139,16 @@ func (ah *AdminAPIHandler) UpdateCategoryHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	weight, err := parseCategoryWeight(c.FormValue("weight"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	category := &models.Category{
-		ID:    categoryID,
-		Key:   c.FormValue("key"),
-		Label: c.FormValue("label"),
+		ID:     categoryID,
+		Key:    c.FormValue("key"),
+		Label:  c.FormValue("label"),
+		Weight: weight,
 	}
 
 	if err := ah.categoryService.UpdateCategory(ctx, category); err != nil {
@@ -154,9 +164,15 @@ func (ah *AdminAPIHandler) UpdateCategoryHandler(c echo.Context) error {
 func (ah *AdminAPIHandler) CreateCategoryHandler(c echo.Context) error {
 	ctx := context.Background()
 
+	weight, err := parseCategoryWeight(c.FormValue("weight"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
 	category := &models.Category{
-		Key:   c.FormValue("key"),
-		Label: c.FormValue("label"),
+		Key:    c.FormValue("key"),
+		Label:  c.FormValue("label"),
+		Weight: weight,
 	}
 
 	if err := ah.categoryService.CreateCategory(ctx, category); err != nil {
@@ -186,3 +202,15 @@ func (ah *AdminAPIHandler) DeleteCategoryHandler(c echo.Context) error {
 	// Return updated categories list
 	return ah.ListCategoriesHandler(c)
 }
+
+// parseCategoryWeight parses the optional weight form field (empty keeps the default of 1)
+func parseCategoryWeight(value string) (float64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight <= 0 {
+		return 0, errors.New("weight must be a positive number")
+	}
+	return weight, nil
+}
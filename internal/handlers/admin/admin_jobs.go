@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hekigan/couples/internal/services"
+	adminFragments "github.com/hekigan/couples/internal/views/fragments/admin"
+	"github.com/labstack/echo/v4"
+)
+
+// ListJobsHandler returns an HTML fragment with the scheduled jobs and their latest runs
+func (ah *AdminAPIHandler) ListJobsHandler(c echo.Context) error {
+	data, err := ah.handler.JobsListData(c.Request().Context())
+	if err != nil {
+		log.Printf("Error fetching job runs: %v", err)
+	}
+	return ah.renderJobsList(c, data)
+}
+
+// RunJobHandler starts a job immediately and returns the updated jobs list
+func (ah *AdminAPIHandler) RunJobHandler(c echo.Context) error {
+	name := c.Param("name")
+
+	message := fmt.Sprintf("Started %s.", name)
+	if err := ah.handler.Scheduler.RunNow(name); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "Job not found")
+		}
+		message = fmt.Sprintf("Not started: %v.", err)
+	}
+
+	data, err := ah.handler.JobsListData(c.Request().Context())
+	if err != nil {
+		log.Printf("Error fetching job runs: %v", err)
+	}
+	data.Message = message
+	return ah.renderJobsList(c, data)
+}
+
+// renderJobsList renders the jobs list fragment
+func (ah *AdminAPIHandler) renderJobsList(c echo.Context, data *services.JobsListData) error {
+	html, err := ah.handler.RenderTemplFragment(c, adminFragments.JobsList(data))
+	if err != nil {
+		log.Printf("Error rendering jobs list: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.HTML(http.StatusOK, html)
+}
@@ -84,10 +84,11 @@ func (ah *AdminAPIHandler) ListQuestionsHandler(c echo.Context) error {
 		allIDs[i] = q.ID
 	}
 	allTranslationStatus, _ := ah.questionService.GetQuestionTranslationStatus(ctx, allIDs)
+	languageCount := len(ah.handler.LanguageService.Enabled())
 	missingTranslationsCount := 0
 	for _, count := range allTranslationStatus {
-		if count < 3 {
-			missingTranslationsCount += (3 - count)
+		if count < languageCount {
+			missingTranslationsCount += languageCount - count
 		}
 	}
 
@@ -149,6 +150,7 @@ func (ah *AdminAPIHandler) ListQuestionsHandler(c echo.Context) error {
 		TotalCount:               total,
 		TotalPages:               totalPages,
 		MissingTranslationsCount: missingTranslationsCount,
+		LanguageCount:            languageCount,
 		// Pagination template fields
 		BaseURL:         "/admin/api/questions/list",
 		PageURL:         "/admin/questions",
@@ -188,17 +190,14 @@ func (ah *AdminAPIHandler) GetQuestionCreateFormHandler(c echo.Context) error {
 	}
 
 	// Create empty form data for new question
+	baseLanguage, translations := ah.questionLanguageFields(nil, services.DefaultLanguage)
 	data := services.QuestionFormData{
 		QuestionID:     "", // Empty indicates create mode
 		BaseQuestionID: "",
-		QuestionText:   "",
-		TranslationFR:  "",
-		TranslationJA:  "",
 		Categories:     categoryOptions,
-		LangEN:         true, // Default to English
-		LangFR:         false,
-		LangJA:         false,
-		SelectedLang:   "en",
+		BaseLanguage:   baseLanguage,
+		Translations:   translations,
+		SelectedLang:   services.DefaultLanguage,
 	}
 
 	html, err := ah.handler.RenderTemplFragment(c, adminFragments.QuestionForm(&data))
@@ -245,31 +244,14 @@ func (ah *AdminAPIHandler) GetQuestionEditFormHandler(c echo.Context) error {
 		}
 	}
 
-	// Prepare translation texts
-	var translationFR, translationJA string
-	if translations.French != nil {
-		translationFR = translations.French.Text
-	}
-	if translations.Japanese != nil {
-		translationJA = translations.Japanese.Text
-	}
-
-	// Determine which text to show in the main question field based on selected language
-	questionText := question.Text
-	if translations.English != nil && question.LanguageCode != "en" {
-		questionText = translations.English.Text // Always show English in main field for reference
-	}
-
+	// The base (English) text is always shown in the main field for reference
+	baseLanguage, translationFields := ah.questionLanguageFields(translations.Questions, question.LanguageCode)
 	data := services.QuestionFormData{
 		QuestionID:     question.ID.String(),
 		BaseQuestionID: question.BaseQuestionID.String(),
-		QuestionText:   questionText,
-		TranslationFR:  translationFR,
-		TranslationJA:  translationJA,
 		Categories:     categoryOptions,
-		LangEN:         question.LanguageCode == "en",
-		LangFR:         question.LanguageCode == "fr",
-		LangJA:         question.LanguageCode == "ja",
+		BaseLanguage:   baseLanguage,
+		Translations:   translationFields,
 		SelectedLang:   question.LanguageCode,
 	}
 
@@ -304,6 +286,9 @@ func (ah *AdminAPIHandler) UpdateQuestionHandler(c echo.Context) error {
 	}
 
 	langCode := c.FormValue("lang_code")
+	if _, ok := ah.handler.LanguageService.Get(langCode); !ok {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unknown language"})
+	}
 	questionText := c.FormValue("question_text")
 	translationText := c.FormValue("question_text_translation")
 
@@ -315,16 +300,9 @@ func (ah *AdminAPIHandler) UpdateQuestionHandler(c echo.Context) error {
 	}
 
 	// Determine which question to update based on selected language
-	var targetQuestion *models.Question
+	targetQuestion := translations.Questions[langCode]
 	textToUpdate := translationText
-
-	switch langCode {
-	case "fr":
-		targetQuestion = translations.French
-	case "ja":
-		targetQuestion = translations.Japanese
-	default:
-		targetQuestion = translations.English
+	if langCode == services.DefaultLanguage {
 		textToUpdate = questionText
 	}
 
@@ -343,8 +321,8 @@ func (ah *AdminAPIHandler) UpdateQuestionHandler(c echo.Context) error {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update question"})
 		}
 	} else {
-		// Create new translation (only for FR/JA, English should always exist)
-		if langCode == "en" {
+		// Create new translation (English, the base question, should always exist)
+		if langCode == services.DefaultLanguage {
 			return c.JSON(http.StatusBadRequest, map[string]string{"error": "English question not found"})
 		}
 
@@ -375,10 +353,8 @@ func (ah *AdminAPIHandler) CreateQuestionHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
 	}
 
-	// Get form values
-	questionTextEN := c.FormValue("question_text_en")
-	questionTextFR := c.FormValue("question_text_fr")
-	questionTextJA := c.FormValue("question_text_ja")
+	// Get form values (question_text_<lang code>)
+	questionTextEN := c.FormValue("question_text_" + services.DefaultLanguage)
 
 	if questionTextEN == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "English question text is required")
@@ -391,7 +367,7 @@ func (ah *AdminAPIHandler) CreateQuestionHandler(c echo.Context) error {
 	englishQuestion := &models.Question{
 		ID:             baseQuestionID,
 		CategoryID:     categoryID,
-		LanguageCode:   "en",
+		LanguageCode:   services.DefaultLanguage,
 		Text:           questionTextEN,
 		BaseQuestionID: baseQuestionID, // Self-reference for base question
 	}
@@ -401,35 +377,24 @@ func (ah *AdminAPIHandler) CreateQuestionHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create English question")
 	}
 
-	// Create French translation if provided
-	if questionTextFR != "" {
-		frenchQuestion := &models.Question{
-			ID:             uuid.New(),
-			CategoryID:     categoryID,
-			LanguageCode:   "fr",
-			Text:           questionTextFR,
-			BaseQuestionID: baseQuestionID, // Reference to English question
-		}
-
-		if err := ah.questionService.CreateQuestion(ctx, frenchQuestion); err != nil {
-			log.Printf("Error creating French translation: %v", err)
-			// Continue even if French creation fails
+	// Create the translations provided, one per enabled language
+	for _, language := range ah.handler.LanguageService.Enabled() {
+		text := c.FormValue("question_text_" + language.Code)
+		if language.Code == services.DefaultLanguage || text == "" {
+			continue
 		}
-	}
 
-	// Create Japanese translation if provided
-	if questionTextJA != "" {
-		japaneseQuestion := &models.Question{
+		translation := &models.Question{
 			ID:             uuid.New(),
 			CategoryID:     categoryID,
-			LanguageCode:   "ja",
-			Text:           questionTextJA,
+			LanguageCode:   language.Code,
+			Text:           text,
 			BaseQuestionID: baseQuestionID, // Reference to English question
 		}
 
-		if err := ah.questionService.CreateQuestion(ctx, japaneseQuestion); err != nil {
-			log.Printf("Error creating Japanese translation: %v", err)
-			// Continue even if Japanese creation fails
+		if err := ah.questionService.CreateQuestion(ctx, translation); err != nil {
+			log.Printf("Error creating %s translation: %v", language.Code, err)
+			// Continue even if a translation fails
 		}
 	}
 
@@ -455,3 +420,32 @@ func (ah *AdminAPIHandler) DeleteQuestionHandler(c echo.Context) error {
 	// Return updated questions list
 	return ah.ListQuestionsHandler(c)
 }
+
+// questionLanguageFields builds the question form's base language and translation fields from
+// the language versions of a question (nil for a new question)
+// Disabled languages are listed only when the question has a version in them.
+func (ah *AdminAPIHandler) questionLanguageFields(questions map[string]*models.Question, selected string) (services.QuestionLanguageField, []services.QuestionLanguageField) {
+	var base services.QuestionLanguageField
+	var translations []services.QuestionLanguageField
+	for _, language := range ah.handler.LanguageService.All() {
+		question := questions[language.Code]
+		if !language.Enabled && question == nil {
+			continue
+		}
+
+		field := services.QuestionLanguageField{
+			Code:     language.Code,
+			Name:     language.NativeName,
+			Selected: language.Code == selected,
+		}
+		if question != nil {
+			field.Text = question.Text
+		}
+		if language.Code == services.DefaultLanguage {
+			base = field
+		} else {
+			translations = append(translations, field)
+		}
+	}
+	return base, translations
+}
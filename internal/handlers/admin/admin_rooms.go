@@ -2,12 +2,14 @@ package admin
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"net/url"
 	"strings"
 
 	"github.com/hekigan/couples/internal/handlers"
+	"github.com/hekigan/couples/internal/models"
 	"github.com/hekigan/couples/internal/services"
 	"github.com/labstack/echo/v4"
 	adminFragments "github.com/hekigan/couples/internal/views/fragments/admin"
@@ -29,7 +31,7 @@ func (ah *AdminAPIHandler) ListRoomsHandler(c echo.Context) error {
 
 	// Default to all statuses if none selected
 	if len(statuses) == 0 {
-		statuses = []string{"waiting", "ready", "playing", "finished"}
+		statuses = models.RoomStateNames()
 	}
 
 	// Default sort to created_at DESC
@@ -95,7 +97,7 @@ func (ah *AdminAPIHandler) ListRoomsHandler(c echo.Context) error {
 			Name:          room.Name,
 			Owner:         owner,
 			Guest:         guest,
-			Status:        room.Status,
+			Status:        string(room.Status),
 			CreatedAt:     room.CreatedAt.Format("2006-01-02 15:04"),
 			CategoryNames: categoryNames,
 		}
@@ -161,6 +163,9 @@ func (ah *AdminAPIHandler) CloseRoomHandler(c echo.Context) error {
 
 	if err := ah.adminService.ForceCloseRoom(ctx, roomID); err != nil {
 		log.Printf("Error closing room: %v", err)
+		if errors.Is(err, models.ErrIllegalRoomTransition) {
+			return echo.NewHTTPError(http.StatusConflict, "Room is already closed")
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to close room")
 	}
 
@@ -168,6 +173,33 @@ func (ah *AdminAPIHandler) CloseRoomHandler(c echo.Context) error {
 	return ah.ListRoomsHandler(c)
 }
 
+// ResetRoomQuestionHistoryHandler forgets which questions a room's two players have seen together
+func (ah *AdminAPIHandler) ResetRoomQuestionHistoryHandler(c echo.Context) error {
+	ctx := context.Background()
+
+	// Extract ID from route
+	roomID, err := handlers.ExtractIDFromParam(c, "id")
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	room, err := ah.handler.RoomService.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Room not found")
+	}
+	if room.GuestID == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Room has no guest")
+	}
+
+	if err := ah.handler.QuestionService.ResetPairHistory(ctx, room.OwnerID, *room.GuestID); err != nil {
+		log.Printf("Error resetting question history: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reset question history")
+	}
+
+	// Return updated rooms list
+	return ah.ListRoomsHandler(c)
+}
+
 // DeleteRoomHandler deletes a room
 func (ah *AdminAPIHandler) DeleteRoomHandler(c echo.Context) error {
 	ctx := context.Background()
@@ -251,7 +283,7 @@ func (ah *AdminAPIHandler) GetRoomDetailsHandler(c echo.Context) error {
 		ID:            room.ID.String(),
 		ShortID:       room.ID.String()[:8],
 		Name:          room.Name,
-		Status:        room.Status,
+		Status:        string(room.Status),
 		Language:      room.Language,
 		MaxQuestions:  room.MaxQuestions,
 		CreatedAt:     room.CreatedAt.Format("2006-01-02 15:04:05"),
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/hekigan/couples/internal/handlers"
 	"github.com/hekigan/couples/internal/services"
@@ -237,3 +238,44 @@ func (ah *AdminAPIHandler) UpdateUserHandler(c echo.Context) error {
 	// Return updated users list
 	return ah.ListUsersHandler(c)
 }
+
+// ExpiredAnonymousUsersHandler returns the guests the anonymous user cleanup would delete,
+// as a dry run (?older_than_hours= overrides the job's threshold)
+func (ah *AdminAPIHandler) ExpiredAnonymousUsersHandler(c echo.Context) error {
+	olderThanHours := services.DefaultAnonymousUserTTLHours
+	if raw := c.QueryParam("older_than_hours"); raw != "" {
+		hours, err := strconv.Atoi(raw)
+		if err != nil || hours < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "older_than_hours must be a positive number")
+		}
+		olderThanHours = hours
+	}
+
+	users, err := ah.handler.UserService.FindExpiredAnonymousUsers(c.Request().Context(), olderThanHours)
+	if err != nil {
+		log.Printf("Error finding expired anonymous users: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to find expired users")
+	}
+
+	data := services.ExpiredAnonymousUsersData{
+		OlderThanHours: olderThanHours,
+		Users:          make([]services.AdminUserInfo, len(users)),
+	}
+	for i, user := range users {
+		data.Users[i] = services.AdminUserInfo{
+			ID:         user.ID.String(),
+			Username:   user.Username,
+			UserType:   "guest",
+			CreatedAt:  user.CreatedAt.Format("2006-01-02 15:04"),
+			LastActive: user.LastActiveAt().Format("2006-01-02 15:04"),
+		}
+	}
+
+	html, err := ah.handler.RenderTemplFragment(c, adminFragments.ExpiredAnonymousUsers(&data))
+	if err != nil {
+		log.Printf("Error rendering expired anonymous users: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.HTML(http.StatusOK, html)
+}
@@ -1,12 +1,26 @@
 package api
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/hekigan/couples/internal/models"
 	"github.com/hekigan/couples/internal/services"
 	"github.com/labstack/echo/v4"
 )
 
+// maxImportSize caps the size of an uploaded import file
+const maxImportSize = 5 << 20
+
 // CSVHandler handles CSV import/export
 type CSVHandler struct {
 	questionService *services.QuestionService
@@ -21,31 +35,115 @@ func NewCSVHandler(questionService *services.QuestionService, categoryService *s
 	}
 }
 
-// ExportQuestionsCSV exports questions to CSV
+// ExportQuestionsCSV exports every question, as CSV or (with ?format=json) JSON
+// The file can be edited and imported back.
 func (h *CSVHandler) ExportQuestionsCSV(c echo.Context) error {
+	records, err := h.questionService.ExportQuestions(c.Request().Context())
+	if err != nil {
+		log.Printf("Error exporting questions: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to export questions")
+	}
+
+	if c.QueryParam("format") == "json" {
+		c.Response().Header().Set("Content-Disposition", "attachment; filename=questions.json")
+		return c.JSON(http.StatusOK, records)
+	}
+
 	c.Response().Header().Set("Content-Type", "text/csv")
 	c.Response().Header().Set("Content-Disposition", "attachment; filename=questions.csv")
-	return c.String(http.StatusOK, "ID,Category ID,Category Name,Language,Question Text,Created At\n")
+	c.Response().WriteHeader(http.StatusOK)
+	return services.WriteQuestionsCSV(c.Response(), records)
 }
 
-// ImportQuestionsCSV imports questions from CSV
+// ImportQuestionsCSV imports questions from an uploaded CSV or JSON file
+// The file comes as the "file" form field or as the request body; JSON is recognized by a
+// .json file name, a JSON content type or format=json. With dry_run=true nothing is written.
+// The report lists row-level errors with 422, in which case nothing was written either.
 func (h *CSVHandler) ImportQuestionsCSV(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+	isJSON := c.FormValue("format") == "json" ||
+		strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON)
+
+	var body io.Reader = c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		upload, err := file.Open()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Failed to read the uploaded file")
+		}
+		defer upload.Close()
+		body = upload
+		isJSON = isJSON || strings.EqualFold(filepath.Ext(file.Filename), ".json")
+	}
+
+	// Read one byte past the cap so an oversized file is refused rather than parsed truncated
+	data, err := io.ReadAll(io.LimitReader(body, maxImportSize+1))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Failed to read the import file")
+	}
+	if len(data) > maxImportSize {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("The import file is larger than %d MB", maxImportSize>>20))
+	}
+
+	var records []services.QuestionRecord
+	if isJSON {
+		records, err = services.ParseQuestionsJSON(bytes.NewReader(data))
+	} else {
+		records, err = services.ParseQuestionsCSV(bytes.NewReader(data))
+	}
+	if errors.Is(err, models.ErrInvalidImportFile) {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Failed to read the import file")
+	}
+
+	report, err := h.questionService.ImportQuestions(c.Request().Context(), records, dryRun)
+	if err != nil {
+		log.Printf("Error importing questions: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to import questions")
+	}
+	if len(report.Errors) > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, report)
+	}
+	return c.JSON(http.StatusOK, report)
 }
 
 // GetImportTemplate downloads CSV template
 func (h *CSVHandler) GetImportTemplate(c echo.Context) error {
 	c.Response().Header().Set("Content-Type", "text/csv")
 	c.Response().Header().Set("Content-Disposition", "attachment; filename=questions_template.csv")
-	return c.String(http.StatusOK, "Category ID,Language,Question Text\n")
+	c.Response().WriteHeader(http.StatusOK)
+	return services.WriteQuestionsCSV(c.Response(), nil)
 }
 
 // ExportCategoriesCSV exports categories to CSV
 func (h *CSVHandler) ExportCategoriesCSV(c echo.Context) error {
+	categories, err := h.categoryService.GetCategories(c.Request().Context())
+	if err != nil {
+		log.Printf("Error exporting categories: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to export categories")
+	}
+
 	c.Response().Header().Set("Content-Type", "text/csv")
 	c.Response().Header().Set("Content-Disposition", "attachment; filename=categories.csv")
-	return c.String(http.StatusOK, "ID,Key,Icon,Created At\n")
-}
-
-
+	c.Response().WriteHeader(http.StatusOK)
 
+	writer := csv.NewWriter(c.Response())
+	if err := writer.Write([]string{"id", "key", "label", "weight", "created_at"}); err != nil {
+		return err
+	}
+	for _, category := range categories {
+		if err := writer.Write([]string{
+			category.ID.String(),
+			category.Key,
+			category.Label,
+			fmt.Sprint(category.Weight),
+			category.CreatedAt.Format(time.RFC3339),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -1,12 +1,23 @@
 package admin
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"slices"
 
 	"github.com/hekigan/couples/internal/handlers"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/services"
+	adminFragments "github.com/hekigan/couples/internal/views/fragments/admin"
 	"github.com/labstack/echo/v4"
 )
 
+// maxTranslationFileSize caps the size of an imported translation file
+const maxTranslationFileSize = 1 << 20
+
 // TranslationHandler handles translation admin operations
 type TranslationHandler struct {
 	handler *handlers.Handler
@@ -19,50 +30,177 @@ func NewTranslationHandler(h *handlers.Handler) *TranslationHandler {
 	}
 }
 
-// ListLanguagesHandler lists all languages
+// ListLanguagesHandler lists all languages, enabled or not, in display order
 func (h *TranslationHandler) ListLanguagesHandler(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string][]string{"languages": {"en", "fr", "es"}})
+	return c.JSON(http.StatusOK, map[string][]models.Language{"languages": h.handler.LanguageService.All()})
+}
+
+// ListTranslationsHandler returns an HTML fragment with the translations of ?lang= (English by default)
+func (h *TranslationHandler) ListTranslationsHandler(c echo.Context) error {
+	html, err := h.handler.RenderTemplFragment(c, adminFragments.TranslationsList(h.handler.TranslationsListData(c.QueryParam("lang"))))
+	if err != nil {
+		log.Printf("Error rendering translations list: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	return c.HTML(http.StatusOK, html)
 }
 
 // GetTranslationsHandler gets translations for a language
+// Every known key is listed with its value and where it comes from (database, file or missing).
 func (h *TranslationHandler) GetTranslationsHandler(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]interface{}{"translations": map[string]string{}})
+	lang, err := h.language(c)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"lang_code":    lang,
+		"translations": h.handler.I18nService.Entries(lang),
+	})
 }
 
-// UpdateTranslationHandler updates a translation
+// UpdateTranslationHandler updates a translation (form fields key and value)
 func (h *TranslationHandler) UpdateTranslationHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	lang, key := c.Param("lang_code"), c.FormValue("key")
+	if err := h.handler.I18nService.UpdateTranslation(c.Request().Context(), lang, key, c.FormValue("value")); err != nil {
+		return translationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Saved %s in %s", key, lang)})
 }
 
-// CreateTranslationHandler creates a translation
+// CreateTranslationHandler creates a translation (form fields lang_code, key and value)
 func (h *TranslationHandler) CreateTranslationHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	lang, key := c.FormValue("lang_code"), c.FormValue("key")
+	if err := h.handler.I18nService.CreateTranslation(c.Request().Context(), lang, key, c.FormValue("value")); err != nil {
+		return translationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Added %s to %s", key, lang)})
 }
 
-// DeleteTranslationHandler deletes a translation
+// DeleteTranslationHandler deletes a translation (query parameter key)
+// A key shipped in static/i18n goes back to the file's value.
 func (h *TranslationHandler) DeleteTranslationHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	lang, key := c.Param("lang_code"), c.QueryParam("key")
+	if err := h.handler.I18nService.DeleteTranslation(c.Request().Context(), lang, key); err != nil {
+		return translationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Deleted %s from %s", key, lang)})
 }
 
-// ExportTranslationsHandler exports translations
+// ExportTranslationsHandler exports translations as a flat JSON file (key -> value)
 func (h *TranslationHandler) ExportTranslationsHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	lang, err := h.language(c)
+	if err != nil {
+		return err
+	}
+	c.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.json", lang))
+	return c.JSONPretty(http.StatusOK, h.handler.I18nService.Strings(lang), "  ")
 }
 
-// ImportTranslationsHandler imports translations
+// ImportTranslationsHandler imports translations from a JSON file, flat or nested like
+// static/i18n/*.json
+// The file comes as the "file" form field or as the request body. Keys in the file are created
+// or updated; other keys are left alone.
 func (h *TranslationHandler) ImportTranslationsHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	lang, err := h.language(c)
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader = c.Request().Body
+	if file, err := c.FormFile("file"); err == nil {
+		upload, err := file.Open()
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read the uploaded file"})
+		}
+		defer upload.Close()
+		body = upload
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxTranslationFileSize))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to read the import file"})
+	}
+
+	values, err := services.ParseTranslations(data)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if err := h.handler.I18nService.SetTranslations(c.Request().Context(), lang, values); err != nil {
+		return translationError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Imported %d %s translations", len(values), lang)})
 }
 
-// ValidateMissingKeysHandler validates missing translation keys
+// ValidateMissingKeysHandler lists, for each language, the keys another language has and it
+// lacks, and the messages whose placeholders differ from their English version
 func (h *TranslationHandler) ValidateMissingKeysHandler(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string][]string{"missing_keys": {}})
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"missing_keys":     h.handler.I18nService.MissingKeys(),
+		"invalid_messages": h.handler.I18nService.Lint(),
+	})
 }
 
-// AddLanguageHandler adds a new language
+// AddLanguageHandler adds a new language (form fields code, native_name, fallback and enabled)
+// Its UI strings fall back to the fallback language until they are translated.
 func (h *TranslationHandler) AddLanguageHandler(c echo.Context) error {
-	return echo.NewHTTPError(http.StatusNotImplemented, "Not yet implemented")
+	language := &models.Language{
+		Code:       c.FormValue("code"),
+		NativeName: c.FormValue("native_name"),
+		Fallback:   c.FormValue("fallback"),
+		Enabled:    c.FormValue("enabled") == "on" || c.FormValue("enabled") == "true",
+	}
+	if err := h.handler.LanguageService.CreateLanguage(c.Request().Context(), language); err != nil {
+		return languageError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Added %s (%s)", language.NativeName, language.Code)})
+}
+
+// UpdateLanguageHandler updates a language (form fields native_name, fallback and enabled)
+func (h *TranslationHandler) UpdateLanguageHandler(c echo.Context) error {
+	language := &models.Language{
+		Code:       c.Param("code"),
+		NativeName: c.FormValue("native_name"),
+		Fallback:   c.FormValue("fallback"),
+		Enabled:    c.FormValue("enabled") == "on" || c.FormValue("enabled") == "true",
+	}
+	if err := h.handler.LanguageService.UpdateLanguage(c.Request().Context(), language); err != nil {
+		return languageError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"success": fmt.Sprintf("Saved %s", language.Code)})
 }
 
+// language returns the :lang_code parameter, or a 404 when no translations exist for it
+func (h *TranslationHandler) language(c echo.Context) (string, error) {
+	lang := c.Param("lang_code")
+	if !slices.Contains(h.handler.I18nService.Languages(), lang) {
+		return "", echo.NewHTTPError(http.StatusNotFound, "Language not found")
+	}
+	return lang, nil
+}
 
+// languageError maps language errors to JSON error responses
+func languageError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidLanguage):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrLanguageExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This language already exists"})
+	case errors.Is(err, models.ErrLanguageNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Language not found"})
+	}
+	log.Printf("Error saving language: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save language"})
+}
 
+// translationError maps translation errors to JSON error responses
+func translationError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidTranslation):
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, models.ErrTranslationExists):
+		return c.JSON(http.StatusConflict, map[string]string{"error": "This key already exists; edit it instead"})
+	case errors.Is(err, models.ErrTranslationNotFound):
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Translation not found"})
+	}
+	log.Printf("Error saving translation: %v", err)
+	return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to save translation"})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/viewmodels"
+	"github.com/hekigan/couples/internal/views/pages"
+	"github.com/labstack/echo/v4"
+)
+
+// maxAPITokenDays is the longest lifetime offered for an API token (0 means no expiry)
+const maxAPITokenDays = 365
+
+// APITokensHandler lists the current user's API tokens
+func (h *Handler) APITokensHandler(c echo.Context) error {
+	return h.renderAPITokensAction(c, nil)
+}
+
+// CreateAPITokenHandler creates an API token and shows it once
+func (h *Handler) CreateAPITokenHandler(c echo.Context) error {
+	return h.renderAPITokensAction(c, func(user *models.User, tokens *viewmodels.APITokensData) (string, error) {
+		days, err := strconv.Atoi(c.FormValue("expires_days"))
+		if err != nil || days < 0 || days > maxAPITokenDays {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid expiry")
+		}
+		form, err := c.FormParams()
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid form")
+		}
+
+		token, _, err := h.APITokenService.CreateToken(c.Request().Context(), user, middleware.GetTwoFactorVerified(c),
+			c.FormValue("name"), form["scopes"], time.Duration(days)*24*time.Hour)
+		if err != nil {
+			return "", err
+		}
+		tokens.NewToken = token
+		return "Token created. Copy it now: it will not be shown again.", nil
+	})
+}
+
+// RevokeAPITokenHandler deletes one of the current user's API tokens
+func (h *Handler) RevokeAPITokenHandler(c echo.Context) error {
+	return h.renderAPITokensAction(c, func(user *models.User, tokens *viewmodels.APITokensData) (string, error) {
+		tokenID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid token ID")
+		}
+		return "The token has been revoked.", h.APITokenService.RevokeToken(c.Request().Context(), user.ID, tokenID)
+	})
+}
+
+// renderAPITokensAction runs an API token action (if any) for the current user and renders the
+// API tokens page with its outcome
+func (h *Handler) renderAPITokensAction(c echo.Context, action func(user *models.User, tokens *viewmodels.APITokensData) (string, error)) error {
+	ctx := c.Request().Context()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	user, err := h.UserService.GetUserByID(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load profile")
+	}
+	if user.IsAnonymous {
+		return c.Redirect(http.StatusSeeOther, "/profile")
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "API Tokens"
+	data.User = user
+
+	tokens := &viewmodels.APITokensData{}
+	if action != nil {
+		message, err := action(user, tokens)
+		if httpErr, ok := err.(*echo.HTTPError); ok {
+			return httpErr
+		}
+		if err != nil {
+			data.Error = apiTokenError(err)
+		} else {
+			data.Success = message
+		}
+	}
+
+	if tokens.Tokens, err = h.APITokenService.ListTokens(ctx, userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load API tokens")
+	}
+	for _, scope := range models.APIScopes {
+		if scope != models.ScopeAdmin || user.IsAdmin {
+			tokens.Scopes = append(tokens.Scopes, scope)
+		}
+	}
+	tokens.AdminLocked = user.IsAdmin && !middleware.GetTwoFactorVerified(c)
+
+	data.Data = tokens
+	return h.RenderTemplComponent(c, pages.APITokensPage(data))
+}
+
+// apiTokenError is the message shown when an API token action fails
+func apiTokenError(err error) string {
+	switch {
+	case errors.Is(err, models.ErrInvalidAPITokenName):
+		return "Give the token a name of at most 100 characters."
+	case errors.Is(err, models.ErrInvalidScope):
+		return "Choose at least one scope. The admin scope needs a session that completed two-factor authentication."
+	}
+	log.Printf("API token action failed: %v", err)
+	return "Something went wrong. Please try again later."
+}
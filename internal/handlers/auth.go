@@ -6,9 +6,12 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/gorilla/sessions"
 	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
 	"github.com/hekigan/couples/internal/services"
 	authPages "github.com/hekigan/couples/internal/views/pages/auth"
 	"github.com/labstack/echo/v4"
@@ -40,7 +43,7 @@ func (h *Handler) LoginPostHandler(c echo.Context) error {
 	}
 
 	// Create auth service
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		log.Printf("Failed to initialize auth service: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -66,21 +69,9 @@ func (h *Handler) LoginPostHandler(c echo.Context) error {
 		})
 	}
 
-	// Create session
-	sess, _ := middleware.GetSession(c)
-	sess.Values["user_id"] = user.ID.String()
-	sess.Values["username"] = user.Username
-	if user.Email != nil {
-		sess.Values["email"] = *user.Email
-	}
-	sess.Values["is_anonymous"] = false
-	sess.Values["is_admin"] = user.IsAdmin
-	sess.Values["access_token"] = session.AccessToken
-	if session.RefreshToken != "" {
-		sess.Values["refresh_token"] = session.RefreshToken
-	}
-
-	if err := middleware.SaveSession(c, sess); err != nil {
+	// Create session (or hold it until the second factor is entered)
+	redirect, err := h.signIn(c, user, session.AccessToken, session.RefreshToken)
+	if err != nil {
 		log.Printf("Failed to save session: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to save session",
@@ -88,7 +79,7 @@ func (h *Handler) LoginPostHandler(c echo.Context) error {
 	}
 
 	// Return HX-Redirect header for HTMX to handle
-	c.Response().Header().Set("HX-Redirect", "/")
+	c.Response().Header().Set("HX-Redirect", redirect)
 	return c.NoContent(http.StatusOK)
 }
 
@@ -141,7 +132,7 @@ func (h *Handler) SignupPostHandler(c echo.Context) error {
 	}
 
 	// Create auth service
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		log.Printf("Failed to initialize auth service: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
@@ -167,21 +158,15 @@ func (h *Handler) SignupPostHandler(c echo.Context) error {
 		})
 	}
 
-	// Create session
-	sess, _ := middleware.GetSession(c)
-	sess.Values["user_id"] = user.ID.String()
-	sess.Values["username"] = user.Username
-	if user.Email != nil {
-		sess.Values["email"] = *user.Email
-	}
-	sess.Values["is_anonymous"] = false
-	sess.Values["is_admin"] = user.IsAdmin
-	sess.Values["access_token"] = session.AccessToken
-	if session.RefreshToken != "" {
-		sess.Values["refresh_token"] = session.RefreshToken
+	// Ask the new user to confirm their email; the account works in the meantime
+	lang, _ := middleware.GetLanguage(c)
+	if err := h.AccountService.SendVerificationEmail(ctx, user, lang); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
 	}
 
-	if err := middleware.SaveSession(c, sess); err != nil {
+	// Create session
+	redirect, err := h.signIn(c, user, session.AccessToken, session.RefreshToken)
+	if err != nil {
 		log.Printf("Failed to save session: %v", err)
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Failed to save session",
@@ -189,7 +174,7 @@ func (h *Handler) SignupPostHandler(c echo.Context) error {
 	}
 
 	// Return HX-Redirect header for HTMX to handle
-	c.Response().Header().Set("HX-Redirect", "/")
+	c.Response().Header().Set("HX-Redirect", redirect)
 	return c.NoContent(http.StatusOK)
 }
 
@@ -244,6 +229,10 @@ func (h *Handler) DevLoginAsAdminHandler(c echo.Context) error {
 
 	// Create session with both user auth and admin auth flags
 	session, _ := middleware.GetSession(c)
+	if err := middleware.RenewSession(c, session); err != nil {
+		log.Printf("Failed to renew session: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save session")
+	}
 	session.Values["user_id"] = adminUser.ID.String()
 	session.Values["username"] = adminUser.Username
 	if adminUser.Email != nil {
@@ -251,6 +240,8 @@ func (h *Handler) DevLoginAsAdminHandler(c echo.Context) error {
 	}
 	session.Values["is_anonymous"] = false
 	session.Values["is_admin"] = true
+	// The shortcut skips the second factor too; it only exists in development
+	session.Values["two_factor_verified"] = true
 
 	if err := middleware.SaveSession(c, session); err != nil {
 		log.Printf("Failed to save session: %v", err)
@@ -293,7 +284,7 @@ func (h *Handler) CreateAnonymousHandler(c echo.Context) error {
 
 // OAuthGoogleHandler initiates Google OAuth flow
 func (h *Handler) OAuthGoogleHandler(c echo.Context) error {
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to initialize auth service")
 	}
@@ -308,7 +299,7 @@ func (h *Handler) OAuthGoogleHandler(c echo.Context) error {
 
 // OAuthFacebookHandler initiates Facebook OAuth flow
 func (h *Handler) OAuthFacebookHandler(c echo.Context) error {
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to initialize auth service")
 	}
@@ -323,7 +314,7 @@ func (h *Handler) OAuthFacebookHandler(c echo.Context) error {
 
 // OAuthGithubHandler initiates GitHub OAuth flow
 func (h *Handler) OAuthGithubHandler(c echo.Context) error {
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to initialize auth service")
 	}
@@ -375,7 +366,7 @@ func (h *Handler) OAuthTokenHandler(c echo.Context) error {
 func (h *Handler) processOAuthTokens(c echo.Context, accessToken, refreshToken string) error {
 	ctx := c.Request().Context()
 
-	authService, err := services.NewAuthService(h.UserService.GetSupabaseClient())
+	authService, err := services.NewAuthService(h.UserService.Store())
 	if err != nil {
 		log.Printf("Failed to initialize auth service: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Authentication failed")
@@ -395,31 +386,87 @@ func (h *Handler) processOAuthTokens(c echo.Context, accessToken, refreshToken s
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save user")
 	}
 
-	// Save to session
-	session, _ := middleware.GetSession(c)
-	session.Values["user_id"] = user.ID.String()
-	session.Values["username"] = user.Username
-	if user.Email != nil {
-		session.Values["email"] = *user.Email
-	}
-	session.Values["is_anonymous"] = false
-	session.Values["is_admin"] = user.IsAdmin
-	session.Values["access_token"] = accessToken
-	if refreshToken != "" {
-		session.Values["refresh_token"] = refreshToken
-	}
-
-	if err := middleware.SaveSession(c, session); err != nil {
+	// Save to session (or hold it until the second factor is entered)
+	redirect, err := h.signIn(c, user, accessToken, refreshToken)
+	if err != nil {
 		log.Printf("Failed to save session: %v", err)
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save session")
 	}
 
 	log.Printf("OAuth login successful for user: %s (%s)", user.ID, *user.Email)
 
-	// Return success for JSON requests, redirect for browser
+	// Return the next page for JSON requests, redirect for browser
 	if c.Request().Header.Get("Content-Type") == "application/json" {
-		return c.NoContent(http.StatusOK)
+		return c.JSON(http.StatusOK, map[string]string{"redirect": redirect})
 	}
 
-	return c.Redirect(http.StatusSeeOther, "/")
+	return c.Redirect(http.StatusSeeOther, redirect)
+}
+
+// signIn starts the session of a user who passed the first factor (password or OAuth) and
+// returns where to send them next. With two-factor authentication enabled, the sign-in is held
+// on the session until the code is entered (see TwoFactorLoginPostHandler); until then the
+// session stays what it was, a guest's or none.
+func (h *Handler) signIn(c echo.Context, user *models.User, accessToken, refreshToken string) (string, error) {
+	enabled, err := h.TwoFactorService.IsEnabled(c.Request().Context(), user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	sess, _ := middleware.GetSession(c)
+	if enabled {
+		sess.Values["pending_user_id"] = user.ID.String()
+		sess.Values["pending_access_token"] = accessToken
+		sess.Values["pending_refresh_token"] = refreshToken
+		sess.Values["pending_since"] = time.Now().Unix()
+		return twoFactorLoginPath, middleware.SaveSession(c, sess)
+	}
+
+	// Keep what the visitor did as a guest
+	h.mergeAnonymousSession(c, user.ID)
+
+	if err := middleware.RenewSession(c, sess); err != nil {
+		return "", err
+	}
+	setUserSession(sess, user, accessToken, refreshToken, false)
+	return "/", middleware.SaveSession(c, sess)
+}
+
+// setUserSession stores a signed-in user on the session
+func setUserSession(sess *sessions.Session, user *models.User, accessToken, refreshToken string, twoFactorVerified bool) {
+	sess.Values["user_id"] = user.ID.String()
+	sess.Values["username"] = user.Username
+	if user.Email != nil {
+		sess.Values["email"] = *user.Email
+	}
+	sess.Values["is_anonymous"] = false
+	sess.Values["is_admin"] = user.IsAdmin
+	sess.Values["two_factor_verified"] = twoFactorVerified
+	sess.Values["access_token"] = accessToken
+	if refreshToken != "" {
+		sess.Values["refresh_token"] = refreshToken
+	}
+}
+
+// mergeAnonymousSession moves the rooms, answers, friends and question history of the guest
+// signed in on this session to the account they just signed up or logged in with
+// A failed merge is logged and leaves the guest's history where it was; the login goes on.
+func (h *Handler) mergeAnonymousSession(c echo.Context, userID uuid.UUID) {
+	session, err := middleware.GetSession(c)
+	if err != nil {
+		return
+	}
+	if isAnonymous, _ := session.Values["is_anonymous"].(bool); !isAnonymous {
+		return
+	}
+	anonymousID, err := uuid.Parse(fmt.Sprint(session.Values["user_id"]))
+	if err != nil || anonymousID == userID {
+		return
+	}
+
+	if err := h.UserService.MergeAnonymousUser(c.Request().Context(), anonymousID, userID); err != nil {
+		log.Printf("⚠️ Failed to merge guest %s into %s: %v", anonymousID, userID, err)
+		return
+	}
+	log.Printf("✅ Merged guest %s into %s", anonymousID, userID)
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -23,7 +24,14 @@ type Handler struct {
 	AnswerService       *services.AnswerService
 	FriendService       *services.FriendService
 	I18nService         *services.I18nService
+	LanguageService     *services.LanguageService
 	NotificationService *services.NotificationService
+	PresenceService     *services.PresenceService
+	Scheduler           *services.Scheduler
+	AccountService      *services.AccountService
+	TwoFactorService    *services.TwoFactorService
+	SessionService      *services.SessionService
+	APITokenService     *services.APITokenService
 	AdminService        *services.AdminService // For admin operations
 	echo                *echo.Echo             // Echo instance for route introspection
 }
@@ -38,7 +46,14 @@ func NewHandler(
 	answerService *services.AnswerService,
 	friendService *services.FriendService,
 	i18nService *services.I18nService,
+	languageService *services.LanguageService,
 	notificationService *services.NotificationService,
+	presenceService *services.PresenceService,
+	scheduler *services.Scheduler,
+	accountService *services.AccountService,
+	twoFactorService *services.TwoFactorService,
+	sessionService *services.SessionService,
+	apiTokenService *services.APITokenService,
 	adminService *services.AdminService,
 	e *echo.Echo,
 ) *Handler {
@@ -51,7 +66,14 @@ func NewHandler(
 		AnswerService:       answerService,
 		FriendService:       friendService,
 		I18nService:         i18nService,
+		LanguageService:     languageService,
 		NotificationService: notificationService,
+		PresenceService:     presenceService,
+		Scheduler:           scheduler,
+		AccountService:      accountService,
+		TwoFactorService:    twoFactorService,
+		SessionService:      sessionService,
+		APITokenService:     apiTokenService,
 		AdminService:        adminService,
 		echo:                e,
 	}
@@ -147,16 +169,38 @@ func NewTemplateData(c echo.Context) *TemplateData {
 func (h *Handler) RenderTemplComponent(c echo.Context, component templ.Component) error {
 	c.Response().Header().Set("Content-Type", "text/html; charset=UTF-8")
 	c.Response().WriteHeader(http.StatusOK)
-	return component.Render(c.Request().Context(), c.Response().Writer)
+	return component.Render(h.renderContext(c), c.Response().Writer)
 }
 
 // RenderTemplFragment renders a templ component and returns HTML string (for SSE)
 // This is used for SSE HTML fragment broadcasting
 func (h *Handler) RenderTemplFragment(c echo.Context, component templ.Component) (string, error) {
+	return h.renderFragment(h.renderContext(c), component)
+}
+
+// renderFragment renders a templ component with ctx, which carries the translator
+func (h *Handler) renderFragment(ctx context.Context, component templ.Component) (string, error) {
 	var buf bytes.Buffer
-	err := component.Render(c.Request().Context(), &buf)
+	err := component.Render(ctx, &buf)
 	if err != nil {
 		return "", fmt.Errorf("failed to render templ component: %w", err)
 	}
 	return buf.String(), nil
 }
+
+// renderContext is the request context with a translator for services.T in the request's language
+func (h *Handler) renderContext(c echo.Context) context.Context {
+	return h.withTranslator(c.Request().Context(), c)
+}
+
+// withTranslator adds a translator in the request's language to ctx
+func (h *Handler) withTranslator(ctx context.Context, c echo.Context) context.Context {
+	if h.I18nService == nil {
+		return ctx
+	}
+	lang, ok := middleware.GetLanguage(c)
+	if !ok {
+		lang = services.DefaultLanguage
+	}
+	return h.I18nService.WithTranslator(ctx, lang)
+}
@@ -52,11 +52,11 @@ func (h *Handler) UpdateCategoriesAPIHandler(c echo.Context) error {
 	// Update room with selected categories
 	room.SelectedCategories = categoryIDs
 	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update categories: "+err.Error())
+		return RoomWriteError(c, err, "Failed to update categories: "+err.Error())
 	}
 
 	// Render the updated categories grid HTML
-	categoriesHTML, err := h.renderCategoriesGrid(c, ctx, room, roomID, isOwner)
+	categoriesHTML, err := h.renderCategoriesGrid(h.withTranslator(ctx, c), room, roomID, isOwner)
 	if err != nil {
 		log.Printf("⚠️ Failed to render categories grid for SSE: %v", err)
 		// Continue without SSE broadcast (graceful degradation)
@@ -173,7 +173,7 @@ func (h *Handler) GetRoomCategoriesHTMLHandler(c echo.Context) error {
 // ToggleCategoryAPIHandler toggles a single category selection (for HTMX)
 func (h *Handler) ToggleCategoryAPIHandler(c echo.Context) error {
 	// Use helper to get room
-	room, roomID, err := h.GetRoomFromRequest(c)
+	room, _, err := h.GetRoomFromRequest(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, err.Error())
 	}
@@ -185,53 +185,15 @@ func (h *Handler) ToggleCategoryAPIHandler(c echo.Context) error {
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
 	}
-	isOwner := room.OwnerID == userID
 
 	// Get category ID from form
-	categoryIDStr := c.FormValue("category_id")
-	categoryID, err := uuid.Parse(categoryIDStr)
+	categoryID, err := uuid.Parse(c.FormValue("category_id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID")
 	}
 
-	// Toggle category - if exists, remove it; if not, add it
-	selectedCategories := room.SelectedCategories
-	found := false
-	newCategories := make([]uuid.UUID, 0, len(selectedCategories))
-	for _, id := range selectedCategories {
-		if id == categoryID {
-			found = true
-			// Don't add it (remove it)
-		} else {
-			newCategories = append(newCategories, id)
-		}
-	}
-
-	if !found {
-		// Add it
-		newCategories = append(newCategories, categoryID)
-	}
-
-	// Update room
-	room.SelectedCategories = newCategories
-	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
-		log.Printf("Failed to update categories: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update categories")
-	}
-
-	// Render the updated categories grid HTML
-	categoriesHTML, err := h.renderCategoriesGrid(c, ctx, room, roomID, isOwner)
-	if err != nil {
-		log.Printf("⚠️ Failed to render categories grid for SSE: %v", err)
-		// Continue without SSE broadcast (graceful degradation)
-	} else {
-		// Broadcast HTML fragment via SSE
-		h.RoomService.GetRealtimeService().BroadcastHTMLFragment(roomID, services.HTMLFragmentEvent{
-			Type:       "categories_updated",
-			Target:     "#categories-grid",
-			SwapMethod: "innerHTML",
-			HTML:       categoriesHTML,
-		})
+	if err := h.toggleCategory(h.withTranslator(ctx, c), room, userID, categoryID); err != nil {
+		return commandError(c, err, "Failed to update categories")
 	}
 
 	// Return success (HTMX will handle via hx-swap="none")
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -33,7 +34,7 @@ func (h *Handler) StartGameAPIHandler(c echo.Context) error {
 	}
 
 	// Verify room is ready (both players present)
-	if room.Status != "ready" {
+	if !room.Status.Can(models.RoomEventStart) {
 		return echo.NewHTTPError(http.StatusBadRequest, "Room is not ready to start. Wait for both players to join.")
 	}
 
@@ -44,7 +45,7 @@ func (h *Handler) StartGameAPIHandler(c echo.Context) error {
 
 	ctx := context.Background()
 	if err := h.GameService.StartGame(ctx, roomID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start game: "+err.Error())
+		return RoomWriteError(c, err, "Failed to start game: "+err.Error())
 	}
 
 	// Return HTMX redirect header to navigate to play page
@@ -72,7 +73,7 @@ func (h *Handler) DrawQuestionAPIHandler(c echo.Context) error {
 	}
 
 	// Verify game is playing
-	if room.Status != "playing" {
+	if room.Status != models.RoomPlaying {
 		return echo.NewHTTPError(http.StatusBadRequest, "Game is not in progress")
 	}
 
@@ -91,8 +92,11 @@ func (h *Handler) DrawQuestionAPIHandler(c echo.Context) error {
 		// No question exists, draw a new one
 		log.Printf("🎴 Drawing new question for room %s", roomID)
 		newQuestion, err := h.GameService.DrawQuestion(ctx, roomID)
+		if errors.Is(err, models.ErrQuestionsAllSeen) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to draw question: "+err.Error())
+			return RoomWriteError(c, err, "Failed to draw question: "+err.Error())
 		}
 		question = newQuestion
 	}
@@ -103,16 +107,14 @@ func (h *Handler) DrawQuestionAPIHandler(c echo.Context) error {
 	})
 }
 
-// SubmitAnswerAPIHandler handles answer submission
-// FIXED: Previously called GetGameFormsHandler (handler-to-handler call), now renders directly
-func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
+// ResetQuestionHistoryAPIHandler forgets which questions the room's two players have seen together
+func (h *Handler) ResetQuestionHistoryAPIHandler(c echo.Context) error {
 	// Use helper to get room and verify participation
-	room, roomID, err := h.GetRoomFromRequest(c)
+	room, _, err := h.GetRoomFromRequest(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, err.Error())
 	}
 
-	ctx := context.Background()
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
@@ -122,10 +124,32 @@ func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
 	if err := h.VerifyRoomParticipant(room, userID); err != nil {
 		return echo.NewHTTPError(http.StatusForbidden, err.Error())
 	}
+	if room.GuestID == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Room has no guest")
+	}
 
-	// Verify it's the user's turn
-	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
-		return echo.NewHTTPError(http.StatusBadRequest, "It's not your turn")
+	ctx := context.Background()
+	if err := h.QuestionService.ResetPairHistory(ctx, room.OwnerID, *room.GuestID); err != nil {
+		log.Printf("❌ Failed to reset question history: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to reset question history")
+	}
+
+	return c.HTML(http.StatusOK, `<small class="text-muted">✅ Question history reset</small>`)
+}
+
+// SubmitAnswerAPIHandler handles answer submission
+// FIXED: Previously called GetGameFormsHandler (handler-to-handler call), now renders directly
+func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
+	// Use helper to get room and verify participation
+	room, roomID, err := h.GetRoomFromRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+
+	ctx := context.Background()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
 	}
 
 	// Parse form data (handles both URL-encoded and multipart forms)
@@ -137,7 +161,6 @@ func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
 	}
 
 	questionIDStr := c.FormValue("question_id")
-	log.Printf("🔍 Received question_id from form: '%s' (length: %d)", questionIDStr, len(questionIDStr))
 	answerText := c.FormValue("answer_text")
 	skipped := c.FormValue("skipped") == "true"
 
@@ -147,34 +170,6 @@ func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid question ID format")
 	}
 
-	log.Printf("🔍 Validating question %s for room %s", questionID, roomID)
-
-	// Verify question exists in database
-	question, err := h.QuestionService.GetQuestionByID(ctx, questionID)
-	if err != nil {
-		log.Printf("❌ Question %s not found in database: %v", questionID, err)
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Question not found in database (ID: %s)", questionID))
-	}
-	log.Printf("✅ Question exists: %s", question.Text)
-
-	// Verify question matches room's current question
-	room, err = h.RoomService.GetRoomByID(ctx, roomID)
-	if err != nil {
-		log.Printf("❌ Failed to get room %s: %v", roomID, err)
-		return echo.NewHTTPError(http.StatusNotFound, "Room not found")
-	}
-
-	if room.CurrentQuestionID == nil {
-		log.Printf("❌ Room %s has no current question", roomID)
-		return echo.NewHTTPError(http.StatusBadRequest, "No active question for this room")
-	}
-
-	if *room.CurrentQuestionID != questionID {
-		log.Printf("❌ Question mismatch: room current=%s, submitted=%s", *room.CurrentQuestionID, questionID)
-		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Question mismatch: you're answering question %s but current question is %s", questionID, *room.CurrentQuestionID))
-	}
-	log.Printf("✅ Question matches room's current question")
-
 	// Get action type from form (either "answered" or "skipped")
 	actionType := c.FormValue("action_type")
 	if actionType == "" {
@@ -185,37 +180,10 @@ func (h *Handler) SubmitAnswerAPIHandler(c echo.Context) error {
 		}
 	}
 
-	answer := &models.Answer{
-		ID:         uuid.New(),
-		RoomID:     roomID,
-		QuestionID: questionID,
-		UserID:     userID,
-		AnswerText: answerText,
-		ActionType: actionType,
+	if err := h.submitAnswer(ctx, room, userID, questionID, answerText, actionType); err != nil {
+		return commandError(c, err, "Failed to change turn: "+err.Error())
 	}
 
-	if err := h.GameService.SubmitAnswer(ctx, answer); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to submit answer: "+err.Error())
-	}
-
-	log.Printf("✅ Answer submitted by user %s in room %s (action: %s)", userID, roomID, actionType)
-
-	// NOTE: We do NOT clear current_question_id here anymore.
-	// The question remains visible so both players can see the question + answer together.
-	// current_question_id will be cleared when the new active player clicks "Next Question"
-
-	// Change turn immediately after answer submission
-	// The new active player will see the answer and click "Next Question" to draw
-	if actionType == "answered" {
-		log.Printf("🔄 Switching turn after answer in room %s", roomID)
-		if err := h.GameService.ChangeTurn(ctx, roomID); err != nil {
-			log.Printf("❌ Failed to change turn: %v", err)
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to change turn: "+err.Error())
-		}
-		log.Printf("✅ Turn switched. New active player will draw next question.")
-	}
-	// Note: For "skipped" action, turn doesn't change but question drawing is deferred to next question click
-
 	// FIXED: Instead of calling GetGameFormsHandler (handler-to-handler call),
 	// render the answer review HTML fragment directly
 	// The submitter sees the answer they just submitted, with no "Next Question" button
@@ -290,7 +258,7 @@ func (h *Handler) NextQuestionAPIHandler(c echo.Context) error {
 	question, err := h.GameService.DrawQuestion(ctx, roomID)
 	if err != nil {
 		log.Printf("❌ Failed to draw question: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to draw question: "+err.Error())
+		return RoomWriteError(c, err, "Failed to draw question: "+err.Error())
 	}
 
 	log.Printf("✅ Next question drawn: %s", question.Text)
@@ -322,7 +290,7 @@ func (h *Handler) FinishGameAPIHandler(c echo.Context) error {
 
 	ctx := context.Background()
 	if err := h.GameService.EndGame(ctx, roomID); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to end game: "+err.Error())
+		return RoomWriteError(c, err, "Failed to end game: "+err.Error())
 	}
 
 	// Use HTMX redirect header for client-side redirect
@@ -333,7 +301,7 @@ func (h *Handler) FinishGameAPIHandler(c echo.Context) error {
 // PlayerTypingAPIHandler broadcasts typing status to other players in the room
 func (h *Handler) PlayerTypingAPIHandler(c echo.Context) error {
 	// Use helper to get room and verify participation
-	room, roomID, err := h.GetRoomFromRequest(c)
+	room, _, err := h.GetRoomFromRequest(c)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusNotFound, err.Error())
 	}
@@ -351,19 +319,14 @@ func (h *Handler) PlayerTypingAPIHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
 	}
 
-	// Use helper to verify participant
-	if err := h.VerifyRoomParticipant(room, userID); err != nil {
-		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	// Broadcast typing status to other players in the room
+	sent, err := h.playerTyping(room, userID, req.IsTyping)
+	if err != nil {
+		return commandError(c, err, "Failed to send typing status")
 	}
-
-	// Verify it's the user's turn
-	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
-		// Silently ignore typing events if it's not their turn
+	if !sent {
 		return c.JSON(http.StatusOK, map[string]string{"status": "ignored"})
 	}
 
-	// Broadcast typing status to other players in the room
-	h.RoomService.BroadcastPlayerTyping(roomID, userID, req.IsTyping)
-
 	return c.JSON(http.StatusOK, map[string]string{"status": "success"})
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -35,6 +36,28 @@ func (h *Handler) GetRoomFromRequest(c echo.Context) (*models.Room, uuid.UUID, e
 	return room, roomID, nil
 }
 
+// RoomWriteError turns a failed room write into an HTTP error
+// A stale write (models.ErrConflict, or a state change that no longer applies) answers 409
+// with HX-Refresh so HTMX reloads the room instead of showing a diverged state. Running out
+// of questions (models.ErrQuestionsAllSeen) is a 409 too, without the reload; anything else
+// is a 500 with message.
+func RoomWriteError(c echo.Context, err error, message string) error {
+	if isStaleRoomWrite(err) {
+		c.Response().Header().Set("HX-Refresh", "true")
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	if errors.Is(err, models.ErrQuestionsAllSeen) {
+		// Nothing to reload: the players need to allow repeats or reset their history
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
+}
+
+// isStaleRoomWrite reports whether a room write failed because the room changed underneath it
+func isStaleRoomWrite(err error) bool {
+	return errors.Is(err, models.ErrConflict) || errors.Is(err, models.ErrIllegalRoomTransition)
+}
+
 // VerifyRoomParticipant checks if the user is a participant (owner or guest) in the room
 // No changes needed - works with uuid.UUID
 func (h *Handler) VerifyRoomParticipant(room *models.Room, userID uuid.UUID) error {
@@ -148,7 +171,7 @@ func (h *Handler) RenderRoomFragments(c echo.Context, room *models.Room, roomID
 	ctx := context.Background()
 
 	// 1. Render categories grid (always shown)
-	categoriesHTML, err = h.renderCategoriesGrid(c, ctx, room, roomID, isOwner)
+	categoriesHTML, err = h.renderCategoriesGrid(h.withTranslator(ctx, c), room, roomID, isOwner)
 	if err != nil {
 		log.Printf("⚠️ Failed to render categories grid: %v", err)
 		categoriesHTML = `<p style="color: #6b7280;">Failed to load categories</p>`
@@ -164,7 +187,7 @@ func (h *Handler) RenderRoomFragments(c echo.Context, room *models.Room, roomID
 	}
 
 	// 3. Render action button (based on role and room status)
-	if room.Status == "ready" {
+	if room.Status == models.RoomReady {
 		actionButtonHTML, err = h.renderActionButton(c, ctx, room, roomID, isOwner)
 		if err != nil {
 			log.Printf("⚠️ Failed to render action button: %v", err)
@@ -176,7 +199,7 @@ func (h *Handler) RenderRoomFragments(c echo.Context, room *models.Room, roomID
 }
 
 // renderCategoriesGrid fetches and renders the categories grid fragment
-func (h *Handler) renderCategoriesGrid(c echo.Context, ctx context.Context, room *models.Room, roomID uuid.UUID, isOwner bool) (string, error) {
+func (h *Handler) renderCategoriesGrid(ctx context.Context, room *models.Room, roomID uuid.UUID, isOwner bool) (string, error) {
 	// Import needed at top of file
 	// roomFragments "github.com/hekigan/couples/internal/views/fragments/room"
 	// "github.com/hekigan/couples/internal/services"
@@ -216,7 +239,7 @@ func (h *Handler) renderCategoriesGrid(c echo.Context, ctx context.Context, room
 	}
 
 	// Import needed: roomFragments "github.com/hekigan/couples/internal/views/fragments/room"
-	return h.RenderTemplFragment(c, roomFragments.CategoriesGrid(&services.CategoriesGridData{
+	return h.renderFragment(ctx, roomFragments.CategoriesGrid(&services.CategoriesGridData{
 		Categories: categoryInfos,
 		RoomID:     roomID.String(),
 		GuestReady: room.GuestReady,
@@ -134,12 +134,17 @@ func (h *Handler) GetGameFormsHandler(c echo.Context) error {
 	}
 
 	log.Printf("🎮 GetGameFormsHandler called for room %s by user %s", roomID, userID)
+	return c.HTML(http.StatusOK, h.renderGameForms(h.withTranslator(ctx, c), roomID, userID))
+}
 
+// renderGameForms renders the answer form, waiting UI or answer review for userID
+// Failures render a loading placeholder rather than an error.
+func (h *Handler) renderGameForms(ctx context.Context, roomID, userID uuid.UUID) string {
 	// Get room to check current turn and state
 	room, err := h.RoomService.GetRoomByID(ctx, roomID)
 	if err != nil {
 		log.Printf("Error fetching room: %v", err)
-		return c.HTML(http.StatusOK, `<div class="loading">Loading game interface...</div>`)
+		return `<div class="loading">Loading game interface...</div>`
 	}
 
 	isMyTurn := room.CurrentTurn != nil && *room.CurrentTurn == userID
@@ -191,7 +196,7 @@ func (h *Handler) GetGameFormsHandler(c echo.Context) error {
 			answeredPlayerName = answeredUser.Username
 		}
 
-		html, err = h.RenderTemplFragment(c, playFragments.AnswerReview(&services.AnswerReviewData{
+		html, err = h.renderFragment(ctx, playFragments.AnswerReview(&services.AnswerReviewData{
 			RoomID:               roomID.String(),
 			AnswerText:           lastAnswer.AnswerText,
 			ActionType:           lastAnswer.ActionType,
@@ -201,7 +206,7 @@ func (h *Handler) GetGameFormsHandler(c echo.Context) error {
 		}))
 		if err != nil {
 			log.Printf("Error rendering answer_review template: %v", err)
-			return c.HTML(http.StatusOK, `<div class="loading">Loading answer...</div>`)
+			return `<div class="loading">Loading answer...</div>`
 		}
 	} else if isMyTurn {
 		// No answer yet and it's my turn - show answer form
@@ -210,26 +215,26 @@ func (h *Handler) GetGameFormsHandler(c echo.Context) error {
 			questionID = room.CurrentQuestionID.String()
 		}
 
-		html, err = h.RenderTemplFragment(c, playFragments.AnswerForm(&services.AnswerFormData{
+		html, err = h.renderFragment(ctx, playFragments.AnswerForm(&services.AnswerFormData{
 			RoomID:     roomID.String(),
 			QuestionID: questionID,
 		}))
 		if err != nil {
 			log.Printf("Error rendering answer_form template: %v", err)
-			return c.HTML(http.StatusOK, `<div class="loading">Loading form...</div>`)
+			return `<div class="loading">Loading form...</div>`
 		}
 	} else {
 		// No answer yet and it's not my turn - show waiting UI
-		html, err = h.RenderTemplFragment(c, playFragments.WaitingUI(&services.WaitingUIData{
+		html, err = h.renderFragment(ctx, playFragments.WaitingUI(&services.WaitingUIData{
 			OtherPlayerName: otherPlayerName,
 		}))
 		if err != nil {
 			log.Printf("Error rendering waiting_ui template: %v", err)
-			return c.HTML(http.StatusOK, `<div class="loading">Loading...</div>`)
+			return `<div class="loading">Loading...</div>`
 		}
 	}
 
-	return c.HTML(http.StatusOK, html)
+	return html
 }
 
 // GetProgressCounterHandler returns HTML fragment for progress counter
@@ -261,8 +266,8 @@ func (h *Handler) GetProgressCounterHandler(c echo.Context) error {
 	return c.HTML(http.StatusOK, html)
 }
 
-// NextQuestionHTMLHandler handles drawing the next question and returns game content
-func (h *Handler) NextQuestionHTMLHandler(c echo.Context) error {
+// GetPresenceIndicatorHandler returns HTML fragment showing whether the other player is connected
+func (h *Handler) GetPresenceIndicatorHandler(c echo.Context) error {
 	roomID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid room ID")
@@ -274,31 +279,64 @@ func (h *Handler) NextQuestionHTMLHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
 	}
 
-	// Verify it's the user's turn
 	room, err := h.RoomService.GetRoomByID(ctx, roomID)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "Room not found")
+		log.Printf("Error fetching room: %v", err)
+		return c.HTML(http.StatusOK, "")
+	}
+	if err := h.VerifyRoomParticipant(room, userID); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
 	}
 
-	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
-		return echo.NewHTTPError(http.StatusForbidden, "Not your turn")
+	// Determine other player
+	otherPlayerID := room.OwnerID
+	if room.OwnerID == userID {
+		if room.GuestID == nil {
+			return c.HTML(http.StatusOK, "")
+		}
+		otherPlayerID = *room.GuestID
 	}
 
-	// Clear the current question ID before drawing a new one
-	// This is necessary because DrawQuestion has an idempotency check
-	// that returns the existing question if CurrentQuestionID is set
-	room.CurrentQuestionID = nil
-	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
-		log.Printf("Error clearing current question: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to clear current question")
+	otherPlayerName := "other player"
+	otherUser, err := h.UserService.GetUserByID(ctx, otherPlayerID)
+	if err == nil && otherUser != nil {
+		otherPlayerName = otherUser.Username
 	}
-	log.Printf("✅ Cleared current_question_id for room %s before drawing next question", roomID)
 
-	// Draw next question (this also broadcasts question_drawn via SSE)
-	_, err = h.GameService.DrawQuestion(ctx, roomID)
+	html, err := h.RenderTemplFragment(c, playFragments.PresenceIndicator(&services.PresenceIndicatorData{
+		OtherPlayerName:   otherPlayerName,
+		OtherPlayerOnline: h.PresenceService.IsOnline(ctx, roomID, otherPlayerID),
+		Paused:            room.Status == models.RoomPaused,
+	}))
 	if err != nil {
-		log.Printf("Error drawing question: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, fmt.Sprintf("Failed to draw question: %v", err))
+		log.Printf("Error rendering presence_indicator template: %v", err)
+		return c.HTML(http.StatusOK, "")
+	}
+
+	return c.HTML(http.StatusOK, html)
+}
+
+// NextQuestionHTMLHandler handles drawing the next question and returns game content
+func (h *Handler) NextQuestionHTMLHandler(c echo.Context) error {
+	roomID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid room ID")
+	}
+
+	ctx := context.Background()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	// Verify it's the user's turn
+	room, err := h.RoomService.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "Room not found")
+	}
+
+	if err := h.nextQuestion(ctx, room, userID); err != nil {
+		return commandError(c, err, fmt.Sprintf("Failed to draw question: %v", err))
 	}
 
 	// Return updated game forms (will show answer form to active player)
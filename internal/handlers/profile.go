@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/viewmodels"
 	"github.com/hekigan/couples/internal/views/pages"
 	"github.com/labstack/echo/v4"
 )
@@ -25,7 +26,7 @@ func (h *Handler) ProfileHandler(c echo.Context) error {
 	data := NewTemplateData(c)
 	data.Title = "My Profile"
 	data.User = user
-	data.Data = user
+	data.Data = &viewmodels.ProfileData{User: user, Languages: h.LanguageService.Enabled()}
 	return h.RenderTemplComponent(c, pages.ProfilePage(data))
 }
 
@@ -51,5 +52,22 @@ func (h *Handler) UpdateProfileHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update profile")
 	}
 
+	// Language preferences, when the form has them; "" means automatic
+	form, err := c.FormParams()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid form")
+	}
+	if form.Has("language_preference") || form.Has("question_language") {
+		uiLanguage, questionLanguage := form.Get("language_preference"), form.Get("question_language")
+		for _, lang := range []string{uiLanguage, questionLanguage} {
+			if lang != "" && !h.LanguageService.IsEnabled(lang) {
+				return echo.NewHTTPError(http.StatusBadRequest, "Unsupported language")
+			}
+		}
+		if err := h.UserService.UpdateLanguagePreferences(ctx, userID, uiLanguage, questionLanguage); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update profile")
+		}
+	}
+
 	return c.Redirect(http.StatusSeeOther, "/profile")
 }
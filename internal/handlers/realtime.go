@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -49,18 +51,47 @@ func formatSSEData(eventType, data string) string {
 	return b.String()
 }
 
-// StreamRoomEvents streams room events via SSE
-func (h *RealtimeHandler) StreamRoomEvents(c echo.Context) error {
-	roomID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid room ID")
+// writeSSEEvent writes one event, with its id when it has one so the browser sends it back
+// as Last-Event-ID on reconnect
+func writeSSEEvent(w io.Writer, event services.RealtimeEvent) error {
+	if event.ID != 0 {
+		if _, err := fmt.Fprintf(w, "id: %d\n", event.ID); err != nil {
+			return err
+		}
 	}
 
+	// Check if this is an HTML fragment (string data) or JSON data
+	if htmlStr, isString := event.Data.(string); isString {
+		// HTML fragment - use proper SSE format for multi-line data
+		// HTMX expects raw HTML in the data field
+		_, err := fmt.Fprint(w, formatSSEData(event.Type, htmlStr))
+		return err
+	}
+
+	// JSON data - use the normal EventToSSE function
+	_, err := fmt.Fprint(w, services.EventToSSE(event))
+	return err
+}
+
+// StreamRoomEvents streams room events via SSE
+// A reconnecting browser sends the last event id it saw (Last-Event-ID): the events it
+// missed are replayed before live delivery resumes, or a resync event asks the page to
+// reload when they are no longer available.
+func (h *RealtimeHandler) StreamRoomEvents(c echo.Context) error {
 	userID, ok := middleware.GetUserID(c)
 	if !ok {
 		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
 	}
 
+	// Only the players may follow the room, or replay its history
+	room, roomID, err := h.handler.GetRoomFromRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err := h.handler.VerifyRoomParticipant(room, userID); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
 	// Set SSE headers
 	c.Response().Header().Set("Content-Type", "text/event-stream")
 	c.Response().Header().Set("Cache-Control", "no-cache")
@@ -70,8 +101,9 @@ func (h *RealtimeHandler) StreamRoomEvents(c echo.Context) error {
 	// Access underlying writer for SSE
 	w := c.Response().Writer
 
-	// Subscribe to room events
-	client := h.realtimeService.Subscribe(roomID, userID)
+	// Subscribe to room events, resuming after the last event the browser received
+	lastEventID, _ := strconv.ParseInt(c.Request().Header.Get("Last-Event-ID"), 10, 64)
+	client, missed, complete := h.realtimeService.SubscribeFrom(c.Request().Context(), roomID, userID, lastEventID)
 	defer h.realtimeService.Unsubscribe(client.ID)
 
 	// Stream events
@@ -82,6 +114,16 @@ func (h *RealtimeHandler) StreamRoomEvents(c echo.Context) error {
 
 	// Send initial connection message
 	fmt.Fprintf(w, "event: connected\ndata: {\"type\":\"connected\",\"room_id\":\"%s\"}\n\n", roomID)
+
+	// Catch up on what happened while disconnected
+	if !complete {
+		fmt.Fprintf(w, "event: resync\ndata: {\"type\":\"resync\",\"room_id\":\"%s\"}\n\n", roomID)
+	}
+	for _, event := range missed {
+		if err := writeSSEEvent(w, event); err != nil {
+			return nil
+		}
+	}
 	flusher.Flush()
 
 	// NOTE: Initial join requests are now rendered server-side in the template
@@ -98,17 +140,8 @@ func (h *RealtimeHandler) StreamRoomEvents(c echo.Context) error {
 				// Channel closed
 				return nil
 			}
-			// Check if this is an HTML fragment (string data) or JSON data
-			if htmlStr, isString := event.Data.(string); isString {
-				// HTML fragment - use proper SSE format for multi-line data
-				// HTMX expects raw HTML in the data field
-				fmt.Fprint(w, formatSSEData(event.Type, htmlStr))
-			} else {
-				// JSON data - use the normal EventToSSE function
-				sseData := services.EventToSSE(event)
-				if _, err := fmt.Fprint(w, sseData); err != nil {
-					return nil
-				}
+			if err := writeSSEEvent(w, event); err != nil {
+				return nil
 			}
 			flusher.Flush()
 		case <-c.Request().Context().Done():
@@ -165,17 +198,8 @@ func (h *RealtimeHandler) StreamUserNotifications(c echo.Context) error {
 				// Channel closed
 				return nil
 			}
-			// Check if this is an HTML fragment (string data) or JSON data
-			if htmlStr, isString := event.Data.(string); isString {
-				// HTML fragment - use proper SSE format for multi-line data
-				// HTMX expects raw HTML in the data field
-				fmt.Fprint(w, formatSSEData(event.Type, htmlStr))
-			} else {
-				// JSON data - use the normal EventToSSE function
-				sseData := services.EventToSSE(event)
-				if _, err := fmt.Fprint(w, sseData); err != nil {
-					return nil
-				}
+			if err := writeSSEEvent(w, event); err != nil {
+				return nil
 			}
 			flusher.Flush()
 		case <-c.Request().Context().Done():
@@ -198,7 +222,7 @@ func (h *RealtimeHandler) GetRoomPlayers(c echo.Context) error {
 // RoomStateResponse represents the room state for the frontend
 type RoomStateResponse struct {
 	ID                  uuid.UUID        `json:"id"`
-	Status              string           `json:"status"`
+	Status              models.RoomState `json:"status"`
 	CurrentQuestion     int              `json:"current_question"`
 	CurrentTurn         *uuid.UUID       `json:"current_turn"` // Note: different field name for frontend compatibility
 	MaxQuestions        int              `json:"max_questions"`
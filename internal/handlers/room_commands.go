@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/services"
+	"github.com/labstack/echo/v4"
+)
+
+// Room commands are the player actions shared by the HTTP endpoints and the room WebSocket.
+// Rejected commands return an *echo.HTTPError with the status the endpoints answer with;
+// failed room writes are returned unwrapped (see commandError).
+
+// commandError turns a room command error into the HTTP error the endpoints return
+func commandError(c echo.Context, err error, message string) error {
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr
+	}
+	return RoomWriteError(c, err, message)
+}
+
+// playerTyping broadcasts the typing status of the active player
+// It reports false when the status was ignored because it isn't the user's turn.
+func (h *Handler) playerTyping(room *models.Room, userID uuid.UUID, isTyping bool) (bool, error) {
+	if err := h.VerifyRoomParticipant(room, userID); err != nil {
+		return false, echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	// Silently ignore typing events if it's not their turn
+	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
+		return false, nil
+	}
+
+	h.RoomService.BroadcastPlayerTyping(room.ID, userID, isTyping)
+	return true, nil
+}
+
+// toggleCategory adds or removes a category from the room's selection and broadcasts the
+// updated categories grid, rendered with ctx's translator
+func (h *Handler) toggleCategory(ctx context.Context, room *models.Room, userID, categoryID uuid.UUID) error {
+	isOwner := room.OwnerID == userID
+
+	// Toggle category - if exists, remove it; if not, add it
+	found := false
+	newCategories := make([]uuid.UUID, 0, len(room.SelectedCategories))
+	for _, id := range room.SelectedCategories {
+		if id == categoryID {
+			found = true
+			// Don't add it (remove it)
+		} else {
+			newCategories = append(newCategories, id)
+		}
+	}
+
+	if !found {
+		// Add it
+		newCategories = append(newCategories, categoryID)
+	}
+
+	// Update room
+	room.SelectedCategories = newCategories
+	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
+		log.Printf("Failed to update categories: %v", err)
+		return err
+	}
+
+	// Render the updated categories grid HTML
+	categoriesHTML, err := h.renderCategoriesGrid(ctx, room, room.ID, isOwner)
+	if err != nil {
+		log.Printf("⚠️ Failed to render categories grid for SSE: %v", err)
+		// Continue without SSE broadcast (graceful degradation)
+		return nil
+	}
+
+	// Broadcast HTML fragment via SSE
+	h.RoomService.GetRealtimeService().BroadcastHTMLFragment(room.ID, services.HTMLFragmentEvent{
+		Type:       "categories_updated",
+		Target:     "#categories-grid",
+		SwapMethod: "innerHTML",
+		HTML:       categoriesHTML,
+	})
+	return nil
+}
+
+// submitAnswer records the active player's answer (or skip) to the current question and
+// passes the turn after an answer
+func (h *Handler) submitAnswer(ctx context.Context, room *models.Room, userID, questionID uuid.UUID, answerText, actionType string) error {
+	if err := h.VerifyRoomParticipant(room, userID); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	// Verify it's the user's turn
+	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
+		return echo.NewHTTPError(http.StatusBadRequest, "It's not your turn")
+	}
+
+	// Verify question exists in database
+	if _, err := h.QuestionService.GetQuestionByID(ctx, questionID); err != nil {
+		log.Printf("❌ Question %s not found in database: %v", questionID, err)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Question not found in database (ID: %s)", questionID))
+	}
+
+	// Verify question matches room's current question
+	if room.CurrentQuestionID == nil {
+		log.Printf("❌ Room %s has no current question", room.ID)
+		return echo.NewHTTPError(http.StatusBadRequest, "No active question for this room")
+	}
+
+	if *room.CurrentQuestionID != questionID {
+		log.Printf("❌ Question mismatch: room current=%s, submitted=%s", *room.CurrentQuestionID, questionID)
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Question mismatch: you're answering question %s but current question is %s", questionID, *room.CurrentQuestionID))
+	}
+
+	answer := &models.Answer{
+		ID:         uuid.New(),
+		RoomID:     room.ID,
+		QuestionID: questionID,
+		UserID:     userID,
+		AnswerText: answerText,
+		ActionType: actionType,
+	}
+
+	if err := h.GameService.SubmitAnswer(ctx, answer); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to submit answer: "+err.Error())
+	}
+
+	log.Printf("✅ Answer submitted by user %s in room %s (action: %s)", userID, room.ID, actionType)
+
+	// NOTE: We do NOT clear current_question_id here anymore.
+	// The question remains visible so both players can see the question + answer together.
+	// current_question_id will be cleared when the new active player clicks "Next Question"
+
+	// Change turn immediately after answer submission
+	// The new active player will see the answer and click "Next Question" to draw
+	// For "skipped" action, turn doesn't change but question drawing is deferred to next question click
+	if actionType == "answered" {
+		log.Printf("🔄 Switching turn after answer in room %s", room.ID)
+		if err := h.GameService.ChangeTurn(ctx, room.ID); err != nil {
+			log.Printf("❌ Failed to change turn: %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// nextQuestion clears the answered question and draws the next one for the active player
+func (h *Handler) nextQuestion(ctx context.Context, room *models.Room, userID uuid.UUID) error {
+	if room.CurrentTurn == nil || *room.CurrentTurn != userID {
+		return echo.NewHTTPError(http.StatusForbidden, "Not your turn")
+	}
+
+	// Clear the current question ID before drawing a new one
+	// This is necessary because DrawQuestion has an idempotency check
+	// that returns the existing question if CurrentQuestionID is set
+	room.CurrentQuestionID = nil
+	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
+		log.Printf("Error clearing current question: %v", err)
+		return err
+	}
+
+	// Draw next question (this also broadcasts question_drawn via SSE)
+	if _, err := h.GameService.DrawQuestion(ctx, room.ID); err != nil {
+		log.Printf("Error drawing question: %v", err)
+		return err
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/hekigan/couples/internal/middleware"
 	"github.com/hekigan/couples/internal/models"
 	"github.com/hekigan/couples/internal/services"
+	"github.com/hekigan/couples/internal/viewmodels"
 	roomFragments "github.com/hekigan/couples/internal/views/fragments/room"
 	gamePages "github.com/hekigan/couples/internal/views/pages/game"
 	"github.com/labstack/echo/v4"
@@ -67,15 +68,15 @@ func (h *Handler) LeaveRoomHandler(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "You are not a guest in this room")
 	}
 
-	// Remove the guest from the room by setting GuestID to nil
-	room.GuestID = nil
-	room.Status = "waiting" // Reset status to waiting
-
+	// Remove the guest from the room; the room goes back to waiting
 	log.Printf("DEBUG LeaveRoom: Updating room to remove guest - GuestID will be set to nil")
 
-	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
+	if _, err := h.RoomService.TransitionWith(ctx, roomID, models.RoomEventGuestLeft, map[string]interface{}{
+		"guest_id":    nil,
+		"guest_ready": false,
+	}); err != nil {
 		log.Printf("ERROR LeaveRoom: Failed to update room: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to leave room")
+		return RoomWriteError(c, err, "Failed to leave room")
 	}
 
 	log.Printf("DEBUG LeaveRoom: Successfully updated room %s, guest removed", roomID)
@@ -102,21 +103,34 @@ func (h *Handler) CreateRoomHandler(c echo.Context) error {
 		data := NewTemplateData(c)
 		data.Title = "Create Room"
 		data.User = currentUser
+		data.Data = &viewmodels.CreateRoomData{
+			Languages:        h.LanguageService.Enabled(),
+			SelectedLanguage: h.defaultRoomLanguage(c, currentUser),
+		}
 		return h.RenderTemplComponent(c, gamePages.CreateRoomPage(data))
 	}
 
 	// POST - Create room
+	language := c.FormValue("language")
+	if language == "" {
+		language = h.defaultRoomLanguage(c, currentUser)
+	}
+	if !h.LanguageService.IsEnabled(language) {
+		return echo.NewHTTPError(http.StatusBadRequest, "Unsupported language")
+	}
 
 	// Parse is_private checkbox (checkbox is "on" when checked, empty when unchecked)
 	isPrivate := c.FormValue("is_private") == "on"
+	allowRepeats := c.FormValue("allow_repeats") == "on"
 
 	room := &models.Room{
-		ID:        uuid.New(),
-		Name:      c.FormValue("name"),
-		OwnerID:   userID,
-		Status:    "waiting",
-		Language:  "en",
-		IsPrivate: isPrivate,
+		ID:           uuid.New(),
+		Name:         c.FormValue("name"),
+		OwnerID:      userID,
+		Status:       models.RoomWaiting,
+		Language:     language,
+		IsPrivate:    isPrivate,
+		AllowRepeats: allowRepeats,
 	}
 
 	if err := h.RoomService.CreateRoom(ctx, room); err != nil {
@@ -241,12 +255,11 @@ func (h *Handler) JoinRoomHandler(c echo.Context) error {
 	}
 
 	// For public rooms, join directly
-	// Join the room by setting the guest_id
-	room.GuestID = &userID
-	room.Status = "ready" // Room is ready when both players are present
-
-	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to join room")
+	// Join the room by setting the guest_id (the room becomes ready)
+	if _, err := h.RoomService.TransitionWith(ctx, room.ID, models.RoomEventGuestJoined, map[string]interface{}{
+		"guest_id": userID.String(),
+	}); err != nil {
+		return RoomWriteError(c, err, "Failed to join room")
 	}
 
 	// Redirect to the room
@@ -268,3 +281,15 @@ func (h *Handler) DeleteRoomHandler(c echo.Context) error {
 
 	return c.Redirect(http.StatusSeeOther, "/?success=Room+deleted")
 }
+
+// defaultRoomLanguage is the question language of a new room: the owner's saved question
+// language, else the UI language, when enabled; DefaultLanguage otherwise
+func (h *Handler) defaultRoomLanguage(c echo.Context, owner *models.User) string {
+	if owner.QuestionLanguage != "" && h.LanguageService.IsEnabled(owner.QuestionLanguage) {
+		return owner.QuestionLanguage
+	}
+	if language, ok := middleware.GetLanguage(c); ok && h.LanguageService.IsEnabled(language) {
+		return language
+	}
+	return services.DefaultLanguage
+}
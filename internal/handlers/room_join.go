@@ -227,7 +227,7 @@ func (h *Handler) AcceptJoinRequestHandler(c echo.Context) error {
 	} else {
 		log.Printf("🔍 Room status after accepting join request: %s", room.Status)
 		if statusBadgeHTML, err := h.RenderTemplFragment(c, roomFragments.StatusBadge(&services.RoomStatusBadgeData{
-			Status: room.Status,
+			Status: string(room.Status),
 		})); err == nil {
 			h.RoomService.GetRealtimeService().BroadcastHTMLFragment(joinRequest.RoomID, services.HTMLFragmentEvent{
 				Type:       "room_status_update",
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/services"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+// RoomCommand is a player action sent upstream over the room WebSocket
+// Ref is echoed back in the command_result event so the client can match replies.
+type RoomCommand struct {
+	Type string `json:"type"` // typing, toggle_category, submit_answer, next_question
+	Ref  string `json:"ref,omitempty"`
+
+	IsTyping   bool   `json:"is_typing,omitempty"`
+	CategoryID string `json:"category_id,omitempty"`
+	QuestionID string `json:"question_id,omitempty"`
+	AnswerText string `json:"answer_text,omitempty"`
+	ActionType string `json:"action_type,omitempty"`
+}
+
+// RoomCommandResult answers a RoomCommand (sent as a command_result event)
+// Status mirrors the HTTP endpoint's; Refresh asks the page to reload like HX-Refresh does,
+// and HTML carries the fragment the endpoint would have returned, if any.
+type RoomCommandResult struct {
+	Ref     string `json:"ref,omitempty"`
+	Status  int    `json:"status"`
+	Error   string `json:"error,omitempty"`
+	Refresh bool   `json:"refresh,omitempty"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// StreamRoomSocket serves the room over a WebSocket: the same events as StreamRoomEvents
+// downstream, as JSON frames ({"id", "type", "data"} with HTML fragments as string data),
+// and RoomCommand frames upstream. Missed events are replayed after ?last_event_id=.
+func (h *RealtimeHandler) StreamRoomSocket(c echo.Context) error {
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	room, roomID, err := h.handler.GetRoomFromRequest(c)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	if err := h.handler.VerifyRoomParticipant(room, userID); err != nil {
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	}
+
+	// The connection only keeps these values: c must not be used once the handler returns
+	socket := roomSocket{
+		roomID:      roomID,
+		userID:      userID,
+		lastEventID: lastEventID(c.QueryParam("last_event_id")),
+		canWrite:    middleware.HasScope(c, models.ScopeRoomsWrite),
+		// Commands finish even if the connection drops; fragments render in the player's language
+		commandCtx: h.handler.withTranslator(context.Background(), c),
+	}
+
+	server := websocket.Server{
+		Handshake: originHandshake(middleware.IsTokenAuthenticated(c)),
+		Handler: func(ws *websocket.Conn) {
+			h.serveRoomSocket(ws.Request().Context(), ws, socket)
+		},
+	}
+	server.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
+
+// roomSocket is what one room WebSocket connection needs from its upgrade request
+type roomSocket struct {
+	roomID, userID uuid.UUID
+	lastEventID    int64
+	canWrite       bool // rooms:write, for API tokens
+	commandCtx     context.Context
+}
+
+// lastEventID parses the ?last_event_id= a reconnecting socket resumes after
+func lastEventID(value string) int64 {
+	id, _ := strconv.ParseInt(value, 10, 64)
+	return id
+}
+
+// originHandshake returns the handshake check for a WebSocket upgrade
+// API token clients send no cookies, so they may omit Origin; session requests must come
+// from a page of this origin (see sameOriginHandshake).
+func originHandshake(tokenAuthenticated bool) func(*websocket.Config, *http.Request) error {
+	return func(config *websocket.Config, r *http.Request) error {
+		if tokenAuthenticated && r.Header.Get("Origin") == "" {
+			return nil
+		}
+		return sameOriginHandshake(config, r)
+	}
+}
+
+// sameOriginHandshake rejects WebSocket connections opened by pages of another origin, or
+// without an Origin: the session cookie comes along with the upgrade, and the CSRF token does not.
+func sameOriginHandshake(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return fmt.Errorf("websocket without an Origin rejected")
+	}
+
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host != r.Host {
+		return fmt.Errorf("cross-origin websocket from %q rejected", origin)
+	}
+	config.Origin = parsed
+	return nil
+}
+
+// serveRoomSocket runs one connection: commands are read and executed in order on their own
+// goroutine, while every frame is written from this one. It returns once both have stopped.
+func (h *RealtimeHandler) serveRoomSocket(ctx context.Context, ws *websocket.Conn, socket roomSocket) {
+	defer ws.Close()
+	roomID, userID := socket.roomID, socket.userID
+
+	client, missed, complete := h.realtimeService.SubscribeFrom(ctx, roomID, userID, socket.lastEventID)
+	defer h.realtimeService.Unsubscribe(client.ID)
+
+	send := func(event services.RealtimeEvent) bool {
+		return websocket.JSON.Send(ws, event) == nil
+	}
+
+	if !send(services.RealtimeEvent{Type: "connected", Data: map[string]string{"room_id": roomID.String()}}) {
+		return
+	}
+	if !complete && !send(services.RealtimeEvent{Type: "resync", Data: map[string]string{"room_id": roomID.String()}}) {
+		return
+	}
+	for _, event := range missed {
+		if !send(event) {
+			return
+		}
+	}
+
+	results := make(chan RoomCommandResult, 8)
+	readerDone := make(chan struct{})
+	stop := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			var cmd RoomCommand
+			if err := websocket.JSON.Receive(ws, &cmd); err != nil {
+				return
+			}
+			select {
+			case results <- h.runRoomCommand(socket, cmd):
+			case <-stop:
+				return
+			}
+		}
+	}()
+	// Closing the connection unblocks Receive; wait for the reader before returning
+	defer func() {
+		close(stop)
+		ws.Close()
+		<-readerDone
+	}()
+
+	ticker := time.NewTicker(15 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-client.Channel:
+			if !ok {
+				return
+			}
+			if !send(event) {
+				return
+			}
+		case result := <-results:
+			if !send(services.RealtimeEvent{Type: "command_result", Data: result}) {
+				return
+			}
+		case <-ticker.C:
+			if !send(services.RealtimeEvent{Type: "ping", Data: map[string]string{"time": time.Now().Format(time.RFC3339)}}) {
+				return
+			}
+		case <-readerDone:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runRoomCommand executes a command with the same checks as its HTTP endpoint
+func (h *RealtimeHandler) runRoomCommand(socket roomSocket, cmd RoomCommand) RoomCommandResult {
+	ctx, roomID, userID := socket.commandCtx, socket.roomID, socket.userID
+	result := RoomCommandResult{Ref: cmd.Ref, Status: http.StatusOK}
+
+	// The socket is opened with a GET, so the route only asked for rooms:read
+	if cmd.Type != "typing" && !socket.canWrite {
+		return commandResultError(result, echo.NewHTTPError(http.StatusForbidden, "API token lacks the "+models.ScopeRoomsWrite+" scope"))
+	}
+
+	// Every command works on the current room, not the one seen when the socket opened
+	room, err := h.handler.RoomService.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return commandResultError(result, echo.NewHTTPError(http.StatusNotFound, "Room not found"))
+	}
+	if err := h.handler.VerifyRoomParticipant(room, userID); err != nil {
+		return commandResultError(result, echo.NewHTTPError(http.StatusForbidden, err.Error()))
+	}
+
+	switch cmd.Type {
+	case "typing":
+		_, err = h.handler.playerTyping(room, userID, cmd.IsTyping)
+
+	case "toggle_category":
+		categoryID, parseErr := uuid.Parse(cmd.CategoryID)
+		if parseErr != nil {
+			return commandResultError(result, echo.NewHTTPError(http.StatusBadRequest, "Invalid category ID"))
+		}
+		err = h.handler.toggleCategory(ctx, room, userID, categoryID)
+
+	case "submit_answer":
+		questionID, parseErr := uuid.Parse(cmd.QuestionID)
+		if parseErr != nil {
+			return commandResultError(result, echo.NewHTTPError(http.StatusBadRequest, "Invalid question ID format"))
+		}
+		actionType := cmd.ActionType
+		if actionType == "" {
+			actionType = "answered"
+		}
+		if err = h.handler.submitAnswer(ctx, room, userID, questionID, cmd.AnswerText, actionType); err == nil {
+			result.HTML = h.handler.renderGameForms(ctx, roomID, userID)
+		}
+
+	case "next_question":
+		if err = h.handler.nextQuestion(ctx, room, userID); err == nil {
+			result.HTML = h.handler.renderGameForms(ctx, roomID, userID)
+		}
+
+	default:
+		return commandResultError(result, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown command %q", cmd.Type)))
+	}
+
+	if err != nil {
+		log.Printf("⚠️ Room command %s failed in room %s: %v", cmd.Type, roomID, err)
+		return commandResultError(result, err)
+	}
+	return result
+}
+
+// commandResultError fills in a failed result the way the HTTP endpoints would answer
+func commandResultError(result RoomCommandResult, err error) RoomCommandResult {
+	switch e := err.(type) {
+	case *echo.HTTPError:
+		result.Status = e.Code
+		result.Error = fmt.Sprint(e.Message)
+	default:
+		if isStaleRoomWrite(err) {
+			result.Status = http.StatusConflict
+			result.Refresh = true
+		} else if errors.Is(err, models.ErrQuestionsAllSeen) {
+			result.Status = http.StatusConflict
+		} else {
+			result.Status = http.StatusInternalServerError
+		}
+		result.Error = err.Error()
+	}
+	return result
+}
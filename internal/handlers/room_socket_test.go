@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/net/websocket"
+)
+
+func TestOriginHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   bool
+		origin  string
+		wantErr bool
+	}{
+		{name: "session without origin", origin: "", wantErr: true},
+		{name: "session same origin", origin: "http://example.com", wantErr: false},
+		{name: "session other origin", origin: "http://evil.example", wantErr: true},
+		{name: "session other port", origin: "http://example.com:8080", wantErr: true},
+		{name: "token without origin", token: true, origin: "", wantErr: false},
+		{name: "token other origin", token: true, origin: "http://evil.example", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/stream/rooms/1/ws", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			err := originHandshake(tt.token)(&websocket.Config{}, req)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("originHandshake(%v) error = %v, wantErr %v", tt.token, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRunRoomCommand_RequiresWriteScope(t *testing.T) {
+	socket := roomSocket{roomID: uuid.New(), userID: uuid.New(), canWrite: false, commandCtx: context.Background()}
+
+	h := &RealtimeHandler{}
+	for _, command := range []string{"toggle_category", "submit_answer", "next_question"} {
+		result := h.runRoomCommand(socket, RoomCommand{Type: command, Ref: "1"})
+		if result.Status != http.StatusForbidden {
+			t.Errorf("%s with a read-only token: status %d, want 403", command, result.Status)
+		}
+	}
+}
+
+func TestCommandResultError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantRefresh bool
+	}{
+		{name: "rejected command", err: echo.NewHTTPError(http.StatusForbidden, "Not your turn"), wantStatus: http.StatusForbidden},
+		{name: "version conflict", err: fmt.Errorf("update room: %w", models.ErrConflict), wantStatus: http.StatusConflict, wantRefresh: true},
+		{name: "illegal transition", err: models.ErrIllegalRoomTransition, wantStatus: http.StatusConflict, wantRefresh: true},
+		{name: "questions all seen", err: fmt.Errorf("draw: %w", models.ErrQuestionsAllSeen), wantStatus: http.StatusConflict},
+		{name: "other failure", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := commandResultError(RoomCommandResult{Ref: "7", Status: http.StatusOK}, tt.err)
+
+			if result.Ref != "7" {
+				t.Errorf("Ref = %q, want 7", result.Ref)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %d, want %d", result.Status, tt.wantStatus)
+			}
+			if result.Refresh != tt.wantRefresh {
+				t.Errorf("Refresh = %v, want %v", result.Refresh, tt.wantRefresh)
+			}
+			if result.Error == "" {
+				t.Error("Error is empty")
+			}
+		})
+	}
+}
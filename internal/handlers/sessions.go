@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/views/pages"
+	"github.com/labstack/echo/v4"
+)
+
+// SessionsHandler lists the devices signed in to the current user's account
+func (h *Handler) SessionsHandler(c echo.Context) error {
+	return h.renderSessionsAction(c, nil)
+}
+
+// RevokeSessionHandler signs one of the current user's devices out
+func (h *Handler) RevokeSessionHandler(c echo.Context) error {
+	return h.renderSessionsAction(c, func(userID uuid.UUID, currentToken string) (string, error) {
+		sessionID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid session ID")
+		}
+		return "The device has been signed out.", h.SessionService.RevokeSession(c.Request().Context(), userID, sessionID)
+	})
+}
+
+// RevokeOtherSessionsHandler signs every device but this one out
+func (h *Handler) RevokeOtherSessionsHandler(c echo.Context) error {
+	return h.renderSessionsAction(c, func(userID uuid.UUID, currentToken string) (string, error) {
+		return "All other devices have been signed out.", h.SessionService.RevokeOtherSessions(c.Request().Context(), userID, currentToken)
+	})
+}
+
+// renderSessionsAction runs a session action (if any) for the current user and renders the
+// devices page with its outcome
+func (h *Handler) renderSessionsAction(c echo.Context, action func(userID uuid.UUID, currentToken string) (string, error)) error {
+	ctx := c.Request().Context()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	var currentToken string
+	if sess, err := middleware.GetSession(c); err == nil {
+		currentToken = sess.ID
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "My Devices"
+
+	if action != nil {
+		message, err := action(userID, currentToken)
+		if httpErr, ok := err.(*echo.HTTPError); ok {
+			return httpErr
+		}
+		if err != nil {
+			log.Printf("Session action for user %s failed: %v", userID, err)
+			data.Error = "Something went wrong. Please try again later."
+		} else {
+			data.Success = message
+		}
+	}
+
+	sessions, err := h.SessionService.ListUserSessions(ctx, userID, currentToken)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load devices")
+	}
+	data.Data = sessions
+	return h.RenderTemplComponent(c, pages.SessionsPage(data))
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/hekigan/couples/internal/viewmodels"
+	"github.com/hekigan/couples/internal/views/pages"
+	authPages "github.com/hekigan/couples/internal/views/pages/auth"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// twoFactorLoginPath is where a sign-in waiting for its second factor continues
+	twoFactorLoginPath = "/login/two-factor"
+	// pendingLoginTTL is how long a sign-in waits for its second factor
+	pendingLoginTTL = 10 * time.Minute
+)
+
+// pendingLoginKeys are the session values holding a sign-in until its second factor is entered
+var pendingLoginKeys = []string{"pending_user_id", "pending_access_token", "pending_refresh_token", "pending_since"}
+
+// TwoFactorLoginHandler displays the code prompt of a sign-in waiting for its second factor
+func (h *Handler) TwoFactorLoginHandler(c echo.Context) error {
+	if _, ok := pendingLoginUserID(c); !ok {
+		return c.Redirect(http.StatusSeeOther, "/login")
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "Two-Factor Authentication - Couple Card Game"
+	return h.RenderTemplComponent(c, authPages.TwoFactorPage(data))
+}
+
+// TwoFactorLoginPostHandler checks the code and completes the sign-in
+func (h *Handler) TwoFactorLoginPostHandler(c echo.Context) error {
+	ctx := c.Request().Context()
+	userID, ok := pendingLoginUserID(c)
+	if !ok {
+		return c.Redirect(http.StatusSeeOther, "/login")
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "Two-Factor Authentication - Couple Card Game"
+
+	if err := h.TwoFactorService.Verify(ctx, userID, c.FormValue("code")); err != nil {
+		data.Error = twoFactorError(err)
+		return h.RenderTemplComponent(c, authPages.TwoFactorPage(data))
+	}
+
+	user, err := h.UserService.GetUserByID(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load user %s after two-factor: %v", userID, err)
+		data.Error = "Something went wrong. Please try again later."
+		return h.RenderTemplComponent(c, authPages.TwoFactorPage(data))
+	}
+
+	// Keep what the visitor did as a guest
+	h.mergeAnonymousSession(c, user.ID)
+
+	sess, _ := middleware.GetSession(c)
+	accessToken, _ := sess.Values["pending_access_token"].(string)
+	refreshToken, _ := sess.Values["pending_refresh_token"].(string)
+	for _, key := range pendingLoginKeys {
+		delete(sess.Values, key)
+	}
+	if err := middleware.RenewSession(c, sess); err != nil {
+		log.Printf("Failed to renew session: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save session")
+	}
+	setUserSession(sess, user, accessToken, refreshToken, true)
+	if err := middleware.SaveSession(c, sess); err != nil {
+		log.Printf("Failed to save session: %v", err)
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to save session")
+	}
+
+	return c.Redirect(http.StatusSeeOther, "/")
+}
+
+// TwoFactorSettingsHandler displays the two-factor settings of the current user
+func (h *Handler) TwoFactorSettingsHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, nil)
+}
+
+// TwoFactorSetupHandler starts enrollment and shows the secret to add to an authenticator app
+func (h *Handler) TwoFactorSetupHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, func(user *models.User, tf *viewmodels.TwoFactorData) (string, error) {
+		enrollment, err := h.TwoFactorService.BeginEnrollment(c.Request().Context(), user)
+		if err != nil {
+			return "", err
+		}
+		tf.Secret = enrollment.Secret
+		tf.URI = enrollment.URI
+		return "", nil
+	})
+}
+
+// TwoFactorConfirmHandler enables two-factor authentication with a first code from the app
+func (h *Handler) TwoFactorConfirmHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, func(user *models.User, tf *viewmodels.TwoFactorData) (string, error) {
+		codes, err := h.TwoFactorService.ConfirmEnrollment(c.Request().Context(), user.ID, c.FormValue("code"))
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidTwoFactorCode) {
+				// Show the secret again so the user can retry
+				if enrollment, beginErr := h.TwoFactorService.BeginEnrollment(c.Request().Context(), user); beginErr == nil {
+					tf.Secret = enrollment.Secret
+					tf.URI = enrollment.URI
+				}
+			}
+			return "", err
+		}
+		tf.RecoveryCodes = codes
+		return "Two-factor authentication is on.", h.markTwoFactorVerified(c)
+	})
+}
+
+// TwoFactorVerifyHandler completes the second factor for the current session
+// Sessions started before enrollment (or by the development admin login) need it to reach the
+// admin pages.
+func (h *Handler) TwoFactorVerifyHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, func(user *models.User, tf *viewmodels.TwoFactorData) (string, error) {
+		if err := h.TwoFactorService.Verify(c.Request().Context(), user.ID, c.FormValue("code")); err != nil {
+			return "", err
+		}
+		return "Code accepted.", h.markTwoFactorVerified(c)
+	})
+}
+
+// TwoFactorDisableHandler turns two-factor authentication off
+func (h *Handler) TwoFactorDisableHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, func(user *models.User, tf *viewmodels.TwoFactorData) (string, error) {
+		if err := h.TwoFactorService.Disable(c.Request().Context(), user, c.FormValue("code")); err != nil {
+			return "", err
+		}
+		return "Two-factor authentication is off.", nil
+	})
+}
+
+// TwoFactorRecoveryCodesHandler replaces the recovery codes
+func (h *Handler) TwoFactorRecoveryCodesHandler(c echo.Context) error {
+	return h.renderTwoFactorAction(c, func(user *models.User, tf *viewmodels.TwoFactorData) (string, error) {
+		codes, err := h.TwoFactorService.RegenerateRecoveryCodes(c.Request().Context(), user.ID, c.FormValue("code"))
+		if err != nil {
+			return "", err
+		}
+		tf.RecoveryCodes = codes
+		return "New recovery codes issued; the old ones no longer work.", nil
+	})
+}
+
+// renderTwoFactorAction runs a two-factor action (if any) for the current user and renders the
+// settings page with its outcome
+func (h *Handler) renderTwoFactorAction(c echo.Context, action func(user *models.User, tf *viewmodels.TwoFactorData) (string, error)) error {
+	ctx := c.Request().Context()
+	userID, ok := middleware.GetUserID(c)
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Not authenticated")
+	}
+
+	user, err := h.UserService.GetUserByID(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load profile")
+	}
+	if user.IsAnonymous {
+		return c.Redirect(http.StatusSeeOther, "/profile")
+	}
+
+	data := NewTemplateData(c)
+	data.Title = "Two-Factor Authentication"
+	data.User = user
+
+	tf := &viewmodels.TwoFactorData{Required: user.IsAdmin}
+	if action != nil {
+		message, err := action(user, tf)
+		if err != nil {
+			data.Error = twoFactorError(err)
+		} else {
+			data.Success = message
+		}
+	}
+
+	if tf.Enabled, err = h.TwoFactorService.IsEnabled(ctx, userID); err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load two-factor settings")
+	}
+	if tf.Enabled {
+		if tf.RemainingCodes, err = h.TwoFactorService.RemainingRecoveryCodes(ctx, userID); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to load two-factor settings")
+		}
+	}
+	tf.Verified = middleware.GetTwoFactorVerified(c)
+
+	data.Data = tf
+	return h.RenderTemplComponent(c, pages.TwoFactorSettingsPage(data))
+}
+
+// markTwoFactorVerified records on the session that it completed the second factor
+func (h *Handler) markTwoFactorVerified(c echo.Context) error {
+	sess, err := middleware.GetSession(c)
+	if err != nil {
+		return err
+	}
+	if err := middleware.RenewSession(c, sess); err != nil {
+		return err
+	}
+	sess.Values["two_factor_verified"] = true
+	middleware.SetTwoFactorVerified(c, true)
+	return middleware.SaveSession(c, sess)
+}
+
+// pendingLoginUserID returns the user of a sign-in waiting for its second factor
+// Sign-ins older than pendingLoginTTL must start over.
+func pendingLoginUserID(c echo.Context) (uuid.UUID, bool) {
+	sess, err := middleware.GetSession(c)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	since, ok := sess.Values["pending_since"].(int64)
+	if !ok || time.Since(time.Unix(since, 0)) > pendingLoginTTL {
+		return uuid.Nil, false
+	}
+	userID, err := uuid.Parse(fmt.Sprint(sess.Values["pending_user_id"]))
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+// twoFactorError is the message shown when a two-factor action fails
+func twoFactorError(err error) string {
+	switch {
+	case errors.Is(err, models.ErrInvalidTwoFactorCode):
+		return "That code is not valid. Please try again."
+	case errors.Is(err, models.ErrTwoFactorLocked):
+		return "Too many wrong codes. Please wait a few minutes and try again."
+	case errors.Is(err, models.ErrTwoFactorRequired):
+		return "Admin accounts must keep two-factor authentication on."
+	case errors.Is(err, models.ErrTwoFactorEnabled):
+		return "Two-factor authentication is already on."
+	case errors.Is(err, models.ErrTwoFactorNotEnabled):
+		return "Two-factor authentication is not on. Set it up first."
+	}
+	log.Printf("Two-factor action failed: %v", err)
+	return "Something went wrong. Please try again later."
+}
@@ -35,7 +35,7 @@ func (h *Handler) SetGuestReadyAPIHandler(c echo.Context) error {
 	room.GuestReady = true
 	if err := h.RoomService.UpdateRoom(ctx, room); err != nil {
 		log.Printf("❌ Failed to update guest ready status: %v", err)
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update ready status: "+err.Error())
+		return RoomWriteError(c, err, "Failed to update ready status: "+err.Error())
 	}
 
 	log.Printf("✅ Guest %s marked as ready in room %s", userID, roomID)
@@ -61,7 +61,7 @@ func (h *Handler) SetGuestReadyAPIHandler(c echo.Context) error {
 
 	// Broadcast room status badge update via SSE (so owner sees status change)
 	if statusBadgeHTML, err := h.RenderTemplFragment(c, roomFragments.StatusBadge(&services.RoomStatusBadgeData{
-		Status: room.Status,
+		Status: string(room.Status),
 	})); err == nil {
 		h.RoomService.GetRealtimeService().BroadcastHTMLFragment(roomID, services.HTMLFragmentEvent{
 			Type:       "room_status_update",
@@ -100,7 +100,7 @@ func (h *Handler) RoomStatusBadgeAPIHandler(c echo.Context) error {
 
 	// Use helper to render HTML fragment for status badge
 	return h.RenderTemplComponent(c, roomFragments.StatusBadge(&services.RoomStatusBadgeData{
-		Status: room.Status,
+		Status: string(room.Status),
 	}))
 }
 
@@ -6,8 +6,12 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
-// EchoRequireAdmin enforces that a user must be an admin
-// Returns 404 (not 403) if unauthorized for security by obscurity
+// TwoFactorSetupPath is where admins without a verified second factor are sent
+const TwoFactorSetupPath = "/profile/two-factor"
+
+// EchoRequireAdmin enforces that a user must be an admin who completed the second factor
+// Returns 404 (not 403) if unauthorized for security by obscurity; an admin session without
+// the second factor is sent to TwoFactorSetupPath to enroll or enter a code.
 func EchoRequireAdmin() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
@@ -23,6 +27,17 @@ func EchoRequireAdmin() echo.MiddlewareFunc {
 				return echo.NewHTTPError(http.StatusNotFound, "Page not found")
 			}
 
+			if !GetTwoFactorVerified(c) {
+				if c.Request().Header.Get("HX-Request") == "true" {
+					c.Response().Header().Set("HX-Redirect", TwoFactorSetupPath)
+					return c.NoContent(http.StatusOK)
+				}
+				if c.Request().Method != http.MethodGet {
+					return echo.NewHTTPError(http.StatusForbidden, "Two-factor authentication required")
+				}
+				return c.Redirect(http.StatusSeeOther, TwoFactorSetupPath)
+			}
+
 			return next(c)
 		}
 	}
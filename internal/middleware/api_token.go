@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// APIScopesKey holds the scopes of a request authenticated by an API token
+const APIScopesKey = "api_scopes"
+
+// tokenAuthPrefixes are the paths where an API token may replace the session
+// The pages (and the API token management on /profile) stay session-only.
+var tokenAuthPrefixes = []string{"/api/", "/admin/api/"}
+
+// TokenAuthenticator resolves bearer tokens (see services.APITokenService)
+type TokenAuthenticator interface {
+	// AuthenticateToken returns the token's user, whether it acts as an admin, and its scopes
+	AuthenticateToken(ctx context.Context, token string) (userID uuid.UUID, isAdmin bool, scopes []string, err error)
+}
+
+// authenticateBearer authenticates a request carrying an "Authorization: Bearer" header on an
+// API path; ok is false when there is no such header
+func authenticateBearer(c echo.Context, tokens TokenAuthenticator) (bool, error) {
+	if tokens == nil || !isTokenAuthPath(c.Request().URL.Path) {
+		return false, nil
+	}
+	token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+	if !ok {
+		return false, nil
+	}
+
+	userID, isAdmin, scopes, err := tokens.AuthenticateToken(c.Request().Context(), strings.TrimSpace(token))
+	if errors.Is(err, models.ErrInvalidAPIToken) {
+		c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+		return false, echo.NewHTTPError(http.StatusUnauthorized, "Invalid or expired API token")
+	}
+	if err != nil {
+		log.Printf("API token authentication failed: %v", err)
+		return false, echo.NewHTTPError(http.StatusInternalServerError, "Authentication failed")
+	}
+
+	SetUserID(c, userID)
+	SetIsAdmin(c, isAdmin)
+	// Admin tokens can only be created from a session that completed the second factor
+	SetTwoFactorVerified(c, isAdmin)
+	c.Set(APIScopesKey, scopes)
+	return true, nil
+}
+
+// isTokenAuthPath reports whether an API token may authenticate requests to path
+func isTokenAuthPath(path string) bool {
+	for _, prefix := range tokenAuthPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAPIScopes returns the scopes of a request authenticated by an API token
+// ok is false for session requests.
+func GetAPIScopes(c echo.Context) ([]string, bool) {
+	scopes, ok := c.Get(APIScopesKey).([]string)
+	return scopes, ok
+}
+
+// IsTokenAuthenticated reports whether the request was authenticated by an API token
+// Such requests carry no cookies a third-party site could replay, so CSRF checks skip them.
+func IsTokenAuthenticated(c echo.Context) bool {
+	_, ok := GetAPIScopes(c)
+	return ok
+}
+
+// HasScope reports whether the request may use scope: session requests always may,
+// token-authenticated requests only when their token carries it
+func HasScope(c echo.Context, scope string) bool {
+	scopes, ok := GetAPIScopes(c)
+	return !ok || slices.Contains(scopes, scope)
+}
+
+// EchoRequireScope refuses token-authenticated requests whose token lacks scope
+// Session requests are not affected.
+func EchoRequireScope(scope string) echo.MiddlewareFunc {
+	return EchoRequireReadWriteScope(scope, scope)
+}
+
+// EchoRequireReadWriteScope is EchoRequireScope with readScope for GET and HEAD requests and
+// writeScope for the others
+func EchoRequireReadWriteScope(readScope, writeScope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			scope := writeScope
+			if method := c.Request().Method; method == http.MethodGet || method == http.MethodHead {
+				scope = readScope
+			}
+			if !HasScope(c, scope) {
+				return echo.NewHTTPError(http.StatusForbidden, "API token lacks the "+scope+" scope")
+			}
+			return next(c)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -8,10 +9,18 @@ import (
 )
 
 // EchoAuth extracts the user ID from the session and adds it to the Echo context
+// On API paths an "Authorization: Bearer" token resolved by tokens replaces the session; an
+// invalid token is refused with 401. tokens may be nil to only accept sessions.
 // This middleware does NOT enforce authentication - it only extracts the user if present
-func EchoAuth() echo.MiddlewareFunc {
+func EchoAuth(tokens TokenAuthenticator) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if ok, err := authenticateBearer(c, tokens); err != nil {
+				return err
+			} else if ok {
+				return next(c)
+			}
+
 			session, err := GetSession(c)
 			if err != nil {
 				return next(c)
@@ -29,12 +38,16 @@ func EchoAuth() echo.MiddlewareFunc {
 				SetIsAdmin(c, isAdmin)
 			}
 
+			if verified, ok := session.Values["two_factor_verified"].(bool); ok {
+				SetTwoFactorVerified(c, verified)
+			}
+
 			return next(c)
 		}
 	}
 }
 
-// EchoRequireAuth enforces that a user must be authenticated
+// EchoRequireAuth enforces that a user must be authenticated, by session or API token
 // If not authenticated, redirects to /login
 func EchoRequireAuth() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -87,3 +100,21 @@ func EchoAnonymousSession() echo.MiddlewareFunc {
 		}
 	}
 }
+
+// LastSeenTracker records user activity (see services.UserService)
+type LastSeenTracker interface {
+	TouchLastSeen(ctx context.Context, userID uuid.UUID)
+}
+
+// EchoTrackLastSeen records the activity of the user set by EchoAuth or EchoAnonymousSession
+// It keeps last_seen_at current, which decides when an anonymous user expires.
+func EchoTrackLastSeen(tracker LastSeenTracker) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if userID, ok := GetUserID(c); ok {
+				tracker.TouchLastSeen(c.Request().Context(), userID)
+			}
+			return next(c)
+		}
+	}
+}
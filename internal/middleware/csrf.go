@@ -20,8 +20,9 @@ func EchoCSRF() echo.MiddlewareFunc {
 		CookieSameSite: http.SameSiteLaxMode,
 		CookiePath:     "/",
 		CookieMaxAge:   86400, // 24 hours
-		// No Skipper - let Echo's CSRF middleware handle all requests
-		// It will generate tokens on GET and validate on POST/DELETE/etc
+		// Only API token requests are skipped; for everything else Echo's CSRF middleware
+		// generates tokens on GET and validates them on POST/DELETE/etc
+		Skipper: IsTokenAuthenticated,
 	}
 
 	return middleware.CSRFWithConfig(config)
@@ -11,12 +11,14 @@ const (
 	UserIDKey   = "user_id"
 	LanguageKey = "language"
 	IsAdminKey  = "is_admin"
+	// TwoFactorKey marks a session that completed the second factor
+	TwoFactorKey = "two_factor_verified"
 )
 
 // GetSession retrieves the session from the Echo context
 // This bridges gorilla/sessions with Echo by accessing the underlying http.Request
 func GetSession(c echo.Context) (*sessions.Session, error) {
-	return Store.Get(c.Request(), "couple-card-game-session")
+	return Store.Get(c.Request(), SessionCookieName)
 }
 
 // SaveSession saves the session to the response
@@ -24,6 +26,11 @@ func SaveSession(c echo.Context, session *sessions.Session) error {
 	return session.Save(c.Request(), c.Response())
 }
 
+// RenewSession gives the session a new token, ending the old one; the caller saves it
+func RenewSession(c echo.Context, session *sessions.Session) error {
+	return Store.Renew(c.Request(), session)
+}
+
 // SetUserID stores the user ID in the Echo context
 func SetUserID(c echo.Context, id uuid.UUID) {
 	c.Set(UserIDKey, id)
@@ -56,3 +63,14 @@ func GetIsAdmin(c echo.Context) (bool, bool) {
 	isAdmin, ok := c.Get(IsAdminKey).(bool)
 	return isAdmin, ok
 }
+
+// SetTwoFactorVerified stores whether the session completed the second factor
+func SetTwoFactorVerified(c echo.Context, verified bool) {
+	c.Set(TwoFactorKey, verified)
+}
+
+// GetTwoFactorVerified reports whether the session completed the second factor
+func GetTwoFactorVerified(c echo.Context) bool {
+	verified, _ := c.Get(TwoFactorKey).(bool)
+	return verified
+}
@@ -1,52 +1,70 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
-// Supported languages
-var supportedLanguages = map[string]bool{
-	"en": true,
-	"fr": true,
-	"ja": true,
+// LanguageRegistry tells which languages the UI can be shown in
+type LanguageRegistry interface {
+	IsEnabled(code string) bool
 }
 
-// EchoI18n detects and sets the user's language preference
-// Priority: query param ?lang= → cookie → Accept-Language header → default "en"
-func EchoI18n() echo.MiddlewareFunc {
+// LanguagePreferences returns the UI language a user saved in their profile ("" for none)
+type LanguagePreferences interface {
+	LanguagePreference(ctx context.Context, userID uuid.UUID) string
+}
+
+// EchoI18n detects and sets the user's language preference among the enabled languages
+// Priority: query param ?lang= → the signed-in user's saved language → cookie →
+// Accept-Language header → default "en". preferences may be nil to skip saved languages.
+func EchoI18n(languages LanguageRegistry, preferences LanguagePreferences) echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			var language string
 
 			// 1. Check query parameter
-			if lang := c.QueryParam("lang"); lang != "" && supportedLanguages[lang] {
+			if lang := c.QueryParam("lang"); lang != "" && languages.IsEnabled(lang) {
 				language = lang
 			}
 
-			// 2. Check cookie if query param not present
+			// 2. Check the language saved in the user's profile, which follows them across devices
+			if language == "" && preferences != nil {
+				if userID, ok := GetUserID(c); ok {
+					if lang := preferences.LanguagePreference(c.Request().Context(), userID); lang != "" && languages.IsEnabled(lang) {
+						language = lang
+					}
+				}
+			}
+
+			// 3. Check cookie if no language yet
 			if language == "" {
 				if cookie, err := c.Cookie("language"); err == nil {
-					if supportedLanguages[cookie.Value] {
+					if languages.IsEnabled(cookie.Value) {
 						language = cookie.Value
 					}
 				}
 			}
 
-			// 3. Check Accept-Language header
+			// 4. Check Accept-Language header
 			if language == "" {
 				acceptLanguage := c.Request().Header.Get("Accept-Language")
 				if acceptLanguage != "" {
 					// Parse Accept-Language header (e.g., "en-US,en;q=0.9,fr;q=0.8")
-					languages := strings.Split(acceptLanguage, ",")
-					for _, lang := range languages {
-						// Extract language code (before - or ;)
-						langCode := strings.Split(strings.TrimSpace(lang), "-")[0]
-						langCode = strings.Split(langCode, ";")[0]
+					for _, lang := range strings.Split(acceptLanguage, ",") {
+						// Try the full tag (pt-BR), then the language code before the -
+						tag := strings.Split(strings.TrimSpace(lang), ";")[0]
+						langCode := strings.ToLower(strings.Split(tag, "-")[0])
 
-						if supportedLanguages[langCode] {
+						if languages.IsEnabled(tag) {
+							language = tag
+							break
+						}
+						if languages.IsEnabled(langCode) {
 							language = langCode
 							break
 						}
@@ -54,7 +72,7 @@ func EchoI18n() echo.MiddlewareFunc {
 				}
 			}
 
-			// 4. Default to "en"
+			// 5. Default to "en"
 			if language == "" {
 				language = "en"
 			}
@@ -1,27 +1,202 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
+	"fmt"
+	"log"
+	"net"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 )
 
-var Store *sessions.CookieStore
+// SessionCookieName is the name of the session cookie
+const SessionCookieName = "couple-card-game-session"
+
+// SessionBackend keeps the server-side sessions, looked up by the token in the cookie
+// services.SessionService implements it on the sessions table.
+type SessionBackend interface {
+	// LoadSession returns the encoded values of the unexpired session with token
+	LoadSession(ctx context.Context, token string) (data string, found bool, err error)
+	// SaveSession creates or updates the session with token
+	SaveSession(ctx context.Context, token, userID, data, userAgent, ipAddress string, expiresAt time.Time) error
+	// DeleteSession ends the session with token
+	DeleteSession(ctx context.Context, token string) error
+}
+
+var Store *DBStore
 
 // InitSessionStore initializes the session store
-func InitSessionStore() {
-	secret := os.Getenv("SESSION_SECRET")
-	if secret == "" {
-		secret = "default-secret-change-in-production"
+// The cookie only carries a random token signed with the first secret; the others are previous
+// secrets still accepted, so a secret can be rotated without logging everyone out. Without any
+// secret (development) a random one is used and sessions end when the server restarts.
+func InitSessionStore(backend SessionBackend, secrets ...string) {
+	var keys [][]byte
+	for _, secret := range secrets {
+		if secret != "" {
+			// Hash key only: the token is random, it needs signing but not encryption
+			keys = append(keys, []byte(secret), nil)
+		}
+	}
+	if len(keys) == 0 {
+		log.Printf("⚠️ SESSION_SECRET is not set: using a random secret, sessions end on restart")
+		keys = append(keys, securecookie.GenerateRandomKey(32), nil)
+	}
+
+	Store = &DBStore{
+		backend: backend,
+		codecs:  securecookie.CodecsFromPairs(keys...),
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   86400 * 7, // 7 days
+			HttpOnly: true,
+			Secure:   os.Getenv("ENV") == "production",
+			SameSite: http.SameSiteLaxMode,
+		},
+	}
+}
+
+// DBStore is a gorilla sessions.Store keeping session values server-side
+// A session's ID is its token; a session removed from the backend (revoked, or its user demoted
+// or deleted) comes back as a new, empty one.
+type DBStore struct {
+	backend SessionBackend
+	codecs  []securecookie.Codec
+	Options *sessions.Options
+}
+
+// Get returns the session for name, loading it once per request
+func (s *DBStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session stored for the request's cookie, or a new session
+// A missing, tampered or unknown cookie is not an error: the visitor just starts over.
+func (s *DBStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	var token string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &token, s.codecs...); err != nil {
+		return session, nil
+	}
+
+	data, found, err := s.backend.LoadSession(r.Context(), token)
+	if err != nil {
+		return session, fmt.Errorf("failed to load session: %w", err)
 	}
-	Store = sessions.NewCookieStore([]byte(secret))
-	Store.Options = &sessions.Options{
-		Path:     "/",
-		MaxAge:   86400 * 7, // 7 days
-		HttpOnly: true,
-		Secure:   os.Getenv("ENV") == "production",
-		SameSite: http.SameSiteLaxMode,
+	if !found {
+		return session, nil
 	}
+	if err := decodeSessionValues(data, session.Values); err != nil {
+		return session, fmt.Errorf("failed to decode session: %w", err)
+	}
+	session.ID = token
+	session.IsNew = false
+	return session, nil
 }
 
+// Save stores the session and sets its cookie; a negative MaxAge ends the session
+func (s *DBStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	ctx := r.Context()
+
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.backend.DeleteSession(ctx, session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return fmt.Errorf("failed to generate session token: %w", err)
+		}
+		session.ID = base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	data, err := encodeSessionValues(session.Values)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	userID, _ := session.Values["user_id"].(string)
+	expiresAt := time.Now().Add(time.Duration(session.Options.MaxAge) * time.Second)
+	if err := s.backend.SaveSession(ctx, session.ID, userID, data, r.UserAgent(), clientIP(r), expiresAt); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return fmt.Errorf("failed to encode session cookie: %w", err)
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// Renew ends the session's current token and gives it a new one on the next Save, keeping its
+// values. Call it when the session gains rights (sign-in, second factor) so a token planted
+// before, or seen by someone else, does not carry them.
+func (s *DBStore) Renew(r *http.Request, session *sessions.Session) error {
+	if session.ID != "" {
+		if err := s.backend.DeleteSession(r.Context(), session.ID); err != nil {
+			return fmt.Errorf("failed to end previous session: %w", err)
+		}
+	}
+	session.ID = ""
+	session.IsNew = true
+	return nil
+}
+
+// encodeSessionValues serializes session values (gob, like gorilla's cookie store) as base64
+func encodeSessionValues(values map[interface{}]interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(values); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodeSessionValues reverses encodeSessionValues into values
+func decodeSessionValues(data string, values map[interface{}]interface{}) error {
+	if data == "" {
+		return nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	return gob.NewDecoder(bytes.NewReader(raw)).Decode(&values)
+}
+
+// clientIP returns the address the request came from, as shown on the devices page
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		ip, _, _ := strings.Cut(forwarded, ",")
+		return strings.TrimSpace(ip)
+	}
+	if ip := r.Header.Get("X-Real-IP"); ip != "" {
+		return ip
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+)
+
+// EchoShutdownContext cancels the request context as soon as shutdownCtx is done
+// http.Server.Shutdown never cancels in-flight requests, so long-lived SSE streams
+// need this to notice the server is stopping and return on their own
+func EchoShutdownContext(shutdownCtx context.Context) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx, cancel := context.WithCancel(c.Request().Context())
+			defer cancel()
+
+			stop := context.AfterFunc(shutdownCtx, cancel)
+			defer stop()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+-- 0001 initial schema (down)
+-- ⚠️ Drops every table and view: ALL DATA IS LOST
+
+DROP VIEW IF EXISTS game_history;
+DROP VIEW IF EXISTS friends_with_details;
+DROP VIEW IF EXISTS invitations_with_details;
+DROP VIEW IF EXISTS active_games;
+DROP VIEW IF EXISTS join_requests_with_users;
+DROP VIEW IF EXISTS rooms_with_players;
+
+-- Order matters due to foreign keys
+DROP TABLE IF EXISTS answers CASCADE;
+DROP TABLE IF EXISTS question_history CASCADE;
+DROP TABLE IF EXISTS notifications CASCADE;
+DROP TABLE IF EXISTS room_invitations CASCADE;
+DROP TABLE IF EXISTS room_join_requests CASCADE;
+DROP TABLE IF EXISTS rooms CASCADE;
+DROP TABLE IF EXISTS questions CASCADE;
+DROP TABLE IF EXISTS categories CASCADE;
+DROP TABLE IF EXISTS friends CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS translations CASCADE;
+
+DROP FUNCTION IF EXISTS update_updated_at_column();
@@ -0,0 +1,896 @@
+-- 0001 initial schema
+-- Tables, triggers and RLS (formerly sql/schema.sql), performance indexes
+-- (sql/indexes.sql) and read views (sql/views.sql).
+-- Every statement is re-runnable, so databases created from the old hand-applied
+-- files can adopt migrations with `server migrate up`.
+
+-- ============================================================================
+-- Couple Card Game Database Schema
+-- PostgreSQL with Supabase
+-- Last Updated: November 6, 2025
+-- ============================================================================
+
+-- Enable UUID extension
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- ============================================================================
+-- CORE TABLES
+-- ============================================================================
+
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    email VARCHAR(255) UNIQUE,
+    username VARCHAR(50) UNIQUE NOT NULL,
+    is_admin BOOLEAN DEFAULT FALSE,
+    is_anonymous BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_anonymous ON users(is_anonymous) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
+
+COMMENT ON TABLE users IS 'Application users - supports both authenticated and anonymous users';
+
+-- Friends table
+CREATE TABLE IF NOT EXISTS friends (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    friend_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'accepted', 'declined')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, friend_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_friends_user_id ON friends(user_id);
+CREATE INDEX IF NOT EXISTS idx_friends_friend_id ON friends(friend_id);
+CREATE INDEX IF NOT EXISTS idx_friends_status ON friends(status);
+
+COMMENT ON TABLE friends IS 'User friendships and friend requests';
+
+-- Categories table
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    key VARCHAR(100) NOT NULL UNIQUE,
+    label VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_key ON categories(key);
+
+COMMENT ON TABLE categories IS 'Question categories (e.g., romance, dreams, past)';
+COMMENT ON COLUMN categories.key IS 'Internal key for category (e.g., couples, friends, sex)';
+COMMENT ON COLUMN categories.label IS 'Human-readable display name for the category';
+
+-- Questions table
+CREATE TABLE IF NOT EXISTS questions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    lang_code VARCHAR(10) NOT NULL,
+    question_text TEXT NOT NULL,
+    base_question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_questions_category_id ON questions(category_id);
+CREATE INDEX IF NOT EXISTS idx_questions_lang_code ON questions(lang_code);
+CREATE INDEX IF NOT EXISTS idx_questions_category_lang ON questions(category_id, lang_code);
+CREATE INDEX IF NOT EXISTS idx_questions_base_question_id ON questions(base_question_id);
+
+COMMENT ON TABLE questions IS 'Game questions in multiple languages';
+COMMENT ON COLUMN questions.base_question_id IS 'Links translations together. English questions reference themselves, translations reference the English version.';
+
+-- Rooms table
+CREATE TABLE IF NOT EXISTS rooms (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255),
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    guest_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    -- IMPORTANT: 'ready' status is REQUIRED for join request flow!
+    -- When a guest joins via join request, status changes: waiting → ready → playing
+    status VARCHAR(50) NOT NULL CHECK (status IN ('waiting', 'ready', 'playing', 'finished')) DEFAULT 'waiting',
+    language VARCHAR(10) DEFAULT 'en',
+    is_private BOOLEAN DEFAULT FALSE,
+    guest_ready BOOLEAN DEFAULT FALSE,
+    max_questions INT DEFAULT 20,
+    current_question INT DEFAULT 0,
+    current_question_id UUID REFERENCES questions(id),
+    selected_categories JSONB,
+    current_player_id UUID REFERENCES users(id),
+    paused_at TIMESTAMP WITH TIME ZONE,
+    disconnected_user UUID REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rooms_owner_id ON rooms(owner_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_guest_id ON rooms(guest_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_status ON rooms(status);
+CREATE INDEX IF NOT EXISTS idx_rooms_language ON rooms(language);
+CREATE INDEX IF NOT EXISTS idx_rooms_current_question_id ON rooms(current_question_id);
+CREATE INDEX IF NOT EXISTS idx_rooms_disconnected_user ON rooms(disconnected_user);
+
+COMMENT ON TABLE rooms IS 'Game rooms where two players play together';
+COMMENT ON COLUMN rooms.name IS 'Optional room name set by owner';
+COMMENT ON COLUMN rooms.status IS 'waiting=no guest, ready=guest joined, playing=game active, finished=game over';
+COMMENT ON COLUMN rooms.language IS 'Game language (en, fr, ja, etc.)';
+COMMENT ON COLUMN rooms.is_private IS 'Whether room requires invitation to join';
+COMMENT ON COLUMN rooms.max_questions IS 'Maximum number of questions for this game';
+COMMENT ON COLUMN rooms.current_question IS 'Current question number (0-based)';
+COMMENT ON COLUMN rooms.current_question_id IS 'ID of the currently active question (persists across page refreshes)';
+COMMENT ON COLUMN rooms.paused_at IS 'Timestamp when game was paused (if paused)';
+COMMENT ON COLUMN rooms.disconnected_user IS 'User who disconnected (if any)';
+
+-- Room join requests table
+CREATE TABLE IF NOT EXISTS room_join_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(50) NOT NULL CHECK (status IN ('pending', 'accepted', 'rejected')) DEFAULT 'pending',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(room_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_join_requests_room_id ON room_join_requests(room_id);
+CREATE INDEX IF NOT EXISTS idx_room_join_requests_user_id ON room_join_requests(user_id);
+CREATE INDEX IF NOT EXISTS idx_room_join_requests_status ON room_join_requests(status);
+
+COMMENT ON TABLE room_join_requests IS 'Requests from users to join private rooms';
+
+-- Room invitations table
+CREATE TABLE IF NOT EXISTS room_invitations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    inviter_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    invitee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(room_id, invitee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_invitations_invitee ON room_invitations(invitee_id);
+CREATE INDEX IF NOT EXISTS idx_room_invitations_room ON room_invitations(room_id);
+CREATE INDEX IF NOT EXISTS idx_room_invitations_status ON room_invitations(status);
+
+COMMENT ON TABLE room_invitations IS 'Room owner invitations sent to friends';
+
+-- Notifications table
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    message TEXT,
+    link VARCHAR(500),
+    read BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user ON notifications(user_id);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON notifications(user_id, read);
+CREATE INDEX IF NOT EXISTS idx_notifications_created ON notifications(created_at);
+CREATE INDEX IF NOT EXISTS idx_notifications_type ON notifications(type);
+
+COMMENT ON TABLE notifications IS 'User notifications for invitations, friend requests, etc.';
+COMMENT ON COLUMN notifications.type IS 'room_invitation, friend_request, game_start, message';
+
+-- Answers table
+CREATE TABLE IF NOT EXISTS answers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    answer_text TEXT,
+    action_type VARCHAR(50) NOT NULL CHECK (action_type IN ('answered', 'skipped')),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_answers_room_id ON answers(room_id);
+CREATE INDEX IF NOT EXISTS idx_answers_user_id ON answers(user_id);
+CREATE INDEX IF NOT EXISTS idx_answers_question_id ON answers(question_id);
+
+COMMENT ON TABLE answers IS 'User answers or skips to game questions';
+
+-- Question history table (prevents repeating questions in a room)
+CREATE TABLE IF NOT EXISTS question_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    asked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(room_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_question_history_room_id ON question_history(room_id);
+
+COMMENT ON TABLE question_history IS 'Tracks which questions have been asked in each room';
+
+-- Translations table
+CREATE TABLE IF NOT EXISTS translations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    lang_code VARCHAR(10) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(lang_code, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_translations_lang_code ON translations(lang_code);
+CREATE INDEX IF NOT EXISTS idx_translations_key ON translations(key);
+
+COMMENT ON TABLE translations IS 'UI translations for internationalization (i18n)';
+
+-- ============================================================================
+-- FUNCTIONS AND TRIGGERS
+-- ============================================================================
+
+-- Function for automatic timestamp updates
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Triggers for updated_at columns
+DROP TRIGGER IF EXISTS update_users_updated_at ON users;
+CREATE TRIGGER update_users_updated_at
+    BEFORE UPDATE ON users
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_categories_updated_at ON categories;
+CREATE TRIGGER update_categories_updated_at
+    BEFORE UPDATE ON categories
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_questions_updated_at ON questions;
+CREATE TRIGGER update_questions_updated_at
+    BEFORE UPDATE ON questions
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_rooms_updated_at ON rooms;
+CREATE TRIGGER update_rooms_updated_at
+    BEFORE UPDATE ON rooms
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_room_join_requests_updated_at ON room_join_requests;
+CREATE TRIGGER update_room_join_requests_updated_at
+    BEFORE UPDATE ON room_join_requests
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_room_invitations_updated_at ON room_invitations;
+CREATE TRIGGER update_room_invitations_updated_at
+    BEFORE UPDATE ON room_invitations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+DROP TRIGGER IF EXISTS update_translations_updated_at ON translations;
+CREATE TRIGGER update_translations_updated_at
+    BEFORE UPDATE ON translations
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- ============================================================================
+-- ROW LEVEL SECURITY (RLS)
+-- ============================================================================
+
+-- Note: RLS is DISABLED for development to support anonymous users.
+-- The Go backend handles authentication and authorization.
+--
+-- For production deployment:
+-- 1. Use Supabase Service Role key in your Go app
+-- 2. Enable RLS on sensitive tables
+-- 3. Create appropriate policies for authenticated users
+-- 4. Keep public tables (categories, questions, translations) open for read
+
+-- Disable RLS on tables that need anonymous user access
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+ALTER TABLE rooms DISABLE ROW LEVEL SECURITY;
+ALTER TABLE room_join_requests DISABLE ROW LEVEL SECURITY;
+ALTER TABLE room_invitations DISABLE ROW LEVEL SECURITY;
+ALTER TABLE notifications DISABLE ROW LEVEL SECURITY;
+
+-- Enable RLS on tables with appropriate policies
+ALTER TABLE friends ENABLE ROW LEVEL SECURITY;
+ALTER TABLE answers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE question_history ENABLE ROW LEVEL SECURITY;
+
+-- Public read-only tables (no RLS needed)
+ALTER TABLE categories DISABLE ROW LEVEL SECURITY;
+ALTER TABLE questions DISABLE ROW LEVEL SECURITY;
+ALTER TABLE translations DISABLE ROW LEVEL SECURITY;
+
+-- Friends policies
+DROP POLICY IF EXISTS "Users can view their own friendships" ON friends;
+CREATE POLICY "Users can view their own friendships" ON friends
+    FOR SELECT USING (auth.uid() = user_id OR auth.uid() = friend_id);
+
+DROP POLICY IF EXISTS "Users can manage their own friendships" ON friends;
+CREATE POLICY "Users can manage their own friendships" ON friends
+    FOR ALL USING (auth.uid() = user_id);
+
+-- Answers policies (only for authenticated users)
+DROP POLICY IF EXISTS "Room participants can view answers" ON answers;
+CREATE POLICY "Room participants can view answers" ON answers
+    FOR SELECT USING (
+        EXISTS (
+            SELECT 1 FROM rooms 
+            WHERE id = room_id 
+            AND (owner_id = auth.uid() OR guest_id = auth.uid())
+        )
+    );
+
+DROP POLICY IF EXISTS "Room participants can create answers" ON answers;
+CREATE POLICY "Room participants can create answers" ON answers
+    FOR INSERT WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM rooms 
+            WHERE id = room_id 
+            AND (owner_id = auth.uid() OR guest_id = auth.uid())
+        )
+    );
+
+-- Question history policies
+DROP POLICY IF EXISTS "Room participants can view question history" ON question_history;
+CREATE POLICY "Room participants can view question history" ON question_history
+    FOR SELECT USING (
+        EXISTS (
+            SELECT 1 FROM rooms 
+            WHERE id = room_id 
+            AND (owner_id = auth.uid() OR guest_id = auth.uid())
+        )
+    );
+
+DROP POLICY IF EXISTS "Room participants can add to question history" ON question_history;
+CREATE POLICY "Room participants can add to question history" ON question_history
+    FOR INSERT WITH CHECK (
+        EXISTS (
+            SELECT 1 FROM rooms 
+            WHERE id = room_id 
+            AND (owner_id = auth.uid() OR guest_id = auth.uid())
+        )
+    );
+
+-- Database Indexes for Performance Optimization
+-- These indexes improve query performance for common access patterns
+
+-- ============================================================================
+-- ROOMS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding rooms by status (for listing active/available rooms)
+CREATE INDEX IF NOT EXISTS idx_rooms_status
+ON rooms(status)
+WHERE status IN ('waiting', 'ready', 'playing');
+
+-- Index for finding rooms by owner (for "my rooms" queries)
+CREATE INDEX IF NOT EXISTS idx_rooms_owner_id
+ON rooms(owner_id)
+WHERE owner_id IS NOT NULL;
+
+-- Index for finding rooms by guest (for "rooms I'm in" queries)
+CREATE INDEX IF NOT EXISTS idx_rooms_guest_id
+ON rooms(guest_id)
+WHERE guest_id IS NOT NULL;
+
+-- Composite index for finding available rooms (public, waiting status)
+CREATE INDEX IF NOT EXISTS idx_rooms_public_waiting
+ON rooms(is_private, status, created_at DESC)
+WHERE is_private = FALSE AND status = 'waiting';
+
+-- Index for finding active games by current player (for turn notifications)
+CREATE INDEX IF NOT EXISTS idx_rooms_current_player
+ON rooms(current_player_id, status)
+WHERE current_player_id IS NOT NULL AND status = 'playing';
+
+-- ============================================================================
+-- ROOM_JOIN_REQUESTS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding pending requests by room (most common query)
+CREATE INDEX IF NOT EXISTS idx_join_requests_room_status
+ON room_join_requests(room_id, status, created_at DESC)
+WHERE status = 'pending';
+
+-- Index for finding user's pending requests (to prevent duplicates)
+CREATE INDEX IF NOT EXISTS idx_join_requests_user_room
+ON room_join_requests(user_id, room_id, status)
+WHERE status = 'pending';
+
+-- Index for finding all requests by user (user's request history)
+CREATE INDEX IF NOT EXISTS idx_join_requests_user_id
+ON room_join_requests(user_id, created_at DESC);
+
+-- ============================================================================
+-- ROOM_INVITATIONS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding pending invitations for a user
+CREATE INDEX IF NOT EXISTS idx_invitations_invitee_status
+ON room_invitations(invitee_id, status, created_at DESC)
+WHERE status = 'pending';
+
+-- Index for finding invitations by room
+CREATE INDEX IF NOT EXISTS idx_invitations_room_id
+ON room_invitations(room_id, status)
+WHERE status = 'pending';
+
+-- Index for finding invitations sent by a user
+CREATE INDEX IF NOT EXISTS idx_invitations_inviter_id
+ON room_invitations(inviter_id, created_at DESC);
+
+-- ============================================================================
+-- ANSWERS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding answers by room (for game history/review)
+CREATE INDEX IF NOT EXISTS idx_answers_room_id
+ON answers(room_id, created_at);
+
+-- Index for finding answers by user (user's answer history)
+CREATE INDEX IF NOT EXISTS idx_answers_user_id
+ON answers(user_id, created_at DESC);
+
+-- Index for finding answers by question (for analysis)
+CREATE INDEX IF NOT EXISTS idx_answers_question_id
+ON answers(question_id);
+
+-- Composite index for finding room answers by user (most common query)
+CREATE INDEX IF NOT EXISTS idx_answers_room_user
+ON answers(room_id, user_id, created_at);
+
+-- ============================================================================
+-- QUESTIONS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding questions by category (for filtered question selection)
+CREATE INDEX IF NOT EXISTS idx_questions_category_lang
+ON questions(category_id, lang_code)
+WHERE category_id IS NOT NULL;
+
+-- Index for finding questions by language
+CREATE INDEX IF NOT EXISTS idx_questions_lang_code
+ON questions(lang_code);
+
+-- Index for finding translations by base question (for translation management)
+CREATE INDEX IF NOT EXISTS idx_questions_base_question_id
+ON questions(base_question_id);
+
+-- ============================================================================
+-- FRIENDS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding user's friends (bidirectional)
+CREATE INDEX IF NOT EXISTS idx_friends_user_status
+ON friends(user_id, status)
+WHERE status = 'accepted';
+
+-- Index for finding friend requests received
+CREATE INDEX IF NOT EXISTS idx_friends_friend_status
+ON friends(friend_id, status)
+WHERE status = 'pending';
+
+-- Composite index for checking friendship existence
+CREATE INDEX IF NOT EXISTS idx_friends_both_users
+ON friends(user_id, friend_id, status);
+
+-- ============================================================================
+-- USERS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding users by username (login, search)
+CREATE INDEX IF NOT EXISTS idx_users_username
+ON users(username)
+WHERE deleted_at IS NULL;
+
+-- Index for finding users by email (login)
+CREATE INDEX IF NOT EXISTS idx_users_email
+ON users(email)
+WHERE deleted_at IS NULL;
+
+-- Index for finding active users (exclude soft-deleted)
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at
+ON users(deleted_at)
+WHERE deleted_at IS NULL;
+
+-- ============================================================================
+-- NOTIFICATIONS TABLE INDEXES
+-- ============================================================================
+
+-- Index for finding unread notifications for a user
+-- Note: Column is called 'read' not 'is_read' in schema.sql
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+ON notifications(user_id, read, created_at DESC)
+WHERE read = FALSE;
+
+-- Index for finding all user notifications (notification center)
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+ON notifications(user_id, created_at DESC);
+
+-- ============================================================================
+-- PERFORMANCE ANALYSIS
+-- ============================================================================
+
+-- To analyze query performance with these indexes, use EXPLAIN ANALYZE:
+--
+-- Example 1: Find pending join requests for a room
+-- EXPLAIN ANALYZE
+-- SELECT * FROM join_requests_with_users
+-- WHERE room_id = '123e4567-e89b-12d3-a456-426614174000'
+-- AND status = 'pending';
+--
+-- Expected: Index Scan using idx_join_requests_room_status
+--
+-- Example 2: Find user's active games
+-- EXPLAIN ANALYZE
+-- SELECT * FROM active_games
+-- WHERE owner_id = '123e4567-e89b-12d3-a456-426614174000'
+-- OR guest_id = '123e4567-e89b-12d3-a456-426614174000';
+--
+-- Expected: Bitmap Index Scan using idx_rooms_owner_id and idx_rooms_guest_id
+--
+-- Example 3: Find user's friends
+-- EXPLAIN ANALYZE
+-- SELECT * FROM friends_with_details
+-- WHERE user_id = '123e4567-e89b-12d3-a456-426614174000';
+--
+-- Expected: Index Scan using idx_friends_user_status
+
+-- ============================================================================
+-- INDEX MAINTENANCE
+-- ============================================================================
+
+-- Rebuild indexes if needed (after bulk operations)
+-- REINDEX TABLE rooms;
+-- REINDEX TABLE room_join_requests;
+-- REINDEX TABLE answers;
+
+-- Analyze tables to update statistics (improves query planner decisions)
+-- ANALYZE rooms;
+-- ANALYZE room_join_requests;
+-- ANALYZE room_invitations;
+-- ANALYZE answers;
+-- ANALYZE users;
+-- ANALYZE friends;
+
+-- ============================================================================
+-- MONITORING
+-- ============================================================================
+
+-- Check index usage (identify unused indexes)
+-- SELECT
+--     schemaname,
+--     tablename,
+--     indexname,
+--     idx_scan as index_scans,
+--     idx_tup_read as tuples_read,
+--     idx_tup_fetch as tuples_fetched
+-- FROM pg_stat_user_indexes
+-- WHERE schemaname = 'public'
+-- ORDER BY idx_scan ASC;
+
+-- Check table sizes (identify large tables needing optimization)
+-- SELECT
+--     tablename,
+--     pg_size_pretty(pg_total_relation_size(schemaname||'.'||tablename)) AS size
+-- FROM pg_tables
+-- WHERE schemaname = 'public'
+-- ORDER BY pg_total_relation_size(schemaname||'.'||tablename) DESC;
+
+-- ============================================================================
+-- NOTES
+-- ============================================================================
+--
+-- 1. Partial indexes (WHERE clauses) are used for frequently queried subsets
+--    - Smaller index size
+--    - Faster index scans
+--    - Only applicable queries use them
+--
+-- 2. Composite indexes follow the "leftmost prefix" rule
+--    - Can be used for queries on (col1), (col1, col2), or (col1, col2, col3)
+--    - Cannot be used for queries on just (col2) or (col3)
+--
+-- 3. Index on (created_at DESC) supports ORDER BY created_at DESC queries
+--
+-- 4. Indexes have overhead:
+--    - Storage space
+--    - INSERT/UPDATE/DELETE slower (index must be updated)
+--    - Choose indexes based on read vs write patterns
+--
+-- 5. PostgreSQL automatically creates indexes for:
+--    - PRIMARY KEY constraints (already in schema.sql)
+--    - UNIQUE constraints (already in schema.sql)
+--
+-- 6. These indexes complement the existing schema indexes
+--
+-- ============================================================================
+
+-- Database Views for Query Optimization
+-- These views eliminate N+1 query problems and simplify complex joins
+
+-- ============================================================================
+-- View 1: Rooms with Player Information
+-- ============================================================================
+-- Purpose: Get room details with owner and guest usernames in a single query
+-- Usage: SELECT * FROM rooms_with_players WHERE id = $1
+-- Performance: Eliminates 2 separate user queries per room fetch
+
+CREATE OR REPLACE VIEW rooms_with_players AS
+SELECT
+    -- Room fields
+    r.id,
+    r.name,
+    r.status,
+    r.language,
+    r.is_private,
+    r.guest_ready,
+    r.max_questions,
+    r.current_question,
+    r.current_question_id,
+    r.selected_categories,
+    r.current_player_id,
+    r.paused_at,
+    r.disconnected_user,
+    r.created_at,
+    r.updated_at,
+
+    -- Owner information
+    r.owner_id,
+    owner.username AS owner_username,
+    owner.email AS owner_email,
+
+    -- Guest information
+    r.guest_id,
+    guest.username AS guest_username,
+    guest.email AS guest_email,
+
+    -- Current player information (for turn indicator)
+    current_player.username AS current_player_username
+FROM rooms r
+LEFT JOIN users owner ON r.owner_id = owner.id
+LEFT JOIN users guest ON r.guest_id = guest.id
+LEFT JOIN users current_player ON r.current_player_id = current_player.id
+WHERE owner.deleted_at IS NULL
+  AND (guest.deleted_at IS NULL OR guest.id IS NULL);
+
+-- ============================================================================
+-- View 2: Join Requests with User Information
+-- ============================================================================
+-- Purpose: Get pending join requests with requester details
+-- Usage: SELECT * FROM join_requests_with_users WHERE room_id = $1 AND status = 'pending'
+-- Performance: Eliminates N queries for user info (one per request)
+
+CREATE OR REPLACE VIEW join_requests_with_users AS
+SELECT
+    -- Join request fields
+    jr.id,
+    jr.room_id,
+    jr.user_id,
+    jr.status,
+    jr.created_at,
+    jr.updated_at,
+
+    -- User information
+    u.username,
+    u.email,
+
+    -- Room information (for authorization checks)
+    r.owner_id AS room_owner_id,
+    r.status AS room_status,
+    r.guest_id AS room_guest_id
+FROM room_join_requests jr
+JOIN users u ON jr.user_id = u.id
+JOIN rooms r ON jr.room_id = r.id
+WHERE u.deleted_at IS NULL;
+
+-- ============================================================================
+-- View 3: Active Games with Current Question
+-- ============================================================================
+-- Purpose: Get all active games with current question details
+-- Usage: SELECT * FROM active_games WHERE id = $1
+-- Performance: Combines room, users, question, and category in one query
+
+CREATE OR REPLACE VIEW active_games AS
+SELECT
+    -- Room fields
+    r.id,
+    r.name,
+    r.status,
+    r.language,
+    r.max_questions,
+    r.current_question,
+    r.current_question_id,
+    r.current_player_id,
+    r.selected_categories,
+    r.paused_at,
+    r.disconnected_user,
+    r.created_at,
+
+    -- Player information
+    r.owner_id,
+    owner.username AS owner_username,
+    r.guest_id,
+    guest.username AS guest_username,
+
+    -- Current player
+    current_player.username AS current_player_username,
+
+    -- Current question details
+    q.id AS question_id,
+    q.question_text AS current_question_text,
+    q.lang_code AS current_question_lang,
+    q.base_question_id AS question_base_id,
+    q.category_id AS question_category_id,
+    c.key AS question_category_key,
+    c.label AS question_category_label
+FROM rooms r
+JOIN users owner ON r.owner_id = owner.id
+LEFT JOIN users guest ON r.guest_id = guest.id
+LEFT JOIN users current_player ON r.current_player_id = current_player.id
+LEFT JOIN questions q ON r.current_question_id = q.id
+LEFT JOIN categories c ON q.category_id = c.id
+WHERE r.status IN ('playing', 'paused')
+  AND owner.deleted_at IS NULL
+  AND (guest.deleted_at IS NULL OR guest.id IS NULL);
+
+-- ============================================================================
+-- View 4: Room Invitations with Details
+-- ============================================================================
+-- Purpose: Get room invitations with inviter, invitee, and room details
+-- Usage: SELECT * FROM invitations_with_details WHERE invitee_id = $1 AND status = 'pending'
+-- Performance: Eliminates 3 separate queries (inviter, invitee, room)
+
+CREATE OR REPLACE VIEW invitations_with_details AS
+SELECT
+    -- Invitation fields
+    inv.id,
+    inv.room_id,
+    inv.inviter_id,
+    inv.invitee_id,
+    inv.status,
+    inv.created_at,
+    inv.updated_at,
+
+    -- Inviter information
+    inviter.username AS inviter_username,
+    inviter.email AS inviter_email,
+
+    -- Invitee information
+    invitee.username AS invitee_username,
+    invitee.email AS invitee_email,
+
+    -- Room information
+    r.name AS room_name,
+    r.status AS room_status,
+    r.language AS room_language,
+    r.is_private AS room_is_private,
+    r.owner_id AS room_owner_id
+FROM room_invitations inv
+JOIN users inviter ON inv.inviter_id = inviter.id
+JOIN users invitee ON inv.invitee_id = invitee.id
+JOIN rooms r ON inv.room_id = r.id
+WHERE inviter.deleted_at IS NULL
+  AND invitee.deleted_at IS NULL;
+
+-- ============================================================================
+-- View 5: Friends List with User Details
+-- ============================================================================
+-- Purpose: Get user's friends with details (bidirectional friendship)
+-- Usage: SELECT * FROM friends_with_details WHERE user_id = $1
+-- Performance: Eliminates separate query for friend user details
+
+CREATE OR REPLACE VIEW friends_with_details AS
+SELECT
+    -- Friendship fields
+    f.id,
+    f.user_id,
+    f.friend_id,
+    f.status,
+    f.created_at,
+
+    -- Friend information
+    friend.username AS friend_username,
+    friend.email AS friend_email,
+
+    -- User information (for reverse lookups)
+    u.username AS user_username
+FROM friends f
+JOIN users u ON f.user_id = u.id
+JOIN users friend ON f.friend_id = friend.id
+WHERE u.deleted_at IS NULL
+  AND friend.deleted_at IS NULL
+  AND f.status = 'accepted';
+
+-- ============================================================================
+-- View 6: Game History with Details
+-- ============================================================================
+-- Purpose: Get completed games with player names and scores
+-- Usage: SELECT * FROM game_history WHERE owner_id = $1 OR guest_id = $1 ORDER BY finished_at DESC
+-- Performance: Single query for game history instead of multiple joins
+
+CREATE OR REPLACE VIEW game_history AS
+SELECT
+    -- Room/Game fields
+    r.id,
+    r.name AS game_name,
+    r.status,
+    r.max_questions,
+    r.current_question AS questions_answered,
+    r.created_at AS started_at,
+    r.updated_at AS finished_at,
+
+    -- Owner information
+    r.owner_id,
+    owner.username AS owner_username,
+
+    -- Guest information
+    r.guest_id,
+    guest.username AS guest_username,
+
+    -- Game metadata
+    r.language,
+    r.selected_categories
+FROM rooms r
+JOIN users owner ON r.owner_id = owner.id
+LEFT JOIN users guest ON r.guest_id = guest.id
+WHERE r.status = 'finished'
+  AND owner.deleted_at IS NULL
+  AND (guest.deleted_at IS NULL OR guest.id IS NULL)
+ORDER BY r.updated_at DESC;
+
+-- ============================================================================
+-- PERFORMANCE NOTES
+-- ============================================================================
+--
+-- 1. These views use LEFT JOINs for optional relationships (guest, current_player)
+-- 2. All views filter out soft-deleted users (deleted_at IS NULL)
+-- 3. Views are created with OR REPLACE to allow updates
+-- 4. No materialization - views are computed on query (real-time data)
+-- 5. Indexes on base tables (rooms, users, etc.) are still used
+--
+-- Query Performance Comparison:
+-- - Before: GetRoomWithPlayers = 3 queries (room + owner + guest)
+-- - After:  GetRoomWithPlayers = 1 query (rooms_with_players view)
+-- - Improvement: ~3x faster, less network overhead
+--
+-- - Before: GetJoinRequestsWithUserInfo = 1 + N queries (requests + N users)
+-- - After:  GetJoinRequestsWithUserInfo = 1 query (join_requests_with_users view)
+-- - Improvement: For 10 requests: 11 queries → 1 query = 11x faster!
+--
+-- ============================================================================
+
+-- Grant permissions to application user (adjust as needed)
+-- GRANT SELECT ON rooms_with_players TO your_app_user;
+-- GRANT SELECT ON join_requests_with_users TO your_app_user;
+-- GRANT SELECT ON active_games TO your_app_user;
+-- GRANT SELECT ON invitations_with_details TO your_app_user;
+-- GRANT SELECT ON friends_with_details TO your_app_user;
+-- GRANT SELECT ON game_history TO your_app_user;
+
+-- ============================================================================
+-- VERIFICATION QUERIES
+-- ============================================================================
+-- Run these to verify views are working correctly:
+
+-- Test rooms_with_players
+-- SELECT id, owner_username, guest_username, status FROM rooms_with_players LIMIT 5;
+
+-- Test join_requests_with_users
+-- SELECT id, username, room_id, status FROM join_requests_with_users WHERE status = 'pending' LIMIT 5;
+
+-- Test active_games
+-- SELECT id, owner_username, guest_username, current_question_text FROM active_games LIMIT 5;
+
+-- Test invitations_with_details
+-- SELECT id, inviter_username, invitee_username, room_name FROM invitations_with_details LIMIT 5;
+
+-- Test friends_with_details
+-- SELECT user_username, friend_username, status FROM friends_with_details LIMIT 5;
+
+-- Test game_history
+-- SELECT game_name, owner_username, guest_username, questions_answered FROM game_history LIMIT 5;
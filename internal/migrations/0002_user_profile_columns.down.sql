@@ -0,0 +1,7 @@
+-- 0002 user profile columns (down)
+
+DROP INDEX IF EXISTS idx_users_last_seen_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_seen_at;
+ALTER TABLE users DROP COLUMN IF EXISTS language_preference;
+ALTER TABLE users DROP COLUMN IF EXISTS avatar_url;
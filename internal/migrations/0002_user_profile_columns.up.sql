@@ -0,0 +1,8 @@
+-- 0002 user profile columns
+-- models.User reads these; OAuth sign-in stores avatar_url
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS language_preference VARCHAR(10);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_last_seen_at ON users(last_seen_at) WHERE deleted_at IS NULL;
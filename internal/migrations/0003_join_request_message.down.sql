@@ -0,0 +1,3 @@
+-- 0003 join request message (down)
+
+ALTER TABLE room_join_requests DROP COLUMN IF EXISTS message;
@@ -0,0 +1,4 @@
+-- 0003 join request message
+-- Optional note a player attaches when asking to join a room (models.RoomJoinRequest.Message)
+
+ALTER TABLE room_join_requests ADD COLUMN IF NOT EXISTS message TEXT;
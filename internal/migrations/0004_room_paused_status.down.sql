@@ -0,0 +1,8 @@
+-- 0004 room paused status (down)
+-- Paused games fall back to playing so the old constraint holds
+
+UPDATE rooms SET status = 'playing' WHERE status = 'paused';
+
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_status_check;
+ALTER TABLE rooms ADD CONSTRAINT rooms_status_check
+    CHECK (status IN ('waiting', 'ready', 'playing', 'finished'));
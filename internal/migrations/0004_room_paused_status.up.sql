@@ -0,0 +1,6 @@
+-- 0004 room paused status
+-- GameService.PauseGame sets status = 'paused' when a player disconnects mid-game
+
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_status_check;
+ALTER TABLE rooms ADD CONSTRAINT rooms_status_check
+    CHECK (status IN ('waiting', 'ready', 'playing', 'paused', 'finished'));
@@ -0,0 +1,3 @@
+-- 0005 category weight (down)
+
+ALTER TABLE categories DROP COLUMN IF EXISTS weight;
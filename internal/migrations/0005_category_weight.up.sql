@@ -0,0 +1,7 @@
+-- 0005 category weight
+-- Relative share of draws a category gets when several are selected in a room (models.Category.Weight)
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS weight REAL NOT NULL DEFAULT 1;
+
+ALTER TABLE categories DROP CONSTRAINT IF EXISTS categories_weight_check;
+ALTER TABLE categories ADD CONSTRAINT categories_weight_check CHECK (weight > 0);
@@ -0,0 +1,80 @@
+-- 0006 player question history
+-- Questions each player has seen, and with whom, across rooms. GetRandomQuestion excludes
+-- anything either partner has already seen; rooms.allow_repeats lets a room fall back to
+-- already-seen questions once the unseen pool is exhausted.
+
+CREATE TABLE IF NOT EXISTS player_question_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    partner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    question_id UUID NOT NULL REFERENCES questions(id) ON DELETE CASCADE,
+    room_id UUID REFERENCES rooms(id) ON DELETE SET NULL,
+    asked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, partner_id, question_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_player_question_history_user_id ON player_question_history(user_id);
+
+COMMENT ON TABLE player_question_history IS 'Tracks which questions each player has seen with each partner, across rooms';
+
+ALTER TABLE player_question_history ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Players can view their own question history" ON player_question_history;
+CREATE POLICY "Players can view their own question history" ON player_question_history
+    FOR SELECT USING (auth.uid() = user_id OR auth.uid() = partner_id);
+
+-- Backfill from the per-room history so existing couples don't start over
+INSERT INTO player_question_history (user_id, partner_id, question_id, room_id, asked_at)
+SELECT r.owner_id, r.guest_id, qh.question_id, qh.room_id, qh.asked_at
+FROM question_history qh
+JOIN rooms r ON r.id = qh.room_id
+WHERE r.guest_id IS NOT NULL
+UNION ALL
+SELECT r.guest_id, r.owner_id, qh.question_id, qh.room_id, qh.asked_at
+FROM question_history qh
+JOIN rooms r ON r.id = qh.room_id
+WHERE r.guest_id IS NOT NULL
+ON CONFLICT (user_id, partner_id, question_id) DO NOTHING;
+
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS allow_repeats BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE OR REPLACE VIEW rooms_with_players AS
+SELECT
+    -- Room fields
+    r.id,
+    r.name,
+    r.status,
+    r.language,
+    r.is_private,
+    r.guest_ready,
+    r.max_questions,
+    r.current_question,
+    r.current_question_id,
+    r.selected_categories,
+    r.current_player_id,
+    r.paused_at,
+    r.disconnected_user,
+    r.created_at,
+    r.updated_at,
+
+    -- Owner information
+    r.owner_id,
+    owner.username AS owner_username,
+    owner.email AS owner_email,
+
+    -- Guest information
+    r.guest_id,
+    guest.username AS guest_username,
+    guest.email AS guest_email,
+
+    -- Current player information (for turn indicator)
+    current_player.username AS current_player_username,
+
+    -- Appended by 0006 (CREATE OR REPLACE VIEW can only add columns at the end)
+    r.allow_repeats
+FROM rooms r
+LEFT JOIN users owner ON r.owner_id = owner.id
+LEFT JOIN users guest ON r.guest_id = guest.id
+LEFT JOIN users current_player ON r.current_player_id = current_player.id
+WHERE owner.deleted_at IS NULL
+  AND (guest.deleted_at IS NULL OR guest.id IS NULL);
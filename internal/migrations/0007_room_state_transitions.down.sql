@@ -0,0 +1,10 @@
+-- 0007 room state transitions (down)
+
+DROP TABLE IF EXISTS room_state_transitions;
+
+UPDATE rooms SET status = 'finished' WHERE status = 'abandoned';
+UPDATE rooms SET status = 'playing' WHERE status = 'paused';
+
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_status_check;
+ALTER TABLE rooms ADD CONSTRAINT rooms_status_check
+    CHECK (status IN ('waiting', 'ready', 'playing', 'finished'));
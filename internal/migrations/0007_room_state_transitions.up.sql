@@ -0,0 +1,36 @@
+-- 0007 room state transitions
+-- Rooms move between states only through RoomService.Transition (see models.RoomState);
+-- 'abandoned' is a room closed before its game finished. Every move is logged.
+
+-- ForceCloseRoom used to write 'completed', which the CHECK never allowed
+UPDATE rooms SET status = 'abandoned' WHERE status = 'completed';
+
+-- 'paused' was written by PauseGame but missing from the original CHECK
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_status_check;
+ALTER TABLE rooms ADD CONSTRAINT rooms_status_check
+    CHECK (status IN ('waiting', 'ready', 'playing', 'paused', 'finished', 'abandoned'));
+
+CREATE TABLE IF NOT EXISTS room_state_transitions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    from_state VARCHAR(20) NOT NULL,
+    to_state VARCHAR(20) NOT NULL,
+    event VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_state_transitions_room_id ON room_state_transitions(room_id, created_at);
+
+COMMENT ON TABLE room_state_transitions IS 'Log of every room state change (from_state --event--> to_state)';
+
+ALTER TABLE room_state_transitions ENABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS "Room participants can view state transitions" ON room_state_transitions;
+CREATE POLICY "Room participants can view state transitions" ON room_state_transitions
+    FOR SELECT USING (
+        EXISTS (
+            SELECT 1 FROM rooms
+            WHERE id = room_id
+            AND (owner_id = auth.uid() OR guest_id = auth.uid())
+        )
+    );
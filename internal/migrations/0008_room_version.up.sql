@@ -0,0 +1,51 @@
+-- 0008 room version
+-- Optimistic concurrency for rooms: every write must name the version it read and bumps it,
+-- so two players acting at once can't silently overwrite each other.
+
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN rooms.version IS 'Incremented on every write; updates compare-and-swap on it';
+
+CREATE OR REPLACE VIEW rooms_with_players AS
+SELECT
+    -- Room fields
+    r.id,
+    r.name,
+    r.status,
+    r.language,
+    r.is_private,
+    r.guest_ready,
+    r.max_questions,
+    r.current_question,
+    r.current_question_id,
+    r.selected_categories,
+    r.current_player_id,
+    r.paused_at,
+    r.disconnected_user,
+    r.created_at,
+    r.updated_at,
+
+    -- Owner information
+    r.owner_id,
+    owner.username AS owner_username,
+    owner.email AS owner_email,
+
+    -- Guest information
+    r.guest_id,
+    guest.username AS guest_username,
+    guest.email AS guest_email,
+
+    -- Current player information (for turn indicator)
+    current_player.username AS current_player_username,
+
+    -- Appended by 0006 (CREATE OR REPLACE VIEW can only add columns at the end)
+    r.allow_repeats,
+
+    -- Appended by 0008
+    r.version
+FROM rooms r
+LEFT JOIN users owner ON r.owner_id = owner.id
+LEFT JOIN users guest ON r.guest_id = guest.id
+LEFT JOIN users current_player ON r.current_player_id = current_player.id
+WHERE owner.deleted_at IS NULL
+  AND (guest.deleted_at IS NULL OR guest.id IS NULL);
@@ -0,0 +1,3 @@
+-- 0009 room events (down)
+
+DROP TABLE IF EXISTS room_events;
@@ -0,0 +1,21 @@
+-- 0009 room events
+-- Optional persistence for the realtime replay buffer (PERSIST_ROOM_EVENTS=true): the last
+-- EVENT_REPLAY_SIZE events of each room, so SSE clients can resume with Last-Event-ID
+-- across a restart. id is the event ID sent to the browser (a microsecond timestamp).
+
+CREATE TABLE IF NOT EXISTS room_events (
+    id BIGINT PRIMARY KEY,
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_events_room_id ON room_events(room_id, id);
+
+COMMENT ON TABLE room_events IS 'Recent realtime events per room, replayed to reconnecting SSE clients';
+COMMENT ON COLUMN room_events.user_id IS 'Set when the event was only sent to this user';
+
+-- Only the server reads and writes this table
+ALTER TABLE room_events ENABLE ROW LEVEL SECURITY;
@@ -0,0 +1,3 @@
+-- 0010 realtime payloads (down)
+
+DROP TABLE IF EXISTS realtime_payloads;
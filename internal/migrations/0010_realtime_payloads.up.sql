@@ -0,0 +1,18 @@
+-- 0010 realtime payloads
+-- With REALTIME_BROKER=postgres, realtime events reach the other server instances through
+-- NOTIFY, whose payloads are limited to 8000 bytes. Larger events (mostly HTML fragments)
+-- are stored here and the notification only carries the row id. Rows are deleted after a
+-- few minutes, once every instance has read them.
+
+CREATE TABLE IF NOT EXISTS realtime_payloads (
+    id UUID PRIMARY KEY,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_realtime_payloads_created_at ON realtime_payloads(created_at);
+
+COMMENT ON TABLE realtime_payloads IS 'Realtime events too large for a NOTIFY payload, read by id';
+
+-- Only the server reads and writes this table
+ALTER TABLE realtime_payloads ENABLE ROW LEVEL SECURITY;
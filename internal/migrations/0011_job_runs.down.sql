@@ -0,0 +1,3 @@
+-- 0011 job runs (down)
+
+DROP TABLE IF EXISTS job_runs;
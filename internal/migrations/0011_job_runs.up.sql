@@ -0,0 +1,22 @@
+-- 0011 job runs
+-- History of the scheduled maintenance jobs (anonymous user cleanup, reconnection
+-- timeouts, stale join requests and invitations), shown on /admin/jobs. Only the instance
+-- elected to run the jobs writes here; the last 100 runs of each job are kept.
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    result TEXT,
+    error TEXT,
+    instance VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job_name ON job_runs(job_name, started_at DESC);
+
+COMMENT ON TABLE job_runs IS 'Executions of the scheduled maintenance jobs';
+
+-- Only the server reads and writes this table
+ALTER TABLE job_runs ENABLE ROW LEVEL SECURITY;
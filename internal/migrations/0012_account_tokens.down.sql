@@ -0,0 +1,5 @@
+-- 0012 account tokens (down)
+
+DROP TABLE IF EXISTS account_tokens;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
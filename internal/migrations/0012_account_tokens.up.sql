@@ -0,0 +1,23 @@
+-- 0012 account tokens
+-- Single-use tokens mailed for password resets, email verification and email changes.
+-- Only a SHA-256 hash of each token is stored; the link in the email carries the token.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP WITH TIME ZONE;
+
+CREATE TABLE IF NOT EXISTS account_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('password_reset', 'email_verification', 'email_change')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_tokens_user_id ON account_tokens(user_id, purpose);
+
+COMMENT ON TABLE account_tokens IS 'Single-use password reset and email verification tokens (hashed)';
+
+-- Only the server reads and writes this table
+ALTER TABLE account_tokens ENABLE ROW LEVEL SECURITY;
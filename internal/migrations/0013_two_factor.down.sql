@@ -0,0 +1,4 @@
+-- 0013 two-factor authentication (down)
+
+DROP TABLE IF EXISTS two_factor_recovery_codes;
+DROP TABLE IF EXISTS user_two_factor;
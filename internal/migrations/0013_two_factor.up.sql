@@ -0,0 +1,37 @@
+-- 0013 two-factor authentication
+-- TOTP second factor for registered users (mandatory for admins). The secret is kept so
+-- codes can be checked; recovery codes are stored as SHA-256 hashes and work once each.
+
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes(user_id);
+
+DROP TRIGGER IF EXISTS update_user_two_factor_updated_at ON user_two_factor;
+CREATE TRIGGER update_user_two_factor_updated_at
+    BEFORE UPDATE ON user_two_factor
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE user_two_factor IS 'TOTP secrets; enabled_at is NULL until enrollment is confirmed';
+COMMENT ON TABLE two_factor_recovery_codes IS 'Single-use two-factor recovery codes (hashed)';
+
+-- Only the server reads and writes these tables
+ALTER TABLE user_two_factor ENABLE ROW LEVEL SECURITY;
+ALTER TABLE two_factor_recovery_codes ENABLE ROW LEVEL SECURITY;
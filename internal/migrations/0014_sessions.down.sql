@@ -0,0 +1,3 @@
+-- 0014 server-side sessions (down)
+
+DROP TABLE IF EXISTS sessions;
@@ -0,0 +1,3 @@
+-- 0015 personal API tokens (down)
+
+DROP TABLE IF EXISTS api_tokens;
@@ -0,0 +1,22 @@
+-- 0015 personal API tokens
+-- Bearer tokens for scripting /api/v1 without a browser session. Only a SHA-256 hash of each
+-- token is stored; token_prefix is kept so the user can tell their tokens apart.
+
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL, -- comma-separated: rooms:read, rooms:write, friends, admin
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_tokens_user_id ON api_tokens(user_id);
+
+COMMENT ON TABLE api_tokens IS 'Personal API tokens (hashed) with their scopes';
+
+-- Only the server reads and writes this table
+ALTER TABLE api_tokens ENABLE ROW LEVEL SECURITY;
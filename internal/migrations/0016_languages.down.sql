@@ -0,0 +1,3 @@
+-- 0016 language registry (down)
+
+DROP TABLE IF EXISTS languages;
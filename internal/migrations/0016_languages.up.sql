@@ -0,0 +1,29 @@
+-- 0016 language registry
+-- The languages the app offers for its UI and questions. A language missing a string or question
+-- falls back along its fallback chain, ending at English (the language of base questions).
+
+CREATE TABLE IF NOT EXISTS languages (
+    code VARCHAR(10) PRIMARY KEY,
+    native_name VARCHAR(50) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    fallback VARCHAR(10) REFERENCES languages(code) ON DELETE SET NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO languages (code, native_name, enabled, fallback, position) VALUES
+    ('en', 'English', TRUE, NULL, 0),
+    ('fr', 'Français', TRUE, 'en', 1),
+    ('ja', '日本語', TRUE, 'en', 2)
+ON CONFLICT (code) DO NOTHING;
+
+DROP TRIGGER IF EXISTS update_languages_updated_at ON languages;
+CREATE TRIGGER update_languages_updated_at
+    BEFORE UPDATE ON languages
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE languages IS 'Languages offered for the UI and questions, with their fallback';
+
+-- Only the server reads and writes this table
+ALTER TABLE languages ENABLE ROW LEVEL SECURITY;
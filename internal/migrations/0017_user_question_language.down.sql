@@ -0,0 +1,3 @@
+-- 0017 user question language (down)
+
+ALTER TABLE users DROP COLUMN IF EXISTS question_language;
@@ -0,0 +1,5 @@
+-- 0017 user question language
+-- language_preference (0002) is the UI language; question_language is the language new rooms
+-- default to. NULL means automatic: the language detected from the request.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS question_language VARCHAR(10);
@@ -0,0 +1,3 @@
+-- 0018 user merged into (down)
+
+ALTER TABLE users DROP COLUMN IF EXISTS merged_into;
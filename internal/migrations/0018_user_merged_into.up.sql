@@ -0,0 +1,6 @@
+-- 0018 user merged into
+-- Set on a guest when merging it into an account starts and gone with the guest once the
+-- merge completes. A guest still carrying it is a merge that failed halfway (the Supabase
+-- backend has no transactions): the scheduler resumes it instead of deleting the guest.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into UUID REFERENCES users(id) ON DELETE SET NULL;
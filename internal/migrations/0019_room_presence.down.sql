@@ -0,0 +1,3 @@
+-- 0019 room presence (down)
+
+DROP TABLE IF EXISTS room_presence;
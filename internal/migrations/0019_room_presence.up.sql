@@ -0,0 +1,21 @@
+-- 0019 room presence
+-- Which server instance holds a live room stream of which player, so every instance sees the
+-- same players online. Each instance refreshes seen_at of its rows while the streams stay
+-- open and deletes them once the player has been gone for the grace period; rows of an
+-- instance that stopped refreshing them (a crash) are ignored and then deleted.
+
+CREATE TABLE IF NOT EXISTS room_presence (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    room_id UUID NOT NULL REFERENCES rooms(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    instance_id TEXT NOT NULL,
+    seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (room_id, user_id, instance_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_room_presence_instance_id ON room_presence(instance_id);
+
+COMMENT ON TABLE room_presence IS 'Live room streams per player and server instance, shared for presence';
+
+-- Only the server reads and writes this table
+ALTER TABLE room_presence ENABLE ROW LEVEL SECURITY;
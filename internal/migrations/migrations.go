@@ -0,0 +1,318 @@
+// Package migrations holds the numbered SQL migrations embedded in the server binary
+// and the runner behind the `server migrate` subcommands.
+//
+// Files are named NNNN_description.up.sql / NNNN_description.down.sql. Applied versions
+// are recorded in the schema_migrations table; each migration runs in its own transaction.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration is one numbered schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration together with when it was applied (nil when pending)
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// lockID is the pg_advisory_lock key that keeps two runners from migrating at once
+const lockID = 7426_0001
+
+// filePattern matches NNNN_description.up.sql / .down.sql
+var filePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// All returns the embedded migrations ordered by version
+func All() ([]Migration, error) {
+	return load(files)
+}
+
+// Latest returns the version the code expects the database to be at
+func Latest() int {
+	all, err := All()
+	if err != nil || len(all) == 0 {
+		return 0
+	}
+	return all[len(all)-1].Version
+}
+
+// load parses and validates a set of migration files
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(data)
+		} else {
+			m.Down = string(data)
+		}
+	}
+
+	all := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		all = append(all, *m)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Version < all[j].Version })
+
+	for i, m := range all {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be contiguous from 1: found %04d at position %d", m.Version, i+1)
+		}
+	}
+	return all, nil
+}
+
+// CheckVersion compares the applied database version with the one the code expects
+// A database behind the code is an error (the server would query missing columns);
+// a database ahead of it is allowed so a rolled-back deploy keeps running.
+func CheckVersion(applied int) error {
+	if latest := Latest(); applied < latest {
+		return fmt.Errorf("database schema is at version %d but this build requires %d: run `server migrate up`", applied, latest)
+	}
+	return nil
+}
+
+// Runner applies and reverts migrations on a PostgreSQL database
+type Runner struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewRunner creates a runner for the embedded migrations
+func NewRunner(db *sql.DB) (*Runner, error) {
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{db: db, migrations: all}, nil
+}
+
+// Up applies every pending migration in order and returns the ones it applied
+func (r *Runner) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range r.migrations {
+			if _, ok := done[m.Version]; ok {
+				continue
+			}
+			if err := apply(ctx, conn, m, true); err != nil {
+				return err
+			}
+			applied = append(applied, m)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the most recently applied migration (nil when nothing is applied)
+func (r *Runner) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		m, err := r.lastApplied(ctx, conn)
+		if err != nil || m == nil {
+			return err
+		}
+		if err := apply(ctx, conn, *m, false); err != nil {
+			return err
+		}
+		reverted = m
+		return nil
+	})
+	return reverted, err
+}
+
+// Redo reverts and re-applies the most recently applied migration
+func (r *Runner) Redo(ctx context.Context) (*Migration, error) {
+	var redone *Migration
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		m, err := r.lastApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return errors.New("no migration has been applied")
+		}
+		if err := apply(ctx, conn, *m, false); err != nil {
+			return err
+		}
+		if err := apply(ctx, conn, *m, true); err != nil {
+			return err
+		}
+		redone = m
+		return nil
+	})
+	return redone, err
+}
+
+// Status lists every migration with its applied time
+func (r *Runner) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, m := range r.migrations {
+			status := Status{Migration: m}
+			if appliedAt, ok := done[m.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Version returns the highest applied version (0 for an empty database)
+func (r *Runner) Version(ctx context.Context) (int, error) {
+	var version int
+	err := r.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		for v := range done {
+			if v > version {
+				version = v
+			}
+		}
+		return err
+	})
+	return version, err
+}
+
+// locked runs fn on a single connection holding the migration advisory lock,
+// after making sure schema_migrations exists
+func (r *Runner) locked(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// lastApplied returns the applied migration with the highest version
+func (r *Runner) lastApplied(ctx context.Context, conn *sql.Conn) (*Migration, error) {
+	done, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		if _, ok := done[r.migrations[i].Version]; ok {
+			m := r.migrations[i]
+			return &m, nil
+		}
+	}
+
+	if len(done) > 0 {
+		return nil, errors.New("database has versions applied that this build does not know about")
+	}
+	return nil, nil
+}
+
+// appliedVersions returns applied version -> applied_at
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// apply runs one direction of a migration and records it, atomically
+func apply(ctx context.Context, conn *sql.Conn, m Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script, record, args := m.Down, "DELETE FROM schema_migrations WHERE version = $1", []interface{}{m.Version}
+	if up {
+		script, record, args = m.Up, "INSERT INTO schema_migrations (version, name) VALUES ($1, $2)", []interface{}{m.Version, m.Name}
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %04d_%s failed: %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestAll_EmbeddedMigrationsAreValid(t *testing.T) {
+	all, err := All()
+	if err != nil {
+		t.Fatalf("embedded migrations are invalid: %v", err)
+	}
+	if len(all) == 0 || Latest() != all[len(all)-1].Version {
+		t.Fatalf("unexpected migrations: %d, latest %d", len(all), Latest())
+	}
+
+	for _, m := range all {
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("%04d_%s has an empty script", m.Version, m.Name)
+		}
+	}
+}
+
+func TestAll_SchemaMatchesModels(t *testing.T) {
+	all, err := All()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var up strings.Builder
+	for _, m := range all {
+		up.WriteString(m.Up)
+	}
+
+	// Columns and values the Go code writes
+	for _, want := range []string{"avatar_url", "language_preference", "question_language", "merged_into", "room_presence", "last_seen_at", "message TEXT", "'paused'"} {
+		if !strings.Contains(up.String(), want) {
+			t.Errorf("no migration adds %s", want)
+		}
+	}
+}
+
+func TestLoad_Validation(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	tests := map[string]struct {
+		files   fstest.MapFS
+		wantErr string
+	}{
+		"missing down": {fstest.MapFS{
+			"0001_init.up.sql": file("CREATE TABLE a ();"),
+		}, "needs both an up and a down"},
+		"gap in versions": {fstest.MapFS{
+			"0001_init.up.sql":   file("x"),
+			"0001_init.down.sql": file("x"),
+			"0003_more.up.sql":   file("x"),
+			"0003_more.down.sql": file("x"),
+		}, "contiguous"},
+		"bad file name": {fstest.MapFS{
+			"init.sql": file("x"),
+		}, "unexpected migration file name"},
+		"mismatched names": {fstest.MapFS{
+			"0001_init.up.sql":    file("x"),
+			"0001_other.down.sql": file("x"),
+		}, "two names"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := load(tt.files)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	latest := Latest()
+
+	if err := CheckVersion(latest); err != nil {
+		t.Errorf("up-to-date database rejected: %v", err)
+	}
+	if err := CheckVersion(latest + 1); err != nil {
+		t.Errorf("database ahead of the code should be allowed: %v", err)
+	}
+	if err := CheckVersion(latest - 1); err == nil || !strings.Contains(err.Error(), "migrate up") {
+		t.Errorf("database behind the code should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account token purposes
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+	TokenEmailChange       = "email_change"
+)
+
+// AccountToken is a single-use token mailed to a user to confirm an account change
+type AccountToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Purpose   string     `json:"purpose"`    // password_reset, email_verification, email_change
+	TokenHash string     `json:"token_hash"` // hex SHA-256 of the mailed token
+	Email     string     `json:"email"`      // address the token was sent to (the new one for email_change)
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Usable reports whether the token has neither been used nor expired at now
+func (t *AccountToken) Usable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API token scopes
+const (
+	ScopeRoomsRead  = "rooms:read"  // read rooms, games, join requests and notifications
+	ScopeRoomsWrite = "rooms:write" // create and play games, answer join requests
+	ScopeFriends    = "friends"     // friends list and room invitations
+	ScopeAdmin      = "admin"       // the admin API (admins only)
+)
+
+// APIScopes lists every scope, in the order they are offered
+var APIScopes = []string{ScopeRoomsRead, ScopeRoomsWrite, ScopeFriends, ScopeAdmin}
+
+// APIToken is a personal token authenticating API requests as its user
+type APIToken struct {
+	ID          uuid.UUID  `json:"id"`
+	UserID      uuid.UUID  `json:"user_id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"` // first characters of the token, for display
+	TokenHash   string     `json:"token_hash"`   // hex SHA-256 of the token
+	Scopes      string     `json:"scopes"`       // comma-separated
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ScopeList returns the token's scopes
+func (t *APIToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// Expired reports whether the token has expired at now
+func (t *APIToken) Expired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
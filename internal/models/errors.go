@@ -9,6 +9,22 @@ var (
 	ErrEmailRequired   = errors.New("email is required for non-anonymous users")
 	ErrUserNotFound    = errors.New("user not found")
 	ErrInvalidUserID   = errors.New("invalid user ID")
+	ErrNotAnonymous    = errors.New("only an anonymous user can be merged into another account")
+	ErrMergePending    = errors.New("the anonymous user is already being merged into another account")
+	ErrEmailInUse      = errors.New("email is already in use")
+	ErrInvalidToken    = errors.New("the link is invalid or has expired")
+
+	// Two-factor errors
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrTwoFactorLocked      = errors.New("too many wrong two-factor codes, try again later")
+	ErrTwoFactorNotEnabled  = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorRequired    = errors.New("two-factor authentication is required for admins")
+
+	// API token errors
+	ErrInvalidAPIToken = errors.New("invalid or expired API token")
+	ErrInvalidScope    = errors.New("invalid API token scope")
+	ErrInvalidAPITokenName = errors.New("API token name must be 1 to 100 characters")
 
 	// Room errors
 	ErrRoomFull       = errors.New("room is full")
@@ -16,12 +32,17 @@ var (
 	ErrInvalidRoomID  = errors.New("invalid room ID")
 	ErrRoomNotStarted = errors.New("room game has not started")
 	ErrRoomAlreadyStarted = errors.New("room game has already started")
+	ErrIllegalRoomTransition = errors.New("illegal room state transition")
 
 	// Game errors
 	ErrGameNotStarted   = errors.New("game has not started")
 	ErrGameAlreadyEnded = errors.New("game has already ended")
 	ErrNotYourTurn      = errors.New("it is not your turn")
 	ErrNoQuestionsAvailable = errors.New("no questions available")
+	ErrQuestionsAllSeen     = errors.New("both players have already seen every available question")
+
+	// Concurrency errors
+	ErrConflict = errors.New("the room was changed by another request, reload and try again")
 
 	// Authorization errors
 	ErrUnauthorized    = errors.New("unauthorized access")
@@ -34,5 +55,18 @@ var (
 	ErrCannotBeFriendWithSelf = errors.New("cannot send friend request to yourself")
 	ErrAlreadyFriends = errors.New("already friends with this user")
 	ErrPendingInvitation = errors.New("friend invitation already pending")
+
+	// Import errors
+	ErrInvalidImportFile = errors.New("invalid import file")
+
+	// Translation errors
+	ErrInvalidTranslation  = errors.New("invalid translation")
+	ErrTranslationExists   = errors.New("translation already exists")
+	ErrTranslationNotFound = errors.New("translation not found")
+
+	// Language errors
+	ErrInvalidLanguage  = errors.New("invalid language")
+	ErrLanguageExists   = errors.New("language already exists")
+	ErrLanguageNotFound = errors.New("language not found")
 )
 
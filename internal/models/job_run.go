@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job run outcomes
+const (
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobRun records one execution of a scheduled maintenance job
+type JobRun struct {
+	ID         uuid.UUID `json:"id"`
+	JobName    string    `json:"job_name"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Status     string    `json:"status"` // succeeded, failed
+	Result     string    `json:"result,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	Instance   string    `json:"instance"` // host:pid of the instance that ran it
+}
+
+// Duration returns how long the run took
+func (r JobRun) Duration() time.Duration {
+	return time.Duration(r.DurationMs) * time.Millisecond
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// Language is a language the app offers for its UI and questions (languages table)
+type Language struct {
+	Code       string    `json:"code"`
+	NativeName string    `json:"native_name"`
+	Enabled    bool      `json:"enabled"`
+	Fallback   string    `json:"fallback"` // Empty for the default language
+	Position   int       `json:"position"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -22,6 +22,7 @@ type Category struct {
 	ID        uuid.UUID `json:"id"`
 	Key       string    `json:"key"`
 	Label     string    `json:"label"`
+	Weight    float64   `json:"weight"` // Relative share of draws when several categories are selected (default 1)
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
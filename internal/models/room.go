@@ -12,17 +12,19 @@ type Room struct {
 	Name               string      `json:"name"`
 	OwnerID            uuid.UUID   `json:"owner_id"`
 	GuestID            *uuid.UUID  `json:"guest_id"`
-	Status             string      `json:"status"` // 'waiting', 'ready', 'playing', 'finished'
+	Status             RoomState   `json:"status"`
 	Language           string      `json:"language"`
 	IsPrivate          bool        `json:"is_private"`
 	GuestReady         bool        `json:"guest_ready"`
 	MaxQuestions       int         `json:"max_questions"`
+	AllowRepeats       bool        `json:"allow_repeats"` // Draw questions the players have already seen once the unseen ones run out
 	CurrentQuestion    int         `json:"current_question"`
 	CurrentQuestionID  *uuid.UUID  `json:"current_question_id"`
 	CurrentTurn        *uuid.UUID  `json:"current_player_id"`
 	SelectedCategories []uuid.UUID `json:"selected_categories"`
 	PausedAt           *time.Time  `json:"paused_at,omitempty"`
 	DisconnectedUser   *uuid.UUID  `json:"disconnected_user,omitempty"`
+	Version            int         `json:"version"` // Bumped on every write; UpdateRoom fails with ErrConflict if it changed since the read
 	CreatedAt          time.Time   `json:"created_at"`
 	UpdatedAt          time.Time   `json:"updated_at"`
 }
@@ -47,7 +49,7 @@ type ActiveGame struct {
 	// Room fields
 	ID                 uuid.UUID   `json:"id"`
 	Name               string      `json:"name"`
-	Status             string      `json:"status"`
+	Status             RoomState   `json:"status"`
 	Language           string      `json:"language"`
 	MaxQuestions       int         `json:"max_questions"`
 	CurrentQuestion    int         `json:"current_question"`
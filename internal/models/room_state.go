@@ -0,0 +1,122 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RoomState is a room's lifecycle state, stored in rooms.status
+type RoomState string
+
+// Room states
+const (
+	RoomWaiting   RoomState = "waiting"   // Owner alone, waiting for a guest
+	RoomReady     RoomState = "ready"     // Both players present, game not started
+	RoomPlaying   RoomState = "playing"   // Game in progress
+	RoomPaused    RoomState = "paused"    // A player disconnected mid-game
+	RoomFinished  RoomState = "finished"  // Game played to the end
+	RoomAbandoned RoomState = "abandoned" // Closed before the game finished
+)
+
+// RoomStates lists every state in lifecycle order
+var RoomStates = []RoomState{RoomWaiting, RoomReady, RoomPlaying, RoomPaused, RoomFinished, RoomAbandoned}
+
+// RoomStateNames returns RoomStates as strings, e.g. for status filters
+func RoomStateNames() []string {
+	names := make([]string, len(RoomStates))
+	for i, state := range RoomStates {
+		names[i] = string(state)
+	}
+	return names
+}
+
+// RoomEvent is something that happens to a room and moves it to another state
+type RoomEvent string
+
+// Room events
+const (
+	RoomEventGuestJoined RoomEvent = "guest_joined"
+	RoomEventGuestLeft   RoomEvent = "guest_left"
+	RoomEventStart       RoomEvent = "start"
+	RoomEventPause       RoomEvent = "pause"
+	RoomEventResume      RoomEvent = "resume"
+	RoomEventFinish      RoomEvent = "finish"
+	RoomEventAbandon     RoomEvent = "abandon"
+)
+
+// roomTransitions is the room state machine: state -> event -> next state
+// waiting → ready → playing ⇄ paused → finished, and any live room can be abandoned
+var roomTransitions = map[RoomState]map[RoomEvent]RoomState{
+	RoomWaiting: {
+		RoomEventGuestJoined: RoomReady,
+		RoomEventAbandon:     RoomAbandoned,
+	},
+	RoomReady: {
+		RoomEventGuestLeft: RoomWaiting,
+		RoomEventStart:     RoomPlaying,
+		RoomEventAbandon:   RoomAbandoned,
+	},
+	RoomPlaying: {
+		RoomEventPause:   RoomPaused,
+		RoomEventFinish:  RoomFinished,
+		RoomEventAbandon: RoomAbandoned,
+	},
+	RoomPaused: {
+		RoomEventResume:  RoomPlaying,
+		RoomEventFinish:  RoomFinished,
+		RoomEventAbandon: RoomAbandoned,
+	},
+}
+
+// Next returns the state a room moves to when event happens in state s
+// Illegal moves return a *RoomTransitionError (which matches ErrIllegalRoomTransition).
+func (s RoomState) Next(event RoomEvent) (RoomState, error) {
+	next, ok := roomTransitions[s][event]
+	if !ok {
+		return s, &RoomTransitionError{From: s, Event: event}
+	}
+	return next, nil
+}
+
+// Can reports whether event is legal in state s
+func (s RoomState) Can(event RoomEvent) bool {
+	_, err := s.Next(event)
+	return err == nil
+}
+
+// IsTerminal reports whether no event can move the room out of state s
+func (s RoomState) IsTerminal() bool {
+	return len(roomTransitions[s]) == 0
+}
+
+// IsLive reports whether a game is under way (playing or paused)
+func (s RoomState) IsLive() bool {
+	return s == RoomPlaying || s == RoomPaused
+}
+
+// RoomTransition is one logged state change (room_state_transitions)
+type RoomTransition struct {
+	ID        uuid.UUID `json:"id"`
+	RoomID    uuid.UUID `json:"room_id"`
+	From      RoomState `json:"from_state"`
+	To        RoomState `json:"to_state"`
+	Event     RoomEvent `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RoomTransitionError is returned when an event is not allowed in the room's current state
+type RoomTransitionError struct {
+	From  RoomState
+	Event RoomEvent
+}
+
+func (e *RoomTransitionError) Error() string {
+	return fmt.Sprintf("%s: %s is not allowed while the room is %s", ErrIllegalRoomTransition, e.Event, e.From)
+}
+
+// Unwrap lets errors.Is(err, ErrIllegalRoomTransition) match
+func (e *RoomTransitionError) Unwrap() error {
+	return ErrIllegalRoomTransition
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a server-side login session, one per browser or device
+type Session struct {
+	ID         uuid.UUID  `json:"id"`
+	TokenHash  string     `json:"token_hash"` // hex SHA-256 of the token in the session cookie
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	Data       string     `json:"data"` // encoded session values
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	// Current marks the session of the request listing them
+	Current bool `json:"-"`
+}
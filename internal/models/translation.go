@@ -6,15 +6,12 @@ import (
 	"github.com/google/uuid"
 )
 
-// Translation represents a translation entry
+// Translation represents a UI string edited by an admin (translations table)
+// Strings not in the table fall back to the static/i18n/*.json files.
 type Translation struct {
-	ID               uuid.UUID `json:"id"`
-	LanguageCode     string    `json:"language_code"`
-	TranslationKey   string    `json:"translation_key"`
-	TranslationValue string    `json:"translation_value"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	LanguageCode string    `json:"lang_code"`
+	Key          string    `json:"key"`
+	Value        string    `json:"value"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
-
-
-
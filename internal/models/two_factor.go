@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserTwoFactor holds a user's TOTP secret
+// EnabledAt is nil while enrollment waits for the first code.
+type UserTwoFactor struct {
+	ID             uuid.UUID  `json:"id"`
+	UserID         uuid.UUID  `json:"user_id"`
+	Secret         string     `json:"secret"` // base32, as shown to authenticator apps
+	EnabledAt      *time.Time `json:"enabled_at,omitempty"`
+	LastUsedStep   int64      `json:"last_used_step"`  // time step of the last accepted code, so a code works once
+	FailedAttempts int        `json:"failed_attempts"` // wrong codes since the last success or lock
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// Enabled reports whether enrollment was confirmed
+func (t *UserTwoFactor) Enabled() bool {
+	return t.EnabledAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code when the device is lost
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	CodeHash  string     `json:"code_hash"` // hex SHA-256 of the normalized code
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -14,10 +14,20 @@ type User struct {
 	AvatarURL          *string    `json:"avatar_url,omitempty"`
 	IsAdmin            bool       `json:"is_admin"`
 	IsAnonymous        bool       `json:"is_anonymous"`
-	LanguagePreference string     `json:"language_preference,omitempty"`
+	LanguagePreference string     `json:"language_preference,omitempty"` // UI language; empty to detect it
+	QuestionLanguage   string     `json:"question_language,omitempty"`   // Default language of new rooms
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
 	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
 	LastSeenAt         *time.Time `json:"last_seen_at,omitempty"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	MergedInto         *uuid.UUID `json:"merged_into,omitempty"` // Account an unfinished merge moves this guest into
 }
 
+// LastActiveAt returns when the user was last seen, or created when never seen since
+func (u *User) LastActiveAt() time.Time {
+	if u.LastSeenAt != nil && u.LastSeenAt.After(u.CreatedAt) {
+		return *u.LastSeenAt
+	}
+	return u.CreatedAt
+}
@@ -0,0 +1,76 @@
+package rendering
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/hekigan/couples/internal/viewmodels"
+)
+
+// TestTemplService_RenderFragment tests HTML fragment rendering for SSE broadcasts
+func TestTemplService_RenderFragment(t *testing.T) {
+	service := NewTemplService()
+
+	t.Run("RenderGameStarted", func(t *testing.T) {
+		html, err := service.RenderFragment("game_started.html", viewmodels.GameStartedData{
+			RoomID: "test-room-id",
+		})
+		if err != nil {
+			t.Fatalf("RenderFragment should not error: %v", err)
+		}
+		if !strings.Contains(html, "test-room-id") {
+			t.Errorf("HTML should contain room ID, got %q", html)
+		}
+	})
+
+	t.Run("RenderQuestionDrawn", func(t *testing.T) {
+		html, err := service.RenderFragment("question_drawn.html", viewmodels.QuestionDrawnData{
+			RoomID:                "test-room-id",
+			QuestionNumber:        5,
+			MaxQuestions:          20,
+			Category:              "couples",
+			CategoryLabel:         "Couples",
+			QuestionText:          "What is your favorite memory together?",
+			IsMyTurn:              true,
+			CurrentPlayerUsername: "Player1",
+		})
+		if err != nil {
+			t.Fatalf("RenderFragment should not error: %v", err)
+		}
+		for _, want := range []string{"Couples", "What is your favorite memory together?"} {
+			if !strings.Contains(html, want) {
+				t.Errorf("HTML should contain %q", want)
+			}
+		}
+	})
+
+	t.Run("WrongDataType", func(t *testing.T) {
+		if _, err := service.RenderFragment("game_started.html", map[string]string{}); err == nil {
+			t.Error("RenderFragment should error for mismatched data type")
+		}
+	})
+
+	t.Run("InvalidTemplate", func(t *testing.T) {
+		if _, err := service.RenderFragment("nonexistent.html", nil); err == nil {
+			t.Error("RenderFragment should error for unknown template")
+		}
+	})
+}
+
+// TestTemplService_ConcurrentRendering tests that rendering is safe for concurrent use
+func TestTemplService_ConcurrentRendering(t *testing.T) {
+	service := NewTemplService()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := service.RenderFragment("game_started.html", viewmodels.GameStartedData{RoomID: "room"}); err != nil {
+				t.Errorf("Concurrent render failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
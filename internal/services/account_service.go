@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// Account token lifetimes
+const (
+	PasswordResetTokenTTL     = time.Hour
+	EmailVerificationTokenTTL = 48 * time.Hour
+)
+
+// CredentialUpdater changes the credentials held by the identity provider
+// AuthService implements it through the Supabase Auth admin API.
+type CredentialUpdater interface {
+	UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+}
+
+// AccountService handles the account flows confirmed by email:
+// password resets, email verification and email changes
+type AccountService struct {
+	*BaseService
+	credentials CredentialUpdater
+	mailer      Mailer
+	i18n        *I18nService
+	baseURL     string
+	now         func() time.Time
+}
+
+// NewAccountService creates a new account service
+// baseURL is the public URL of the app the emailed links point to.
+func NewAccountService(store Store, credentials CredentialUpdater, mailer Mailer, i18n *I18nService, baseURL string) *AccountService {
+	return &AccountService{
+		BaseService: NewBaseService(store, "AccountService"),
+		credentials: credentials,
+		mailer:      mailer,
+		i18n:        i18n,
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		now:         time.Now,
+	}
+}
+
+// RequestPasswordReset mails a password reset link to the registered user with this email
+// Unknown emails are not an error, so the form does not reveal which addresses have accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email, lang string) error {
+	user, err := s.findUserByEmail(ctx, email)
+	if errors.Is(err, ErrNotFound) {
+		s.logger.Debug("Password reset requested for an unknown email")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.TokenPasswordReset, *user.Email, PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendTokenMail(ctx, user, *user.Email, "email.password_reset", "/reset-password", token, PasswordResetTokenTTL, lang)
+}
+
+// CheckPasswordResetToken reports whether token can still reset a password
+func (s *AccountService) CheckPasswordResetToken(ctx context.Context, token string) error {
+	_, err := s.findToken(ctx, token, models.TokenPasswordReset)
+	return err
+}
+
+// ResetPassword sets a new password with a token from RequestPasswordReset
+// Every session and API token of the user is revoked, so whoever knew the old password is
+// signed out. Following the link proves the user owns the address, so the email is marked
+// verified too.
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	accountToken, err := s.consumeToken(ctx, token, models.TokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if err := s.credentials.UpdatePassword(ctx, accountToken.UserID, password); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	err = s.WithTx(ctx, func(tx *BaseService) error {
+		if err := revokeUserSessions(ctx, tx, accountToken.UserID); err != nil {
+			return err
+		}
+		if err := revokeUserAPITokens(ctx, tx, accountToken.UserID); err != nil {
+			return err
+		}
+		return markEmailVerified(ctx, tx, accountToken, s.now())
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Success("Password reset for user_id=%s", accountToken.UserID.String())
+	return nil
+}
+
+// SendVerificationEmail mails a link confirming the user's current email
+func (s *AccountService) SendVerificationEmail(ctx context.Context, user *models.User, lang string) error {
+	if user.Email == nil || *user.Email == "" {
+		return models.ErrEmailRequired
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.TokenEmailVerification, *user.Email, EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendTokenMail(ctx, user, *user.Email, "email.verify", "/verify-email", token, EmailVerificationTokenTTL, lang)
+}
+
+// RequestEmailChange mails a confirmation link to the new address
+// The email only changes once that link is followed (see VerifyEmail).
+func (s *AccountService) RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail, lang string) error {
+	newEmail = normalizeEmail(newEmail)
+	if newEmail == "" {
+		return models.ErrEmailRequired
+	}
+
+	var user models.User
+	if err := s.GetSingleRecord(ctx, "users", userID, &user); err != nil {
+		return err
+	}
+	if user.IsAnonymous {
+		return models.ErrEmailRequired
+	}
+	if user.Email != nil && normalizeEmail(*user.Email) == newEmail {
+		return nil
+	}
+
+	count, err := s.CountRecords(ctx, "users", map[string]interface{}{"email": newEmail})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return models.ErrEmailInUse
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.TokenEmailChange, newEmail, EmailVerificationTokenTTL)
+	if err != nil {
+		return err
+	}
+	return s.sendTokenMail(ctx, &user, newEmail, "email.change", "/verify-email", token, EmailVerificationTokenTTL, lang)
+}
+
+// VerifyEmail confirms an email with a token from SendVerificationEmail or RequestEmailChange
+// and returns the updated user. An email change is applied to the identity provider first.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	accountToken, err := s.consumeToken(ctx, token, models.TokenEmailVerification, models.TokenEmailChange)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountToken.Purpose == models.TokenEmailChange {
+		if err := s.credentials.UpdateEmail(ctx, accountToken.UserID, accountToken.Email); err != nil {
+			return nil, fmt.Errorf("failed to update email: %w", err)
+		}
+		if err := s.UpdateRecord(ctx, "users", accountToken.UserID, map[string]interface{}{"email": accountToken.Email}); err != nil {
+			return nil, err
+		}
+		s.logger.Success("Email changed for user_id=%s", accountToken.UserID.String())
+	}
+
+	if err := markEmailVerified(ctx, s.BaseService, accountToken, s.now()); err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.GetSingleRecord(ctx, "users", accountToken.UserID, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteExpiredTokens deletes the tokens that expired or were used more than a day ago
+func (s *AccountService) DeleteExpiredTokens(ctx context.Context) (int, error) {
+	cutoff := s.now().Add(-24 * time.Hour)
+	deleted := 0
+	for _, q := range []*Query{NewQuery().Lt("expires_at", cutoff), NewQuery().Lt("used_at", cutoff)} {
+		count, err := s.store.Count(ctx, "account_tokens", q)
+		if err != nil {
+			return deleted, err
+		}
+		if count == 0 {
+			continue
+		}
+		if err := s.store.Delete(ctx, "account_tokens", q); err != nil {
+			return deleted, fmt.Errorf("failed to delete expired tokens: %w", err)
+		}
+		deleted += count
+	}
+	return deleted, nil
+}
+
+// findUserByEmail finds the registered user with email
+func (s *AccountService) findUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	email = normalizeEmail(email)
+	if email == "" {
+		return nil, fmt.Errorf("empty email: %w", ErrNotFound)
+	}
+
+	var user models.User
+	if err := s.QuerySingleRecord(ctx, "users", Where().Eq("email", email).Eq("is_anonymous", false), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// issueToken stores a new token for purpose and returns it; only its hash is kept
+// Earlier unused tokens of the same purpose are deleted, so only the latest link works.
+func (s *AccountService) issueToken(ctx context.Context, userID uuid.UUID, purpose, email string, ttl time.Duration) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := s.WithTx(ctx, func(tx *BaseService) error {
+		unused := Where(WithUserID(userID)).Eq("purpose", purpose).Eq("used_at", nil)
+		if err := tx.store.Delete(ctx, "account_tokens", unused); err != nil {
+			return fmt.Errorf("failed to delete earlier tokens: %w", err)
+		}
+		return tx.InsertRecord(ctx, "account_tokens", map[string]interface{}{
+			"id":         uuid.New().String(),
+			"user_id":    userID.String(),
+			"purpose":    purpose,
+			"token_hash": hashToken(token),
+			"email":      email,
+			"expires_at": s.now().Add(ttl).UTC(),
+		})
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// findToken returns the usable token with one of purposes, or models.ErrInvalidToken
+func (s *AccountService) findToken(ctx context.Context, token string, purposes ...string) (*models.AccountToken, error) {
+	if token == "" {
+		return nil, models.ErrInvalidToken
+	}
+
+	var accountToken models.AccountToken
+	err := s.QuerySingleRecord(ctx, "account_tokens", Where().Eq("token_hash", hashToken(token)).In("purpose", purposes), &accountToken)
+	if errors.Is(err, ErrNotFound) {
+		return nil, models.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !accountToken.Usable(s.now()) {
+		return nil, models.ErrInvalidToken
+	}
+	return &accountToken, nil
+}
+
+// consumeToken marks a usable token as used; of two concurrent requests only one succeeds
+func (s *AccountService) consumeToken(ctx context.Context, token string, purposes ...string) (*models.AccountToken, error) {
+	accountToken, err := s.findToken(ctx, token, purposes...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.store.Update(ctx, "account_tokens",
+		Where().Eq("id", accountToken.ID.String()).Eq("used_at", nil),
+		map[string]interface{}{"used_at": s.now().UTC()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to use token: %w", err)
+	}
+	var claimed []json.RawMessage
+	if err := json.Unmarshal(data, &claimed); err != nil {
+		return nil, fmt.Errorf("failed to parse account_tokens data: %w", err)
+	}
+	if len(claimed) == 0 {
+		return nil, models.ErrInvalidToken
+	}
+	return accountToken, nil
+}
+
+// markEmailVerified records that the user confirmed the address the token was sent to
+// Nothing changes when the user's email has changed since.
+func markEmailVerified(ctx context.Context, b *BaseService, accountToken *models.AccountToken, now time.Time) error {
+	_, err := b.store.Update(ctx, "users",
+		Where().Eq("id", accountToken.UserID.String()).Eq("email", accountToken.Email),
+		map[string]interface{}{"email_verified_at": now.UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to mark email verified: %w", err)
+	}
+	return nil
+}
+
+// sendTokenMail mails the link carrying token, with subject and body from the <key>.subject
+// and <key>.body translations
+func (s *AccountService) sendTokenMail(ctx context.Context, user *models.User, to, key, path, token string, ttl time.Duration, lang string) error {
+	if user.LanguagePreference != "" {
+		lang = user.LanguagePreference
+	}
+
+	args := MessageArgs{
+		"username": user.Username,
+		"email":    to,
+		"link":     s.baseURL + path + "?token=" + url.QueryEscape(token),
+		"hours":    int(ttl.Hours()),
+	}
+	mail := Mail{
+		To:      to,
+		Subject: s.i18n.Translate(lang, key+".subject", args),
+		Text:    s.i18n.Translate(lang, key+".body", args),
+	}
+	if err := s.mailer.Send(ctx, mail); err != nil {
+		return err
+	}
+
+	s.logger.Debug("Sent %s mail to user_id=%s", key, user.ID.String())
+	return nil
+}
+
+// hashToken returns the hex SHA-256 of token, as stored in account_tokens.token_hash
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeEmail trims and lower-cases an email address
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
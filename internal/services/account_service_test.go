@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// recordingMailer keeps the mails it is asked to send
+type recordingMailer struct {
+	mu    sync.Mutex
+	mails []Mail
+}
+
+func (m *recordingMailer) Send(ctx context.Context, mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mails = append(m.mails, mail)
+	return nil
+}
+
+// last returns the latest mail and the token its link carries
+func (m *recordingMailer) last(t *testing.T) (Mail, string) {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.mails) == 0 {
+		t.Fatal("no mail was sent")
+	}
+	mail := m.mails[len(m.mails)-1]
+	_, rest, ok := strings.Cut(mail.Text, "?token=")
+	if !ok {
+		t.Fatalf("mail has no link: %q", mail.Text)
+	}
+	token, _, _ := strings.Cut(rest, "\n")
+	return mail, token
+}
+
+// recordingCredentials keeps the credential changes made through it
+type recordingCredentials struct {
+	passwords map[uuid.UUID]string
+	emails    map[uuid.UUID]string
+}
+
+func (c *recordingCredentials) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	c.passwords[userID] = password
+	return nil
+}
+
+func (c *recordingCredentials) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	c.emails[userID] = email
+	return nil
+}
+
+// newTestAccountService creates an account service with the repository's translations,
+// whose clock starts now
+func newTestAccountService(t *testing.T) (*AccountService, Store, *recordingMailer, *recordingCredentials, *time.Time) {
+	t.Helper()
+
+	store := SetupTestStore(t)
+	mailer := &recordingMailer{}
+	credentials := &recordingCredentials{passwords: map[uuid.UUID]string{}, emails: map[uuid.UUID]string{}}
+	service := NewAccountService(store, credentials, mailer, NewI18nService(store, "../../static/i18n", NewLanguageService(store)), "https://couples.example/")
+
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	return service, store, mailer, credentials, &now
+}
+
+// createUserWithEmail creates a registered user with an email and language
+func createUserWithEmail(t *testing.T, store Store, username, email, lang string) uuid.UUID {
+	t.Helper()
+	user := CreateTestUser(t, store, username, username, false)
+	AssertNoError(t, NewBaseService(store, "Test").UpdateRecord(context.Background(), "users", user.ID, map[string]interface{}{
+		"email": email, "language_preference": lang,
+	}), "set email")
+	return user.ID
+}
+
+// TestAccountService_PasswordReset tests the reset request, the single-use link and expiry
+func TestAccountService_PasswordReset(t *testing.T) {
+	service, store, mailer, credentials, now := newTestAccountService(t)
+	ctx := context.Background()
+	userID := createUserWithEmail(t, store, "alice", "alice@example.com", "")
+
+	AssertNoError(t, service.RequestPasswordReset(ctx, "nobody@example.com", "en"), "unknown email")
+	AssertEqual(t, 0, len(mailer.mails), "mails for an unknown email")
+
+	AssertNoError(t, service.RequestPasswordReset(ctx, " Alice@Example.com ", "en"), "request reset")
+	mail, token := mailer.last(t)
+	AssertEqual(t, "alice@example.com", mail.To, "recipient")
+	AssertEqual(t, "Reset your Couple Card Game password", mail.Subject, "subject")
+	AssertTrue(t, strings.Contains(mail.Text, "https://couples.example/reset-password?token="), "link in body")
+
+	var tokens []models.AccountToken
+	AssertNoError(t, NewBaseService(store, "Test").QueryRecords(ctx, "account_tokens", NewQuery(), &tokens), "tokens")
+	AssertEqual(t, 1, len(tokens), "stored tokens")
+	AssertEqual(t, hashToken(token), tokens[0].TokenHash, "only the hash is stored")
+
+	sessions := NewSessionService(store)
+	AssertNoError(t, sessions.SaveSession(ctx, "old-session", userID.String(), "", "Firefox", "", now.Add(time.Hour)), "save session")
+	_, _, err := NewAPITokenService(store).CreateToken(ctx, &models.User{ID: userID, Username: "alice"}, false, "script",
+		[]string{models.ScopeFriends}, 0)
+	AssertNoError(t, err, "create API token")
+
+	AssertNoError(t, service.CheckPasswordResetToken(ctx, token), "check token")
+	AssertNoError(t, service.ResetPassword(ctx, token, "new-secret"), "reset")
+	AssertEqual(t, "new-secret", credentials.passwords[userID], "new password")
+
+	remaining, err := sessions.ListUserSessions(ctx, userID, "")
+	AssertNoError(t, err, "list sessions")
+	AssertEqual(t, 0, len(remaining), "sessions revoked by the reset")
+	apiTokens, err := NewAPITokenService(store).ListTokens(ctx, userID)
+	AssertNoError(t, err, "list API tokens")
+	AssertEqual(t, 0, len(apiTokens), "API tokens revoked by the reset")
+
+	var user models.User
+	AssertNoError(t, service.GetSingleRecord(ctx, "users", userID, &user), "user")
+	AssertNotNil(t, user.EmailVerifiedAt, "email verified by the reset")
+
+	if err := service.ResetPassword(ctx, token, "again"); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("reusing the link: error = %v, want ErrInvalidToken", err)
+	}
+
+	AssertNoError(t, service.RequestPasswordReset(ctx, "alice@example.com", "en"), "second request")
+	_, expired := mailer.last(t)
+	*now = now.Add(PasswordResetTokenTTL + time.Minute)
+	if err := service.CheckPasswordResetToken(ctx, expired); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("expired link: error = %v, want ErrInvalidToken", err)
+	}
+}
+
+// TestAccountService_OnlyLatestLinkWorks tests that a new request invalidates the earlier link
+func TestAccountService_OnlyLatestLinkWorks(t *testing.T) {
+	service, store, mailer, _, _ := newTestAccountService(t)
+	ctx := context.Background()
+	createUserWithEmail(t, store, "bob", "bob@example.com", "")
+
+	AssertNoError(t, service.RequestPasswordReset(ctx, "bob@example.com", "en"), "first request")
+	_, first := mailer.last(t)
+	AssertNoError(t, service.RequestPasswordReset(ctx, "bob@example.com", "en"), "second request")
+	_, second := mailer.last(t)
+
+	if err := service.CheckPasswordResetToken(ctx, first); !errors.Is(err, models.ErrInvalidToken) {
+		t.Errorf("first link: error = %v, want ErrInvalidToken", err)
+	}
+	AssertNoError(t, service.CheckPasswordResetToken(ctx, second), "second link")
+}
+
+// TestAccountService_EmailChange tests that the new address must confirm the change,
+// in the user's language
+func TestAccountService_EmailChange(t *testing.T) {
+	service, store, mailer, credentials, _ := newTestAccountService(t)
+	ctx := context.Background()
+	userID := createUserWithEmail(t, store, "claire", "claire@example.com", "fr")
+	createUserWithEmail(t, store, "dan", "dan@example.com", "")
+
+	if err := service.RequestEmailChange(ctx, userID, "DAN@example.com", "en"); !errors.Is(err, models.ErrEmailInUse) {
+		t.Errorf("taken email: error = %v, want ErrEmailInUse", err)
+	}
+
+	AssertNoError(t, service.RequestEmailChange(ctx, userID, "claire@new.example", "en"), "request change")
+	mail, token := mailer.last(t)
+	AssertEqual(t, "claire@new.example", mail.To, "mail goes to the new address")
+	AssertEqual(t, "Confirmez votre nouvelle adresse e-mail", mail.Subject, "subject in the user's language")
+	AssertTrue(t, strings.Contains(mail.Text, "claire@new.example"), "new address in body")
+
+	var user models.User
+	AssertNoError(t, service.GetSingleRecord(ctx, "users", userID, &user), "user")
+	AssertEqual(t, "claire@example.com", *user.Email, "email unchanged before confirmation")
+
+	updated, err := service.VerifyEmail(ctx, token)
+	AssertNoError(t, err, "verify")
+	AssertEqual(t, "claire@new.example", *updated.Email, "email changed")
+	AssertNotNil(t, updated.EmailVerifiedAt, "new email verified")
+	AssertEqual(t, "claire@new.example", credentials.emails[userID], "identity provider email")
+}
+
+// TestAccountService_DeleteExpiredTokens tests that only long-expired or long-used tokens are deleted
+func TestAccountService_DeleteExpiredTokens(t *testing.T) {
+	service, store, mailer, _, now := newTestAccountService(t)
+	ctx := context.Background()
+	createUserWithEmail(t, store, "erin", "erin@example.com", "")
+	frankID := createUserWithEmail(t, store, "frank", "frank@example.com", "")
+
+	AssertNoError(t, service.RequestPasswordReset(ctx, "erin@example.com", "en"), "erin request")
+	*now = now.Add(48 * time.Hour)
+
+	var frank models.User
+	AssertNoError(t, service.GetSingleRecord(ctx, "users", frankID, &frank), "frank")
+	AssertNoError(t, service.SendVerificationEmail(ctx, &frank, "en"), "frank verification")
+	_, token := mailer.last(t)
+	_, err := service.VerifyEmail(ctx, token)
+	AssertNoError(t, err, "verify")
+
+	deleted, err := service.DeleteExpiredTokens(ctx)
+	AssertNoError(t, err, "delete")
+	AssertEqual(t, 1, deleted, "deleted tokens")
+}
@@ -2,27 +2,22 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/postgrest-go"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
 // AdminService handles admin-specific operations
 type AdminService struct {
 	*BaseService
-	client *supabase.Client
 }
 
 // NewAdminService creates a new AdminService
-func NewAdminService(client *supabase.Client) *AdminService {
+func NewAdminService(store Store) *AdminService {
 	return &AdminService{
-		BaseService: NewBaseService(client, "AdminService"),
-		client:      client,
+		BaseService: NewBaseService(store, "AdminService"),
 	}
 }
 
@@ -106,7 +101,7 @@ func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats,
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if count, err := s.BaseService.CountRecords(ctx, "rooms", map[string]interface{}{"status": "active"}); err == nil {
+		if count, err := s.BaseService.CountQueryRecords(ctx, "rooms", NewQuery().In("status", []string{string(models.RoomPlaying), string(models.RoomPaused)})); err == nil {
 			mu.Lock()
 			stats.ActiveRooms = count
 			mu.Unlock()
@@ -117,7 +112,7 @@ func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats,
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if count, err := s.BaseService.CountRecords(ctx, "rooms", map[string]interface{}{"status": "completed"}); err == nil {
+		if count, err := s.BaseService.CountRecords(ctx, "rooms", map[string]interface{}{"status": string(models.RoomFinished)}); err == nil {
 			mu.Lock()
 			stats.CompletedRooms = count
 			mu.Unlock()
@@ -154,29 +149,16 @@ func (s *AdminService) GetDashboardStats(ctx context.Context) (*DashboardStats,
 
 // ListAllUsers retrieves all users with pagination
 func (s *AdminService) ListAllUsers(ctx context.Context, limit, offset int) ([]*models.User, error) {
-	// Custom query - uses Order which is not supported by BaseService
-	query := s.client.From("users").
-		Select("*", "", false).
-		Order("created_at", &postgrest.OrderOpts{Ascending: false})
-
-	if limit > 0 {
-		query = query.Range(offset, offset+limit-1, "")
-	}
-
-	data, _, err := query.Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list users: %w", err)
-	}
-
 	var users []*models.User
-	if err := json.Unmarshal(data, &users); err != nil {
-		return nil, fmt.Errorf("failed to parse users: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "users", NewQuery().OrderBy("created_at", false).Page(limit, offset), &users); err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 
 	return users, nil
 }
 
 // ToggleUserAdmin toggles the is_admin flag for a user
+// The user's sessions are ended, so the change applies from their next login.
 func (s *AdminService) ToggleUserAdmin(ctx context.Context, userID uuid.UUID) error {
 	// First get the current user
 	var user models.User
@@ -189,12 +171,22 @@ func (s *AdminService) ToggleUserAdmin(ctx context.Context, userID uuid.UUID) er
 		"is_admin": !user.IsAdmin,
 	}
 
-	return s.BaseService.UpdateRecord(ctx, "users", userID, updateData)
+	return s.WithTx(ctx, func(tx *BaseService) error {
+		if err := tx.UpdateRecord(ctx, "users", userID, updateData); err != nil {
+			return err
+		}
+		return revokeUserSessions(ctx, tx, userID)
+	})
 }
 
-// DeleteUser deletes a user
+// DeleteUser deletes a user and ends their sessions
 func (s *AdminService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	return s.BaseService.DeleteRecord(ctx, "users", userID)
+	return s.WithTx(ctx, func(tx *BaseService) error {
+		if err := revokeUserSessions(ctx, tx, userID); err != nil {
+			return err
+		}
+		return tx.DeleteRecord(ctx, "users", userID)
+	})
 }
 
 // GetUserByID retrieves a user by ID
@@ -232,7 +224,13 @@ func (s *AdminService) CreateUser(ctx context.Context, username, email string, i
 }
 
 // UpdateUser updates a user's information
+// Changing the admin flag ends the user's sessions, like ToggleUserAdmin.
 func (s *AdminService) UpdateUser(ctx context.Context, userID uuid.UUID, username, email string, isAdmin, isAnonymous bool) error {
+	var user models.User
+	if err := s.BaseService.GetSingleRecord(ctx, "users", userID, &user); err != nil {
+		return err
+	}
+
 	updateData := map[string]interface{}{
 		"username":     username,
 		"is_admin":     isAdmin,
@@ -245,66 +243,68 @@ func (s *AdminService) UpdateUser(ctx context.Context, userID uuid.UUID, usernam
 		updateData["name"] = username
 	}
 
-	return s.BaseService.UpdateRecord(ctx, "users", userID, updateData)
+	return s.WithTx(ctx, func(tx *BaseService) error {
+		if err := tx.UpdateRecord(ctx, "users", userID, updateData); err != nil {
+			return err
+		}
+		if user.IsAdmin == isAdmin {
+			return nil
+		}
+		return revokeUserSessions(ctx, tx, userID)
+	})
 }
 
 // ListAllRooms retrieves rooms with filtering and sorting
 func (s *AdminService) ListAllRooms(ctx context.Context, limit, offset int, search string, statuses []string, sortBy, sortOrder string) ([]*models.RoomWithPlayers, error) {
-	query := s.client.From("rooms_with_players").
-		Select("*", "", false)
-
-	// Apply search filter (case-insensitive across name, owner, guest)
-	if search != "" {
-		searchPattern := fmt.Sprintf("%%%s%%", search)
-		query = query.Or(fmt.Sprintf("name.ilike.%s,owner_username.ilike.%s,guest_username.ilike.%s",
-			searchPattern, searchPattern, searchPattern), "")
-	}
-
-	// Apply status filter
-	if len(statuses) > 0 {
-		query = query.In("status", statuses)
-	}
+	query := roomSearchQuery(search, statuses)
 
 	// Apply sorting (default: created_at DESC)
 	ascending := sortOrder == "asc"
 	switch sortBy {
 	case "owner":
-		query = query.Order("owner_username", &postgrest.OrderOpts{Ascending: ascending, NullsFirst: !ascending})
+		query = query.OrderByNulls("owner_username", ascending, !ascending)
 	case "guest":
-		query = query.Order("guest_username", &postgrest.OrderOpts{Ascending: ascending, NullsFirst: !ascending})
+		query = query.OrderByNulls("guest_username", ascending, !ascending)
 	case "status":
-		query = query.Order("status", &postgrest.OrderOpts{Ascending: ascending})
+		query = query.OrderBy("status", ascending)
 	case "created_at":
-		query = query.Order("created_at", &postgrest.OrderOpts{Ascending: ascending})
+		query = query.OrderBy("created_at", ascending)
 	default:
-		query = query.Order("created_at", &postgrest.OrderOpts{Ascending: false})
+		query = query.OrderBy("created_at", false)
 	}
 
 	// Apply pagination
-	if limit > 0 {
-		query = query.Range(offset, offset+limit-1, "")
-	}
-
-	data, _, err := query.Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list rooms: %w", err)
-	}
+	query = query.Page(limit, offset)
 
 	var rooms []*models.RoomWithPlayers
-	if err := json.Unmarshal(data, &rooms); err != nil {
-		return nil, fmt.Errorf("failed to parse rooms: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "rooms_with_players", query, &rooms); err != nil {
+		return nil, fmt.Errorf("failed to list rooms: %w", err)
 	}
 
 	return rooms, nil
 }
 
-// ForceCloseRoom closes a room regardless of its state
-func (s *AdminService) ForceCloseRoom(ctx context.Context, roomID uuid.UUID) error {
-	updateData := map[string]interface{}{
-		"status": "completed",
+// roomSearchQuery builds the admin room filter: case-insensitive search across
+// name, owner and guest, plus an optional status filter
+func roomSearchQuery(search string, statuses []string) *Query {
+	query := NewQuery().SearchIn(search, "name", "owner_username", "guest_username")
+
+	if len(statuses) > 0 {
+		query = query.In("status", statuses)
 	}
 
-	return s.BaseService.UpdateRecord(ctx, "rooms", roomID, updateData)
+	return query
+}
+
+// ForceCloseRoom abandons a room whatever stage it is at
+// Rooms that are already finished or abandoned return models.ErrIllegalRoomTransition.
+func (s *AdminService) ForceCloseRoom(ctx context.Context, roomID uuid.UUID) error {
+	return RetryOnConflict(ctx, func() error {
+		return s.WithTx(ctx, func(tx *BaseService) error {
+			_, err := transitionRoom(ctx, tx, roomID, models.RoomEventAbandon, nil)
+			return err
+		})
+	})
 }
 
 // GetUserCount returns the total number of users
@@ -319,23 +319,10 @@ func (s *AdminService) GetRoomCount(ctx context.Context) (int, error) {
 
 // GetFilteredRoomCount returns count of rooms matching filters
 func (s *AdminService) GetFilteredRoomCount(ctx context.Context, search string, statuses []string) (int, error) {
-	query := s.client.From("rooms_with_players").
-		Select("*", "exact", true) // Count query
-
-	if search != "" {
-		searchPattern := fmt.Sprintf("%%%s%%", search)
-		query = query.Or(fmt.Sprintf("name.ilike.%s,owner_username.ilike.%s,guest_username.ilike.%s",
-			searchPattern, searchPattern, searchPattern), "")
-	}
-
-	if len(statuses) > 0 {
-		query = query.In("status", statuses)
-	}
-
-	_, count, err := query.Execute()
+	count, err := s.BaseService.CountQueryRecords(ctx, "rooms_with_players", roomSearchQuery(search, statuses))
 	if err != nil {
 		return 0, fmt.Errorf("failed to count rooms: %w", err)
 	}
 
-	return int(count), nil
+	return count, nil
 }
@@ -2,26 +2,22 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
 // AnswerService handles answer-related operations
 type AnswerService struct {
 	*BaseService
-	client *supabase.Client
 }
 
 // NewAnswerService creates a new answer service
-func NewAnswerService(client *supabase.Client) *AnswerService {
+func NewAnswerService(store Store) *AnswerService {
 	return &AnswerService{
-		BaseService: NewBaseService(client, "AnswerService"),
-		client:      client,
+		BaseService: NewBaseService(store, "AnswerService"),
 	}
 }
 
@@ -54,20 +50,9 @@ func (s *AnswerService) CreateAnswer(ctx context.Context, answer *models.Answer)
 
 // GetAnswersByRoom retrieves all answers for a room, ordered by creation time
 func (s *AnswerService) GetAnswersByRoom(ctx context.Context, roomID uuid.UUID) ([]models.Answer, error) {
-	// Custom query because we need ORDER BY - not supported by BaseService.GetRecords()
-	data, _, err := s.client.From("answers").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
-		Order("created_at", nil).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch answers: %w", err)
-	}
-
 	var answers []models.Answer
-	if err := json.Unmarshal(data, &answers); err != nil {
-		return nil, fmt.Errorf("failed to parse answers: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "answers", Where(WithRoomID(roomID)).OrderBy("created_at", true), &answers); err != nil {
+		return nil, fmt.Errorf("failed to fetch answers: %w", err)
 	}
 
 	return answers, nil
@@ -98,33 +83,23 @@ func (s *AnswerService) GetAnswersByQuestion(ctx context.Context, questionID uui
 
 // GetLastAnswerForQuestion retrieves the most recent answer for a specific question in a room
 func (s *AnswerService) GetLastAnswerForQuestion(ctx context.Context, roomID, questionID uuid.UUID) (*models.Answer, error) {
-	// Custom query with multiple filters - not a perfect fit for BaseService
 	log.Printf("📥 GetLastAnswerForQuestion: room=%s, question=%s", roomID, questionID)
 
-	data, _, err := s.client.From("answers").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
+	q := Where(WithRoomID(roomID)).
 		Eq("question_id", questionID.String()).
-		Execute()
+		OrderBy("created_at", false)
 
-	if err != nil {
+	var answers []models.Answer
+	if err := s.BaseService.QueryRecords(ctx, "answers", q, &answers); err != nil {
 		log.Printf("❌ GetLastAnswerForQuestion query error: %v", err)
 		return nil, fmt.Errorf("failed to fetch answer: %w", err)
 	}
 
-	log.Printf("📥 GetLastAnswerForQuestion response: %s", string(data))
-
-	var answers []models.Answer
-	if err := json.Unmarshal(data, &answers); err != nil {
-		log.Printf("❌ GetLastAnswerForQuestion parse error: %v", err)
-		return nil, fmt.Errorf("failed to parse answer: %w", err)
-	}
-
 	if len(answers) == 0 {
 		log.Printf("⚠️ GetLastAnswerForQuestion: no answers found")
 		return nil, nil // No answer found
 	}
 
-	log.Printf("✅ GetLastAnswerForQuestion: found %d answers, returning first", len(answers))
+	log.Printf("✅ GetLastAnswerForQuestion: found %d answers, returning most recent", len(answers))
 	return &answers[0], nil
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 
 	"github.com/google/uuid"
@@ -117,12 +118,36 @@ func TestGetAnswersByRoom_OrderedByCreation(t *testing.T) {
 	}
 
 	t.Run("answers should be ordered by created_at ascending", func(t *testing.T) {
-		t.Skip("Requires test database with multiple answers")
+		ctx := context.Background()
+		store := SetupTestStore(t)
+		answerService := NewAnswerService(store)
 
-		// Test logic:
-		// 1. Create multiple answers for same room with different timestamps
-		// 2. Call GetAnswersByRoom
-		// 3. Verify returned slice is ordered by created_at ascending
+		owner := CreateTestUser(t, store, "owner", "Owner", true)
+		roomID := CreateTestRoom(t, store, owner.ID, "en")
+		categoryID := CreateTestCategory(t, store, "ordering")
+
+		texts := []string{"first", "second", "third"}
+		for _, text := range texts {
+			answer := &models.Answer{
+				RoomID:     roomID,
+				QuestionID: CreateTestQuestion(t, store, categoryID, "en", "Question for "+text),
+				UserID:     owner.ID,
+				AnswerText: text,
+				ActionType: "answered",
+			}
+			AssertNoError(t, answerService.CreateAnswer(ctx, answer), "create answer")
+		}
+
+		answers, err := answerService.GetAnswersByRoom(ctx, roomID)
+		AssertNoError(t, err, "get answers")
+		if len(answers) != len(texts) {
+			t.Fatalf("expected %d answers, got %d", len(texts), len(answers))
+		}
+		for i, text := range texts {
+			if answers[i].AnswerText != text {
+				t.Errorf("answer %d = %q, want %q", i, answers[i].AnswerText, text)
+			}
+		}
 	})
 }
 
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+const (
+	// apiTokenPrefix starts every API token, so leaked tokens are easy to recognize
+	apiTokenPrefix = "cpl_"
+	// apiTokenTouchInterval is how stale last_used_at may get before a request refreshes it
+	apiTokenTouchInterval = time.Minute
+	// maxAPITokenNameLength matches api_tokens.name
+	maxAPITokenNameLength = 100
+)
+
+// APITokenService manages personal API tokens and authenticates the requests carrying them
+type APITokenService struct {
+	*BaseService
+	now func() time.Time
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(store Store) *APITokenService {
+	return &APITokenService{
+		BaseService: NewBaseService(store, "APITokenService"),
+		now:         time.Now,
+	}
+}
+
+// CreateToken issues a token for a registered user and returns it (only shown this once)
+// The admin scope needs an admin whose session completed the second factor (secondFactor),
+// since the token skips it. ttl 0 means the token does not expire.
+func (s *APITokenService) CreateToken(ctx context.Context, user *models.User, secondFactor bool, name string, scopes []string, ttl time.Duration) (string, *models.APIToken, error) {
+	if user.IsAnonymous {
+		return "", nil, models.ErrEmailRequired
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPITokenNameLength {
+		return "", nil, models.ErrInvalidAPITokenName
+	}
+	if len(scopes) == 0 {
+		return "", nil, models.ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(models.APIScopes, scope) {
+			return "", nil, models.ErrInvalidScope
+		}
+		if scope == models.ScopeAdmin && (!user.IsAdmin || !secondFactor) {
+			return "", nil, models.ErrInvalidScope
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	apiToken := &models.APIToken{
+		ID:          uuid.New(),
+		UserID:      user.ID,
+		Name:        name,
+		TokenPrefix: token[:len(apiTokenPrefix)+6],
+		TokenHash:   hashToken(token),
+		Scopes:      strings.Join(scopes, ","),
+		CreatedAt:   s.now().UTC(),
+	}
+	data := map[string]interface{}{
+		"id":           apiToken.ID.String(),
+		"user_id":      user.ID.String(),
+		"name":         apiToken.Name,
+		"token_prefix": apiToken.TokenPrefix,
+		"token_hash":   apiToken.TokenHash,
+		"scopes":       apiToken.Scopes,
+	}
+	if ttl > 0 {
+		expiresAt := s.now().Add(ttl).UTC()
+		apiToken.ExpiresAt = &expiresAt
+		data["expires_at"] = expiresAt
+	}
+	if err := s.InsertRecord(ctx, "api_tokens", data); err != nil {
+		return "", nil, err
+	}
+
+	s.logger.Success("Created API token %s for user_id=%s (%s)", apiToken.ID.String(), user.ID.String(), apiToken.Scopes)
+	return token, apiToken, nil
+}
+
+// ListTokens returns the user's tokens, newest first
+func (s *APITokenService) ListTokens(ctx context.Context, userID uuid.UUID) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	if err := s.QueryRecords(ctx, "api_tokens", Where(WithUserID(userID)).OrderBy("created_at", false), &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// RevokeToken deletes one of the user's tokens
+func (s *APITokenService) RevokeToken(ctx context.Context, userID, tokenID uuid.UUID) error {
+	if err := s.store.Delete(ctx, "api_tokens", Where(WithUserID(userID)).Eq("id", tokenID.String())); err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+	s.logger.Info("Revoked API token %s of user_id=%s", tokenID.String(), userID.String())
+	return nil
+}
+
+// revokeUserAPITokens deletes every API token of the user, e.g. after a password reset
+func revokeUserAPITokens(ctx context.Context, b *BaseService, userID uuid.UUID) error {
+	if err := b.DeleteRecordsWithFilter(ctx, "api_tokens", WithUserID(userID)); err != nil {
+		return fmt.Errorf("failed to revoke API tokens: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateToken returns who a bearer token acts as: its user, whether it carries admin
+// rights (the admin scope held by a user who is still an admin) and its scopes
+// Unknown and expired tokens, and tokens of deleted users, return models.ErrInvalidAPIToken.
+func (s *APITokenService) AuthenticateToken(ctx context.Context, token string) (uuid.UUID, bool, []string, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return uuid.Nil, false, nil, models.ErrInvalidAPIToken
+	}
+
+	var apiToken models.APIToken
+	err := s.QuerySingleRecord(ctx, "api_tokens", Where().Eq("token_hash", hashToken(token)), &apiToken)
+	if errors.Is(err, ErrNotFound) {
+		return uuid.Nil, false, nil, models.ErrInvalidAPIToken
+	}
+	if err != nil {
+		return uuid.Nil, false, nil, err
+	}
+	now := s.now()
+	if apiToken.Expired(now) {
+		return uuid.Nil, false, nil, models.ErrInvalidAPIToken
+	}
+
+	var user models.User
+	err = s.GetSingleRecord(ctx, "users", apiToken.UserID, &user)
+	if errors.Is(err, ErrNotFound) {
+		// The user is gone: the token is of no use to anyone
+		if err := s.RevokeToken(ctx, apiToken.UserID, apiToken.ID); err != nil {
+			s.logger.Warn("Failed to revoke API token %s of a deleted user: %v", apiToken.ID.String(), err)
+		}
+		return uuid.Nil, false, nil, models.ErrInvalidAPIToken
+	}
+	if err != nil {
+		return uuid.Nil, false, nil, err
+	}
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) > apiTokenTouchInterval {
+		if err := s.UpdateRecord(ctx, "api_tokens", apiToken.ID, map[string]interface{}{"last_used_at": now.UTC()}); err != nil {
+			s.logger.Warn("Failed to update last use of API token %s: %v", apiToken.ID.String(), err)
+		}
+	}
+
+	scopes := apiToken.ScopeList()
+	return user.ID, user.IsAdmin && slices.Contains(scopes, models.ScopeAdmin), scopes, nil
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/middleware"
+	"github.com/hekigan/couples/internal/models"
+	"github.com/labstack/echo/v4"
+)
+
+// TestAPITokenService_Lifecycle tests creating, using, expiring and revoking a token
+func TestAPITokenService_Lifecycle(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewAPITokenService(store)
+	ctx := context.Background()
+	testUser := CreateTestUser(t, store, "frank", "frank", false)
+	user, err := NewUserService(store).GetUserByID(ctx, testUser.ID)
+	AssertNoError(t, err, "load user")
+
+	token, created, err := service.CreateToken(ctx, user, false, " script ", []string{models.ScopeRoomsRead}, time.Hour)
+	AssertNoError(t, err, "create")
+	AssertEqual(t, "script", created.Name, "name trimmed")
+	AssertTrue(t, created.TokenHash != token, "only the hash is stored")
+
+	userID, isAdmin, scopes, err := service.AuthenticateToken(ctx, token)
+	AssertNoError(t, err, "authenticate")
+	AssertEqual(t, user.ID, userID, "token user")
+	AssertFalse(t, isAdmin, "not admin")
+	AssertEqual(t, 1, len(scopes), "scopes")
+
+	tokens, err := service.ListTokens(ctx, user.ID)
+	AssertNoError(t, err, "list")
+	AssertEqual(t, 1, len(tokens), "tokens")
+	AssertNotNil(t, tokens[0].LastUsedAt, "last use recorded")
+
+	_, _, _, err = service.AuthenticateToken(ctx, token+"x")
+	AssertTrue(t, errors.Is(err, models.ErrInvalidAPIToken), "unknown token refused")
+
+	now := time.Now().Add(2 * time.Hour)
+	service.now = func() time.Time { return now }
+	_, _, _, err = service.AuthenticateToken(ctx, token)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidAPIToken), "expired token refused")
+
+	forever, created, err := service.CreateToken(ctx, user, false, "forever", []string{models.ScopeFriends}, 0)
+	AssertNoError(t, err, "create without expiry")
+	AssertNoError(t, service.RevokeToken(ctx, user.ID, created.ID), "revoke")
+	_, _, _, err = service.AuthenticateToken(ctx, forever)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidAPIToken), "revoked token refused")
+
+	_, _, err = service.CreateToken(ctx, user, false, "", []string{models.ScopeFriends}, 0)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidAPITokenName), "empty name refused")
+	_, _, err = service.CreateToken(ctx, user, false, "none", nil, 0)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidScope), "no scope refused")
+	_, _, err = service.CreateToken(ctx, user, false, "bogus", []string{"rooms:delete"}, 0)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidScope), "unknown scope refused")
+
+	// A token outliving its user is refused and removed
+	orphan, created, err := service.CreateToken(ctx, user, false, "orphan", []string{models.ScopeFriends}, 0)
+	AssertNoError(t, err, "create orphan")
+	AssertNoError(t, store.Delete(ctx, "users", Where().Eq("id", user.ID.String())), "delete user")
+	_, _, _, err = service.AuthenticateToken(ctx, orphan)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidAPIToken), "deleted user's token refused")
+	count, err := store.Count(ctx, "api_tokens", Where().Eq("id", created.ID.String()))
+	AssertNoError(t, err, "count tokens")
+	AssertEqual(t, 0, count, "deleted user's token revoked")
+
+	guest := CreateTestUser(t, store, "guest", "guest", true)
+	guestUser, _ := NewUserService(store).GetUserByID(ctx, guest.ID)
+	_, _, err = service.CreateToken(ctx, guestUser, false, "guest", []string{models.ScopeRoomsRead}, 0)
+	AssertTrue(t, errors.Is(err, models.ErrEmailRequired), "guests cannot create tokens")
+}
+
+// TestAPITokenService_AdminScope tests who may create admin tokens and that they stop working
+// when the user is no longer an admin
+func TestAPITokenService_AdminScope(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewAPITokenService(store)
+	ctx := context.Background()
+	testUser := CreateTestUser(t, store, "grace", "grace", false)
+	user, _ := NewUserService(store).GetUserByID(ctx, testUser.ID)
+	adminScopes := []string{models.ScopeAdmin}
+
+	_, _, err := service.CreateToken(ctx, user, true, "admin", adminScopes, 0)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidScope), "non-admins cannot create admin tokens")
+
+	AssertNoError(t, NewAdminService(store).ToggleUserAdmin(ctx, user.ID), "promote")
+	user, _ = NewUserService(store).GetUserByID(ctx, user.ID)
+	_, _, err = service.CreateToken(ctx, user, false, "admin", adminScopes, 0)
+	AssertTrue(t, errors.Is(err, models.ErrInvalidScope), "admin tokens need the second factor")
+
+	token, _, err := service.CreateToken(ctx, user, true, "admin", adminScopes, 0)
+	AssertNoError(t, err, "create admin token")
+	_, isAdmin, _, err := service.AuthenticateToken(ctx, token)
+	AssertNoError(t, err, "authenticate")
+	AssertTrue(t, isAdmin, "admin token acts as admin")
+
+	readToken, _, _ := service.CreateToken(ctx, user, true, "read", []string{models.ScopeRoomsRead}, 0)
+	_, isAdmin, _, _ = service.AuthenticateToken(ctx, readToken)
+	AssertFalse(t, isAdmin, "token without the admin scope is not admin")
+
+	AssertNoError(t, NewAdminService(store).ToggleUserAdmin(ctx, user.ID), "demote")
+	_, isAdmin, _, err = service.AuthenticateToken(ctx, token)
+	AssertNoError(t, err, "authenticate after demotion")
+	AssertFalse(t, isAdmin, "demoted user's token loses admin rights")
+}
+
+// TestAPITokenService_BearerMiddleware tests bearer authentication, scopes and the CSRF exemption
+// through the middleware
+func TestAPITokenService_BearerMiddleware(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewAPITokenService(store)
+	middleware.InitSessionStore(NewSessionService(store), "current-secret")
+	ctx := context.Background()
+	testUser := CreateTestUser(t, store, "heidi", "heidi", false)
+	user, _ := NewUserService(store).GetUserByID(ctx, testUser.ID)
+	token, _, err := service.CreateToken(ctx, user, false, "reader", []string{models.ScopeRoomsRead}, 0)
+	AssertNoError(t, err, "create")
+
+	e := echo.New()
+	e.Use(middleware.EchoAuth(service))
+	e.Use(middleware.EchoCSRF())
+	ok := func(c echo.Context) error {
+		userID, _ := middleware.GetUserID(c)
+		return c.String(http.StatusOK, userID.String())
+	}
+	api := e.Group("/api/v1", middleware.EchoRequireAuth(),
+		middleware.EchoRequireReadWriteScope(models.ScopeRoomsRead, models.ScopeRoomsWrite))
+	api.GET("/rooms", ok)
+	api.POST("/rooms", ok)
+	e.GET("/profile", ok)
+
+	do := func(method, path, auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if auth != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+auth)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/rooms", token)
+	AssertEqual(t, http.StatusOK, rec.Code, "read with rooms:read")
+	AssertEqual(t, user.ID.String(), rec.Body.String(), "request runs as the token user")
+
+	rec = do(http.MethodPost, "/api/v1/rooms", token)
+	AssertEqual(t, http.StatusForbidden, rec.Code, "write without rooms:write (and no CSRF token)")
+
+	writer, _, _ := service.CreateToken(ctx, user, false, "writer", []string{models.ScopeRoomsWrite}, 0)
+	rec = do(http.MethodPost, "/api/v1/rooms", writer)
+	AssertEqual(t, http.StatusOK, rec.Code, "write with rooms:write skips CSRF")
+
+	rec = do(http.MethodGet, "/api/v1/rooms", "cpl_unknown")
+	AssertEqual(t, http.StatusUnauthorized, rec.Code, "invalid token")
+	AssertTrue(t, rec.Header().Get(echo.HeaderWWWAuthenticate) != "", "WWW-Authenticate set")
+
+	rec = do(http.MethodGet, "/profile", token)
+	AssertEqual(t, uuid.Nil.String(), rec.Body.String(), "tokens are ignored outside the API")
+}
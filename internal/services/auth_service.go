@@ -11,22 +11,23 @@ import (
 	"github.com/google/uuid"
 	"github.com/supabase-community/gotrue-go"
 	"github.com/supabase-community/gotrue-go/types"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
 // AuthService handles authentication operations with Supabase Auth
 type AuthService struct {
-	supabase    *supabase.Client
+	*BaseService
 	authClient  gotrue.Client
+	adminClient gotrue.Client // authenticated with the service role key for the admin API
 	redirectURL string
 }
 
 // NewAuthService creates a new authentication service
-func NewAuthService(client *supabase.Client) (*AuthService, error) {
+func NewAuthService(store Store) (*AuthService, error) {
 	supabaseURL := os.Getenv("SUPABASE_URL")
 	// Use SUPABASE_ANON_KEY for auth operations (signup, login)
 	supabaseKey := os.Getenv("SUPABASE_ANON_KEY")
+	serviceRoleKey := os.Getenv("SUPABASE_SERVICE_ROLE_KEY")
 	redirectURL := os.Getenv("OAUTH_REDIRECT_URL")
 
 	if redirectURL == "" {
@@ -43,8 +44,9 @@ func NewAuthService(client *supabase.Client) (*AuthService, error) {
 	authClient := gotrue.New(projectRef, supabaseKey)
 
 	return &AuthService{
-		supabase:    client,
+		BaseService: NewBaseService(store, "AuthService"),
 		authClient:  authClient,
+		adminClient: authClient.WithToken(serviceRoleKey),
 		redirectURL: redirectURL,
 	}, nil
 }
@@ -129,19 +131,10 @@ func (s *AuthService) CreateOrUpdateUserFromOAuth(ctx context.Context, oauthUser
 	}
 
 	var existingUsers []models.User
-	query := s.supabase.From("users").
-		Select("*", "exact", false).
-		Eq("id", userID.String())
-
-	data, _, err := query.Execute()
-	if err != nil {
+	if err := s.BaseService.QueryRecords(ctx, "users", Where().Eq("id", userID.String()), &existingUsers); err != nil {
 		return nil, fmt.Errorf("failed to query user: %w", err)
 	}
 
-	if err := json.Unmarshal(data, &existingUsers); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal user data: %w", err)
-	}
-
 	if len(existingUsers) > 0 {
 		user := &existingUsers[0]
 		// Update user if necessary (e.g., username, avatar)
@@ -157,10 +150,7 @@ func (s *AuthService) CreateOrUpdateUserFromOAuth(ctx context.Context, oauthUser
 		}
 
 		if len(updateData) > 0 {
-			updatedData, _, err := s.supabase.From("users").
-				Update(updateData, "", "").
-				Eq("id", userID.String()).
-				Execute()
+			updatedData, err := s.store.Update(ctx, "users", Where().Eq("id", userID.String()), updateData)
 			if err != nil {
 				return nil, fmt.Errorf("failed to update user: %w", err)
 			}
@@ -176,22 +166,21 @@ func (s *AuthService) CreateOrUpdateUserFromOAuth(ctx context.Context, oauthUser
 	}
 
 	// User does not exist, create new user
-	newUser := &models.User{
-		ID:          userID,
-		Email:       &oauthUser.Email,
-		Username:    oauthUser.Username, // Username from Supabase metadata
-		IsAnonymous: false,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+	now := time.Now()
+	newUser := map[string]interface{}{
+		"id":           userID.String(),
+		"email":        oauthUser.Email,
+		"username":     oauthUser.Username, // Username from Supabase metadata
+		"is_anonymous": false,
+		"created_at":   now,
+		"updated_at":   now,
 	}
 
 	if oauthUser.Avatar != "" {
-		newUser.AvatarURL = &oauthUser.Avatar
+		newUser["avatar_url"] = oauthUser.Avatar
 	}
 
-	insertData, _, err := s.supabase.From("users").
-		Insert(newUser, false, "", "", "").
-		Execute()
+	insertData, err := s.store.Insert(ctx, "users", newUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new user: %w", err)
 	}
@@ -285,3 +274,26 @@ func (s *AuthService) SignupWithPassword(ctx context.Context, email, password, u
 	}, nil
 }
 
+// UpdatePassword sets a user's password through the admin API (after a password reset)
+func (s *AuthService) UpdatePassword(ctx context.Context, userID uuid.UUID, password string) error {
+	if _, err := s.adminClient.AdminUpdateUser(types.AdminUpdateUserRequest{
+		UserID:   userID,
+		Password: password,
+	}); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// UpdateEmail sets a user's email through the admin API
+// The address is marked confirmed: AccountService has already verified it by mail.
+func (s *AuthService) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	if _, err := s.adminClient.AdminUpdateUser(types.AdminUpdateUserRequest{
+		UserID:       userID,
+		Email:        email,
+		EmailConfirm: true,
+	}); err != nil {
+		return fmt.Errorf("failed to update email: %w", err)
+	}
+	return nil
+}
@@ -3,28 +3,74 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
-	supabase "github.com/supabase-community/supabase-go"
+	"github.com/hekigan/couples/internal/models"
 )
 
 // BaseService provides common database query patterns
 // Eliminates 61+ query pattern duplications across all services
 // Uses interface{} instead of generics for Go <1.18 compatibility
+// All access goes through a Store (SupabaseStore in production, MemoryStore in tests)
 type BaseService struct {
-	client *supabase.Client
+	store  Store
 	logger *ServiceLogger
 }
 
 // NewBaseService creates a new base service instance
-func NewBaseService(client *supabase.Client, serviceName string) *BaseService {
+func NewBaseService(store Store, serviceName string) *BaseService {
 	return &BaseService{
-		client: client,
+		store:  store,
 		logger: NewServiceLogger(serviceName),
 	}
 }
 
+// Store returns the underlying persistence backend
+func (b *BaseService) Store() Store {
+	return b.store
+}
+
+// WithTx runs fn in a store transaction; fn receives a BaseService bound to the transaction
+// Example usage:
+//
+//	err := s.WithTx(ctx, func(tx *BaseService) error {
+//	    if err := tx.InsertRecord(ctx, "answers", answer); err != nil {
+//	        return err // rolls back
+//	    }
+//	    return tx.UpdateRecord(ctx, "rooms", roomID, update)
+//	})
+func (b *BaseService) WithTx(ctx context.Context, fn func(tx *BaseService) error) error {
+	return b.store.WithTx(ctx, func(tx Store) error {
+		return fn(b.withStore(tx))
+	})
+}
+
+// withStore returns a copy of the BaseService bound to another store (e.g. an open transaction)
+func (b *BaseService) withStore(store Store) *BaseService {
+	return &BaseService{
+		store:  store,
+		logger: b.logger,
+	}
+}
+
+// conflictAttempts is how many times RetryOnConflict runs fn before giving up
+const conflictAttempts = 3
+
+// RetryOnConflict re-runs a read-modify-write while it fails with models.ErrConflict
+// fn must re-read what it modifies on every call. Once the attempts are used up the
+// conflict is returned so handlers can answer 409.
+func RetryOnConflict(ctx context.Context, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < conflictAttempts; attempt++ {
+		if err = fn(); !errors.Is(err, models.ErrConflict) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
 // GetSingleRecord fetches a single record by ID
 // Caller must pass a pointer to the struct where the result will be unmarshaled
 // Example usage:
@@ -36,20 +82,10 @@ func (b *BaseService) GetSingleRecord(
 	id uuid.UUID,
 	result interface{},
 ) error {
-	data, _, err := b.client.From(table).
-		Select("*", "", false).
-		Eq("id", id.String()).
-		Single().
-		Execute()
-
-	if err != nil {
+	if err := b.QuerySingleRecord(ctx, table, Where().Eq("id", id.String()), result); err != nil {
 		return fmt.Errorf("failed to fetch %s by id %s: %w", table, id.String(), err)
 	}
 
-	if err := json.Unmarshal(data, result); err != nil {
-		return fmt.Errorf("failed to parse %s data: %w", table, err)
-	}
-
 	return nil
 }
 
@@ -65,32 +101,10 @@ func (b *BaseService) GetRecords(
 	filters map[string]interface{},
 	result interface{},
 ) error {
-	query := b.client.From(table).Select("*", "", false)
-
-	// Apply filters
-	for key, value := range filters {
-		// Convert value to string for Eq method
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case fmt.Stringer:
-			strValue = v.String()
-		default:
-			strValue = fmt.Sprintf("%v", v)
-		}
-		query = query.Eq(key, strValue)
-	}
-
-	data, _, err := query.Execute()
-	if err != nil {
+	if err := b.QueryRecords(ctx, table, Where(filters), result); err != nil {
 		return fmt.Errorf("failed to fetch %s records: %w", table, err)
 	}
 
-	if err := json.Unmarshal(data, result); err != nil {
-		return fmt.Errorf("failed to parse %s data: %w", table, err)
-	}
-
 	return nil
 }
 
@@ -107,40 +121,10 @@ func (b *BaseService) GetRecordsWithLimit(
 	offset int,
 	result interface{},
 ) error {
-	query := b.client.From(table).Select("*", "", false)
-
-	// Apply filters
-	for key, value := range filters {
-		// Convert value to string for Eq method
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case fmt.Stringer:
-			strValue = v.String()
-		default:
-			strValue = fmt.Sprintf("%v", v)
-		}
-		query = query.Eq(key, strValue)
-	}
-
-	// Apply pagination
-	if limit > 0 {
-		query = query.Limit(limit, "")
-	}
-	if offset > 0 {
-		query = query.Range(offset, offset+limit-1, "")
-	}
-
-	data, _, err := query.Execute()
-	if err != nil {
+	if err := b.QueryRecords(ctx, table, Where(filters).Page(limit, offset), result); err != nil {
 		return fmt.Errorf("failed to fetch %s records with limit: %w", table, err)
 	}
 
-	if err := json.Unmarshal(data, result); err != nil {
-		return fmt.Errorf("failed to parse %s data: %w", table, err)
-	}
-
 	return nil
 }
 
@@ -157,11 +141,7 @@ func (b *BaseService) InsertRecord(
 	table string,
 	data map[string]interface{},
 ) error {
-	_, _, err := b.client.From(table).
-		Insert(data, false, "", "", "").
-		Execute()
-
-	if err != nil {
+	if _, err := b.store.Insert(ctx, table, data); err != nil {
 		return fmt.Errorf("failed to insert into %s: %w", table, err)
 	}
 
@@ -181,12 +161,7 @@ func (b *BaseService) UpdateRecord(
 	id uuid.UUID,
 	data map[string]interface{},
 ) error {
-	_, _, err := b.client.From(table).
-		Update(data, "", "").
-		Eq("id", id.String()).
-		Execute()
-
-	if err != nil {
+	if _, err := b.store.Update(ctx, table, Where().Eq("id", id.String()), data); err != nil {
 		return fmt.Errorf("failed to update %s by id %s: %w", table, id.String(), err)
 	}
 
@@ -204,25 +179,7 @@ func (b *BaseService) UpdateRecordsWithFilter(
 	filters map[string]interface{},
 	data map[string]interface{},
 ) error {
-	query := b.client.From(table).Update(data, "", "")
-
-	// Apply filters
-	for key, value := range filters {
-		// Convert value to string for Eq method
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case fmt.Stringer:
-			strValue = v.String()
-		default:
-			strValue = fmt.Sprintf("%v", v)
-		}
-		query = query.Eq(key, strValue)
-	}
-
-	_, _, err := query.Execute()
-	if err != nil {
+	if _, err := b.store.Update(ctx, table, Where(filters), data); err != nil {
 		return fmt.Errorf("failed to update %s records: %w", table, err)
 	}
 
@@ -237,12 +194,7 @@ func (b *BaseService) DeleteRecord(
 	table string,
 	id uuid.UUID,
 ) error {
-	_, _, err := b.client.From(table).
-		Delete("", "").
-		Eq("id", id.String()).
-		Execute()
-
-	if err != nil {
+	if err := b.store.Delete(ctx, table, Where().Eq("id", id.String())); err != nil {
 		return fmt.Errorf("failed to delete %s by id %s: %w", table, id.String(), err)
 	}
 
@@ -258,25 +210,7 @@ func (b *BaseService) DeleteRecordsWithFilter(
 	table string,
 	filters map[string]interface{},
 ) error {
-	query := b.client.From(table).Delete("", "")
-
-	// Apply filters
-	for key, value := range filters {
-		// Convert value to string for Eq method
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case fmt.Stringer:
-			strValue = v.String()
-		default:
-			strValue = fmt.Sprintf("%v", v)
-		}
-		query = query.Eq(key, strValue)
-	}
-
-	_, _, err := query.Execute()
-	if err != nil {
+	if err := b.store.Delete(ctx, table, Where(filters)); err != nil {
 		return fmt.Errorf("failed to delete %s records: %w", table, err)
 	}
 
@@ -292,27 +226,80 @@ func (b *BaseService) CountRecords(
 	table string,
 	filters map[string]interface{},
 ) (int, error) {
-	query := b.client.From(table).Select("id", "exact", false)
-
-	// Apply filters
-	for key, value := range filters {
-		// Convert value to string for Eq method
-		var strValue string
-		switch v := value.(type) {
-		case string:
-			strValue = v
-		case fmt.Stringer:
-			strValue = v.String()
-		default:
-			strValue = fmt.Sprintf("%v", v)
-		}
-		query = query.Eq(key, strValue)
+	count, err := b.store.Count(ctx, table, Where(filters))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count %s records: %w", table, err)
+	}
+
+	return count, nil
+}
+
+// QueryRecords fetches the records matching an arbitrary query (ordering, ranges, IN filters)
+// Example usage:
+//   var questions []models.Question
+//   q := Where().Eq("lang_code", "en").In("category_id", ToStringSlice(ids)).OrderBy("created_at", false)
+//   err := base.QueryRecords(ctx, "questions", q, &questions)
+func (b *BaseService) QueryRecords(
+	ctx context.Context,
+	table string,
+	q *Query,
+	result interface{},
+) error {
+	data, err := b.store.Select(ctx, table, q)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s records: %w", table, err)
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to parse %s data: %w", table, err)
+	}
+
+	return nil
+}
+
+// QuerySingleRecord fetches exactly one record matching the query
+// Returns an error wrapping ErrNotFound when nothing matches
+// Example usage:
+//   var invitation models.RoomInvitation
+//   err := base.QuerySingleRecord(ctx, "room_invitations", Where(WithRoomID(roomID)).Eq("invitee_id", id), &invitation)
+func (b *BaseService) QuerySingleRecord(
+	ctx context.Context,
+	table string,
+	q *Query,
+	result interface{},
+) error {
+	var rows []json.RawMessage
+	if err := b.QueryRecords(ctx, table, q, &rows); err != nil {
+		return err
 	}
 
-	_, count, err := query.Execute()
+	switch len(rows) {
+	case 0:
+		return fmt.Errorf("no %s record matched: %w", table, ErrNotFound)
+	case 1:
+	default:
+		return fmt.Errorf("expected a single %s record, got %d", table, len(rows))
+	}
+
+	if err := json.Unmarshal(rows[0], result); err != nil {
+		return fmt.Errorf("failed to parse %s data: %w", table, err)
+	}
+
+	return nil
+}
+
+// CountQueryRecords counts the records matching an arbitrary query
+// Example usage:
+//   count, err := base.CountQueryRecords(ctx, "questions", Where().Eq("lang_code", "en").In("category_id", ids))
+func (b *BaseService) CountQueryRecords(
+	ctx context.Context,
+	table string,
+	q *Query,
+) (int, error) {
+	count, err := b.store.Count(ctx, table, q)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count %s records: %w", table, err)
 	}
 
-	return int(count), nil
+	return count, nil
 }
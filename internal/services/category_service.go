@@ -4,21 +4,18 @@ import (
 	"context"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
 // CategoryService handles category-related operations
 type CategoryService struct {
 	*BaseService
-	client *supabase.Client
 }
 
 // NewCategoryService creates a new category service
-func NewCategoryService(client *supabase.Client) *CategoryService {
+func NewCategoryService(store Store) *CategoryService {
 	return &CategoryService{
-		BaseService: NewBaseService(client, "CategoryService"),
-		client:      client,
+		BaseService: NewBaseService(store, "CategoryService"),
 	}
 }
 
@@ -51,6 +48,9 @@ func (s *CategoryService) CreateCategory(ctx context.Context, category *models.C
 		"key":   category.Key,
 		"label": category.Label,
 	}
+	if category.Weight > 0 {
+		categoryMap["weight"] = category.Weight
+	}
 
 	return s.BaseService.InsertRecord(ctx, "categories", categoryMap)
 }
@@ -61,6 +61,9 @@ func (s *CategoryService) UpdateCategory(ctx context.Context, category *models.C
 		"key":   category.Key,
 		"label": category.Label,
 	}
+	if category.Weight > 0 {
+		categoryMap["weight"] = category.Weight
+	}
 
 	return s.BaseService.UpdateRecord(ctx, "categories", category.ID, categoryMap)
 }
@@ -2,25 +2,22 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
 // FriendService handles friend-related operations
 type FriendService struct {
 	*BaseService
-	client *supabase.Client
 }
 
 // NewFriendService creates a new friend service
-func NewFriendService(client *supabase.Client) *FriendService {
+func NewFriendService(store Store) *FriendService {
 	return &FriendService{
-		BaseService: NewBaseService(client, "FriendService"),
-		client:      client,
+		BaseService: NewBaseService(store, "FriendService"),
 	}
 }
 
@@ -198,20 +195,14 @@ func (s *FriendService) GetFriendshipByID(ctx context.Context, friendshipID uuid
 
 // SearchUsersByUsername searches for users by username (for adding friends)
 func (s *FriendService) SearchUsersByUsername(ctx context.Context, query string) ([]models.User, error) {
-	// Custom query - uses Ilike and Limit which are not supported by BaseService
-	data, _, err := s.client.From("users").
-		Select("id,username,name,is_anonymous", "", false).
-		Ilike("username", fmt.Sprintf("%%%s%%", query)).
-		Limit(10, "").
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to search users: %w", err)
-	}
+	q := NewQuery().
+		Select("id,username,name,is_anonymous").
+		SearchIn(query, "username").
+		Page(10, 0)
 
 	var users []models.User
-	if err := json.Unmarshal(data, &users); err != nil {
-		return nil, fmt.Errorf("failed to parse users: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "users", q, &users); err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 
 	return users, nil
@@ -219,19 +210,9 @@ func (s *FriendService) SearchUsersByUsername(ctx context.Context, query string)
 
 // Helper function to get user info
 func (s *FriendService) getUserInfo(ctx context.Context, userID uuid.UUID) (*models.User, error) {
-	// Custom query - only selecting specific fields, not all (*)
-	data, _, err := s.client.From("users").
-		Select("id,username,name", "", false).
-		Eq("id", userID.String()).
-		Single().
-		Execute()
-
-	if err != nil {
-		return nil, err
-	}
-
+	// Only selecting specific fields, not all (*)
 	var user models.User
-	if err := json.Unmarshal(data, &user); err != nil {
+	if err := s.BaseService.QuerySingleRecord(ctx, "users", Where().Eq("id", userID.String()).Select("id,username,name"), &user); err != nil {
 		return nil, err
 	}
 
@@ -240,35 +221,18 @@ func (s *FriendService) getUserInfo(ctx context.Context, userID uuid.UUID) (*mod
 
 // Helper function to check if friendship already exists
 func (s *FriendService) checkExistingFriendship(ctx context.Context, userID1, userID2 uuid.UUID) (*models.Friend, error) {
-	// Custom query - complex logic checking both directions
-	// Check direction 1: userID1 -> userID2
-	data1, _, _ := s.client.From("friends").
-		Select("*", "", false).
-		Eq("user_id", userID1.String()).
-		Eq("friend_id", userID2.String()).
-		Single().
-		Execute()
-
-	if data1 != nil && len(data1) > 0 {
-		var friend models.Friend
-		if err := json.Unmarshal(data1, &friend); err == nil {
-			return &friend, nil
-		}
-	}
-
-	// Check direction 2: userID2 -> userID1
-	data2, _, _ := s.client.From("friends").
-		Select("*", "", false).
-		Eq("user_id", userID2.String()).
-		Eq("friend_id", userID1.String()).
-		Single().
-		Execute()
+	// Check both directions: userID1 -> userID2, then userID2 -> userID1
+	for _, pair := range [][2]uuid.UUID{{userID1, userID2}, {userID2, userID1}} {
+		q := Where(WithUserID(pair[0]), WithFriendID(pair[1]))
 
-	if data2 != nil && len(data2) > 0 {
 		var friend models.Friend
-		if err := json.Unmarshal(data2, &friend); err == nil {
+		err := s.BaseService.QuerySingleRecord(ctx, "friends", q, &friend)
+		if err == nil {
 			return &friend, nil
 		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("not found")
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"time"
@@ -10,12 +11,11 @@ import (
 	"github.com/hekigan/couples/internal/models"
 	"github.com/hekigan/couples/internal/rendering"
 	"github.com/hekigan/couples/internal/viewmodels"
-	"github.com/supabase-community/supabase-go"
 )
 
 // GameService handles game logic
 type GameService struct {
-	client          *supabase.Client
+	*BaseService
 	roomService     *RoomService
 	questionService *QuestionService
 	categoryService *CategoryService
@@ -26,7 +26,7 @@ type GameService struct {
 
 // NewGameService creates a new game service
 func NewGameService(
-	client *supabase.Client,
+	store Store,
 	roomService *RoomService,
 	questionService *QuestionService,
 	categoryService *CategoryService,
@@ -35,7 +35,7 @@ func NewGameService(
 	renderService *rendering.TemplService,
 ) *GameService {
 	return &GameService{
-		client:          client,
+		BaseService:     NewBaseService(store, "GameService"),
 		roomService:     roomService,
 		questionService: questionService,
 		categoryService: categoryService,
@@ -52,6 +52,10 @@ func (s *GameService) StartGame(ctx context.Context, roomID uuid.UUID) error {
 		return err
 	}
 
+	if !room.Status.Can(models.RoomEventStart) {
+		return &models.RoomTransitionError{From: room.Status, Event: models.RoomEventStart}
+	}
+
 	// Randomly decide who goes first
 	rand.Seed(time.Now().UnixNano())
 	if room.GuestID != nil && rand.Intn(2) == 0 {
@@ -69,11 +73,11 @@ func (s *GameService) StartGame(ctx context.Context, roomID uuid.UUID) error {
 		return fmt.Errorf("no questions available for the selected categories and language '%s'", room.Language)
 	}
 
-	room.Status = "playing"
-	room.CurrentQuestion = 0
-	room.MaxQuestions = totalQuestions
-
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
+	if _, err := s.roomService.TransitionWith(ctx, roomID, models.RoomEventStart, map[string]interface{}{
+		"current_player_id": room.CurrentTurn.String(),
+		"current_question":  0,
+		"max_questions":     totalQuestions,
+	}); err != nil {
 		return err
 	}
 
@@ -131,53 +135,22 @@ func (s *GameService) GetCurrentQuestion(ctx context.Context, roomID uuid.UUID)
 
 // DrawQuestion draws a new question for the room filtered by selected categories
 func (s *GameService) DrawQuestion(ctx context.Context, roomID uuid.UUID) (*models.Question, error) {
-	room, err := s.roomService.GetRoomByID(ctx, roomID)
-	if err != nil {
-		return nil, err
-	}
-
-	// IDEMPOTENCY CHECK: If a question is already drawn, return it
-	if room.CurrentQuestionID != nil {
-		fmt.Printf("⚠️ Question already exists for room %s, returning existing question %s\n", roomID, *room.CurrentQuestionID)
-		fmt.Printf("🔍 DEBUG: CurrentQuestionID type: %T, value: %v\n", *room.CurrentQuestionID, *room.CurrentQuestionID)
-
-		existingQuestion, err := s.questionService.GetQuestionByID(ctx, *room.CurrentQuestionID)
-		if err != nil {
-			fmt.Printf("❌ Failed to fetch existing question: %v\n", err)
-			// Clear the invalid question ID and draw a new one
-			fmt.Printf("🔧 Clearing invalid CurrentQuestionID and drawing new question\n")
-			room.CurrentQuestionID = nil
-			// Fall through to draw a new question
-		} else {
-			// Return the existing question without broadcasting again
-			return existingQuestion, nil
-		}
-	}
-
-	// Get random question filtered by categories and history
-	question, err := s.questionService.GetRandomQuestion(ctx, roomID, room.Language, room.SelectedCategories)
+	// A concurrent draw or turn change makes the room write conflict: re-read and try again,
+	// which returns the other request's question if it drew one
+	var room *models.Room
+	var question *models.Question
+	var existing bool
+	err := RetryOnConflict(ctx, func() error {
+		var err error
+		room, question, existing, err = s.advanceQuestion(ctx, roomID)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	// Mark question as asked to prevent repetition
-	if err := s.questionService.MarkQuestionAsked(ctx, roomID, question.ID); err != nil {
-		// Check if this is a duplicate key error (constraint violation)
-		if isConstraintViolation(err) {
-			fmt.Printf("⚠️ Duplicate question marking detected (race condition), continuing anyway. Room: %s, Question: %s\n", roomID, question.ID)
-			// Question is already marked, this is fine - continue
-		} else {
-			// Real error, return it
-			return nil, err
-		}
-	}
-
-	// Set current question ID and increment counter
-	// Increment BEFORE saving so first question shows as "Question 1"
-	room.CurrentQuestion++
-	room.CurrentQuestionID = &question.ID
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
-		return nil, err
+	if existing {
+		// Return the existing question without broadcasting again
+		return question, nil
 	}
 
 	// Get room with players to have usernames for HTML fragment
@@ -243,6 +216,71 @@ func (s *GameService) DrawQuestion(ctx context.Context, roomID uuid.UUID) (*mode
 	return question, nil
 }
 
+// advanceQuestion draws the room's next question and saves it as the current one
+// existing is true when the room already had a current question, which is returned as is.
+func (s *GameService) advanceQuestion(ctx context.Context, roomID uuid.UUID) (room *models.Room, question *models.Question, existing bool, err error) {
+	room, err = s.roomService.GetRoomByID(ctx, roomID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// IDEMPOTENCY CHECK: If a question is already drawn, return it
+	if room.CurrentQuestionID != nil {
+		fmt.Printf("⚠️ Question already exists for room %s, returning existing question %s\n", roomID, *room.CurrentQuestionID)
+		fmt.Printf("🔍 DEBUG: CurrentQuestionID type: %T, value: %v\n", *room.CurrentQuestionID, *room.CurrentQuestionID)
+
+		existingQuestion, err := s.questionService.GetQuestionByID(ctx, *room.CurrentQuestionID)
+		if err != nil {
+			fmt.Printf("❌ Failed to fetch existing question: %v\n", err)
+			// Clear the invalid question ID and draw a new one
+			fmt.Printf("🔧 Clearing invalid CurrentQuestionID and drawing new question\n")
+			room.CurrentQuestionID = nil
+			// Fall through to draw a new question
+		} else {
+			return room, existingQuestion, true, nil
+		}
+	}
+
+	// Get random question filtered by categories and history
+	question, err = s.questionService.GetRandomQuestion(ctx, room)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Marking the history and advancing the room happen in one transaction,
+	// so a failed room update doesn't burn the question
+	err = s.WithTx(ctx, func(tx *BaseService) error {
+		// Mark question as asked to prevent repetition. The marking gets its own savepoint:
+		// on Postgres a duplicate key aborts the whole transaction otherwise.
+		err := tx.WithTx(ctx, func(mark *BaseService) error {
+			return s.questionService.withStore(mark.store).MarkQuestionAsked(ctx, room, question.ID)
+		})
+		if err != nil {
+			// Check if this is a duplicate key error (constraint violation)
+			if !isConstraintViolation(err) {
+				// Real error, return it
+				return err
+			}
+			fmt.Printf("⚠️ Duplicate question marking detected (race condition), continuing anyway. Room: %s, Question: %s\n", roomID, question.ID)
+			// Question is already marked, this is fine - continue
+		}
+
+		// Set current question ID and increment counter
+		// Increment BEFORE saving so first question shows as "Question 1"
+		room.CurrentQuestion++
+		room.CurrentQuestionID = &question.ID
+		return s.roomService.withStore(tx.store).saveRoom(ctx, room)
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Only announce the new question once it is committed
+	s.roomService.realtimeService.BroadcastRoomUpdate(room.ID, room)
+
+	return room, question, false, nil
+}
+
 // isConstraintViolation checks if the error is a unique constraint violation
 func isConstraintViolation(err error) bool {
 	if err == nil {
@@ -283,33 +321,56 @@ func (s *GameService) EndGame(ctx context.Context, roomID uuid.UUID) error {
 		return err
 	}
 
-	room.Status = "finished"
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
+	// Both players may click "end" at once; the second call is a no-op
+	if room.Status == models.RoomFinished {
+		return nil
+	}
+
+	if _, err := s.roomService.Transition(ctx, roomID, models.RoomEventFinish); err != nil {
 		return err
 	}
 
 	s.realtimeService.BroadcastGameFinished(roomID, map[string]interface{}{
 		"room_id": roomID,
-		"status":  "finished",
+		"status":  models.RoomFinished,
 	})
 
 	return nil
 }
 
-// ChangeTurn changes the current turn to the other player
-func (s *GameService) ChangeTurn(ctx context.Context, roomID uuid.UUID) error {
-	room, err := s.roomService.GetRoomByID(ctx, roomID)
-	if err != nil {
+// AbandonGame ends a game that can no longer be finished (e.g. a player never came back)
+func (s *GameService) AbandonGame(ctx context.Context, roomID uuid.UUID) error {
+	if _, err := s.roomService.Transition(ctx, roomID, models.RoomEventAbandon); err != nil {
 		return err
 	}
 
-	if room.CurrentTurn != nil && *room.CurrentTurn == room.OwnerID && room.GuestID != nil {
-		room.CurrentTurn = room.GuestID
-	} else {
-		room.CurrentTurn = &room.OwnerID
-	}
+	s.realtimeService.BroadcastGameFinished(roomID, map[string]interface{}{
+		"room_id": roomID,
+		"status":  models.RoomAbandoned,
+	})
+
+	return nil
+}
 
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
+// ChangeTurn changes the current turn to the other player
+func (s *GameService) ChangeTurn(ctx context.Context, roomID uuid.UUID) error {
+	var room *models.Room
+	err := RetryOnConflict(ctx, func() error {
+		var err error
+		room, err = s.roomService.GetRoomByID(ctx, roomID)
+		if err != nil {
+			return err
+		}
+
+		if room.CurrentTurn != nil && *room.CurrentTurn == room.OwnerID && room.GuestID != nil {
+			room.CurrentTurn = room.GuestID
+		} else {
+			room.CurrentTurn = &room.OwnerID
+		}
+
+		return s.roomService.UpdateRoom(ctx, room)
+	})
+	if err != nil {
 		return err
 	}
 
@@ -328,16 +389,15 @@ func (s *GameService) PauseGame(ctx context.Context, roomID uuid.UUID, disconnec
 	}
 
 	// Only pause if game is currently playing
-	if room.Status != "playing" {
+	if !room.Status.Can(models.RoomEventPause) {
 		return nil
 	}
 
 	now := time.Now()
-	room.Status = "paused"
-	room.PausedAt = &now
-	room.DisconnectedUser = &disconnectedUserID
-
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
+	if _, err := s.roomService.TransitionWith(ctx, roomID, models.RoomEventPause, map[string]interface{}{
+		"paused_at":         now,
+		"disconnected_user": disconnectedUserID.String(),
+	}); err != nil {
 		return err
 	}
 
@@ -361,15 +421,14 @@ func (s *GameService) ResumeGame(ctx context.Context, roomID uuid.UUID) error {
 	}
 
 	// Only resume if game is paused
-	if room.Status != "paused" {
+	if !room.Status.Can(models.RoomEventResume) {
 		return nil
 	}
 
-	room.Status = "playing"
-	room.PausedAt = nil
-	room.DisconnectedUser = nil
-
-	if err := s.roomService.UpdateRoom(ctx, room); err != nil {
+	if _, err := s.roomService.TransitionWith(ctx, roomID, models.RoomEventResume, map[string]interface{}{
+		"paused_at":         nil,
+		"disconnected_user": nil,
+	}); err != nil {
 		return err
 	}
 
@@ -377,7 +436,7 @@ func (s *GameService) ResumeGame(ctx context.Context, roomID uuid.UUID) error {
 		Type: "game_resumed",
 		Data: map[string]interface{}{
 			"room_id": roomID.String(),
-			"status":  "playing",
+			"status":  models.RoomPlaying,
 		},
 	})
 
@@ -392,7 +451,7 @@ func (s *GameService) CheckReconnectionTimeout(ctx context.Context, roomID uuid.
 	}
 
 	// If not paused or no pause time, no timeout
-	if room.Status != "paused" || room.PausedAt == nil {
+	if room.Status != models.RoomPaused || room.PausedAt == nil {
 		return false, nil
 	}
 
@@ -401,8 +460,8 @@ func (s *GameService) CheckReconnectionTimeout(ctx context.Context, roomID uuid.
 	timeout := time.Duration(timeoutMinutes) * time.Minute
 
 	if elapsed > timeout {
-		// Timeout exceeded, end the game
-		if err := s.EndGame(ctx, roomID); err != nil {
+		// Timeout exceeded, the game can't be finished
+		if err := s.AbandonGame(ctx, roomID); err != nil {
 			return false, err
 		}
 		return true, nil
@@ -411,5 +470,25 @@ func (s *GameService) CheckReconnectionTimeout(ctx context.Context, roomID uuid.
 	return false, nil
 }
 
+// EndTimedOutGames ends every paused game that has waited longer than timeoutMinutes for a
+// player to reconnect, and returns how many were ended
+func (s *GameService) EndTimedOutGames(ctx context.Context, timeoutMinutes int) (int, error) {
+	rooms, err := s.roomService.GetRoomsByStatus(ctx, models.RoomPaused)
+	if err != nil {
+		return 0, err
+	}
 
-
+	ended := 0
+	var errs []error
+	for _, room := range rooms {
+		timedOut, err := s.CheckReconnectionTimeout(ctx, room.ID, timeoutMinutes)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", room.ID, err))
+			continue
+		}
+		if timedOut {
+			ended++
+		}
+	}
+	return ended, errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -395,7 +396,7 @@ func TestCheckReconnectionTimeout(t *testing.T) {
 			// 2. If paused, set PausedAt to (now + pausedAtOffset)
 			// 3. Call CheckReconnectionTimeout
 			// 4. Verify return value matches wantTimedOut
-			// 5. If timed out, verify room.Status = "finished"
+			// 5. If timed out, verify room.Status = "abandoned"
 		})
 	}
 }
@@ -412,11 +413,51 @@ func TestCheckReconnectionTimeout_EndsGameOnTimeout(t *testing.T) {
 		// Test logic:
 		// 1. Create paused room with old PausedAt time
 		// 2. Call CheckReconnectionTimeout with low timeout
-		// 3. Verify room.Status = "finished"
+		// 3. Verify room.Status = "abandoned"
 		// 4. Verify returned (true, nil)
 	})
 }
 
+// TestEndTimedOutGames tests that only games paused past the timeout are ended
+func TestEndTimedOutGames(t *testing.T) {
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	realtimeService := NewRealtimeService()
+	roomService := NewRoomService(store, realtimeService)
+	gameService := NewGameService(store, roomService, nil, nil, nil, realtimeService, nil)
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	guest := CreateTestUser(t, store, "guest", "Guest", true)
+	pausedRoom := func(pausedFor time.Duration) uuid.UUID {
+		roomID := CreateTestRoom(t, store, owner.ID, "en")
+		_, err := roomService.TransitionWith(ctx, roomID, models.RoomEventGuestJoined, map[string]interface{}{
+			"guest_id": guest.ID.String(),
+		})
+		AssertNoError(t, err, "guest joined")
+		_, err = roomService.Transition(ctx, roomID, models.RoomEventStart)
+		AssertNoError(t, err, "start")
+		_, err = roomService.TransitionWith(ctx, roomID, models.RoomEventPause, map[string]interface{}{
+			"paused_at":         time.Now().Add(-pausedFor),
+			"disconnected_user": guest.ID.String(),
+		})
+		AssertNoError(t, err, "pause")
+		return roomID
+	}
+	stale := pausedRoom(10 * time.Minute)
+	recent := pausedRoom(time.Minute)
+
+	ended, err := gameService.EndTimedOutGames(ctx, 5)
+	AssertNoError(t, err, "end timed out games")
+	AssertEqual(t, 1, ended, "games ended")
+
+	room, err := roomService.GetRoomByID(ctx, stale)
+	AssertNoError(t, err, "get stale room")
+	AssertEqual(t, models.RoomAbandoned, room.Status, "stale room status")
+	room, err = roomService.GetRoomByID(ctx, recent)
+	AssertNoError(t, err, "get recent room")
+	AssertEqual(t, models.RoomPaused, room.Status, "recent room status")
+}
+
 // Benchmark tests for performance-critical operations
 func BenchmarkStartGame(b *testing.B) {
 	b.Skip("Requires test database setup")
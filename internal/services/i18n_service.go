@@ -6,25 +6,64 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sync"
 
-	"github.com/supabase-community/supabase-go"
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
 )
 
+// translationPageSize is how many rows Reload reads at a time
+const translationPageSize = 1000
+
+// translationKeyPattern is what a translation key may look like (dotted, as in the JSON files)
+var translationKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// Where a translation's value comes from
+const (
+	TranslationSourceDatabase = "database" // the translations table (seeded from the files, then edited)
+	TranslationSourceFile     = "file"     // static/i18n/*.json only
+	TranslationSourceMissing  = "missing"  // another language has the key, this one doesn't
+)
+
+// TranslationEntry is a key's value in one language
+type TranslationEntry struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
 // I18nService handles internationalization
+// The translations table is the source of truth; the static/i18n/*.json files seed it on first
+// start and provide the strings it lacks (e.g. keys added in a release). Languages come from the
+// language registry, which also gives the fallback chain of missing strings.
 type I18nService struct {
-	client        *supabase.Client
+	*BaseService
 	translationDir string
-	translations   map[string]map[string]string // language -> key -> value
+	languages      *LanguageService
+
+	mu        sync.RWMutex
+	defaults  map[string]map[string]string // from the files: language -> key -> value
+	overrides map[string]map[string]string // from the translations table
+
+	messages sync.Map // parsed messages by source string, for Translate
+
+	reloader reloadLoop
 }
 
 // NewI18nService creates a new i18n service
-func NewI18nService(client *supabase.Client, translationDir string) *I18nService {
+// Only the files are read here; Load reads the translations table.
+func NewI18nService(store Store, translationDir string, languages *LanguageService) *I18nService {
 	service := &I18nService{
-		client:        client,
+		BaseService:    NewBaseService(store, "I18nService"),
 		translationDir: translationDir,
-		translations:   make(map[string]map[string]string),
+		languages:      languages,
+		defaults:       make(map[string]map[string]string),
+		overrides:      make(map[string]map[string]string),
+	}
+	if err := service.loadTranslations(); err != nil {
+		service.logger.Warn("Failed to load translation files: %v", err)
 	}
-	service.loadTranslations()
 	return service
 }
 
@@ -37,40 +76,415 @@ func (s *I18nService) loadTranslations() error {
 
 	for _, file := range files {
 		lang := filepath.Base(file[:len(file)-5]) // Remove .json extension
-		
+
 		data, err := os.ReadFile(file)
 		if err != nil {
 			continue
 		}
 
-		var translations map[string]string
-		if err := json.Unmarshal(data, &translations); err != nil {
+		var tree map[string]interface{}
+		if err := json.Unmarshal(data, &tree); err != nil {
 			continue
 		}
 
-		s.translations[lang] = translations
+		translations := make(map[string]string)
+		flattenTranslations("", tree, translations)
+		s.defaults[lang] = translations
+	}
+
+	return nil
+}
+
+// Load seeds the translations table from the files when it is empty, then reads it
+func (s *I18nService) Load(ctx context.Context) error {
+	count, err := s.CountRecords(ctx, "translations", map[string]interface{}{})
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		s.mu.RLock()
+		defaults := s.defaults
+		s.mu.RUnlock()
+
+		seeded := 0
+		err := s.WithTx(ctx, func(tx *BaseService) error {
+			for lang, translations := range defaults {
+				for key, value := range translations {
+					if err := tx.InsertRecord(ctx, "translations", map[string]interface{}{
+						"id":        uuid.New().String(),
+						"lang_code": lang,
+						"key":       key,
+						"value":     value,
+					}); err != nil {
+						return err
+					}
+					seeded++
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("failed to seed translations: %w", err)
+		}
+		s.logger.Success("Seeded %d translations from %s", seeded, s.translationDir)
+	}
+	return s.Reload(ctx)
+}
+
+// Reload re-reads the translations table
+func (s *I18nService) Reload(ctx context.Context) error {
+	overrides := make(map[string]map[string]string)
+	for offset := 0; ; offset += translationPageSize {
+		var rows []models.Translation
+		// (key, lang_code) is unique, so the pages neither overlap nor skip rows
+		q := NewQuery().Select("lang_code,key,value").OrderBy("key", true).OrderBy("lang_code", true).
+			Page(translationPageSize, offset)
+		if err := s.QueryRecords(ctx, "translations", q, &rows); err != nil {
+			return fmt.Errorf("failed to load translations: %w", err)
+		}
+		for _, row := range rows {
+			if overrides[row.LanguageCode] == nil {
+				overrides[row.LanguageCode] = make(map[string]string)
+			}
+			overrides[row.LanguageCode][row.Key] = row.Value
+		}
+		if len(rows) < translationPageSize {
+			break
+		}
 	}
 
+	s.mu.Lock()
+	s.overrides = overrides
+	s.mu.Unlock()
+	s.messages.Clear()
 	return nil
 }
 
+// Start reloads the translations table every reloadInterval
+func (s *I18nService) Start() {
+	s.reloader.start(s.logger, s.Reload)
+}
+
+// Stop ends the periodic reload
+func (s *I18nService) Stop() {
+	s.reloader.stop()
+}
+
 // GetTranslation retrieves a translation for a key and language
+// A language lacking the key falls back along its fallback chain.
 func (s *I18nService) GetTranslation(ctx context.Context, lang, key string) (string, error) {
-	if translations, ok := s.translations[lang]; ok {
-		if value, ok := translations[key]; ok {
+	chain := s.languages.FallbackChain(lang)
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, code := range chain {
+		if value, _, ok := s.lookup(code, key); ok {
 			return value, nil
 		}
 	}
-	
-	// Fallback to English
-	if translations, ok := s.translations["en"]; ok {
-		if value, ok := translations[key]; ok {
-			return value, nil
+
+	return key, nil // Return key if translation not found
+}
+
+// Translate renders the ICU MessageFormat string of a key in lang with args
+// ("Question {current} of {total}", "{count, plural, one {# request} other {# requests}}").
+// Plural rules are those of the language the string was found in, so a French page showing an
+// English fallback string follows English rules. An unknown key returns the key, and a string
+// that does not parse is returned as is.
+func (s *I18nService) Translate(lang, key string, args MessageArgs) string {
+	chain := s.languages.FallbackChain(lang)
+
+	s.mu.RLock()
+	value, found := "", ""
+	for _, code := range chain {
+		if v, _, ok := s.lookup(code, key); ok {
+			value, found = v, code
+			break
 		}
 	}
+	s.mu.RUnlock()
 
-	return key, nil // Return key if translation not found
+	if found == "" {
+		return key
+	}
+	msg, err := s.message(value)
+	if err != nil {
+		s.logger.Warn("Invalid %s message %s: %v", found, key, err)
+		return value
+	}
+	return msg.Format(found, args)
+}
+
+// message returns the parsed form of a message, parsing it on first use
+func (s *I18nService) message(source string) (*message, error) {
+	if cached, ok := s.messages.Load(source); ok {
+		return cached.(*message), nil
+	}
+	msg, err := parseMessage(source)
+	if err != nil {
+		return nil, err
+	}
+	s.messages.Store(source, msg)
+	return msg, nil
+}
+
+// lookup returns a key's value in lang and its source; callers hold s.mu
+func (s *I18nService) lookup(lang, key string) (string, string, bool) {
+	if value, ok := s.overrides[lang][key]; ok {
+		return value, TranslationSourceDatabase, true
+	}
+	if value, ok := s.defaults[lang][key]; ok {
+		return value, TranslationSourceFile, true
+	}
+	return "", TranslationSourceMissing, false
+}
+
+// Languages returns the codes of the registered languages, enabled or not, in display order
+func (s *I18nService) Languages() []string {
+	return s.languages.Codes()
+}
+
+// Strings returns every string of a language, as key -> value
+func (s *I18nService) Strings(lang string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	strings := make(map[string]string, len(s.defaults[lang])+len(s.overrides[lang]))
+	for key, value := range s.defaults[lang] {
+		strings[key] = value
+	}
+	for key, value := range s.overrides[lang] {
+		strings[key] = value
+	}
+	return strings
+}
+
+// Entries returns every key of any language with its value in lang, sorted by key
+// Keys lang lacks come with TranslationSourceMissing and an empty value.
+func (s *I18nService) Entries(lang string) []TranslationEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := sortedKeys(s.allKeys())
+	entries := make([]TranslationEntry, len(keys))
+	for i, key := range keys {
+		value, source, _ := s.lookup(lang, key)
+		entries[i] = TranslationEntry{Key: key, Value: value, Source: source}
+	}
+	return entries
+}
+
+// MissingKeys returns, for each language, the keys another language has and it lacks
+func (s *I18nService) MissingKeys() map[string][]string {
+	languages := s.Languages()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := sortedKeys(s.allKeys())
+	missing := make(map[string][]string, len(languages))
+	for _, lang := range languages {
+		missing[lang] = []string{}
+		for _, key := range all {
+			if _, _, ok := s.lookup(lang, key); !ok {
+				missing[lang] = append(missing[lang], key)
+			}
+		}
+	}
+	return missing
+}
+
+// Lint checks that every message parses and uses the placeholders of its DefaultLanguage version
+func (s *I18nService) Lint() []TranslationProblem {
+	translations := make(map[string]map[string]string)
+	for _, lang := range s.Languages() {
+		translations[lang] = s.Strings(lang)
+	}
+	return LintTranslations(translations, DefaultLanguage)
+}
+
+// allKeys returns the keys of every language; callers hold s.mu
+func (s *I18nService) allKeys() map[string]bool {
+	keys := make(map[string]bool)
+	for _, source := range []map[string]map[string]string{s.defaults, s.overrides} {
+		for _, translations := range source {
+			for key := range translations {
+				keys[key] = true
+			}
+		}
+	}
+	return keys
+}
+
+// CreateTranslation adds a key to a language
+// Returns models.ErrTranslationExists when the language already has it.
+func (s *I18nService) CreateTranslation(ctx context.Context, lang, key, value string) error {
+	if err := s.validate(lang, key, value); err != nil {
+		return err
+	}
+	s.mu.RLock()
+	_, _, exists := s.lookup(lang, key)
+	s.mu.RUnlock()
+	if exists {
+		return models.ErrTranslationExists
+	}
+	return s.SetTranslations(ctx, lang, map[string]string{key: value})
+}
+
+// UpdateTranslation changes (or fills in) a language's value for a key some language has
+// Returns models.ErrTranslationNotFound for unknown keys.
+func (s *I18nService) UpdateTranslation(ctx context.Context, lang, key, value string) error {
+	if err := s.validate(lang, key, value); err != nil {
+		return err
+	}
+	s.mu.RLock()
+	known := s.allKeys()[key]
+	s.mu.RUnlock()
+	if !known {
+		return models.ErrTranslationNotFound
+	}
+	return s.SetTranslations(ctx, lang, map[string]string{key: value})
+}
+
+// SetTranslations stores values (key -> value) for a language in one transaction and reloads
+// Used for edits and imports; every key and value is validated first.
+func (s *I18nService) SetTranslations(ctx context.Context, lang string, values map[string]string) error {
+	for key, value := range values {
+		if err := s.validate(lang, key, value); err != nil {
+			return fmt.Errorf("%w: %s", err, key)
+		}
+	}
+
+	err := s.WithTx(ctx, func(tx *BaseService) error {
+		for key, value := range values {
+			result, err := tx.store.Update(ctx, "translations", Where().Eq("lang_code", lang).Eq("key", key),
+				map[string]interface{}{"value": value})
+			if err != nil {
+				return fmt.Errorf("failed to update translation: %w", err)
+			}
+			var updated []json.RawMessage
+			if err := json.Unmarshal(result, &updated); err != nil {
+				return fmt.Errorf("failed to parse translations data: %w", err)
+			}
+			if len(updated) > 0 {
+				continue
+			}
+			if err := tx.InsertRecord(ctx, "translations", map[string]interface{}{
+				"id":        uuid.New().String(),
+				"lang_code": lang,
+				"key":       key,
+				"value":     value,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("Saved %d %s translations", len(values), lang)
+	return s.Reload(ctx)
+}
+
+// DeleteTranslation removes a key from a language in the translations table
+// A key shipped in the JSON files falls back to the file's value. Returns
+// models.ErrTranslationNotFound when the table has no such row.
+func (s *I18nService) DeleteTranslation(ctx context.Context, lang, key string) error {
+	s.mu.RLock()
+	_, exists := s.overrides[lang][key]
+	s.mu.RUnlock()
+	if !exists {
+		return models.ErrTranslationNotFound
+	}
+
+	if err := s.DeleteRecordsWithFilter(ctx, "translations", map[string]interface{}{"lang_code": lang, "key": key}); err != nil {
+		return err
+	}
+	s.logger.Info("Deleted %s translation %s", lang, key)
+	return s.Reload(ctx)
+}
+
+// ParseTranslations reads a translation file, flat or nested like static/i18n/*.json
+func ParseTranslations(data []byte) (map[string]string, error) {
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+	}
+	translations := make(map[string]string)
+	flattenTranslations("", tree, translations)
+	return translations, nil
+}
+
+// validate checks a translation before it is stored
+// Disabled languages can be translated ahead of being enabled.
+func (s *I18nService) validate(lang, key, value string) error {
+	if _, ok := s.languages.Get(lang); !ok {
+		return fmt.Errorf("%w: unknown language %q", models.ErrInvalidTranslation, lang)
+	}
+	if len(key) > 255 || !translationKeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key %q", models.ErrInvalidTranslation, key)
+	}
+	if value == "" {
+		return fmt.Errorf("%w: empty value", models.ErrInvalidTranslation)
+	}
+	if _, err := parseMessage(value); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidTranslation, err)
+	}
+	return nil
 }
 
+// flattenTranslations adds the strings of a nested translation file to out under dotted keys
+// ({"auth": {"login": "..."}} becomes "auth.login"); flat files keep their keys.
+func flattenTranslations(prefix string, tree map[string]interface{}, out map[string]string) {
+	for key, value := range tree {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case string:
+			out[key] = v
+		case map[string]interface{}:
+			flattenTranslations(key, v, out)
+		}
+	}
+}
 
+// translatorKey is the context key of the translator used by T
+type translatorKey struct{}
 
+// translator is what T needs: the service and the request's language
+type translator struct {
+	i18n *I18nService
+	lang string
+}
+
+// WithTranslator returns a context whose templ components translate into lang with T
+func (s *I18nService) WithTranslator(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, translatorKey{}, translator{i18n: s, lang: lang})
+}
+
+// T translates a key for templ components, in the language set by WithTranslator
+// Components rendered without a translator (e.g. SSE broadcasts) get the key.
+//
+//	{ services.T(ctx, "play.question_progress", services.MessageArgs{"current": 3, "total": 20}) }
+func T(ctx context.Context, key string, args ...MessageArgs) string {
+	t, ok := ctx.Value(translatorKey{}).(translator)
+	if !ok {
+		return key
+	}
+	var merged MessageArgs
+	if len(args) == 1 {
+		merged = args[0]
+	} else if len(args) > 1 {
+		merged = make(MessageArgs)
+		for _, a := range args {
+			for name, value := range a {
+				merged[name] = value
+			}
+		}
+	}
+	return t.i18n.Translate(t.lang, key, merged)
+}
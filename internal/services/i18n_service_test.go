@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hekigan/couples/internal/models"
+)
+
+// writeTranslationFiles writes translation files (language -> JSON) to a temporary directory
+func writeTranslationFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for lang, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, lang+".json"), []byte(content), 0o644); err != nil {
+			t.Fatalf("write %s: %v", lang, err)
+		}
+	}
+	return dir
+}
+
+// TestI18nService_LoadAndEdit tests seeding the table from the files, edits and their fallbacks
+func TestI18nService_LoadAndEdit(t *testing.T) {
+	store := SetupTestStore(t)
+	ctx := context.Background()
+	dir := writeTranslationFiles(t, map[string]string{
+		"en": `{"app": {"title": "Couples", "bye": "Bye"}}`,
+		"fr": `{"app.title": "Couples FR"}`,
+	})
+
+	service := NewI18nService(store, dir, NewLanguageService(store))
+	title, _ := service.GetTranslation(ctx, "fr", "app.title")
+	AssertEqual(t, "Couples FR", title, "files work before Load")
+
+	AssertNoError(t, service.Load(ctx), "load")
+	count, _ := service.CountRecords(ctx, "translations", map[string]interface{}{})
+	AssertEqual(t, 3, count, "table seeded from the files")
+
+	AssertNoError(t, service.UpdateTranslation(ctx, "fr", "app.title", "Les couples"), "update")
+	title, _ = service.GetTranslation(ctx, "fr", "app.title")
+	AssertEqual(t, "Les couples", title, "edit applies")
+	bye, _ := service.GetTranslation(ctx, "fr", "app.bye")
+	AssertEqual(t, "Bye", bye, "missing French falls back to English")
+	unknown, _ := service.GetTranslation(ctx, "fr", "app.nope")
+	AssertEqual(t, "app.nope", unknown, "unknown keys return the key")
+
+	// A second instance sees the edit, and loading again does not seed twice
+	other := NewI18nService(store, dir, NewLanguageService(store))
+	AssertNoError(t, other.Load(ctx), "load other")
+	title, _ = other.GetTranslation(ctx, "fr", "app.title")
+	AssertEqual(t, "Les couples", title, "edit shared through the table")
+	count, _ = service.CountRecords(ctx, "translations", map[string]interface{}{})
+	AssertEqual(t, 3, count, "seeded once")
+
+	// Deleting the row brings back the file's value
+	AssertNoError(t, service.DeleteTranslation(ctx, "fr", "app.title"), "delete")
+	title, _ = service.GetTranslation(ctx, "fr", "app.title")
+	AssertEqual(t, "Couples FR", title, "file value after delete")
+	AssertTrue(t, errors.Is(service.DeleteTranslation(ctx, "fr", "app.title"), models.ErrTranslationNotFound), "only table rows can be deleted")
+
+	AssertNoError(t, other.Reload(ctx), "reload")
+	title, _ = other.GetTranslation(ctx, "fr", "app.title")
+	AssertEqual(t, "Couples FR", title, "reload picks up the delete")
+
+	entries := service.Entries("fr")
+	AssertEqual(t, 2, len(entries), "entries cover every key")
+	AssertEqual(t, "app.bye", entries[0].Key, "sorted")
+	AssertEqual(t, TranslationSourceMissing, entries[0].Source, "missing in French")
+	AssertEqual(t, TranslationSourceFile, entries[1].Source, "back to the file")
+}
+
+// TestI18nService_Validation tests refused edits and the missing keys report
+func TestI18nService_Validation(t *testing.T) {
+	store := SetupTestStore(t)
+	ctx := context.Background()
+	dir := writeTranslationFiles(t, map[string]string{
+		"en": `{"home": {"welcome": "Welcome", "play": "Play"}}`,
+		"ja": `{"home": {"welcome": "ようこそ"}}`,
+	})
+	service := NewI18nService(store, dir, NewLanguageService(store))
+	AssertNoError(t, service.Load(ctx), "load")
+
+	AssertEqual(t, 1, len(service.MissingKeys()["ja"]), "ja lacks home.play")
+	AssertEqual(t, 0, len(service.MissingKeys()["en"]), "en complete")
+
+	AssertTrue(t, errors.Is(service.CreateTranslation(ctx, "ja", "home.welcome", "x"), models.ErrTranslationExists), "existing key")
+	AssertTrue(t, errors.Is(service.CreateTranslation(ctx, "de", "home.play", "Spielen"), models.ErrInvalidTranslation), "unknown language")
+	AssertTrue(t, errors.Is(service.CreateTranslation(ctx, "ja", "home..play", "x"), models.ErrInvalidTranslation), "bad key")
+	AssertTrue(t, errors.Is(service.CreateTranslation(ctx, "ja", "home.play", ""), models.ErrInvalidTranslation), "empty value")
+	AssertTrue(t, errors.Is(service.UpdateTranslation(ctx, "ja", "home.nope", "x"), models.ErrTranslationNotFound), "update needs a known key")
+
+	AssertNoError(t, service.CreateTranslation(ctx, "ja", "home.play", "遊ぶ"), "create")
+	AssertEqual(t, 0, len(service.MissingKeys()["ja"]), "ja complete")
+
+	values, err := ParseTranslations([]byte(`{"home": {"welcome": "いらっしゃい"}, "about": "概要"}`))
+	AssertNoError(t, err, "parse nested file")
+	AssertNoError(t, service.SetTranslations(ctx, "ja", values), "import")
+	AssertEqual(t, "いらっしゃい", service.Strings("ja")["home.welcome"], "import updates")
+	AssertEqual(t, 1, len(service.MissingKeys()["en"]), "imported key missing in English")
+
+	_, err = ParseTranslations([]byte(`["not", "an", "object"]`))
+	AssertTrue(t, errors.Is(err, models.ErrInvalidImportFile), "bad file refused")
+}
+
+// TestI18nService_Translate tests messages with arguments, the fallback's plural rules and T
+func TestI18nService_Translate(t *testing.T) {
+	store := SetupTestStore(t)
+	ctx := context.Background()
+	dir := writeTranslationFiles(t, map[string]string{
+		"en": `{"requests": "{count, plural, one {# request} other {# requests}}", "hi": "Hi {name}"}`,
+		"fr": `{"hi": "Salut {name}"}`,
+	})
+	service := NewI18nService(store, dir, NewLanguageService(store))
+	AssertNoError(t, service.Load(ctx), "load")
+
+	AssertEqual(t, "Salut Ana", service.Translate("fr", "hi", MessageArgs{"name": "Ana"}), "placeholder")
+	// French would say "0 request"; the English fallback string keeps English rules
+	AssertEqual(t, "0 requests", service.Translate("fr", "requests", MessageArgs{"count": 0}), "fallback plural rules")
+	AssertEqual(t, "nope", service.Translate("fr", "nope", nil), "unknown key")
+
+	AssertTrue(t, errors.Is(service.UpdateTranslation(ctx, "fr", "hi", "Salut {name"), models.ErrInvalidTranslation), "invalid message refused")
+
+	AssertEqual(t, "hi", T(ctx, "hi"), "no translator")
+	AssertEqual(t, "Hi Bo", T(service.WithTranslator(ctx, "en"), "hi", MessageArgs{"name": "Bo"}), "translator from the context")
+}
@@ -153,7 +153,7 @@ func TestGameFlow_ReconnectionTimeout(t *testing.T) {
 		// 2. Pause game with old PausedAt timestamp (e.g., 10 minutes ago)
 		// 3. Call CheckReconnectionTimeout(room.ID, 5) // 5 minute timeout
 		// 4. Verify returns (true, nil) - timeout occurred
-		// 5. Verify room.Status = "finished"
+		// 5. Verify room.Status = "abandoned"
 	})
 
 	t.Run("game continues if within timeout", func(t *testing.T) {
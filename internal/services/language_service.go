@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hekigan/couples/internal/models"
+)
+
+// DefaultLanguage is the language of base questions and the end of every fallback chain
+const DefaultLanguage = "en"
+
+// DefaultLanguages are the languages the migrations insert
+var DefaultLanguages = []models.Language{
+	{Code: "en", NativeName: "English", Enabled: true, Position: 0},
+	{Code: "fr", NativeName: "Français", Enabled: true, Fallback: "en", Position: 1},
+	{Code: "ja", NativeName: "日本語", Enabled: true, Fallback: "en", Position: 2},
+}
+
+// languageCodePattern is what a language code may look like: ISO 639 with an optional region
+// or script (pt-BR, zh-Hant), 10 characters at most like the code columns
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,6})?$`)
+
+// LanguageService is the registry of languages (languages table)
+// It keeps the table in memory for the per-request lookups of the i18n middleware; edits reload
+// it on this instance, and Start re-reads it periodically for the others.
+type LanguageService struct {
+	*BaseService
+
+	mu        sync.RWMutex
+	languages []models.Language // ordered by position, then code
+
+	reloader reloadLoop
+}
+
+// NewLanguageService creates a new language service
+// Until Load, the registry holds DefaultLanguages.
+func NewLanguageService(store Store) *LanguageService {
+	return &LanguageService{
+		BaseService: NewBaseService(store, "LanguageService"),
+		languages:   append([]models.Language(nil), DefaultLanguages...),
+	}
+}
+
+// Load reads the languages table
+func (s *LanguageService) Load(ctx context.Context) error {
+	var languages []models.Language
+	if err := s.QueryRecords(ctx, "languages", NewQuery().OrderBy("position", true), &languages); err != nil {
+		return fmt.Errorf("failed to load languages: %w", err)
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		if languages[i].Position != languages[j].Position {
+			return languages[i].Position < languages[j].Position
+		}
+		return languages[i].Code < languages[j].Code
+	})
+	if len(languages) == 0 {
+		return fmt.Errorf("failed to load languages: the languages table is empty")
+	}
+
+	s.mu.Lock()
+	s.languages = languages
+	s.mu.Unlock()
+	return nil
+}
+
+// Start re-reads the languages table every reloadInterval
+func (s *LanguageService) Start() {
+	s.reloader.start(s.logger, s.Load)
+}
+
+// Stop ends the periodic reload
+func (s *LanguageService) Stop() {
+	s.reloader.stop()
+}
+
+// All returns every language, enabled or not, in display order
+func (s *LanguageService) All() []models.Language {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.Language(nil), s.languages...)
+}
+
+// Enabled returns the enabled languages in display order
+func (s *LanguageService) Enabled() []models.Language {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var enabled []models.Language
+	for _, language := range s.languages {
+		if language.Enabled {
+			enabled = append(enabled, language)
+		}
+	}
+	return enabled
+}
+
+// Codes returns the codes of every language, enabled or not, in display order
+func (s *LanguageService) Codes() []string {
+	languages := s.All()
+	codes := make([]string, len(languages))
+	for i, language := range languages {
+		codes[i] = language.Code
+	}
+	return codes
+}
+
+// Get returns a language by code
+func (s *LanguageService) Get(code string) (models.Language, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, language := range s.languages {
+		if language.Code == code {
+			return language, true
+		}
+	}
+	return models.Language{}, false
+}
+
+// IsEnabled reports whether code is an enabled language
+func (s *LanguageService) IsEnabled(code string) bool {
+	language, ok := s.Get(code)
+	return ok && language.Enabled
+}
+
+// FallbackChain returns code followed by the languages to try when it lacks a string, ending at
+// DefaultLanguage
+func (s *LanguageService) FallbackChain(code string) []string {
+	chain := []string{code}
+	seen := map[string]bool{code: true}
+	for current := code; ; {
+		language, ok := s.Get(current)
+		if !ok || language.Fallback == "" || seen[language.Fallback] {
+			break
+		}
+		current = language.Fallback
+		chain = append(chain, current)
+		seen[current] = true
+	}
+	if !seen[DefaultLanguage] {
+		chain = append(chain, DefaultLanguage)
+	}
+	return chain
+}
+
+// CreateLanguage adds a language after the existing ones
+// An empty fallback falls back to DefaultLanguage.
+func (s *LanguageService) CreateLanguage(ctx context.Context, language *models.Language) error {
+	language.Code = strings.TrimSpace(language.Code)
+	language.NativeName = strings.TrimSpace(language.NativeName)
+	if !languageCodePattern.MatchString(language.Code) {
+		return fmt.Errorf("%w: code %q (expected e.g. de or pt-BR)", models.ErrInvalidLanguage, language.Code)
+	}
+	if _, exists := s.Get(language.Code); exists {
+		return models.ErrLanguageExists
+	}
+	if language.Fallback == "" {
+		language.Fallback = DefaultLanguage
+	}
+	if err := s.validate(language); err != nil {
+		return err
+	}
+
+	languages := s.All()
+	if len(languages) > 0 {
+		language.Position = languages[len(languages)-1].Position + 1
+	}
+	if err := s.InsertRecord(ctx, "languages", map[string]interface{}{
+		"code":        language.Code,
+		"native_name": language.NativeName,
+		"enabled":     language.Enabled,
+		"fallback":    language.Fallback,
+		"position":    language.Position,
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Success("Added language %s (%s)", language.Code, language.NativeName)
+	return s.Load(ctx)
+}
+
+// UpdateLanguage changes a language's name, enabled flag and fallback
+// The default language cannot be disabled or given a fallback.
+func (s *LanguageService) UpdateLanguage(ctx context.Context, language *models.Language) error {
+	if _, exists := s.Get(language.Code); !exists {
+		return models.ErrLanguageNotFound
+	}
+	language.NativeName = strings.TrimSpace(language.NativeName)
+	if language.Code == DefaultLanguage {
+		if !language.Enabled || language.Fallback != "" {
+			return fmt.Errorf("%w: %s is the default language", models.ErrInvalidLanguage, DefaultLanguage)
+		}
+	} else if language.Fallback == "" {
+		language.Fallback = DefaultLanguage
+	}
+	if err := s.validate(language); err != nil {
+		return err
+	}
+
+	var fallback interface{}
+	if language.Fallback != "" {
+		fallback = language.Fallback
+	}
+	if err := s.UpdateRecordsWithFilter(ctx, "languages", map[string]interface{}{"code": language.Code}, map[string]interface{}{
+		"native_name": language.NativeName,
+		"enabled":     language.Enabled,
+		"fallback":    fallback,
+	}); err != nil {
+		return err
+	}
+
+	s.logger.Info("Updated language %s", language.Code)
+	return s.Load(ctx)
+}
+
+// validate checks a language's name and that its fallback exists without looping back to it
+func (s *LanguageService) validate(language *models.Language) error {
+	if language.NativeName == "" || len([]rune(language.NativeName)) > 50 {
+		return fmt.Errorf("%w: the native name is required (50 characters at most)", models.ErrInvalidLanguage)
+	}
+	if language.Fallback == "" {
+		return nil
+	}
+	if language.Fallback == language.Code {
+		return fmt.Errorf("%w: a language cannot fall back to itself", models.ErrInvalidLanguage)
+	}
+	if _, ok := s.Get(language.Fallback); !ok {
+		return fmt.Errorf("%w: unknown fallback %q", models.ErrInvalidLanguage, language.Fallback)
+	}
+	for _, code := range s.FallbackChain(language.Fallback) {
+		if code == language.Code {
+			return fmt.Errorf("%w: falling back to %s would loop", models.ErrInvalidLanguage, language.Fallback)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hekigan/couples/internal/models"
+)
+
+// TestLanguageService_CreateAndUpdate tests adding a language, its fallback chain and edits
+func TestLanguageService_CreateAndUpdate(t *testing.T) {
+	store := SetupTestStore(t)
+	ctx := context.Background()
+	service := NewLanguageService(store)
+	AssertNoError(t, service.Load(ctx), "load")
+	AssertEqual(t, 3, len(service.Enabled()), "seeded languages")
+
+	// Added languages are disabled until enabled and fall back to English by default
+	AssertNoError(t, service.CreateLanguage(ctx, &models.Language{Code: "pt-BR", NativeName: "Português"}), "create pt-BR")
+	AssertFalse(t, service.IsEnabled("pt-BR"), "disabled")
+	AssertEqual(t, "pt-BR en", strings.Join(service.FallbackChain("pt-BR"), " "), "falls back to English")
+
+	AssertNoError(t, service.CreateLanguage(ctx, &models.Language{Code: "pt", NativeName: "Português", Enabled: true}), "create pt")
+	AssertNoError(t, service.UpdateLanguage(ctx, &models.Language{Code: "pt-BR", NativeName: "Português (Brasil)", Enabled: true, Fallback: "pt"}), "update pt-BR")
+	AssertTrue(t, service.IsEnabled("pt-BR"), "enabled")
+	AssertEqual(t, "pt-BR pt en", strings.Join(service.FallbackChain("pt-BR"), " "), "falls back through pt")
+	AssertEqual(t, "en fr ja pt-BR pt", strings.Join(service.Codes(), " "), "added languages go last")
+
+	// Another instance sees the edits once it loads
+	other := NewLanguageService(store)
+	AssertNoError(t, other.Load(ctx), "load other")
+	language, ok := other.Get("pt-BR")
+	AssertTrue(t, ok, "pt-BR shared through the table")
+	AssertEqual(t, "Português (Brasil)", language.NativeName, "name updated")
+}
+
+// TestLanguageService_Validation tests refused languages and edits
+func TestLanguageService_Validation(t *testing.T) {
+	store := SetupTestStore(t)
+	ctx := context.Background()
+	service := NewLanguageService(store)
+	AssertNoError(t, service.Load(ctx), "load")
+
+	AssertTrue(t, errors.Is(service.CreateLanguage(ctx, &models.Language{Code: "fr", NativeName: "Français"}), models.ErrLanguageExists), "existing code")
+	AssertTrue(t, errors.Is(service.CreateLanguage(ctx, &models.Language{Code: "German", NativeName: "Deutsch"}), models.ErrInvalidLanguage), "bad code")
+	AssertTrue(t, errors.Is(service.CreateLanguage(ctx, &models.Language{Code: "de"}), models.ErrInvalidLanguage), "name required")
+	AssertTrue(t, errors.Is(service.CreateLanguage(ctx, &models.Language{Code: "de", NativeName: "Deutsch", Fallback: "nl"}), models.ErrInvalidLanguage), "unknown fallback")
+	AssertTrue(t, errors.Is(service.UpdateLanguage(ctx, &models.Language{Code: "de", NativeName: "Deutsch"}), models.ErrLanguageNotFound), "update needs a known code")
+
+	AssertTrue(t, errors.Is(service.UpdateLanguage(ctx, &models.Language{Code: "en", NativeName: "English"}), models.ErrInvalidLanguage), "default language stays enabled")
+	AssertTrue(t, errors.Is(service.UpdateLanguage(ctx, &models.Language{Code: "en", NativeName: "English", Enabled: true, Fallback: "fr"}), models.ErrInvalidLanguage), "default language has no fallback")
+
+	// fr -> ja -> fr would loop
+	AssertNoError(t, service.UpdateLanguage(ctx, &models.Language{Code: "ja", NativeName: "日本語", Enabled: true, Fallback: "fr"}), "ja falls back to fr")
+	AssertTrue(t, errors.Is(service.UpdateLanguage(ctx, &models.Language{Code: "fr", NativeName: "Français", Enabled: true, Fallback: "ja"}), models.ErrInvalidLanguage), "fallback loop")
+	AssertEqual(t, "ja fr en", strings.Join(service.FallbackChain("ja"), " "), "chain ends at English")
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// LeaderElector decides which instance runs the scheduled jobs
+type LeaderElector interface {
+	// IsLeader reports whether this instance leads, trying to become leader when it does not
+	IsLeader(ctx context.Context) bool
+	// Close gives up leadership
+	Close() error
+}
+
+// LocalLeader always leads, for a single instance
+type LocalLeader struct{}
+
+// IsLeader always returns true
+func (LocalLeader) IsLeader(ctx context.Context) bool { return true }
+
+// Close does nothing
+func (LocalLeader) Close() error { return nil }
+
+// PostgresLeader elects one instance through a session-level advisory lock
+// The leader holds a connection with the lock for as long as it runs; when that connection
+// breaks, the lock is released by PostgreSQL and another instance takes over. The connection
+// comes from a pool of its own, so holding it never starves the pool requests and the
+// realtime broker use.
+type PostgresLeader struct {
+	pool *PostgresStore
+	key  int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// OpenPostgresLeader opens a one-connection pool to databaseURL for an elector; instances
+// using the same name compete
+func OpenPostgresLeader(ctx context.Context, databaseURL, name string) (*PostgresLeader, error) {
+	pool, err := NewPostgresStore(ctx, databaseURL, PostgresPoolConfig{MaxConns: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the job scheduler lock connection: %w", err)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return &PostgresLeader{pool: pool, key: int64(h.Sum64())}, nil
+}
+
+// IsLeader checks the held lock is still alive, or tries to take it
+func (l *PostgresLeader) IsLeader(ctx context.Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true
+		}
+		fmt.Printf("⚠️ Warning: lost the job scheduler lock\n")
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.pool.DB().Conn(ctx)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: failed to acquire a connection for the job scheduler lock: %v\n", err)
+		return false
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&locked); err != nil || !locked {
+		if err != nil {
+			fmt.Printf("⚠️ Warning: failed to try the job scheduler lock: %v\n", err)
+		}
+		conn.Close()
+		return false
+	}
+
+	fmt.Printf("👑 This instance now runs the scheduled jobs\n")
+	l.conn = conn
+	return true
+}
+
+// Close releases the lock and closes the elector's pool
+func (l *PostgresLeader) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	if l.conn != nil {
+		if _, execErr := l.conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", l.key); execErr != nil {
+			err = fmt.Errorf("failed to release the job scheduler lock: %w", execErr)
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+	if closeErr := l.pool.Close(); closeErr != nil && err == nil {
+		err = fmt.Errorf("failed to close the job scheduler lock connection: %w", closeErr)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Mail is a plain-text email
+type Mail struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+// Mailer sends emails
+// SMTPMailer delivers them; FileMailer keeps them on disk (or in the log) for development.
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// errHeaderInjection is returned for recipients or subjects that contain line breaks
+var errHeaderInjection = errors.New("mail header contains a line break")
+
+// SMTPMailer sends mail through an SMTP server, upgrading to TLS when the server offers STARTTLS
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates an SMTP mailer; user and password may be empty for an open relay
+func NewSMTPMailer(host string, port int, user, password, from string) *SMTPMailer {
+	m := &SMTPMailer{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+	}
+	if user != "" {
+		m.auth = smtp.PlainAuth("", user, password, host)
+	}
+	return m
+}
+
+// Send delivers the mail
+// net/smtp has no context support, so ctx is only checked before connecting.
+func (m *SMTPMailer) Send(ctx context.Context, mail Mail) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg, err := buildMessage(m.from, mail, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{mail.To}, msg); err != nil {
+		return fmt.Errorf("failed to send mail to %s: %w", mail.To, err)
+	}
+	return nil
+}
+
+// FileMailer writes each mail to a .eml file in dir, or to the log when dir is empty
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer for development that never sends anything
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send writes the mail out
+func (m *FileMailer) Send(ctx context.Context, mail Mail) error {
+	now := time.Now()
+	msg, err := buildMessage(m.from, mail, now)
+	if err != nil {
+		return err
+	}
+
+	if m.dir == "" {
+		log.Printf("📧 Mail to %s: %s\n%s", mail.To, mail.Subject, mail.Text)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102-150405.000000000"), sanitizeFileName(mail.To))
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, msg, 0o644); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	log.Printf("📧 Mail to %s written to %s", mail.To, path)
+	return nil
+}
+
+// buildMessage formats mail as a UTF-8, quoted-printable RFC 5322 message
+func buildMessage(from string, mail Mail, date time.Time) ([]byte, error) {
+	if strings.ContainsAny(mail.To+mail.Subject+from, "\r\n") {
+		return nil, errHeaderInjection
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", mail.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(mail.Text, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sanitizeFileName keeps the letters, digits, dots and dashes of s
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, s)
+}
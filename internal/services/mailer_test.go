@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestFileMailer_WritesMessage tests that mail is written as an encoded .eml file
+func TestFileMailer_WritesMessage(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir, "noreply@couples.example")
+
+	AssertNoError(t, mailer.Send(context.Background(), Mail{
+		To:      "alice@example.com",
+		Subject: "パスワードの再設定",
+		Text:    "Open this link:\nhttps://couples.example/reset-password?token=abc",
+	}), "send")
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	AssertNoError(t, err, "glob")
+	AssertEqual(t, 1, len(files), "written files")
+
+	data, err := os.ReadFile(files[0])
+	AssertNoError(t, err, "read")
+	msg := string(data)
+	for _, want := range []string{
+		"From: noreply@couples.example\r\n",
+		"To: alice@example.com\r\n",
+		"Subject: =?utf-8?q?",
+		"Content-Transfer-Encoding: quoted-printable\r\n",
+		"token=3Dabc",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message lacks %q:\n%s", want, msg)
+		}
+	}
+}
+
+// TestFileMailer_RejectsHeaderInjection tests that line breaks cannot add headers
+func TestFileMailer_RejectsHeaderInjection(t *testing.T) {
+	mailer := NewFileMailer(t.TempDir(), "noreply@couples.example")
+
+	err := mailer.Send(context.Background(), Mail{To: "alice@example.com\r\nBcc: eve@example.com", Subject: "Hi"})
+	AssertError(t, err, "recipient with a line break")
+}
@@ -0,0 +1,795 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore is an in-process Store used by tests and offline development
+// It mirrors the parts of the schema (internal/migrations) the services rely on:
+// column defaults, UNIQUE constraints, ON DELETE CASCADE, updated_at triggers and the
+// rooms_with_players / join_requests_with_users / active_games views.
+// Tables that are not described in memorySchema are accepted as-is.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	txMu   sync.Mutex // serializes WithTx
+	tables map[string][]memoryRow
+	now    func() time.Time
+}
+
+// memoryRow is one row, normalized through JSON (UUIDs and timestamps are strings, numbers are float64)
+type memoryRow map[string]interface{}
+
+// memoryTable describes the constraints MemoryStore enforces for a table
+type memoryTable struct {
+	defaults   map[string]interface{}
+	timestamps []string          // columns defaulting to NOW()
+	unique     [][]string        // UNIQUE constraints (the primary key "id" is always unique)
+	references map[string]string // column -> parent table, ON DELETE CASCADE
+	touch      bool              // update_updated_at_column trigger
+}
+
+// memorySchema mirrors the tables created by internal/migrations
+var memorySchema = map[string]memoryTable{
+	"users": {
+		defaults:   map[string]interface{}{"is_admin": false, "is_anonymous": false},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"email"}, {"username"}},
+		touch:      true,
+	},
+	"friends": {
+		timestamps: []string{"created_at"},
+		unique:     [][]string{{"user_id", "friend_id"}},
+		references: map[string]string{"user_id": "users", "friend_id": "users"},
+	},
+	"categories": {
+		defaults:   map[string]interface{}{"weight": 1.0},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"key"}},
+		touch:      true,
+	},
+	"questions": {
+		timestamps: []string{"created_at", "updated_at"},
+		references: map[string]string{"category_id": "categories", "base_question_id": "questions"},
+		touch:      true,
+	},
+	"rooms": {
+		defaults: map[string]interface{}{
+			"status":           "waiting",
+			"language":         "en",
+			"is_private":       false,
+			"guest_ready":      false,
+			"max_questions":    20,
+			"current_question": 0,
+			"allow_repeats":    false,
+			"version":          0,
+		},
+		timestamps: []string{"created_at", "updated_at"},
+		references: map[string]string{"owner_id": "users", "guest_id": "users"},
+		touch:      true,
+	},
+	"room_join_requests": {
+		defaults:   map[string]interface{}{"status": "pending"},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"room_id", "user_id"}},
+		references: map[string]string{"room_id": "rooms", "user_id": "users"},
+		touch:      true,
+	},
+	"room_invitations": {
+		defaults:   map[string]interface{}{"status": "pending"},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"room_id", "invitee_id"}},
+		references: map[string]string{"room_id": "rooms", "inviter_id": "users", "invitee_id": "users"},
+		touch:      true,
+	},
+	"notifications": {
+		defaults:   map[string]interface{}{"read": false},
+		timestamps: []string{"created_at"},
+		references: map[string]string{"user_id": "users"},
+	},
+	"answers": {
+		timestamps: []string{"created_at"},
+		references: map[string]string{"room_id": "rooms", "question_id": "questions", "user_id": "users"},
+	},
+	"question_history": {
+		timestamps: []string{"asked_at"},
+		unique:     [][]string{{"room_id", "question_id"}},
+		references: map[string]string{"room_id": "rooms", "question_id": "questions"},
+	},
+	"player_question_history": {
+		timestamps: []string{"asked_at"},
+		unique:     [][]string{{"user_id", "partner_id", "question_id"}},
+		references: map[string]string{"user_id": "users", "partner_id": "users", "question_id": "questions"},
+	},
+	"room_state_transitions": {
+		timestamps: []string{"created_at"},
+		references: map[string]string{"room_id": "rooms"},
+	},
+	"room_events": {
+		timestamps: []string{"created_at"},
+		references: map[string]string{"room_id": "rooms", "user_id": "users"},
+	},
+	"room_presence": {
+		timestamps: []string{"seen_at"},
+		unique:     [][]string{{"room_id", "user_id", "instance_id"}},
+		references: map[string]string{"room_id": "rooms", "user_id": "users"},
+	},
+	"job_runs": {
+		timestamps: []string{"started_at"},
+	},
+	"account_tokens": {
+		timestamps: []string{"created_at"},
+		unique:     [][]string{{"token_hash"}},
+		references: map[string]string{"user_id": "users"},
+	},
+	"user_two_factor": {
+		defaults:   map[string]interface{}{"last_used_step": 0, "failed_attempts": 0},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"user_id"}},
+		references: map[string]string{"user_id": "users"},
+		touch:      true,
+	},
+	"two_factor_recovery_codes": {
+		timestamps: []string{"created_at"},
+		references: map[string]string{"user_id": "users"},
+	},
+	"sessions": {
+		defaults:   map[string]interface{}{"data": "", "user_agent": "", "ip_address": ""},
+		timestamps: []string{"created_at", "last_seen_at"},
+		unique:     [][]string{{"token_hash"}},
+		references: map[string]string{"user_id": "users"},
+	},
+	"api_tokens": {
+		timestamps: []string{"created_at"},
+		unique:     [][]string{{"token_hash"}},
+		references: map[string]string{"user_id": "users"},
+	},
+	"translations": {
+		timestamps: []string{"updated_at"},
+		unique:     [][]string{{"lang_code", "key"}},
+		touch:      true,
+	},
+	"languages": {
+		defaults:   map[string]interface{}{"enabled": true, "fallback": nil, "position": 0},
+		timestamps: []string{"created_at", "updated_at"},
+		unique:     [][]string{{"code"}},
+		touch:      true,
+	},
+}
+
+// memoryViews mirrors the migration-defined views the services read from
+var memoryViews = map[string]func(m *MemoryStore) []memoryRow{
+	"rooms_with_players":       (*MemoryStore).roomsWithPlayers,
+	"join_requests_with_users": (*MemoryStore).joinRequestsWithUsers,
+	"active_games":             (*MemoryStore).activeGames,
+}
+
+// NewMemoryStore creates an in-memory store holding only the rows the migrations insert
+// (the default languages)
+func NewMemoryStore() *MemoryStore {
+	m := &MemoryStore{
+		tables: make(map[string][]memoryRow),
+		now:    time.Now,
+	}
+	for _, language := range DefaultLanguages {
+		var fallback interface{}
+		if language.Fallback != "" {
+			fallback = language.Fallback
+		}
+		if _, err := m.Insert(context.Background(), "languages", map[string]interface{}{
+			"code":        language.Code,
+			"native_name": language.NativeName,
+			"enabled":     language.Enabled,
+			"fallback":    fallback,
+			"position":    language.Position,
+		}); err != nil {
+			panic(err)
+		}
+	}
+	return m
+}
+
+// Select returns matching rows as a JSON array
+func (m *MemoryStore) Select(ctx context.Context, table string, q *Query) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rows := m.filter(m.source(table), q)
+	sortRows(rows, q)
+	rows = pageRows(rows, q)
+
+	columns := ""
+	if q != nil {
+		columns = q.Columns
+	}
+	return json.Marshal(projectRows(rows, columns))
+}
+
+// Count returns the number of matching rows
+func (m *MemoryStore) Count(ctx context.Context, table string, q *Query) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.filter(m.source(table), q)), nil
+}
+
+// Insert inserts one row, applying the table defaults and constraints
+func (m *MemoryStore) Insert(ctx context.Context, table string, data map[string]interface{}) ([]byte, error) {
+	if _, isView := memoryViews[table]; isView {
+		return nil, fmt.Errorf("cannot insert into view %q", table)
+	}
+
+	row, err := normalizeRow(data)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := memorySchema[table]
+	if row["id"] == nil {
+		row["id"] = uuid.New().String()
+	}
+	for column, value := range schema.defaults {
+		if _, ok := row[column]; !ok {
+			row[column] = value
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.timestamp()
+	for _, column := range schema.timestamps {
+		if row[column] == nil {
+			row[column] = now
+		}
+	}
+
+	// Defaults went in as Go values; normalize them like the rest of the row
+	if row, err = normalizeRow(row); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkUnique(table, row, nil); err != nil {
+		return nil, err
+	}
+
+	m.tables[table] = append(m.tables[table], row)
+	return json.Marshal([]memoryRow{row})
+}
+
+// Update applies data to every matching row
+func (m *MemoryStore) Update(ctx context.Context, table string, q *Query, data map[string]interface{}) ([]byte, error) {
+	if _, isView := memoryViews[table]; isView {
+		return nil, fmt.Errorf("cannot update view %q", table)
+	}
+
+	changes, err := normalizeRow(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	schema := memorySchema[table]
+	now := m.timestamp()
+
+	updated := []memoryRow{}
+	for i, row := range m.tables[table] {
+		if !rowMatches(row, q) {
+			continue
+		}
+
+		next := copyRow(row)
+		for column, value := range changes {
+			next[column] = value
+		}
+		if schema.touch {
+			next["updated_at"] = now
+		}
+
+		if err := m.checkUnique(table, next, row); err != nil {
+			return nil, err
+		}
+
+		m.tables[table][i] = next
+		updated = append(updated, next)
+	}
+
+	return json.Marshal(updated)
+}
+
+// Delete removes every matching row and cascades to referencing rows
+func (m *MemoryStore) Delete(ctx context.Context, table string, q *Query) error {
+	if _, isView := memoryViews[table]; isView {
+		return fmt.Errorf("cannot delete from view %q", table)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deleteWhere(table, func(row memoryRow) bool { return rowMatches(row, q) })
+	return nil
+}
+
+// WithTx runs fn and restores the contents of the store if fn fails or panics
+// Transactions are serialized with each other, but writes made outside a transaction
+// while one is open are discarded by its rollback.
+func (m *MemoryStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+
+	return m.runRestoring(fn)
+}
+
+// runRestoring runs fn against the open transaction and puts the tables back as they
+// were if it fails or panics
+func (m *MemoryStore) runRestoring(fn func(tx Store) error) (err error) {
+	m.mu.RLock()
+	snapshot := make(map[string][]memoryRow, len(m.tables))
+	for table, rows := range m.tables {
+		// Rows are replaced, never modified in place, so copying the slices is enough
+		snapshot[table] = append([]memoryRow(nil), rows...)
+	}
+	m.mu.RUnlock()
+
+	rollback := func() {
+		m.mu.Lock()
+		m.tables = snapshot
+		m.mu.Unlock()
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(memoryTx{m}); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
+
+// memoryTx is the Store handed to WithTx callbacks
+type memoryTx struct {
+	*MemoryStore
+}
+
+// WithTx runs fn inside the already open transaction, undoing only its writes if it fails
+func (tx memoryTx) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	return tx.runRestoring(fn)
+}
+
+// deleteWhere removes the rows of table matching match and cascades ON DELETE CASCADE references
+func (m *MemoryStore) deleteWhere(table string, match func(memoryRow) bool) {
+	var remaining []memoryRow
+	deleted := make(map[string]bool)
+	for _, row := range m.tables[table] {
+		if match(row) {
+			deleted[cellString(row["id"])] = true
+			continue
+		}
+		remaining = append(remaining, row)
+	}
+	if len(deleted) == 0 {
+		return
+	}
+	m.tables[table] = remaining
+
+	for child, schema := range memorySchema {
+		for column, parent := range schema.references {
+			if parent != table {
+				continue
+			}
+			column := column
+			m.deleteWhere(child, func(row memoryRow) bool {
+				return row[column] != nil && deleted[cellString(row[column])]
+			})
+		}
+	}
+}
+
+// checkUnique enforces the primary key and the UNIQUE constraints of table
+// previous is the row being replaced by an update (nil for inserts)
+func (m *MemoryStore) checkUnique(table string, row, previous memoryRow) error {
+	constraints := append([][]string{{"id"}}, memorySchema[table].unique...)
+
+	for _, existing := range m.tables[table] {
+		if previous != nil && cellString(existing["id"]) == cellString(previous["id"]) {
+			continue
+		}
+		for _, columns := range constraints {
+			if sameKey(row, existing, columns) {
+				return fmt.Errorf("duplicate key value violates unique constraint \"%s_%s_key\" (SQLSTATE 23505)",
+					table, strings.Join(columns, "_"))
+			}
+		}
+	}
+	return nil
+}
+
+// sameKey reports whether two rows collide on columns (NULLs never collide, as in SQL)
+func sameKey(a, b memoryRow, columns []string) bool {
+	for _, column := range columns {
+		if a[column] == nil || b[column] == nil || cellString(a[column]) != cellString(b[column]) {
+			return false
+		}
+	}
+	return true
+}
+
+// source returns a copy of the rows of a table or view
+func (m *MemoryStore) source(table string) []memoryRow {
+	if view, ok := memoryViews[table]; ok {
+		return view(m)
+	}
+
+	rows := make([]memoryRow, len(m.tables[table]))
+	for i, row := range m.tables[table] {
+		rows[i] = copyRow(row)
+	}
+	return rows
+}
+
+// filter keeps the rows matching q
+func (m *MemoryStore) filter(rows []memoryRow, q *Query) []memoryRow {
+	matched := []memoryRow{}
+	for _, row := range rows {
+		if rowMatches(row, q) {
+			matched = append(matched, row)
+		}
+	}
+	return matched
+}
+
+// timestamp returns the current time in the format PostgREST returns timestamptz columns
+func (m *MemoryStore) timestamp() string {
+	return m.now().UTC().Format(time.RFC3339Nano)
+}
+
+// rowByID indexes a table by id (callers must hold the lock)
+func (m *MemoryStore) rowByID(table string) map[string]memoryRow {
+	index := make(map[string]memoryRow, len(m.tables[table]))
+	for _, row := range m.tables[table] {
+		index[cellString(row["id"])] = row
+	}
+	return index
+}
+
+// roomsWithPlayers mirrors the rooms_with_players view
+func (m *MemoryStore) roomsWithPlayers() []memoryRow {
+	users := m.rowByID("users")
+
+	var rows []memoryRow
+	for _, room := range m.tables["rooms"] {
+		owner := users[cellString(room["owner_id"])]
+		guest := users[cellString(room["guest_id"])]
+		if (owner != nil && owner["deleted_at"] != nil) || (guest != nil && guest["deleted_at"] != nil) {
+			continue
+		}
+		currentPlayer := users[cellString(room["current_player_id"])]
+
+		row := copyRow(room)
+		row["owner_username"] = joinedColumn(owner, "username")
+		row["owner_email"] = joinedColumn(owner, "email")
+		row["guest_username"] = joinedColumn(guest, "username")
+		row["guest_email"] = joinedColumn(guest, "email")
+		row["current_player_username"] = joinedColumn(currentPlayer, "username")
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// joinRequestsWithUsers mirrors the join_requests_with_users view
+func (m *MemoryStore) joinRequestsWithUsers() []memoryRow {
+	users := m.rowByID("users")
+	rooms := m.rowByID("rooms")
+
+	var rows []memoryRow
+	for _, request := range m.tables["room_join_requests"] {
+		user := users[cellString(request["user_id"])]
+		room := rooms[cellString(request["room_id"])]
+		if user == nil || room == nil || user["deleted_at"] != nil {
+			continue
+		}
+
+		row := copyRow(request)
+		row["username"] = user["username"]
+		row["email"] = user["email"]
+		row["room_owner_id"] = room["owner_id"]
+		row["room_status"] = room["status"]
+		row["room_guest_id"] = room["guest_id"]
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// activeGames mirrors the active_games view
+func (m *MemoryStore) activeGames() []memoryRow {
+	users := m.rowByID("users")
+	questions := m.rowByID("questions")
+	categories := m.rowByID("categories")
+
+	var rows []memoryRow
+	for _, room := range m.tables["rooms"] {
+		status := cellString(room["status"])
+		if status != "playing" && status != "paused" {
+			continue
+		}
+		owner := users[cellString(room["owner_id"])]
+		guest := users[cellString(room["guest_id"])]
+		if owner == nil || owner["deleted_at"] != nil || (guest != nil && guest["deleted_at"] != nil) {
+			continue
+		}
+		currentPlayer := users[cellString(room["current_player_id"])]
+		question := questions[cellString(room["current_question_id"])]
+		var category memoryRow
+		if question != nil {
+			category = categories[cellString(question["category_id"])]
+		}
+
+		row := copyRow(room)
+		row["owner_username"] = joinedColumn(owner, "username")
+		row["guest_username"] = joinedColumn(guest, "username")
+		row["current_player_username"] = joinedColumn(currentPlayer, "username")
+		row["question_id"] = joinedColumn(question, "id")
+		row["current_question_text"] = joinedColumn(question, "question_text")
+		row["current_question_lang"] = joinedColumn(question, "lang_code")
+		row["question_base_id"] = joinedColumn(question, "base_question_id")
+		row["question_category_id"] = joinedColumn(question, "category_id")
+		row["question_category_key"] = joinedColumn(category, "key")
+		row["question_category_label"] = joinedColumn(category, "label")
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// joinedColumn reads a column of a LEFT JOINed row (NULL when the join found nothing)
+func joinedColumn(row memoryRow, name string) interface{} {
+	if row == nil {
+		return nil
+	}
+	return row[name]
+}
+
+// rowMatches applies the equality, IN, NOT IN, less-than and search filters of q
+func rowMatches(row memoryRow, q *Query) bool {
+	if q == nil {
+		return true
+	}
+
+	for key, want := range q.Filters {
+		cell := row[key]
+		if want == nil {
+			if cell != nil {
+				return false
+			}
+			continue
+		}
+		if cell == nil || compareCells(cell, normalizeValue(want)) != 0 {
+			return false
+		}
+	}
+
+	for key, values := range q.InFilters {
+		if row[key] == nil || !containsString(values, cellString(row[key])) {
+			return false
+		}
+	}
+
+	// NULL NOT IN (...) is NULL in SQL, so rows with a NULL column are dropped too
+	for key, values := range q.NotInFilters {
+		if row[key] == nil || containsString(values, cellString(row[key])) {
+			return false
+		}
+	}
+
+	for key, limit := range q.LtFilters {
+		if row[key] == nil || compareCells(row[key], normalizeValue(limit)) >= 0 {
+			return false
+		}
+	}
+
+	if q.Search != nil {
+		term := strings.ToLower(q.Search.Term)
+		found := false
+		for _, column := range q.Search.Columns {
+			if row[column] != nil && strings.Contains(strings.ToLower(cellString(row[column])), term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sortRows applies the query ordering (stable, so insertion order breaks ties)
+func sortRows(rows []memoryRow, q *Query) {
+	if q == nil || len(q.Orders) == 0 {
+		return
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, order := range q.Orders {
+			a, b := rows[i][order.Column], rows[j][order.Column]
+			switch {
+			case a == nil && b == nil:
+				continue
+			case a == nil:
+				return order.NullsFirst
+			case b == nil:
+				return !order.NullsFirst
+			}
+
+			cmp := compareCells(a, b)
+			if cmp == 0 {
+				continue
+			}
+			if order.Ascending {
+				return cmp < 0
+			}
+			return cmp > 0
+		}
+		return false
+	})
+}
+
+// pageRows applies the query limit and offset
+func pageRows(rows []memoryRow, q *Query) []memoryRow {
+	if q == nil || q.Limit <= 0 {
+		return rows
+	}
+	if q.Offset >= len(rows) {
+		return []memoryRow{}
+	}
+	end := q.Offset + q.Limit
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[q.Offset:end]
+}
+
+// projectRows keeps only the requested columns ("" or "*" keeps everything)
+func projectRows(rows []memoryRow, columns string) []memoryRow {
+	if columns == "" || columns == "*" {
+		return rows
+	}
+
+	names := strings.Split(columns, ",")
+	projected := make([]memoryRow, len(rows))
+	for i, row := range rows {
+		projected[i] = make(memoryRow, len(names))
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			projected[i][name] = row[name]
+		}
+	}
+	return projected
+}
+
+// compareCells orders two non-NULL values: timestamps chronologically, numbers numerically,
+// everything else by its string form
+func compareCells(a, b interface{}) int {
+	as, bs := cellString(a), cellString(b)
+
+	// Numeric comparison only when one side is actually a number ("10" vs 9.5)
+	_, aNumber := a.(float64)
+	_, bNumber := b.(float64)
+	if aNumber || bNumber {
+		af, aErr := strconv.ParseFloat(as, 64)
+		bf, bErr := strconv.ParseFloat(bs, 64)
+		if aErr == nil && bErr == nil {
+			switch {
+			case af < bf:
+				return -1
+			case af > bf:
+				return 1
+			}
+			return 0
+		}
+	}
+
+	if at, err := time.Parse(time.RFC3339Nano, as); err == nil {
+		if bt, err := time.Parse(time.RFC3339Nano, bs); err == nil {
+			return at.Compare(bt)
+		}
+	}
+
+	return strings.Compare(as, bs)
+}
+
+// cellString converts a normalized value to the text PostgreSQL would compare it as
+func cellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// normalizeValue converts a filter value the same way row values are stored
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string, bool, float64:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return filterValue(value)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return filterValue(value)
+	}
+	return decoded
+}
+
+// normalizeRow round-trips data through JSON so rows hold the same types PostgREST would return
+func normalizeRow(data map[string]interface{}) (memoryRow, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	row := memoryRow{}
+	if err := json.Unmarshal(encoded, &row); err != nil {
+		return nil, fmt.Errorf("failed to decode row: %w", err)
+	}
+
+	// Store timestamps in UTC so they sort and compare consistently
+	for column, value := range row {
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+				row[column] = t.UTC().Format(time.RFC3339Nano)
+			}
+		}
+	}
+	return row, nil
+}
+
+// copyRow returns a shallow copy of a row
+func copyRow(row memoryRow) memoryRow {
+	clone := make(memoryRow, len(row))
+	for column, value := range row {
+		clone[column] = value
+	}
+	return clone
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
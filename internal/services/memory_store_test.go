@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// selectRows runs a Select against the store and decodes the result
+func selectRows(t *testing.T, store Store, table string, q *Query) []map[string]interface{} {
+	t.Helper()
+
+	data, err := store.Select(context.Background(), table, q)
+	if err != nil {
+		t.Fatalf("select %s: %v", table, err)
+	}
+
+	var rows []map[string]interface{}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		t.Fatalf("decode %s: %v", table, err)
+	}
+	return rows
+}
+
+func TestMemoryStore_InsertAppliesDefaults(t *testing.T) {
+	store := NewMemoryStore()
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	rows := selectRows(t, store, "rooms", Where().Eq("id", roomID.String()))
+	if len(rows) != 1 {
+		t.Fatalf("expected 1 room, got %d", len(rows))
+	}
+
+	room := rows[0]
+	if room["max_questions"] != float64(20) || room["current_question"] != float64(0) {
+		t.Errorf("column defaults not applied: %v", room)
+	}
+	if room["created_at"] == nil || room["updated_at"] == nil {
+		t.Errorf("timestamps not set: %v", room)
+	}
+}
+
+func TestMemoryStore_Filters(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for _, status := range []string{"pending", "accepted", "declined"} {
+		_, err := store.Insert(ctx, "friends", map[string]interface{}{
+			"user_id":   uuid.New().String(),
+			"friend_id": uuid.New().String(),
+			"status":    status,
+		})
+		AssertNoError(t, err, "insert friend")
+	}
+
+	tests := map[string]struct {
+		query *Query
+		want  int
+	}{
+		"nil query matches everything": {nil, 3},
+		"equality":                     {Where(WithStatus("accepted")), 1},
+		"in":                           {NewQuery().In("status", []string{"pending", "declined"}), 2},
+		"empty in matches nothing":     {NewQuery().In("status", []string{}), 0},
+		"not in":                       {NewQuery().NotIn("status", []string{"pending"}), 2},
+		"empty not in is a no-op":      {NewQuery().NotIn("status", nil), 3},
+		"less than":                    {NewQuery().Lt("status", "declined"), 1},
+		"search is case-insensitive":   {NewQuery().SearchIn("ACCEPT", "status"), 1},
+		"page":                         {NewQuery().Page(2, 2), 1},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			count, err := store.Count(ctx, "friends", tt.query)
+			AssertNoError(t, err, "count")
+			if tt.query != nil && tt.query.Limit > 0 {
+				count = len(selectRows(t, store, "friends", tt.query))
+			}
+			if count != tt.want {
+				t.Errorf("got %d rows, want %d", count, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_NullFilter(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	guest := CreateTestUser(t, store, "guest", "Guest", true)
+
+	CreateTestRoom(t, store, owner.ID, "en")
+	joined := CreateTestRoom(t, store, owner.ID, "en")
+	_, err := store.Update(ctx, "rooms", Where().Eq("id", joined.String()), map[string]interface{}{"guest_id": guest.ID.String()})
+	AssertNoError(t, err, "update room")
+
+	count, err := store.Count(ctx, "rooms", Where().Eq("guest_id", nil))
+	AssertNoError(t, err, "count")
+	if count != 1 {
+		t.Errorf("expected 1 room without guest, got %d", count)
+	}
+}
+
+func TestMemoryStore_OrderAndProjection(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	for _, key := range []string{"b", "c", "a"} {
+		_, err := store.Insert(ctx, "categories", map[string]interface{}{"key": key, "label": strings.ToUpper(key)})
+		AssertNoError(t, err, "insert category")
+	}
+
+	rows := selectRows(t, store, "categories", NewQuery().Select("key").OrderBy("key", false).Page(2, 0))
+	if len(rows) != 2 || rows[0]["key"] != "c" || rows[1]["key"] != "b" {
+		t.Fatalf("unexpected order: %v", rows)
+	}
+	if _, ok := rows[0]["label"]; ok {
+		t.Errorf("unselected column returned: %v", rows[0])
+	}
+}
+
+func TestMemoryStore_UniqueConstraint(t *testing.T) {
+	store := NewMemoryStore()
+	CreateTestCategory(t, store, "romance")
+
+	_, err := store.Insert(context.Background(), "categories", map[string]interface{}{"key": "romance", "label": "Again"})
+	if err == nil {
+		t.Fatal("expected unique violation")
+	}
+	if !isConstraintViolation(err) {
+		t.Errorf("error should look like a Postgres unique violation: %v", err)
+	}
+}
+
+func TestMemoryStore_DeleteCascades(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	_, err := store.Insert(ctx, "room_join_requests", map[string]interface{}{
+		"room_id": roomID.String(),
+		"user_id": uuid.New().String(),
+	})
+	AssertNoError(t, err, "insert join request")
+
+	AssertNoError(t, store.Delete(ctx, "users", Where().Eq("id", owner.ID.String())), "delete user")
+
+	for _, table := range []string{"rooms", "room_join_requests"} {
+		count, err := store.Count(ctx, table, nil)
+		AssertNoError(t, err, "count")
+		if count != 0 {
+			t.Errorf("%s: expected cascade delete, %d rows left", table, count)
+		}
+	}
+}
+
+func TestMemoryStore_ViewsJoinBaseTables(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	var room models.RoomWithPlayers
+	err := NewBaseService(store, "Test").QuerySingleRecord(ctx, "rooms_with_players", Where().Eq("id", roomID.String()), &room)
+	AssertNoError(t, err, "query view")
+	if room.OwnerUsername == nil || *room.OwnerUsername != owner.Username {
+		t.Errorf("owner username not joined: %+v", room.OwnerUsername)
+	}
+
+	if _, err := store.Insert(ctx, "rooms_with_players", map[string]interface{}{"name": "x"}); err == nil {
+		t.Error("views must reject writes")
+	}
+}
+
+func TestBaseService_QuerySingleRecordNotFound(t *testing.T) {
+	base := NewBaseService(NewMemoryStore(), "Test")
+
+	var user models.User
+	err := base.GetSingleRecord(context.Background(), "users", uuid.New(), &user)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryStore_WithTx(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	CreateTestCategory(t, store, "kept")
+
+	t.Run("rolls back every write on error", func(t *testing.T) {
+		err := store.WithTx(ctx, func(tx Store) error {
+			if _, err := tx.Insert(ctx, "categories", map[string]interface{}{"key": "discarded", "label": "Discarded"}); err != nil {
+				return err
+			}
+			if err := tx.Delete(ctx, "categories", Where().Eq("key", "kept")); err != nil {
+				return err
+			}
+			return errors.New("abort")
+		})
+		if err == nil || err.Error() != "abort" {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+
+		rows := selectRows(t, store, "categories", nil)
+		if len(rows) != 1 || rows[0]["key"] != "kept" {
+			t.Errorf("rollback did not restore the table: %v", rows)
+		}
+	})
+
+	t.Run("commits nested transactions", func(t *testing.T) {
+		err := store.WithTx(ctx, func(tx Store) error {
+			return tx.WithTx(ctx, func(inner Store) error {
+				_, err := inner.Insert(ctx, "categories", map[string]interface{}{"key": "committed", "label": "Committed"})
+				return err
+			})
+		})
+		AssertNoError(t, err, "with tx")
+
+		count, err := store.Count(ctx, "categories", nil)
+		AssertNoError(t, err, "count")
+		if count != 2 {
+			t.Errorf("expected 2 categories after commit, got %d", count)
+		}
+	})
+
+	t.Run("a failed nested call only undoes its own writes", func(t *testing.T) {
+		err := store.WithTx(ctx, func(tx Store) error {
+			if _, err := tx.Insert(ctx, "categories", map[string]interface{}{"key": "outer", "label": "Outer"}); err != nil {
+				return err
+			}
+			nestedErr := tx.WithTx(ctx, func(inner Store) error {
+				if _, err := inner.Insert(ctx, "categories", map[string]interface{}{"key": "inner", "label": "Inner"}); err != nil {
+					return err
+				}
+				return errors.New("abort")
+			})
+			AssertError(t, nestedErr, "nested error")
+			return nil
+		})
+		AssertNoError(t, err, "with tx")
+
+		count, err := store.Count(ctx, "categories", Where().In("key", []string{"outer", "inner"}))
+		AssertNoError(t, err, "count")
+		AssertEqual(t, 1, count, "only the outer insert is kept")
+	})
+}
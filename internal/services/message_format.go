@@ -0,0 +1,534 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// MessageArgs are the named values of a message's placeholders
+type MessageArgs map[string]interface{}
+
+// pluralCategories are the CLDR plural categories, indexed by plural.Form
+var pluralCategories = [...]string{
+	plural.Other: "other",
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+}
+
+// message is a parsed ICU MessageFormat string
+// Supported: {name}, {name, number}, {name, plural, ...}, {name, selectordinal, ...} (with
+// =N cases, CLDR categories, offset: and #) and {name, select, ...}. Apostrophes quote
+// braces and # as in ICU ('{' is a literal brace and a doubled apostrophe is one apostrophe);
+// any other apostrophe is plain text, so French elisions need no escaping.
+type message struct {
+	nodes []messageNode
+}
+
+// messageNode is a piece of a message: text, a placeholder or a plural/select
+type messageNode interface {
+	format(b *strings.Builder, tag language.Tag, args MessageArgs, pound *float64)
+	placeholders(names map[string]bool)
+}
+
+type textNode string
+
+type argumentNode struct {
+	name string
+}
+
+// poundNode is # inside a plural: the plural's value minus its offset
+type poundNode struct{}
+
+type pluralNode struct {
+	name    string
+	ordinal bool
+	offset  float64
+	cases   map[string][]messageNode // "=N" or a CLDR category
+}
+
+type selectNode struct {
+	name  string
+	cases map[string][]messageNode
+}
+
+// parseMessage parses an ICU MessageFormat string
+func parseMessage(source string) (*message, error) {
+	p := &messageParser{src: []rune(source)}
+	nodes, err := p.parseNodes(false, false)
+	if err != nil {
+		return nil, err
+	}
+	return &message{nodes: nodes}, nil
+}
+
+// Format renders the message for a language
+// Placeholders without a value are left as {name}; plurals without a numeric value use "other".
+func (m *message) Format(lang string, args MessageArgs) string {
+	var b strings.Builder
+	tag := language.Make(lang)
+	for _, node := range m.nodes {
+		node.format(&b, tag, args, nil)
+	}
+	return b.String()
+}
+
+// Placeholders returns the sorted names of the arguments the message uses
+func (m *message) Placeholders() []string {
+	names := make(map[string]bool)
+	for _, node := range m.nodes {
+		node.placeholders(names)
+	}
+	result := make([]string, 0, len(names))
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (n textNode) format(b *strings.Builder, _ language.Tag, _ MessageArgs, _ *float64) {
+	b.WriteString(string(n))
+}
+
+func (n textNode) placeholders(map[string]bool) {}
+
+func (n argumentNode) format(b *strings.Builder, _ language.Tag, args MessageArgs, _ *float64) {
+	value, ok := args[n.name]
+	if !ok {
+		b.WriteString("{" + n.name + "}")
+		return
+	}
+	if _, isText := value.(string); !isText {
+		if number, ok := toNumber(value); ok {
+			b.WriteString(formatNumber(number))
+			return
+		}
+	}
+	fmt.Fprint(b, value)
+}
+
+func (n argumentNode) placeholders(names map[string]bool) {
+	names[n.name] = true
+}
+
+func (poundNode) format(b *strings.Builder, _ language.Tag, _ MessageArgs, pound *float64) {
+	if pound == nil {
+		b.WriteString("#")
+		return
+	}
+	b.WriteString(formatNumber(*pound))
+}
+
+func (poundNode) placeholders(map[string]bool) {}
+
+func (n *pluralNode) format(b *strings.Builder, tag language.Tag, args MessageArgs, _ *float64) {
+	number, ok := toNumber(args[n.name])
+	if !ok {
+		formatNodes(b, n.cases["other"], tag, args, nil)
+		return
+	}
+
+	value := number - n.offset
+	nodes, exact := n.cases["="+formatNumber(number)]
+	if !exact {
+		rules := plural.Cardinal
+		if n.ordinal {
+			rules = plural.Ordinal
+		}
+		nodes, ok = n.cases[pluralCategories[matchPlural(rules, tag, value)]]
+		if !ok {
+			nodes = n.cases["other"]
+		}
+	}
+	formatNodes(b, nodes, tag, args, &value)
+}
+
+func (n *pluralNode) placeholders(names map[string]bool) {
+	names[n.name] = true
+	for _, nodes := range n.cases {
+		for _, node := range nodes {
+			node.placeholders(names)
+		}
+	}
+}
+
+func (n *selectNode) format(b *strings.Builder, tag language.Tag, args MessageArgs, pound *float64) {
+	nodes, ok := n.cases[fmt.Sprint(args[n.name])]
+	if !ok {
+		nodes = n.cases["other"]
+	}
+	formatNodes(b, nodes, tag, args, pound)
+}
+
+func (n *selectNode) placeholders(names map[string]bool) {
+	names[n.name] = true
+	for _, nodes := range n.cases {
+		for _, node := range nodes {
+			node.placeholders(names)
+		}
+	}
+}
+
+// formatNodes renders the nodes of a plural or select case
+func formatNodes(b *strings.Builder, nodes []messageNode, tag language.Tag, args MessageArgs, pound *float64) {
+	for _, node := range nodes {
+		node.format(b, tag, args, pound)
+	}
+}
+
+// matchPlural returns the CLDR plural form of a number, from its decimal operands
+func matchPlural(rules *plural.Rules, tag language.Tag, number float64) plural.Form {
+	digits := strconv.FormatFloat(math.Abs(number), 'f', -1, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+	trimmed := strings.TrimRight(fraction, "0")
+
+	i, _ := strconv.Atoi(lastDigits(integer, 7))
+	f, _ := strconv.Atoi(lastDigits(fraction, 7))
+	t, _ := strconv.Atoi(lastDigits(trimmed, 7))
+	return rules.MatchPlural(tag, i, len(fraction), len(trimmed), f, t)
+}
+
+// lastDigits keeps the last n digits of a number (plural rules only look at the low digits)
+func lastDigits(digits string, n int) string {
+	if len(digits) > n {
+		return digits[len(digits)-n:]
+	}
+	return digits
+}
+
+// toNumber converts a plural or number argument to a float64
+func toNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// formatNumber prints a number without exponent or trailing zeros
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// messageParser is a recursive descent parser for ICU MessageFormat
+type messageParser struct {
+	src []rune
+	pos int
+}
+
+// parseNodes reads text and arguments up to the end of the string, or up to the } closing a
+// case when nested; # is a node inside plural cases
+func (p *messageParser) parseNodes(nested, inPlural bool) ([]messageNode, error) {
+	var nodes []messageNode
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			p.parseQuote(&text, inPlural)
+		case r == '{':
+			flush()
+			p.pos++
+			node, err := p.parseArgument(inPlural)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, node)
+		case r == '}':
+			if !nested {
+				return nil, fmt.Errorf("unmatched } at %d", p.pos)
+			}
+			flush()
+			p.pos++
+			return nodes, nil
+		case r == '#' && inPlural:
+			flush()
+			p.pos++
+			nodes = append(nodes, poundNode{})
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+
+	if nested {
+		return nil, fmt.Errorf("unclosed { at the end of the message")
+	}
+	flush()
+	return nodes, nil
+}
+
+// parseQuote reads an apostrophe: doubled, it is one apostrophe; '{...' quotes up to the next lone
+// apostrophe, and any other apostrophe is text
+func (p *messageParser) parseQuote(text *strings.Builder, inPlural bool) {
+	p.pos++
+	if p.pos >= len(p.src) {
+		text.WriteRune('\'')
+		return
+	}
+	next := p.src[p.pos]
+	if next == '\'' {
+		text.WriteRune('\'')
+		p.pos++
+		return
+	}
+	if next != '{' && next != '}' && !(next == '#' && inPlural) {
+		text.WriteRune('\'')
+		return
+	}
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		p.pos++
+		if r != '\'' {
+			text.WriteRune(r)
+			continue
+		}
+		if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+			text.WriteRune('\'')
+			p.pos++
+			continue
+		}
+		return
+	}
+}
+
+// parseArgument reads an argument after its {
+func (p *messageParser) parseArgument(inPlural bool) (messageNode, error) {
+	p.skipSpace()
+	name := p.readWord()
+	if name == "" {
+		return nil, fmt.Errorf("expected an argument name at %d", p.pos)
+	}
+	p.skipSpace()
+	if p.consume('}') {
+		return argumentNode{name: name}, nil
+	}
+	if !p.consume(',') {
+		return nil, fmt.Errorf("expected , or } after %q at %d", name, p.pos)
+	}
+
+	p.skipSpace()
+	kind := p.readWord()
+	p.skipSpace()
+	switch kind {
+	case "number":
+		// A style (", integer") is accepted and ignored
+		if p.consume(',') {
+			p.skipSpace()
+			p.readWord()
+			p.skipSpace()
+		}
+		if !p.consume('}') {
+			return nil, fmt.Errorf("expected } after {%s, number at %d", name, p.pos)
+		}
+		return argumentNode{name: name}, nil
+	case "plural", "selectordinal":
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected , after {%s, %s at %d", name, kind, p.pos)
+		}
+		node := &pluralNode{name: name, ordinal: kind == "selectordinal"}
+		p.skipSpace()
+		if strings.HasPrefix(string(p.src[p.pos:]), "offset:") {
+			p.pos += len("offset:")
+			p.skipSpace()
+			offset, err := strconv.ParseFloat(p.readWord(), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid offset in {%s at %d", name, p.pos)
+			}
+			node.offset = offset
+		}
+		cases, err := p.parseCases(name, true)
+		if err != nil {
+			return nil, err
+		}
+		node.cases = cases
+		return node, nil
+	case "select":
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected , after {%s, select at %d", name, p.pos)
+		}
+		cases, err := p.parseCases(name, inPlural)
+		if err != nil {
+			return nil, err
+		}
+		return &selectNode{name: name, cases: cases}, nil
+	}
+	return nil, fmt.Errorf("unknown argument type %q in {%s at %d", kind, name, p.pos)
+}
+
+// parseCases reads the "selector {message}" cases of a plural or select up to its }
+// Plural selectors are =N or a CLDR category; both kinds need an "other" case.
+func (p *messageParser) parseCases(name string, isPlural bool) (map[string][]messageNode, error) {
+	cases := make(map[string][]messageNode)
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+		selector := p.readSelector()
+		if selector == "" {
+			return nil, fmt.Errorf("expected a case or } in {%s at %d", name, p.pos)
+		}
+		if isPlural && !validPluralSelector(selector) {
+			return nil, fmt.Errorf("invalid plural case %q in {%s", selector, name)
+		}
+		if _, duplicate := cases[selector]; duplicate {
+			return nil, fmt.Errorf("duplicate case %q in {%s", selector, name)
+		}
+		p.skipSpace()
+		if !p.consume('{') {
+			return nil, fmt.Errorf("expected { after case %q in {%s at %d", selector, name, p.pos)
+		}
+		nodes, err := p.parseNodes(true, isPlural)
+		if err != nil {
+			return nil, err
+		}
+		cases[selector] = nodes
+	}
+	if _, ok := cases["other"]; !ok {
+		return nil, fmt.Errorf("missing the other case in {%s", name)
+	}
+	return cases, nil
+}
+
+// validPluralSelector reports whether s is =N or a CLDR plural category
+func validPluralSelector(s string) bool {
+	if number, ok := strings.CutPrefix(s, "="); ok {
+		_, err := strconv.ParseFloat(number, 64)
+		return err == nil
+	}
+	for _, category := range pluralCategories {
+		if s == category {
+			return true
+		}
+	}
+	return false
+}
+
+// readWord reads an argument name, type or number
+func (p *messageParser) readWord() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// readSelector reads a case selector: a word, or = followed by a number
+func (p *messageParser) readSelector() string {
+	if p.consume('=') {
+		return "=" + p.readWord()
+	}
+	return p.readWord()
+}
+
+// consume skips r if it is the next rune
+func (p *messageParser) consume(r rune) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == r {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *messageParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+// TranslationProblem is a message the lint refuses
+type TranslationProblem struct {
+	Lang    string `json:"lang"`
+	Key     string `json:"key"`
+	Problem string `json:"problem"`
+}
+
+// LintTranslations checks translations (language -> key -> message): every message must parse,
+// and use the same placeholders as the base language's version of the key
+func LintTranslations(translations map[string]map[string]string, base string) []TranslationProblem {
+	basePlaceholders := make(map[string][]string)
+	for key, value := range translations[base] {
+		if msg, err := parseMessage(value); err == nil {
+			basePlaceholders[key] = msg.Placeholders()
+		}
+	}
+
+	var problems []TranslationProblem
+	for _, lang := range sortedKeys(translations) {
+		for _, key := range sortedKeys(translations[lang]) {
+			msg, err := parseMessage(translations[lang][key])
+			if err != nil {
+				problems = append(problems, TranslationProblem{Lang: lang, Key: key, Problem: err.Error()})
+				continue
+			}
+			expected, ok := basePlaceholders[key]
+			if !ok || lang == base {
+				continue
+			}
+			if got := msg.Placeholders(); !slices.Equal(got, expected) {
+				problems = append(problems, TranslationProblem{
+					Lang:    lang,
+					Key:     key,
+					Problem: fmt.Sprintf("placeholders %s differ from %s's %s", formatPlaceholders(got), base, formatPlaceholders(expected)),
+				})
+			}
+		}
+	}
+	return problems
+}
+
+// formatPlaceholders prints placeholder names as {a} {b}
+func formatPlaceholders(names []string) string {
+	if len(names) == 0 {
+		return "(none)"
+	}
+	return "{" + strings.Join(names, "} {") + "}"
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestMessageFormat tests placeholders, CLDR plurals, select and quoting
+func TestMessageFormat(t *testing.T) {
+	requests := "{count, plural, =0 {No requests} one {# pending request} other {# pending requests}}"
+	tests := []struct {
+		name     string
+		lang     string
+		source   string
+		args     MessageArgs
+		expected string
+	}{
+		{"placeholders", "en", "Question {current} of {total}", MessageArgs{"current": 3, "total": 20}, "Question 3 of 20"},
+		{"missing placeholder kept", "en", "Hi {username}", nil, "Hi {username}"},
+		{"exact case", "en", requests, MessageArgs{"count": 0}, "No requests"},
+		{"english one", "en", requests, MessageArgs{"count": 1}, "1 pending request"},
+		{"english other", "en", requests, MessageArgs{"count": 2}, "2 pending requests"},
+		{"english decimal is other", "en", "{n, plural, one {# hour} other {# hours}}", MessageArgs{"n": 1.5}, "1.5 hours"},
+		{"french one includes 1.5", "fr", "{n, plural, one {# heure} other {# heures}}", MessageArgs{"n": 1.5}, "1.5 heure"},
+		{"french zero is one", "fr", "{n, plural, one {# demande} other {# demandes}}", MessageArgs{"n": 0}, "0 demande"},
+		{"russian few", "ru", "{n, plural, one {# вопрос} few {# вопроса} many {# вопросов} other {# вопроса}}", MessageArgs{"n": 23}, "23 вопроса"},
+		{"russian many", "ru", "{n, plural, one {# вопрос} few {# вопроса} many {# вопросов} other {# вопроса}}", MessageArgs{"n": 11}, "11 вопросов"},
+		{"japanese has no one", "ja", "{n, plural, one {one} other {# 件}}", MessageArgs{"n": 1}, "1 件"},
+		{"offset", "en", "{n, plural, offset:1 =0 {Nobody} =1 {You} one {You and # other} other {You and # others}}", MessageArgs{"n": 3}, "You and 2 others"},
+		{"ordinal", "en", "{n, selectordinal, one {#st} two {#nd} few {#rd} other {#th}}", MessageArgs{"n": 22}, "22nd"},
+		{"select", "en", "{gender, select, female {She} male {He} other {They}} answered", MessageArgs{"gender": "female"}, "She answered"},
+		{"select other", "en", "{gender, select, female {She} other {They}} answered", nil, "They answered"},
+		{"pound in select in plural", "en", "{n, plural, other {{who, select, other {# by {who}}}}}", MessageArgs{"n": 4, "who": "Ann"}, "4 by Ann"},
+		{"elision", "fr", "l'équipe de {name}", MessageArgs{"name": "Ana"}, "l'équipe de Ana"},
+		{"quoted braces", "en", "Use '{name}' or ''{name}''", MessageArgs{"name": "x"}, "Use {name} or 'x'"},
+		{"number argument", "en", "{n, number} points", MessageArgs{"n": 2.50}, "2.5 points"},
+		{"text argument", "en", "Code {code}", MessageArgs{"code": "007"}, "Code 007"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := parseMessage(tt.source)
+			AssertNoError(t, err, "parse")
+			AssertEqual(t, tt.expected, msg.Format(tt.lang, tt.args), "format")
+		})
+	}
+}
+
+// TestMessageFormat_Invalid tests messages that do not parse
+func TestMessageFormat_Invalid(t *testing.T) {
+	for _, source := range []string{
+		"Hello {name",
+		"Hello name}",
+		"{}",
+		"{n, plural, one {#}}",
+		"{n, plural, few {a} lots {b} other {c}}",
+		"{n, plural, one {a} one {b} other {c}}",
+		"{n, date}",
+		"{n, select, other {unclosed}",
+	} {
+		_, err := parseMessage(source)
+		AssertError(t, err, source)
+	}
+}
+
+// TestLintTranslations tests the placeholder comparison with the base language
+func TestLintTranslations(t *testing.T) {
+	problems := LintTranslations(map[string]map[string]string{
+		"en": {"progress": "Question {current} of {total}", "hello": "Hi {username}"},
+		"fr": {"progress": "Question {current} sur {max}", "hello": "Salut {username}", "extra": "{broken"},
+		"ja": {"progress": "{total} 問中 {current} 問目"},
+	}, "en")
+
+	AssertEqual(t, 2, len(problems), "problems")
+	AssertEqual(t, "fr extra", problems[0].Lang+" "+problems[0].Key, "parse error first (sorted by key)")
+	AssertEqual(t, "fr progress", problems[1].Lang+" "+problems[1].Key, "placeholder mismatch")
+	AssertTrue(t, strings.Contains(problems[1].Problem, "{current} {max}"), "problem names the placeholders")
+}
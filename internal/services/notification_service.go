@@ -2,29 +2,28 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
-	supabase "github.com/supabase-community/supabase-go"
 
 	"github.com/hekigan/couples/internal/models"
 )
 
 type NotificationService struct {
 	*BaseService
-	client *supabase.Client
+	realtimeService *RealtimeService
 }
 
-func NewNotificationService(client *supabase.Client) *NotificationService {
+func NewNotificationService(store Store, realtimeService *RealtimeService) *NotificationService {
 	return &NotificationService{
-		BaseService: NewBaseService(client, "NotificationService"),
-		client:      client,
+		BaseService:     NewBaseService(store, "NotificationService"),
+		realtimeService: realtimeService,
 	}
 }
 
-// CreateNotification creates a new notification
+// CreateNotification creates a new notification and pushes it to the user's open streams
+// along with their unread count
 func (s *NotificationService) CreateNotification(ctx context.Context, notification *models.Notification) error {
 	notification.ID = uuid.New()
 	notification.CreatedAt = time.Now()
@@ -40,7 +39,23 @@ func (s *NotificationService) CreateNotification(ctx context.Context, notificati
 		"created_at": notification.CreatedAt,
 	}
 
-	return s.BaseService.InsertRecord(ctx, "notifications", data)
+	if err := s.BaseService.InsertRecord(ctx, "notifications", data); err != nil {
+		return err
+	}
+
+	unread, err := s.GetUnreadCount(ctx, notification.UserID)
+	if err != nil {
+		// The notification is stored: the client refreshes the count itself when it's missing
+		unread = -1
+	}
+	s.realtimeService.BroadcastToUser(notification.UserID, RealtimeEvent{
+		Type: "notification",
+		Data: map[string]interface{}{
+			"notification": notification,
+			"unread_count": unread,
+		},
+	})
+	return nil
 }
 
 // GetUserNotifications gets all notifications for a user
@@ -122,24 +137,31 @@ func (s *NotificationService) CreateRoomInvitation(ctx context.Context, invitati
 
 // GetRoomInvitation gets a specific room invitation
 func (s *NotificationService) GetRoomInvitation(ctx context.Context, roomID, inviteeID uuid.UUID) (*models.RoomInvitation, error) {
-	// Custom query with multiple filters - BaseService doesn't have a GetSingleRecordWithFilters method
-	data, _, err := s.client.From("room_invitations").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
-		Eq("invitee_id", inviteeID.String()).
-		Single().
-		Execute()
+	q := Where(WithRoomID(roomID)).Eq("invitee_id", inviteeID.String())
 
-	if err != nil {
+	var invitation models.RoomInvitation
+	if err := s.BaseService.QuerySingleRecord(ctx, "room_invitations", q, &invitation); err != nil {
 		return nil, fmt.Errorf("invitation not found: %w", err)
 	}
 
-	var invitation models.RoomInvitation
-	if err := json.Unmarshal(data, &invitation); err != nil {
-		return nil, fmt.Errorf("failed to parse invitation: %w", err)
+	return &invitation, nil
+}
+
+// DeleteStaleInvitations deletes room invitations still pending after olderThan
+func (s *NotificationService) DeleteStaleInvitations(ctx context.Context, olderThan time.Duration) (int, error) {
+	q := Where().Eq("status", models.InvitationStatusPending).Lt("created_at", time.Now().Add(-olderThan))
+	count, err := s.store.Count(ctx, "room_invitations", q)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
 	}
 
-	return &invitation, nil
+	if err := s.store.Delete(ctx, "room_invitations", q); err != nil {
+		return 0, fmt.Errorf("failed to delete stale invitations: %w", err)
+	}
+	return count, nil
 }
 
 // CancelRoomInvitation cancels a room invitation
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/hekigan/couples/internal/models"
+)
+
+// TestCreateNotification_PushesToUser tests that every new notification reaches the user's streams
+func TestCreateNotification_PushesToUser(t *testing.T) {
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	realtime := NewRealtimeService()
+	service := NewNotificationService(store, realtime)
+
+	user := CreateTestUser(t, store, "notified_user", "Notified User", false)
+	stream := realtime.Subscribe(uuid.Nil, user.ID)
+	defer realtime.Unsubscribe(stream.ID)
+
+	// A burst: each notification is pushed, none is skipped
+	for _, title := range []string{"First", "Second"} {
+		err := service.CreateNotification(ctx, &models.Notification{
+			UserID: user.ID,
+			Type:   models.NotificationTypeRoomInvite,
+			Title:  title,
+		})
+		AssertNoError(t, err, "create notification")
+	}
+
+	for i, title := range []string{"First", "Second"} {
+		event := <-stream.Channel
+		AssertEqual(t, "notification", event.Type, "Event type should be notification")
+
+		data := event.Data.(map[string]interface{})
+		AssertEqual(t, title, data["notification"].(*models.Notification).Title, "Notifications should arrive in order")
+		AssertEqual(t, i+1, data["unread_count"], "Unread count should be included")
+	}
+}
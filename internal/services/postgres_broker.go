@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// realtimeChannel is the LISTEN/NOTIFY channel realtime events travel on
+const realtimeChannel = "realtime_events"
+
+// notifyPayloadLimit keeps NOTIFY payloads under PostgreSQL's 8000 byte limit;
+// larger messages (typically HTML fragments) are stored in realtime_payloads and sent by reference
+const notifyPayloadLimit = 7900
+
+// payloadRetention is how long a stored payload stays readable by the other instances
+const payloadRetention = 5 * time.Minute
+
+// PostgresBroker fans realtime events out to every instance through LISTEN/NOTIFY
+// One pooled connection is held for listening while the broker runs. Events published while
+// that connection is being re-established are not delivered: clients catch up through their
+// next reconnect (Last-Event-ID replay, or a reload).
+type PostgresBroker struct {
+	store  *PostgresStore
+	listen func(ctx context.Context, db *sql.DB, channel string, handle func(payload string)) error
+
+	mu        sync.Mutex
+	lastSweep time.Time
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// notification is a NOTIFY payload: the message itself, or the id of the
+// realtime_payloads row holding it
+type notification struct {
+	Message *BrokerMessage `json:"message,omitempty"`
+	Ref     string         `json:"ref,omitempty"`
+}
+
+// NewPostgresBroker creates a broker on store's connection pool
+func NewPostgresBroker(store *PostgresStore) *PostgresBroker {
+	return newPostgresBroker(store, listenPgx)
+}
+
+func newPostgresBroker(store *PostgresStore, listen func(ctx context.Context, db *sql.DB, channel string, handle func(payload string)) error) *PostgresBroker {
+	return &PostgresBroker{store: store, listen: listen}
+}
+
+// Publish sends msg with NOTIFY, storing it first when it is too large for a payload
+func (b *PostgresBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	data, err := json.Marshal(notification{Message: &msg})
+	if err != nil {
+		return fmt.Errorf("failed to encode realtime event: %w", err)
+	}
+
+	if len(data) > notifyPayloadLimit {
+		ref := uuid.New().String()
+		if _, err := b.store.Insert(ctx, "realtime_payloads", map[string]interface{}{
+			"id":   ref,
+			"data": string(data),
+		}); err != nil {
+			return fmt.Errorf("failed to store realtime payload: %w", err)
+		}
+		if data, err = json.Marshal(notification{Ref: ref}); err != nil {
+			return fmt.Errorf("failed to encode realtime event: %w", err)
+		}
+		b.sweepPayloads(ctx)
+	}
+
+	if _, err := b.store.DB().ExecContext(ctx, "SELECT pg_notify($1, $2)", realtimeChannel, string(data)); err != nil {
+		return fmt.Errorf("failed to publish realtime event: %w", err)
+	}
+	return nil
+}
+
+// Start listens in the background, reconnecting with backoff when the connection drops
+func (b *PostgresBroker) Start(deliver func(BrokerMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cancel != nil {
+		return errors.New("postgres realtime broker already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.done = make(chan struct{})
+	go b.run(ctx, deliver)
+	return nil
+}
+
+// Close stops listening and waits for the listener to return
+func (b *PostgresBroker) Close() error {
+	b.mu.Lock()
+	cancel, done := b.cancel, b.done
+	b.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	<-done
+	return nil
+}
+
+// run keeps a listener open until ctx is cancelled
+func (b *PostgresBroker) run(ctx context.Context, deliver func(BrokerMessage)) {
+	defer close(b.done)
+
+	const maxBackoff = 30 * time.Second
+	backoff := time.Second
+	for {
+		err := b.listen(ctx, b.store.DB(), realtimeChannel, func(payload string) {
+			backoff = time.Second
+			b.handle(ctx, payload, deliver)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		fmt.Printf("⚠️ Warning: realtime listener stopped (%v), reconnecting in %s\n", err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// handle decodes a notification, fetching a stored payload, and delivers it
+func (b *PostgresBroker) handle(ctx context.Context, payload string, deliver func(BrokerMessage)) {
+	var n notification
+	if err := json.Unmarshal([]byte(payload), &n); err != nil {
+		fmt.Printf("⚠️ Warning: ignoring malformed realtime notification: %v\n", err)
+		return
+	}
+
+	if n.Ref != "" {
+		stored, err := b.loadPayload(ctx, n.Ref)
+		if err != nil {
+			fmt.Printf("⚠️ Warning: %v\n", err)
+			return
+		}
+		n = stored
+	}
+
+	if n.Message != nil {
+		deliver(*n.Message)
+	}
+}
+
+// loadPayload reads a message stored by Publish
+func (b *PostgresBroker) loadPayload(ctx context.Context, ref string) (notification, error) {
+	data, err := b.store.Select(ctx, "realtime_payloads", Where().Eq("id", ref).Select("data"))
+	if err != nil {
+		return notification{}, fmt.Errorf("failed to load realtime payload %s: %w", ref, err)
+	}
+
+	var rows []struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return notification{}, fmt.Errorf("failed to parse realtime payload %s: %w", ref, err)
+	}
+	if len(rows) == 0 {
+		return notification{}, fmt.Errorf("realtime payload %s has expired", ref)
+	}
+
+	var n notification
+	if err := json.Unmarshal([]byte(rows[0].Data), &n); err != nil {
+		return notification{}, fmt.Errorf("failed to parse realtime payload %s: %w", ref, err)
+	}
+	return n, nil
+}
+
+// sweepPayloads deletes stored payloads every instance has had time to read, at most once a minute
+func (b *PostgresBroker) sweepPayloads(ctx context.Context) {
+	b.mu.Lock()
+	due := time.Since(b.lastSweep) > time.Minute
+	if due {
+		b.lastSweep = time.Now()
+	}
+	b.mu.Unlock()
+
+	if !due {
+		return
+	}
+	if _, err := b.store.DB().ExecContext(ctx,
+		"DELETE FROM realtime_payloads WHERE created_at < NOW() - make_interval(secs => $1)",
+		payloadRetention.Seconds()); err != nil {
+		fmt.Printf("⚠️ Warning: failed to delete old realtime payloads: %v\n", err)
+	}
+}
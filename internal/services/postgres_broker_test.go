@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestPostgresBroker_Publish(t *testing.T) {
+	ctx := context.Background()
+	log := &recordingLog{}
+	store := NewPostgresStoreFromDB(sql.OpenDB(recordingConnector{log}))
+	defer store.Close()
+	broker := newPostgresBroker(store, nil)
+
+	t.Run("small events are sent inline", func(t *testing.T) {
+		log.reset()
+		err := broker.Publish(ctx, BrokerMessage{RoomID: uuid.New(), Event: RealtimeEvent{ID: 1, Type: "room_update"}})
+		AssertNoError(t, err, "publish")
+		assertStatements(t, log, "SELECT pg_notify($1, $2)")
+	})
+
+	t.Run("large events are sent by reference", func(t *testing.T) {
+		log.reset()
+		err := broker.Publish(ctx, BrokerMessage{RoomID: uuid.New(), Event: RealtimeEvent{
+			ID:   2,
+			Type: "question_drawn",
+			Data: strings.Repeat("<p>question</p>", 1000),
+		}})
+		AssertNoError(t, err, "publish")
+
+		statements := log.all()
+		AssertEqual(t, 3, len(statements), "Payload insert, sweep and notify expected")
+		AssertTrue(t, strings.Contains(statements[0], `INSERT INTO "realtime_payloads"`), "Payload should be stored first")
+		AssertTrue(t, strings.HasPrefix(statements[1], "DELETE FROM realtime_payloads"), "Old payloads should be swept")
+		AssertEqual(t, "SELECT pg_notify($1, $2)", statements[2], "Reference should be notified last")
+	})
+}
+
+func TestPostgresBroker_Deliver(t *testing.T) {
+	store := NewPostgresStoreFromDB(sql.OpenDB(recordingConnector{&recordingLog{}}))
+	defer store.Close()
+
+	want := BrokerMessage{RoomID: uuid.New(), UserID: uuid.New(), Event: RealtimeEvent{ID: 7, Type: "turn_changed"}}
+	payload, err := json.Marshal(notification{Message: &want})
+	AssertNoError(t, err, "encode notification")
+
+	broker := newPostgresBroker(store, func(ctx context.Context, db *sql.DB, channel string, handle func(string)) error {
+		AssertEqual(t, realtimeChannel, channel, "Listener should use the realtime channel")
+		handle("not json")
+		handle(string(payload))
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	delivered := make(chan BrokerMessage, 1)
+	AssertNoError(t, broker.Start(func(msg BrokerMessage) { delivered <- msg }), "start")
+
+	select {
+	case got := <-delivered:
+		AssertEqual(t, want.RoomID, got.RoomID, "Room should round-trip")
+		AssertEqual(t, want.UserID, got.UserID, "User should round-trip")
+		AssertEqual(t, want.Event.ID, got.Event.ID, "Event ID should round-trip")
+		AssertEqual(t, want.Event.Type, got.Event.Type, "Event type should round-trip")
+	case <-time.After(time.Second):
+		t.Fatal("notification was not delivered")
+	}
+
+	AssertError(t, broker.Start(func(BrokerMessage) {}), "Starting twice should fail")
+	AssertNoError(t, broker.Close(), "close")
+}
+
+// TestPostgresBroker_ListenNotify round-trips events between two brokers through a real
+// database; it runs when TEST_DATABASE_URL points at a migrated Postgres (as in CI)
+func TestPostgresBroker_ListenNotify(t *testing.T) {
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	ctx := context.Background()
+
+	open := func() *PostgresStore {
+		store, err := NewPostgresStore(ctx, databaseURL, PostgresPoolConfig{MaxConns: 2})
+		AssertNoError(t, err, "connect")
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+
+	publisher := NewPostgresBroker(open())
+	subscriber := NewPostgresBroker(open())
+
+	delivered := make(chan BrokerMessage, 2)
+	AssertNoError(t, subscriber.Start(func(msg BrokerMessage) { delivered <- msg }), "start")
+	defer subscriber.Close()
+
+	roomID := uuid.New()
+	large := strings.Repeat("<p>question</p>", 1000)
+	// The listener connects in the background: publish until the first event arrives
+	deadline := time.After(10 * time.Second)
+	for received := false; !received; {
+		AssertNoError(t, publisher.Publish(ctx, BrokerMessage{RoomID: roomID, Event: RealtimeEvent{ID: 1, Type: "room_update"}}), "publish")
+		select {
+		case got := <-delivered:
+			AssertEqual(t, roomID, got.RoomID, "Room should round-trip")
+			received = true
+		case <-time.After(200 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("notification was not delivered")
+		}
+	}
+	// Drain duplicates from the retries
+	for len(delivered) > 0 {
+		<-delivered
+	}
+
+	AssertNoError(t, publisher.Publish(ctx, BrokerMessage{RoomID: roomID, Event: RealtimeEvent{ID: 2, Type: "question_drawn", Data: large}}), "publish large")
+	for {
+		select {
+		case got := <-delivered:
+			if got.Event.ID != 2 {
+				continue
+			}
+			AssertEqual(t, large, got.Event.Data, "Stored payload should round-trip")
+			return
+		case <-time.After(5 * time.Second):
+			t.Fatal("large notification was not delivered")
+		}
+	}
+}
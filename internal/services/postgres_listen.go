@@ -0,0 +1,47 @@
+package services
+
+// LISTEN support for PostgresBroker: database/sql has no notification API, so the
+// dedicated connection is unwrapped to the pgx connection underneath.
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// listenPgx LISTENs on channel over a dedicated connection and calls handle with every
+// notification's payload until ctx is done or the connection fails
+func listenPgx(ctx context.Context, db *sql.DB, channel string, handle func(payload string)) error {
+	name, err := quoteIdent(channel)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire listener connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "LISTEN "+name); err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", channel, err)
+	}
+	// Don't hand a listening connection back to the pool
+	defer conn.ExecContext(context.Background(), "UNLISTEN *")
+
+	return conn.Raw(func(driverConn any) error {
+		stdConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		for {
+			n, err := stdConn.Conn().WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+			handle(n.Payload)
+		}
+	})
+}
@@ -0,0 +1,479 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	// Registers the "pgx" database/sql driver
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresDriverName is the database/sql driver PostgresStore opens, registered by pgx's stdlib package
+const PostgresDriverName = "pgx"
+
+// PostgresStore implements Store directly against PostgreSQL through a pooled *sql.DB
+// Unlike SupabaseStore, WithTx runs a real database transaction.
+type PostgresStore struct {
+	db    *sql.DB
+	conn  sqlConn // db, or tx inside WithTx
+	tx    *sql.Tx
+	depth int // nesting level of WithTx, naming the savepoints
+}
+
+// sqlConn is the part of *sql.DB and *sql.Tx PostgresStore uses
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// PostgresPoolConfig sizes the connection pool
+type PostgresPoolConfig struct {
+	MaxConns        int
+	ConnMaxIdleTime time.Duration
+}
+
+// NewPostgresStore opens a connection pool for databaseURL and verifies it with a ping
+func NewPostgresStore(ctx context.Context, databaseURL string, pool PostgresPoolConfig) (*PostgresStore, error) {
+	db, err := sql.Open(PostgresDriverName, databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres pool: %w", err)
+	}
+
+	if pool.MaxConns > 0 {
+		db.SetMaxOpenConns(pool.MaxConns)
+		db.SetMaxIdleConns(pool.MaxConns)
+	}
+	if pool.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+	}
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+
+	return NewPostgresStoreFromDB(db), nil
+}
+
+// NewPostgresStoreFromDB wraps an already opened pool
+func NewPostgresStoreFromDB(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db, conn: db}
+}
+
+// DB returns the underlying connection pool
+func (s *PostgresStore) DB() *sql.DB {
+	return s.db
+}
+
+// Close closes the connection pool
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// Select returns matching rows as a JSON array
+func (s *PostgresStore) Select(ctx context.Context, table string, q *Query) ([]byte, error) {
+	query, args, err := buildSelectSQL(table, q)
+	if err != nil {
+		return nil, err
+	}
+	return s.queryJSON(ctx, query, args)
+}
+
+// Count returns the number of matching rows
+func (s *PostgresStore) Count(ctx context.Context, table string, q *Query) (int, error) {
+	where, args, err := buildWhereSQL(q, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	name, err := quoteIdent(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := s.conn.QueryRowContext(ctx, "SELECT count(*) FROM "+name+where, args...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Insert inserts one row and returns it as a JSON array
+func (s *PostgresStore) Insert(ctx context.Context, table string, data map[string]interface{}) ([]byte, error) {
+	query, args, err := buildInsertSQL(table, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.queryJSON(ctx, query, args)
+}
+
+// Update updates every matching row and returns the updated rows as a JSON array
+func (s *PostgresStore) Update(ctx context.Context, table string, q *Query, data map[string]interface{}) ([]byte, error) {
+	query, args, err := buildUpdateSQL(table, q, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.queryJSON(ctx, query, args)
+}
+
+// Delete removes every matching row
+func (s *PostgresStore) Delete(ctx context.Context, table string, q *Query) error {
+	where, args, err := buildWhereSQL(q, 0)
+	if err != nil {
+		return err
+	}
+
+	name, err := quoteIdent(table)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.conn.ExecContext(ctx, "DELETE FROM "+name+where, args...)
+	return err
+}
+
+// WithTx runs fn in a database transaction, committing when it returns nil and rolling
+// back when it returns an error or panics. Inside a transaction it joins the open one.
+func (s *PostgresStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	if s.tx != nil {
+		return s.withSavepoint(ctx, fn)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(&PostgresStore{db: s.db, conn: tx, tx: tx, depth: 1}); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// withSavepoint runs a nested WithTx: once a statement fails Postgres refuses every
+// other statement of the transaction, so the nested writes get a savepoint to roll back to
+func (s *PostgresStore) withSavepoint(ctx context.Context, fn func(tx Store) error) error {
+	name := fmt.Sprintf("sp_%d", s.depth)
+	if _, err := s.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+
+	if err := fn(&PostgresStore{db: s.db, conn: s.tx, tx: s.tx, depth: s.depth + 1}); err != nil {
+		if _, rollbackErr := s.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rollbackErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback to savepoint failed: %w", rollbackErr))
+		}
+		return err
+	}
+
+	if _, err := s.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// queryJSON runs a statement that yields a single JSON array
+func (s *PostgresStore) queryJSON(ctx context.Context, query string, args []interface{}) ([]byte, error) {
+	var data []byte
+	if err := s.conn.QueryRowContext(ctx, query, args...).Scan(&data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// identPattern restricts table and column names to plain lowercase identifiers
+var identPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+
+// quoteIdent validates and double-quotes an identifier
+func quoteIdent(name string) (string, error) {
+	if !identPattern.MatchString(name) {
+		return "", fmt.Errorf("invalid identifier %q", name)
+	}
+	return `"` + name + `"`, nil
+}
+
+// buildSelectSQL renders a query as a statement returning a JSON array (like PostgREST)
+func buildSelectSQL(table string, q *Query) (string, []interface{}, error) {
+	name, err := quoteIdent(table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	columns := "*"
+	if q != nil && q.Columns != "" && q.Columns != "*" {
+		var quoted []string
+		for _, column := range strings.Split(q.Columns, ",") {
+			c, err := quoteIdent(strings.TrimSpace(column))
+			if err != nil {
+				return "", nil, err
+			}
+			quoted = append(quoted, c)
+		}
+		columns = strings.Join(quoted, ", ")
+	}
+
+	where, args, err := buildWhereSQL(q, 0)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var tail strings.Builder
+	if q != nil {
+		for i, order := range q.Orders {
+			column, err := quoteIdent(order.Column)
+			if err != nil {
+				return "", nil, err
+			}
+			if i == 0 {
+				tail.WriteString(" ORDER BY ")
+			} else {
+				tail.WriteString(", ")
+			}
+			direction, nulls := "DESC", "NULLS LAST"
+			if order.Ascending {
+				direction = "ASC"
+			}
+			if order.NullsFirst {
+				nulls = "NULLS FIRST"
+			}
+			fmt.Fprintf(&tail, "%s %s %s", column, direction, nulls)
+		}
+		if q.Limit > 0 {
+			fmt.Fprintf(&tail, " LIMIT %d", q.Limit)
+		}
+		if q.Offset > 0 {
+			fmt.Fprintf(&tail, " OFFSET %d", q.Offset)
+		}
+	}
+
+	query := fmt.Sprintf("SELECT coalesce(json_agg(t), '[]') FROM (SELECT %s FROM %s%s%s) t", columns, name, where, tail.String())
+	return query, args, nil
+}
+
+// buildInsertSQL renders an INSERT returning the stored row as a JSON array
+func buildInsertSQL(table string, data map[string]interface{}) (string, []interface{}, error) {
+	name, err := quoteIdent(table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(data) == 0 {
+		return returningJSON(fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING *", name)), nil, nil
+	}
+
+	var columns, placeholders []string
+	var args []interface{}
+	for _, key := range sortedKeys(data) {
+		column, err := quoteIdent(key)
+		if err != nil {
+			return "", nil, err
+		}
+		value, err := sqlValue(data[key])
+		if err != nil {
+			return "", nil, fmt.Errorf("column %s: %w", key, err)
+		}
+		columns = append(columns, column)
+		args = append(args, value)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+
+	insert := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING *",
+		name, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	return returningJSON(insert), args, nil
+}
+
+// buildUpdateSQL renders an UPDATE returning the updated rows as a JSON array
+func buildUpdateSQL(table string, q *Query, data map[string]interface{}) (string, []interface{}, error) {
+	name, err := quoteIdent(table)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) == 0 {
+		return "", nil, errors.New("update requires at least one column")
+	}
+
+	var assignments []string
+	var args []interface{}
+	for _, key := range sortedKeys(data) {
+		column, err := quoteIdent(key)
+		if err != nil {
+			return "", nil, err
+		}
+		value, err := sqlValue(data[key])
+		if err != nil {
+			return "", nil, fmt.Errorf("column %s: %w", key, err)
+		}
+		args = append(args, value)
+		assignments = append(assignments, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	where, whereArgs, err := buildWhereSQL(q, len(args))
+	if err != nil {
+		return "", nil, err
+	}
+
+	update := fmt.Sprintf("UPDATE %s SET %s%s RETURNING *", name, strings.Join(assignments, ", "), where)
+	return returningJSON(update), append(args, whereArgs...), nil
+}
+
+// returningJSON wraps a data-modifying statement so it yields its RETURNING rows as a JSON array
+func returningJSON(statement string) string {
+	return "WITH r AS (" + statement + ") SELECT coalesce(json_agg(r), '[]') FROM r"
+}
+
+// buildWhereSQL renders the filters of q; placeholders are numbered after offset existing args
+func buildWhereSQL(q *Query, offset int) (string, []interface{}, error) {
+	if q == nil {
+		return "", nil, nil
+	}
+
+	var conditions []string
+	var args []interface{}
+	placeholder := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", offset+len(args))
+	}
+
+	for _, key := range sortedKeys(q.Filters) {
+		column, err := quoteIdent(key)
+		if err != nil {
+			return "", nil, err
+		}
+		if q.Filters[key] == nil {
+			conditions = append(conditions, column+" IS NULL")
+			continue
+		}
+		conditions = append(conditions, column+" = "+placeholder(filterValue(q.Filters[key])))
+	}
+
+	inList := func(key string, values []string, operator string) (string, error) {
+		column, err := quoteIdent(key)
+		if err != nil {
+			return "", err
+		}
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			placeholders[i] = placeholder(value)
+		}
+		return fmt.Sprintf("%s %s (%s)", column, operator, strings.Join(placeholders, ", ")), nil
+	}
+
+	for _, key := range sortedKeys(q.InFilters) {
+		values := q.InFilters[key]
+		if len(values) == 0 {
+			conditions = append(conditions, "FALSE")
+			continue
+		}
+		condition, err := inList(key, values, "IN")
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, key := range sortedKeys(q.NotInFilters) {
+		values := q.NotInFilters[key]
+		if len(values) == 0 {
+			continue
+		}
+		condition, err := inList(key, values, "NOT IN")
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, key := range sortedKeys(q.LtFilters) {
+		column, err := quoteIdent(key)
+		if err != nil {
+			return "", nil, err
+		}
+		conditions = append(conditions, column+" < "+placeholder(filterValue(q.LtFilters[key])))
+	}
+
+	if q.Search != nil && len(q.Search.Columns) > 0 {
+		pattern := placeholder("%" + q.Search.Term + "%")
+		matches := make([]string, len(q.Search.Columns))
+		for i, key := range q.Search.Columns {
+			column, err := quoteIdent(key)
+			if err != nil {
+				return "", nil, err
+			}
+			matches[i] = column + "::text ILIKE " + pattern
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// sqlValue converts a service value into a driver argument
+// UUIDs and other Stringers are sent as text; slices, maps and structs as JSON (JSONB columns).
+func sqlValue(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return sqlValue(rv.Elem().Interface())
+	}
+
+	switch v := value.(type) {
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, []byte, time.Time:
+		return v, nil
+	case driver.Valuer:
+		return v.Value()
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	}
+
+	return nil, fmt.Errorf("unsupported value type %T", value)
+}
+
+// sortedKeys returns the keys of m in order, so generated SQL is deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBuildSelectSQL(t *testing.T) {
+	q := Where(WithRoomID(uuid.MustParse("11111111-1111-1111-1111-111111111111")), WithStatus("pending")).
+		Select("id,user_id").
+		In("lang_code", []string{"en", "fr"}).
+		NotIn("id", []string{"a"}).
+		SearchIn("bob", "username", "name").
+		OrderByNulls("created_at", false, true).
+		Page(10, 20)
+
+	query, args, err := buildSelectSQL("room_join_requests", q)
+	AssertNoError(t, err, "build select")
+
+	want := `SELECT coalesce(json_agg(t), '[]') FROM (SELECT "id", "user_id" FROM "room_join_requests"` +
+		` WHERE "room_id" = $1 AND "status" = $2 AND "lang_code" IN ($3, $4) AND "id" NOT IN ($5)` +
+		` AND ("username"::text ILIKE $6 OR "name"::text ILIKE $6)` +
+		` ORDER BY "created_at" DESC NULLS FIRST LIMIT 10 OFFSET 20) t`
+	if query != want {
+		t.Errorf("query:\n got %s\nwant %s", query, want)
+	}
+
+	wantArgs := []interface{}{"11111111-1111-1111-1111-111111111111", "pending", "en", "fr", "a", "%bob%"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+}
+
+func TestBuildWhereSQL_EdgeCases(t *testing.T) {
+	tests := map[string]struct {
+		query *Query
+		want  string
+	}{
+		"nil query":            {nil, ""},
+		"null filter":          {Where().Eq("guest_id", nil), ` WHERE "guest_id" IS NULL`},
+		"empty in":             {NewQuery().In("id", nil), ` WHERE FALSE`},
+		"empty not in":         {NewQuery().NotIn("id", nil), ""},
+		"ignored empty search": {NewQuery().SearchIn("", "name"), ""},
+		"less than":            {NewQuery().Lt("expires_at", time.Now()), ` WHERE "expires_at" < $1`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			where, _, err := buildWhereSQL(tt.query, 0)
+			AssertNoError(t, err, "build where")
+			if where != tt.want {
+				t.Errorf("got %q, want %q", where, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildUpdateSQL_NumbersWhereAfterSet(t *testing.T) {
+	guestID := uuid.MustParse("22222222-2222-2222-2222-222222222222")
+	query, args, err := buildUpdateSQL("rooms", Where().Eq("id", "r1"), map[string]interface{}{
+		"guest_id":            &guestID,
+		"current_question_id": (*uuid.UUID)(nil),
+		"selected_categories": []uuid.UUID{guestID},
+	})
+	AssertNoError(t, err, "build update")
+
+	if !strings.Contains(query, `SET "current_question_id" = $1, "guest_id" = $2, "selected_categories" = $3 WHERE "id" = $4 RETURNING *`) {
+		t.Errorf("unexpected query: %s", query)
+	}
+
+	wantArgs := []interface{}{nil, guestID.String(), `["22222222-2222-2222-2222-222222222222"]`, "r1"}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", args, wantArgs)
+	}
+}
+
+func TestBuildSQL_RejectsInvalidIdentifiers(t *testing.T) {
+	if _, _, err := buildSelectSQL("users; drop table users", nil); err == nil {
+		t.Error("expected invalid table name to be rejected")
+	}
+	if _, _, err := buildSelectSQL("users", Where().Eq(`id" OR 1=1 --`, "x")); err == nil {
+		t.Error("expected invalid column name to be rejected")
+	}
+}
+
+func TestPostgresStore_WithTx(t *testing.T) {
+	ctx := context.Background()
+	log := &recordingLog{}
+	store := NewPostgresStoreFromDB(sql.OpenDB(recordingConnector{log}))
+	defer store.Close()
+
+	t.Run("commits on success", func(t *testing.T) {
+		log.reset()
+		err := store.WithTx(ctx, func(tx Store) error {
+			return tx.Delete(ctx, "answers", Where().Eq("id", "a1"))
+		})
+		AssertNoError(t, err, "with tx")
+		assertStatements(t, log, "BEGIN", `DELETE FROM "answers" WHERE "id" = $1`, "COMMIT")
+	})
+
+	t.Run("rolls back on error", func(t *testing.T) {
+		log.reset()
+		failure := errors.New("boom")
+		err := store.WithTx(ctx, func(tx Store) error {
+			if err := tx.Delete(ctx, "answers", nil); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Fatalf("expected the callback error, got %v", err)
+		}
+		assertStatements(t, log, "BEGIN", `DELETE FROM "answers"`, "ROLLBACK")
+	})
+
+	t.Run("nested calls run in a savepoint", func(t *testing.T) {
+		log.reset()
+		err := store.WithTx(ctx, func(tx Store) error {
+			return tx.WithTx(ctx, func(inner Store) error {
+				return inner.Delete(ctx, "answers", nil)
+			})
+		})
+		AssertNoError(t, err, "with tx")
+		assertStatements(t, log, "BEGIN", "SAVEPOINT sp_1", `DELETE FROM "answers"`, "RELEASE SAVEPOINT sp_1", "COMMIT")
+	})
+
+	t.Run("a failed nested call rolls back to its savepoint", func(t *testing.T) {
+		log.reset()
+		err := store.WithTx(ctx, func(tx Store) error {
+			nestedErr := tx.WithTx(ctx, func(inner Store) error {
+				if err := inner.Delete(ctx, "answers", nil); err != nil {
+					return err
+				}
+				return errors.New("duplicate")
+			})
+			if nestedErr == nil {
+				t.Error("expected the nested error")
+			}
+			return tx.Delete(ctx, "rooms", nil)
+		})
+		AssertNoError(t, err, "with tx")
+		assertStatements(t, log, "BEGIN", "SAVEPOINT sp_1", `DELETE FROM "answers"`, "ROLLBACK TO SAVEPOINT sp_1", `DELETE FROM "rooms"`, "COMMIT")
+	})
+}
+
+func assertStatements(t *testing.T, log *recordingLog, want ...string) {
+	t.Helper()
+	if got := log.all(); !reflect.DeepEqual(got, want) {
+		t.Errorf("statements = %q, want %q", got, want)
+	}
+}
+
+// recordingLog collects the statements sent through the recording driver
+type recordingLog struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (l *recordingLog) add(statement string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statements = append(l.statements, statement)
+}
+
+func (l *recordingLog) all() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.statements...)
+}
+
+func (l *recordingLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statements = nil
+}
+
+// recordingConnector is a minimal database/sql driver that records statements and
+// answers every query with an empty JSON array
+type recordingConnector struct{ log *recordingLog }
+
+func (c recordingConnector) Connect(context.Context) (driver.Conn, error) {
+	return recordingConn{c.log}, nil
+}
+
+func (c recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn struct{ log *recordingLog }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c.log, query}, nil
+}
+
+func (c recordingConn) Close() error { return nil }
+
+func (c recordingConn) Begin() (driver.Tx, error) {
+	c.log.add("BEGIN")
+	return recordingTx{c.log}, nil
+}
+
+type recordingTx struct{ log *recordingLog }
+
+func (tx recordingTx) Commit() error   { tx.log.add("COMMIT"); return nil }
+func (tx recordingTx) Rollback() error { tx.log.add("ROLLBACK"); return nil }
+
+type recordingStmt struct {
+	log   *recordingLog
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+
+func (s recordingStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.log.add(s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s recordingStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.log.add(s.query)
+	return &recordingRows{}, nil
+}
+
+type recordingRows struct{ done bool }
+
+func (r *recordingRows) Columns() []string { return []string{"json"} }
+func (r *recordingRows) Close() error      { return nil }
+
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = []byte("[]")
+	return nil
+}
@@ -0,0 +1,349 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// DefaultDisconnectGrace is how long a player may have no live connection before being
+// reported offline: page navigations and network flaps reconnect well within it
+const DefaultDisconnectGrace = 15 * time.Second
+
+// presenceSweepInterval is how often paused rooms are checked for returned players
+const presenceSweepInterval = 30 * time.Second
+
+// presenceHeartbeatInterval is how often an instance refreshes the presence rows of its streams
+const presenceHeartbeatInterval = 10 * time.Second
+
+// presenceTTL is how long a presence row counts without a refresh: its instance has stopped
+const presenceTTL = 3 * presenceHeartbeatInterval
+
+// PresenceObserver is told when realtime clients join and leave a room's stream
+type PresenceObserver interface {
+	ClientConnected(roomID, userID uuid.UUID)
+	ClientDisconnected(roomID, userID uuid.UUID)
+}
+
+// presenceKey identifies a player in a room
+type presenceKey struct {
+	roomID uuid.UUID
+	userID uuid.UUID
+}
+
+// presenceEntry is a player's connections to a room on this instance
+type presenceEntry struct {
+	connections int
+	// online is the state last announced to the room
+	online bool
+	// offline fires once the player has had no connection for the grace period
+	offline *time.Timer
+}
+
+// PresenceService tracks which players have a live realtime connection to their room
+// A player is announced offline (presence_changed) after the grace period without a
+// connection, which pauses a game in progress; the game resumes once every player is
+// back. Paused games whose players do not return are ended by the reconnection-timeouts
+// job (see GameService.EndTimedOutGames).
+// Each instance tracks the streams it serves and shares them in room_presence, so a player
+// connected to another instance counts as online: they are not announced offline, and games
+// resume once both players are back wherever they connected.
+type PresenceService struct {
+	*BaseService
+	roomService     *RoomService
+	gameService     *GameService
+	realtimeService *RealtimeService
+	grace           time.Duration
+	// instanceID marks this instance's rows in room_presence
+	instanceID string
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[presenceKey]*presenceEntry
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewPresenceService creates a presence service announcing players offline after grace
+func NewPresenceService(store Store, roomService *RoomService, gameService *GameService, realtimeService *RealtimeService, grace time.Duration) *PresenceService {
+	return &PresenceService{
+		BaseService:     NewBaseService(store, "PresenceService"),
+		roomService:     roomService,
+		gameService:     gameService,
+		realtimeService: realtimeService,
+		grace:           grace,
+		instanceID:      uuid.New().String(),
+		now:             time.Now,
+		entries:         make(map[presenceKey]*presenceEntry),
+	}
+}
+
+// ClientConnected records a new connection, announcing the player online when they were not
+func (s *PresenceService) ClientConnected(roomID, userID uuid.UUID) {
+	key := presenceKey{roomID: roomID, userID: userID}
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &presenceEntry{}
+		s.entries[key] = entry
+	}
+	first := entry.connections == 0 && entry.offline == nil
+	entry.connections++
+	if entry.offline != nil {
+		// Back within the grace period: nobody noticed
+		entry.offline.Stop()
+		entry.offline = nil
+	}
+	announce := !entry.online
+	entry.online = true
+	s.mu.Unlock()
+
+	if first {
+		s.markPresent(context.Background(), key)
+	}
+	if announce {
+		s.wentOnline(key)
+	}
+}
+
+// ClientDisconnected records a closed connection, starting the grace period after the last one
+func (s *PresenceService) ClientDisconnected(roomID, userID uuid.UUID) {
+	key := presenceKey{roomID: roomID, userID: userID}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	if entry.connections--; entry.connections > 0 || entry.offline != nil {
+		return
+	}
+	entry.offline = time.AfterFunc(s.grace, func() { s.graceExpired(key) })
+}
+
+// IsOnline reports whether the player has a live connection to the room, or lost it less
+// than the grace period ago, on this instance or another
+func (s *PresenceService) IsOnline(ctx context.Context, roomID, userID uuid.UUID) bool {
+	key := presenceKey{roomID: roomID, userID: userID}
+
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	online := ok && entry.online
+	s.mu.Unlock()
+
+	return online || s.presentElsewhere(ctx, key)
+}
+
+// graceExpired announces a player offline unless they reconnected in the meantime
+func (s *PresenceService) graceExpired(key presenceKey) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	if !ok || entry.connections > 0 {
+		s.mu.Unlock()
+		return
+	}
+	announce := entry.online
+	delete(s.entries, key)
+	s.mu.Unlock()
+
+	ctx := context.Background()
+	if err := s.store.Delete(ctx, "room_presence", s.presenceQuery(key)); err != nil {
+		s.logger.Warn("Failed to clear presence of %s in room %s: %v", key.userID, key.roomID, err)
+	}
+
+	// Still connected to another instance: nothing changed for the room
+	if announce && !s.presentElsewhere(ctx, key) {
+		s.wentOffline(key)
+	}
+}
+
+// presenceQuery selects this instance's presence row for key
+func (s *PresenceService) presenceQuery(key presenceKey) *Query {
+	return Where(WithRoomID(key.roomID), WithUserID(key.userID)).Eq("instance_id", s.instanceID)
+}
+
+// markPresent records in room_presence that this instance serves the player
+func (s *PresenceService) markPresent(ctx context.Context, key presenceKey) {
+	seenAt := s.now().UTC()
+	updated, err := s.store.Update(ctx, "room_presence", s.presenceQuery(key), map[string]interface{}{"seen_at": seenAt})
+	if err != nil {
+		s.logger.Warn("Failed to record presence of %s in room %s: %v", key.userID, key.roomID, err)
+		return
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(updated, &rows); err == nil && len(rows) > 0 {
+		return
+	}
+
+	if err := s.InsertRecord(ctx, "room_presence", map[string]interface{}{
+		"room_id":     key.roomID.String(),
+		"user_id":     key.userID.String(),
+		"instance_id": s.instanceID,
+		"seen_at":     seenAt,
+	}); err != nil {
+		s.logger.Warn("Failed to record presence of %s in room %s: %v", key.userID, key.roomID, err)
+	}
+}
+
+// presentElsewhere reports whether another instance holds a live stream of the player
+// A failed lookup counts as absent, like a player nobody has heard from.
+func (s *PresenceService) presentElsewhere(ctx context.Context, key presenceKey) bool {
+	var rows []struct {
+		SeenAt time.Time `json:"seen_at"`
+	}
+	q := Where(WithRoomID(key.roomID), WithUserID(key.userID)).NotIn("instance_id", []string{s.instanceID}).Select("seen_at")
+	if err := s.QueryRecords(ctx, "room_presence", q, &rows); err != nil {
+		s.logger.Warn("Failed to load presence of %s in room %s: %v", key.userID, key.roomID, err)
+		return false
+	}
+
+	cutoff := s.now().Add(-presenceTTL)
+	for _, row := range rows {
+		if row.SeenAt.After(cutoff) {
+			return true
+		}
+	}
+	return false
+}
+
+// wentOnline announces the player and resumes the room's game if everyone is back
+func (s *PresenceService) wentOnline(key presenceKey) {
+	s.broadcastPresence(key, true)
+
+	ctx := context.Background()
+	room, err := s.roomService.GetRoomByID(ctx, key.roomID)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: failed to load room %s for presence: %v\n", key.roomID, err)
+		return
+	}
+	s.resumeIfComplete(ctx, room)
+}
+
+// wentOffline announces the player and pauses the room's game if one is in progress
+func (s *PresenceService) wentOffline(key presenceKey) {
+	s.broadcastPresence(key, false)
+
+	if err := s.gameService.PauseGame(context.Background(), key.roomID, key.userID); err != nil {
+		fmt.Printf("⚠️ Warning: failed to pause room %s after %s disconnected: %v\n", key.roomID, key.userID, err)
+	}
+}
+
+// resumeIfComplete resumes a paused game once every player is online
+func (s *PresenceService) resumeIfComplete(ctx context.Context, room *models.Room) {
+	if room.Status != models.RoomPaused || room.GuestID == nil {
+		return
+	}
+	if !s.IsOnline(ctx, room.ID, room.OwnerID) || !s.IsOnline(ctx, room.ID, *room.GuestID) {
+		return
+	}
+
+	if err := s.gameService.ResumeGame(ctx, room.ID); err != nil {
+		fmt.Printf("⚠️ Warning: failed to resume room %s: %v\n", room.ID, err)
+	}
+}
+
+// broadcastPresence tells the room a player came online or went offline
+func (s *PresenceService) broadcastPresence(key presenceKey, online bool) {
+	s.realtimeService.Broadcast(key.roomID, RealtimeEvent{
+		Type: "presence_changed",
+		Data: map[string]interface{}{
+			"room_id": key.roomID.String(),
+			"user_id": key.userID.String(),
+			"online":  online,
+		},
+	})
+}
+
+// Start runs the sweeper in the background until Stop
+func (s *PresenceService) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.run(ctx)
+}
+
+// Stop ends the sweeper, drops pending offline announcements and clears this instance's
+// presence rows: its players reconnect to another instance
+func (s *PresenceService) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	for _, entry := range s.entries {
+		if entry.offline != nil {
+			entry.offline.Stop()
+		}
+	}
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	if err := s.store.Delete(context.Background(), "room_presence", Where().Eq("instance_id", s.instanceID)); err != nil {
+		s.logger.Warn("Failed to clear presence of this instance: %v", err)
+	}
+}
+
+// run refreshes this instance's presence rows every presenceHeartbeatInterval and sweeps
+// paused rooms every presenceSweepInterval
+func (s *PresenceService) run(ctx context.Context) {
+	defer close(s.done)
+
+	heartbeat := time.NewTicker(presenceHeartbeatInterval)
+	defer heartbeat.Stop()
+	sweep := time.NewTicker(presenceSweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			s.Heartbeat(ctx)
+		case <-sweep.C:
+			s.Sweep(ctx)
+		}
+	}
+}
+
+// Heartbeat refreshes the presence rows of the streams this instance serves
+func (s *PresenceService) Heartbeat(ctx context.Context) {
+	q := Where().Eq("instance_id", s.instanceID)
+	if _, err := s.store.Update(ctx, "room_presence", q, map[string]interface{}{"seen_at": s.now().UTC()}); err != nil {
+		s.logger.Warn("Failed to refresh presence: %v", err)
+	}
+}
+
+// Sweep deletes the presence rows of stopped instances and resumes paused games whose
+// players are all back (a resume is missed when a player returns while the pause is written)
+func (s *PresenceService) Sweep(ctx context.Context) {
+	if err := s.store.Delete(ctx, "room_presence", NewQuery().Lt("seen_at", s.now().Add(-presenceTTL))); err != nil {
+		s.logger.Warn("Failed to delete stale presence: %v", err)
+	}
+
+	rooms, err := s.roomService.GetRoomsByStatus(ctx, models.RoomPaused)
+	if err != nil {
+		fmt.Printf("⚠️ Warning: failed to list paused rooms: %v\n", err)
+		return
+	}
+
+	for i := range rooms {
+		s.resumeIfComplete(ctx, &rooms[i])
+	}
+}
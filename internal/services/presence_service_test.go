@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// newTestPresence wires a presence service to a playing room between owner and guest
+func newTestPresence(t *testing.T, grace time.Duration) (*PresenceService, *RealtimeService, *RoomService, uuid.UUID, uuid.UUID, uuid.UUID) {
+	t.Helper()
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	realtimeService := NewRealtimeService()
+	roomService := NewRoomService(store, realtimeService)
+	gameService := NewGameService(store, roomService, nil, nil, nil, realtimeService, nil)
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	guest := CreateTestUser(t, store, "guest", "Guest", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+	_, err := roomService.TransitionWith(ctx, roomID, models.RoomEventGuestJoined, map[string]interface{}{
+		"guest_id": guest.ID.String(),
+	})
+	AssertNoError(t, err, "guest joined")
+	_, err = roomService.Transition(ctx, roomID, models.RoomEventStart)
+	AssertNoError(t, err, "start")
+
+	presence := NewPresenceService(store, roomService, gameService, realtimeService, grace)
+	realtimeService.SetPresenceObserver(presence)
+	t.Cleanup(presence.Stop)
+
+	return presence, realtimeService, roomService, roomID, owner.ID, guest.ID
+}
+
+// waitForStatus polls the room until it reaches want
+func waitForStatus(t *testing.T, roomService *RoomService, roomID uuid.UUID, want models.RoomState) *models.Room {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		room, err := roomService.GetRoomByID(context.Background(), roomID)
+		AssertNoError(t, err, "get room")
+		if room.Status == want {
+			return room
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("room status = %s, want %s", room.Status, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestPresenceService_PauseAndResume tests that a lasting disconnect pauses the game and a
+// return resumes it, while a quick reconnect goes unnoticed
+func TestPresenceService_PauseAndResume(t *testing.T) {
+	presence, realtimeService, roomService, roomID, ownerID, guestID := newTestPresence(t, 50*time.Millisecond)
+
+	watcher := realtimeService.Subscribe(roomID, ownerID)
+	guest := realtimeService.Subscribe(roomID, guestID)
+	AssertTrue(t, presence.IsOnline(context.Background(), roomID, ownerID), "owner online")
+	AssertTrue(t, presence.IsOnline(context.Background(), roomID, guestID), "guest online")
+
+	// A page navigation: the new stream opens before the grace period ends
+	realtimeService.Unsubscribe(guest.ID)
+	guest = realtimeService.Subscribe(roomID, guestID)
+	time.Sleep(100 * time.Millisecond)
+	if room := waitForStatus(t, roomService, roomID, models.RoomPlaying); room.PausedAt != nil {
+		t.Error("a quick reconnect paused the game")
+	}
+
+	realtimeService.Unsubscribe(guest.ID)
+	AssertTrue(t, presence.IsOnline(context.Background(), roomID, guestID), "guest online during the grace period")
+	room := waitForStatus(t, roomService, roomID, models.RoomPaused)
+	if room.DisconnectedUser == nil || *room.DisconnectedUser != guestID {
+		t.Errorf("disconnected user = %v, want %s", room.DisconnectedUser, guestID)
+	}
+	AssertFalse(t, presence.IsOnline(context.Background(), roomID, guestID), "guest offline after the grace period")
+
+	realtimeService.Subscribe(roomID, guestID)
+	room = waitForStatus(t, roomService, roomID, models.RoomPlaying)
+	if room.DisconnectedUser != nil {
+		t.Errorf("disconnected user = %v after resume, want nil", room.DisconnectedUser)
+	}
+
+	// The other player was told about each change
+	var changes []bool
+	for len(watcher.Channel) > 0 {
+		event := <-watcher.Channel
+		if event.Type == "presence_changed" {
+			changes = append(changes, event.Data.(map[string]interface{})["online"].(bool))
+		}
+	}
+	if len(changes) < 2 || changes[len(changes)-2] || !changes[len(changes)-1] {
+		t.Errorf("presence changes = %v, want ... offline, online", changes)
+	}
+}
+
+// TestPresenceService_SweepResumes tests that the sweeper resumes a game whose players are back
+func TestPresenceService_SweepResumes(t *testing.T) {
+	presence, realtimeService, roomService, roomID, ownerID, guestID := newTestPresence(t, time.Minute)
+	ctx := context.Background()
+
+	realtimeService.Subscribe(roomID, ownerID)
+	realtimeService.Subscribe(roomID, guestID)
+
+	// Paused by another instance that saw the guest leave
+	_, err := roomService.TransitionWith(ctx, roomID, models.RoomEventPause, map[string]interface{}{
+		"paused_at":         time.Now(),
+		"disconnected_user": guestID.String(),
+	})
+	AssertNoError(t, err, "pause")
+
+	presence.Sweep(ctx)
+
+	waitForStatus(t, roomService, roomID, models.RoomPlaying)
+}
+
+// TestPresenceService_SharedAcrossInstances tests that a player connected to another instance
+// counts as online there: no pause when they leave this one, and paused games resume
+func TestPresenceService_SharedAcrossInstances(t *testing.T) {
+	presence, realtimeService, roomService, roomID, ownerID, guestID := newTestPresence(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	otherRealtime := NewRealtimeService()
+	other := NewPresenceService(roomService.store, roomService, presence.gameService, otherRealtime, 50*time.Millisecond)
+	otherRealtime.SetPresenceObserver(other)
+
+	realtimeService.Subscribe(roomID, ownerID)
+	otherRealtime.Subscribe(roomID, guestID)
+	AssertTrue(t, presence.IsOnline(ctx, roomID, guestID), "guest online through the other instance")
+
+	// The guest leaves this instance but keeps a stream on the other one
+	guest := realtimeService.Subscribe(roomID, guestID)
+	realtimeService.Unsubscribe(guest.ID)
+	time.Sleep(150 * time.Millisecond)
+	if room := waitForStatus(t, roomService, roomID, models.RoomPlaying); room.PausedAt != nil {
+		t.Error("leaving one instance paused the game")
+	}
+
+	// Paused while the guest switched instances: this instance resumes it
+	_, err := roomService.TransitionWith(ctx, roomID, models.RoomEventPause, map[string]interface{}{
+		"paused_at":         time.Now(),
+		"disconnected_user": guestID.String(),
+	})
+	AssertNoError(t, err, "pause")
+	presence.Sweep(ctx)
+	waitForStatus(t, roomService, roomID, models.RoomPlaying)
+
+	// An instance that stops refreshing its rows (a crash) no longer counts
+	later := time.Now().Add(presenceTTL + time.Second)
+	presence.now = func() time.Time { return later }
+	AssertFalse(t, presence.IsOnline(ctx, roomID, guestID), "stale presence ignored")
+	presence.now = time.Now
+
+	// A stopped instance takes its players with it
+	other.Stop()
+	AssertFalse(t, presence.IsOnline(ctx, roomID, guestID), "guest gone with the other instance")
+}
@@ -0,0 +1,472 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// maxImportRows caps the size of a question import
+const maxImportRows = 5000
+
+// QuestionColumns are the columns of question exports and imports, in order
+var QuestionColumns = []string{"id", "base_question_id", "category_key", "lang_code", "question_text"}
+
+// QuestionRecord is a question as exported and imported
+// On import, ID and BaseQuestionID may also be labels local to the file (any non-UUID text),
+// so new questions can be linked to their translations without making up UUIDs. An empty ID
+// creates a question; an empty BaseQuestionID makes an English row a base question.
+type QuestionRecord struct {
+	ID             string `json:"id"`
+	BaseQuestionID string `json:"base_question_id"`
+	CategoryKey    string `json:"category_key"`
+	LanguageCode   string `json:"lang_code"`
+	Text           string `json:"question_text"`
+	Row            int    `json:"-"` // line (CSV) or position (JSON) in the imported file
+}
+
+// QuestionImportError is a problem with one row of an import
+type QuestionImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// QuestionImportReport is the outcome of an import, or what it would do for a dry run
+// An import with errors changes nothing.
+type QuestionImportReport struct {
+	DryRun    bool                  `json:"dry_run"`
+	Rows      int                   `json:"rows"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Unchanged int                   `json:"unchanged"`
+	Errors    []QuestionImportError `json:"errors,omitempty"`
+}
+
+// addError records a problem with a row
+func (r *QuestionImportReport) addError(row int, field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, QuestionImportError{Row: row, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ExportQuestions returns every question, grouped by category with each base question
+// followed by its translations
+func (s *QuestionService) ExportQuestions(ctx context.Context) ([]QuestionRecord, error) {
+	categories, err := s.categoryKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	questions, err := s.GetAllQuestions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch questions: %w", err)
+	}
+
+	sort.Slice(questions, func(i, j int) bool {
+		a, b := questions[i], questions[j]
+		if keyA, keyB := categories[a.CategoryID], categories[b.CategoryID]; keyA != keyB {
+			return keyA < keyB
+		}
+		if a.BaseQuestionID != b.BaseQuestionID {
+			return a.BaseQuestionID.String() < b.BaseQuestionID.String()
+		}
+		if isBaseA, isBaseB := a.ID == a.BaseQuestionID, b.ID == b.BaseQuestionID; isBaseA != isBaseB {
+			return isBaseA
+		}
+		return a.LanguageCode < b.LanguageCode
+	})
+
+	records := make([]QuestionRecord, len(questions))
+	for i, question := range questions {
+		records[i] = QuestionRecord{
+			ID:             question.ID.String(),
+			BaseQuestionID: question.BaseQuestionID.String(),
+			CategoryKey:    categories[question.CategoryID],
+			LanguageCode:   question.LanguageCode,
+			Text:           question.Text,
+		}
+	}
+	return records, nil
+}
+
+// WriteQuestionsCSV writes records as CSV, with a header row
+func WriteQuestionsCSV(w io.Writer, records []QuestionRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(QuestionColumns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := writer.Write([]string{record.ID, record.BaseQuestionID, record.CategoryKey, record.LanguageCode, record.Text}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ParseQuestionsCSV reads records from CSV with a header row naming QuestionColumns (in any
+// order; id and base_question_id may be left out)
+// Problems with the file as a whole return models.ErrInvalidImportFile.
+func ParseQuestionsCSV(r io.Reader) ([]QuestionRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet apps often save a byte order mark before the first column
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"category_key", "lang_code", "question_text"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing column %s", models.ErrInvalidImportFile, required)
+		}
+	}
+
+	field := func(fields []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(fields) {
+			return fields[i]
+		}
+		return ""
+	}
+
+	var records []QuestionRecord
+	for {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+		}
+		if strings.TrimSpace(strings.Join(fields, "")) == "" {
+			continue
+		}
+		if len(records) == maxImportRows {
+			return nil, fmt.Errorf("%w: more than %d rows", models.ErrInvalidImportFile, maxImportRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		records = append(records, QuestionRecord{
+			ID:             field(fields, "id"),
+			BaseQuestionID: field(fields, "base_question_id"),
+			CategoryKey:    field(fields, "category_key"),
+			LanguageCode:   field(fields, "lang_code"),
+			Text:           field(fields, "question_text"),
+			Row:            line,
+		})
+	}
+	return records, nil
+}
+
+// ParseQuestionsJSON reads records from a JSON array of objects with QuestionColumns as keys
+// Problems with the file as a whole return models.ErrInvalidImportFile.
+func ParseQuestionsJSON(r io.Reader) ([]QuestionRecord, error) {
+	var records []QuestionRecord
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidImportFile, err)
+	}
+	if len(records) > maxImportRows {
+		return nil, fmt.Errorf("%w: more than %d rows", models.ErrInvalidImportFile, maxImportRows)
+	}
+	for i := range records {
+		records[i].Row = i + 1
+	}
+	return records, nil
+}
+
+// importedQuestion is an imported row resolved against the database
+type importedQuestion struct {
+	row      int
+	question models.Question
+	existing *models.Question // nil for a new question
+}
+
+// ImportQuestions creates and updates questions from records
+// Categories are resolved by key; a row whose id matches a question updates it. Rows are checked
+// for unknown categories and languages, broken translation links, duplicate texts and two
+// translations of a question in the same language. With any error, or for a dry run, nothing
+// is written and the report previews the outcome.
+func (s *QuestionService) ImportQuestions(ctx context.Context, records []QuestionRecord, dryRun bool) (*QuestionImportReport, error) {
+	report := &QuestionImportReport{DryRun: dryRun, Rows: len(records)}
+
+	categories, err := s.categoryKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoryIDs := make(map[string]uuid.UUID, len(categories))
+	for id, key := range categories {
+		categoryIDs[key] = id
+	}
+	languages, err := s.languageCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	questions, err := s.GetAllQuestions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch questions: %w", err)
+	}
+	existing := make(map[uuid.UUID]*models.Question, len(questions))
+	for i := range questions {
+		existing[questions[i].ID] = &questions[i]
+	}
+
+	imported := resolveImportRows(records, categoryIDs, languages, existing, report)
+	checkImportConsistency(imported, questions, report)
+	if len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	for _, item := range imported {
+		switch {
+		case item.existing == nil:
+			report.Created++
+		case item.existing.CategoryID == item.question.CategoryID &&
+			item.existing.LanguageCode == item.question.LanguageCode &&
+			item.existing.Text == item.question.Text:
+			report.Unchanged++
+		default:
+			report.Updated++
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if err := s.applyImport(ctx, imported); err != nil {
+		return nil, err
+	}
+	s.logger.Success("Imported questions: %d created, %d updated, %d unchanged", report.Created, report.Updated, report.Unchanged)
+	return report, nil
+}
+
+// resolveImportRows validates each row on its own and resolves its ids and category
+// Rows with errors are left out of the result.
+func resolveImportRows(records []QuestionRecord, categoryIDs map[string]uuid.UUID, languages []string, existing map[uuid.UUID]*models.Question, report *QuestionImportReport) []importedQuestion {
+	labels := make(map[string]uuid.UUID)
+	resolve := func(ref string) uuid.UUID {
+		if id, err := uuid.Parse(ref); err == nil {
+			return id
+		}
+		if _, ok := labels[ref]; !ok {
+			labels[ref] = uuid.New()
+		}
+		return labels[ref]
+	}
+
+	rowOf := make(map[uuid.UUID]int)
+	var imported []importedQuestion
+	for _, record := range records {
+		ref := strings.TrimSpace(record.ID)
+		baseRef := strings.TrimSpace(record.BaseQuestionID)
+		lang := strings.TrimSpace(record.LanguageCode)
+		for _, code := range languages {
+			if strings.EqualFold(code, lang) {
+				lang = code
+			}
+		}
+		text := strings.TrimSpace(record.Text)
+		errorCount := len(report.Errors)
+
+		id := uuid.New()
+		if ref != "" {
+			id = resolve(ref)
+			if row, ok := rowOf[id]; ok {
+				report.addError(record.Row, "id", "id %s is also used on row %d", ref, row)
+			}
+			rowOf[id] = record.Row
+		}
+
+		categoryID, ok := categoryIDs[strings.TrimSpace(record.CategoryKey)]
+		if !ok {
+			report.addError(record.Row, "category_key", "unknown category %q", record.CategoryKey)
+		}
+		if !slices.Contains(languages, lang) {
+			report.addError(record.Row, "lang_code", "unsupported language %q (expected one of %s)", record.LanguageCode, strings.Join(languages, ", "))
+		}
+		if text == "" {
+			report.addError(record.Row, "question_text", "question text is empty")
+		}
+
+		baseID := id
+		if baseRef != "" {
+			baseID = resolve(baseRef)
+		}
+		switch {
+		case baseRef == "" && lang != DefaultLanguage:
+			report.addError(record.Row, "base_question_id", "translations need the base_question_id of their English question")
+		case baseID == id && lang != DefaultLanguage:
+			report.addError(record.Row, "lang_code", "base questions must be in English")
+		}
+
+		current := existing[id]
+		if current != nil && current.BaseQuestionID != baseID {
+			report.addError(record.Row, "base_question_id", "cannot move an existing question to another base question")
+		}
+
+		if len(report.Errors) > errorCount {
+			continue
+		}
+		imported = append(imported, importedQuestion{
+			row: record.Row,
+			question: models.Question{
+				ID:             id,
+				CategoryID:     categoryID,
+				LanguageCode:   lang,
+				Text:           text,
+				BaseQuestionID: baseID,
+			},
+			existing: current,
+		})
+	}
+	return imported
+}
+
+// checkImportConsistency checks the imported rows against each other and the questions they
+// leave untouched: translations link to an English base question in the same category, a base
+// question has one translation per language, and texts are not repeated within a language
+func checkImportConsistency(imported []importedQuestion, questions []models.Question, report *QuestionImportReport) {
+	// The questions as they will be after the import; rows sort after untouched questions, so
+	// conflicts are reported on the rows
+	final := make(map[uuid.UUID]models.Question, len(questions)+len(imported))
+	rowOf := make(map[uuid.UUID]int, len(imported))
+	for _, item := range imported {
+		final[item.question.ID] = item.question
+		rowOf[item.question.ID] = item.row
+	}
+	var ordered []models.Question
+	for _, question := range questions {
+		if _, ok := rowOf[question.ID]; !ok {
+			final[question.ID] = question
+			ordered = append(ordered, question)
+		}
+	}
+	for _, item := range imported {
+		ordered = append(ordered, item.question)
+	}
+
+	describe := func(id uuid.UUID) string {
+		if row, ok := rowOf[id]; ok {
+			return fmt.Sprintf("row %d", row)
+		}
+		return "question " + id.String()
+	}
+
+	translations := make(map[string]uuid.UUID)
+	texts := make(map[string]uuid.UUID)
+	for _, question := range ordered {
+		row, imported := rowOf[question.ID]
+
+		if imported && question.ID != question.BaseQuestionID {
+			base, ok := final[question.BaseQuestionID]
+			switch {
+			case !ok:
+				report.addError(row, "base_question_id", "base question %s not found", question.BaseQuestionID)
+				continue
+			case base.ID != base.BaseQuestionID:
+				report.addError(row, "base_question_id", "%s is a translation, not a base question", describe(base.ID))
+				continue
+			case base.CategoryID != question.CategoryID:
+				report.addError(row, "category_key", "category differs from its base question (%s)", describe(base.ID))
+			}
+		}
+
+		translationKey := question.BaseQuestionID.String() + "|" + question.LanguageCode
+		if other, ok := translations[translationKey]; ok && imported {
+			report.addError(row, "lang_code", "%s is already the %s version of this question", describe(other), question.LanguageCode)
+		} else if !ok {
+			translations[translationKey] = question.ID
+		}
+
+		textKey := question.LanguageCode + "|" + strings.Join(strings.Fields(strings.ToLower(question.Text)), " ")
+		if other, ok := texts[textKey]; ok && imported {
+			report.addError(row, "question_text", "duplicate of %s", describe(other))
+		} else if !ok {
+			texts[textKey] = question.ID
+		}
+	}
+}
+
+// applyImport writes the imported rows in one transaction
+// Base questions are inserted before the translations referencing them. A base question moved
+// to another category takes its translations along.
+func (s *QuestionService) applyImport(ctx context.Context, imported []importedQuestion) error {
+	sort.SliceStable(imported, func(i, j int) bool {
+		return imported[i].question.ID == imported[i].question.BaseQuestionID &&
+			imported[j].question.ID != imported[j].question.BaseQuestionID
+	})
+
+	return s.WithTx(ctx, func(tx *BaseService) error {
+		for _, item := range imported {
+			question := item.question
+			data := map[string]interface{}{
+				"category_id":   question.CategoryID.String(),
+				"lang_code":     question.LanguageCode,
+				"question_text": question.Text,
+			}
+
+			if item.existing == nil {
+				data["id"] = question.ID.String()
+				data["base_question_id"] = question.BaseQuestionID.String()
+				if err := tx.InsertRecord(ctx, "questions", data); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if item.existing.CategoryID == question.CategoryID &&
+				item.existing.LanguageCode == question.LanguageCode &&
+				item.existing.Text == question.Text {
+				continue
+			}
+			if err := tx.UpdateRecord(ctx, "questions", question.ID, data); err != nil {
+				return err
+			}
+			if question.ID == question.BaseQuestionID && item.existing.CategoryID != question.CategoryID {
+				if _, err := tx.store.Update(ctx, "questions", Where().Eq("base_question_id", question.ID.String()),
+					map[string]interface{}{"category_id": question.CategoryID.String()}); err != nil {
+					return fmt.Errorf("failed to move translations: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// languageCodes returns the codes of the registered languages, enabled or not, so questions can
+// be prepared before a language is enabled
+func (s *QuestionService) languageCodes(ctx context.Context) ([]string, error) {
+	var languages []models.Language
+	if err := s.QueryRecords(ctx, "languages", NewQuery().Select("code").OrderBy("position", true), &languages); err != nil {
+		return nil, fmt.Errorf("failed to fetch languages: %w", err)
+	}
+	codes := make([]string, len(languages))
+	for i, language := range languages {
+		codes[i] = language.Code
+	}
+	return codes, nil
+}
+
+// categoryKeys maps category ids to keys
+func (s *QuestionService) categoryKeys(ctx context.Context) (map[uuid.UUID]string, error) {
+	var categories []models.Category
+	if err := s.QueryRecords(ctx, "categories", NewQuery().Select("id,key"), &categories); err != nil {
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
+	}
+	keys := make(map[uuid.UUID]string, len(categories))
+	for _, category := range categories {
+		keys[category.ID] = category.Key
+	}
+	return keys, nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hekigan/couples/internal/models"
+)
+
+// TestQuestionImport_CreatesWithLabels tests importing new questions linked by file-local labels
+func TestQuestionImport_CreatesWithLabels(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewQuestionService(store)
+	ctx := context.Background()
+	categoryID := CreateTestCategory(t, store, "dreams")
+
+	file := "category_key,lang_code,question_text,id,base_question_id\n" +
+		"dreams,en,Where would you live?,q1,\n" +
+		"dreams,fr,Où aimerais-tu vivre ?,,q1\n" +
+		"\n" +
+		"dreams,en,What scares you?,,\n"
+	records, err := ParseQuestionsCSV(strings.NewReader(file))
+	AssertNoError(t, err, "parse")
+	AssertEqual(t, 3, len(records), "blank lines skipped")
+	AssertEqual(t, 5, records[2].Row, "rows are file lines")
+
+	report, err := service.ImportQuestions(ctx, records, true)
+	AssertNoError(t, err, "dry run")
+	AssertEqual(t, 0, len(report.Errors), "no errors")
+	AssertEqual(t, 3, report.Created, "created in preview")
+	count, _ := service.CountQuestionsForCategories(ctx, "en", nil)
+	AssertEqual(t, 0, count, "dry run writes nothing")
+
+	report, err = service.ImportQuestions(ctx, records, false)
+	AssertNoError(t, err, "import")
+	AssertEqual(t, 3, report.Created, "created")
+
+	english, _ := service.ListQuestions(ctx, 10, 0, &categoryID, stringPtr("en"))
+	AssertEqual(t, 2, len(english), "English questions")
+	for _, question := range english {
+		AssertEqual(t, question.ID, question.BaseQuestionID, "English questions are bases")
+		if question.Text == "Where would you live?" {
+			translations, err := service.GetQuestionTranslations(ctx, question.ID)
+			AssertNoError(t, err, "translations")
+			AssertNotNil(t, translations.Questions["fr"], "French linked by label")
+		}
+	}
+}
+
+// TestQuestionImport_RoundTrip tests that an export imports back unchanged, and that edits to
+// it update the questions
+func TestQuestionImport_RoundTrip(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewQuestionService(store)
+	ctx := context.Background()
+	categoryID := CreateTestCategory(t, store, "past")
+	CreateTestCategory(t, store, "future")
+	baseID := CreateTestQuestion(t, store, categoryID, "en", "First kiss?")
+	AssertNoError(t, service.CreateQuestion(ctx, &models.Question{
+		CategoryID: categoryID, LanguageCode: "ja", Text: "初めてのキスは？", BaseQuestionID: baseID,
+	}), "translation")
+	CreateTestQuestion(t, store, categoryID, "en", "Best holiday, with \"quotes\", and commas?")
+
+	records, err := service.ExportQuestions(ctx)
+	AssertNoError(t, err, "export")
+	AssertEqual(t, 3, len(records), "exported")
+
+	var buf bytes.Buffer
+	AssertNoError(t, WriteQuestionsCSV(&buf, records), "write")
+	parsed, err := ParseQuestionsCSV(&buf)
+	AssertNoError(t, err, "parse")
+	report, err := service.ImportQuestions(ctx, parsed, false)
+	AssertNoError(t, err, "import")
+	AssertEqual(t, 0, len(report.Errors), "no errors")
+	AssertEqual(t, 3, report.Unchanged, "round trip leaves everything unchanged")
+
+	// Move the base question (only) to another category: its translation follows
+	var edited []QuestionRecord
+	for _, record := range parsed {
+		if record.ID == baseID.String() {
+			record.CategoryKey = "future"
+			edited = append(edited, record)
+		}
+	}
+	report, err = service.ImportQuestions(ctx, edited, false)
+	AssertNoError(t, err, "import edit")
+	AssertEqual(t, 1, report.Updated, "updated")
+	translations, _ := service.GetQuestionTranslations(ctx, baseID)
+	AssertEqual(t, translations.Questions["en"].CategoryID, translations.Questions["ja"].CategoryID, "translation moved along")
+	AssertTrue(t, translations.Questions["en"].CategoryID != categoryID, "base moved")
+}
+
+// TestQuestionImport_ReportsRowErrors tests row-level validation and that an import with errors
+// changes nothing
+func TestQuestionImport_ReportsRowErrors(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewQuestionService(store)
+	ctx := context.Background()
+	categoryID := CreateTestCategory(t, store, "couples")
+	otherID := CreateTestCategory(t, store, "friends")
+	baseID := CreateTestQuestion(t, store, categoryID, "en", "Favorite  song?")
+	AssertNoError(t, service.CreateQuestion(ctx, &models.Question{
+		CategoryID: categoryID, LanguageCode: "fr", Text: "Chanson préférée ?", BaseQuestionID: baseID,
+	}), "translation")
+	CreateTestQuestion(t, store, otherID, "en", "Other category")
+
+	base := baseID.String()
+	records, err := ParseQuestionsJSON(strings.NewReader(`[
+		{"category_key": "nope", "lang_code": "en", "question_text": "Unknown category"},
+		{"category_key": "couples", "lang_code": "de", "question_text": "Lieblingslied?", "base_question_id": "` + base + `"},
+		{"category_key": "couples", "lang_code": "fr", "question_text": "Sans base"},
+		{"category_key": "couples", "lang_code": "en", "question_text": "favorite song?"},
+		{"category_key": "couples", "lang_code": "fr", "question_text": "Autre chanson ?", "base_question_id": "` + base + `"},
+		{"category_key": "friends", "lang_code": "ja", "question_text": "好きな歌は？", "base_question_id": "` + base + `"},
+		{"category_key": "couples", "lang_code": "en", "question_text": "Fine question"},
+		{"category_key": "couples", "lang_code": "en", "question_text": "Fine question"},
+		{"category_key": "couples", "lang_code": "ja", "question_text": "Missing base", "base_question_id": "ghost"},
+		{"category_key": "couples", "lang_code": "en", "question_text": " "}
+	]`))
+	AssertNoError(t, err, "parse")
+
+	report, err := service.ImportQuestions(ctx, records, false)
+	AssertNoError(t, err, "import")
+
+	fields := make(map[int]string)
+	for _, rowError := range report.Errors {
+		fields[rowError.Row] = rowError.Field
+	}
+	expected := map[int]string{
+		1:  "category_key",     // unknown category
+		2:  "lang_code",        // unsupported language
+		3:  "base_question_id", // translation without a base
+		4:  "question_text",    // duplicate of an existing question (case and spacing ignored)
+		5:  "lang_code",        // second French version of the same question
+		6:  "category_key",     // category differs from the base question
+		8:  "question_text",    // duplicate of row 7
+		9:  "base_question_id", // unknown base
+		10: "question_text",    // empty
+	}
+	if len(report.Errors) != len(expected) {
+		t.Fatalf("expected %d errors, got %+v", len(expected), report.Errors)
+	}
+	for row, field := range expected {
+		AssertEqual(t, field, fields[row], "error field")
+	}
+
+	count, _ := service.CountQuestionsForCategories(ctx, "en", nil)
+	AssertEqual(t, 2, count, "nothing written")
+
+	_, err = ParseQuestionsCSV(strings.NewReader("id,question_text\n1,hello\n"))
+	AssertTrue(t, errors.Is(err, models.ErrInvalidImportFile), "missing columns refused")
+}
+
+// stringPtr returns a pointer to s
+func stringPtr(s string) *string {
+	return &s
+}
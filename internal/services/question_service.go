@@ -2,36 +2,62 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
+	"math"
+	"math/rand"
+	"sort"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/postgrest-go"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
+// historyPageSize is how many history rows historyQuestionIDs reads at a time
+const historyPageSize = 1000
+
+// HistoryScope decides which earlier sessions exclude a question from GetRandomQuestion
+type HistoryScope string
+
+const (
+	// HistoryScopePair excludes questions the room's two players have seen together
+	HistoryScopePair HistoryScope = "pair"
+	// HistoryScopeUser excludes questions either player has seen, with any partner
+	HistoryScopeUser HistoryScope = "user"
+)
+
 // QuestionService handles question-related operations
 type QuestionService struct {
 	*BaseService
-	client *supabase.Client
+	intn         func(n int) int // random source for GetRandomQuestion (tests swap in a seeded one)
+	historyScope HistoryScope
 }
 
 // NewQuestionService creates a new question service
-func NewQuestionService(client *supabase.Client) *QuestionService {
+func NewQuestionService(store Store) *QuestionService {
 	return &QuestionService{
-		BaseService: NewBaseService(client, "QuestionService"),
-		client:      client,
+		BaseService:  NewBaseService(store, "QuestionService"),
+		intn:         rand.Intn,
+		historyScope: HistoryScopePair,
 	}
 }
 
+// withStore returns a copy of the service bound to another store (e.g. an open transaction)
+func (s *QuestionService) withStore(store Store) *QuestionService {
+	return &QuestionService{
+		BaseService:  s.BaseService.withStore(store),
+		intn:         s.intn,
+		historyScope: s.historyScope,
+	}
+}
+
+// SetHistoryScope changes which earlier sessions exclude a question from the draw
+func (s *QuestionService) SetHistoryScope(scope HistoryScope) {
+	s.historyScope = scope
+}
+
 // QuestionTranslations holds all language versions of a question
 type QuestionTranslations struct {
 	BaseQuestionID uuid.UUID
-	English        *models.Question
-	French         *models.Question
-	Japanese       *models.Question
+	Questions      map[string]*models.Question // Language code -> question; DefaultLanguage is the base
 }
 
 // GetQuestionByID retrieves a question by ID
@@ -54,98 +80,234 @@ func (s *QuestionService) GetQuestionTranslations(ctx context.Context, baseQuest
 
 	translations := &QuestionTranslations{
 		BaseQuestionID: baseQuestionID,
+		Questions:      make(map[string]*models.Question, len(questions)),
 	}
 
 	// Organize by language code
 	for i := range questions {
-		switch questions[i].LanguageCode {
-		case "en":
-			translations.English = &questions[i]
-		case "fr":
-			translations.French = &questions[i]
-		case "ja":
-			translations.Japanese = &questions[i]
-		}
+		translations.Questions[questions[i].LanguageCode] = &questions[i]
 	}
 
 	return translations, nil
 }
 
-// GetRandomQuestion gets a random question for a room, filtered by categories and excluding already asked questions
-func (s *QuestionService) GetRandomQuestion(ctx context.Context, roomID uuid.UUID, language string, categoryIDs []uuid.UUID) (*models.Question, error) {
-	// First, get the list of question IDs already asked in this room
-	historyData, _, err := s.client.From("question_history").
-		Select("question_id", "", false).
-		Eq("room_id", roomID.String()).
-		Execute()
-
-	var askedQuestionIDs []string
-	if err == nil && len(historyData) > 0 {
-		var historyRecords []map[string]interface{}
-		if err := json.Unmarshal(historyData, &historyRecords); err == nil {
-			for _, record := range historyRecords {
-				if qid, ok := record["question_id"].(string); ok {
-					askedQuestionIDs = append(askedQuestionIDs, qid)
-				}
-			}
+// GetRandomQuestion draws a random question for a room, filtered by language and categories
+// Questions already asked in the room, or seen by either player in an earlier session, are
+// excluded; when only seen ones are left, a room with AllowRepeats draws from those instead.
+// Only ids are fetched for the eligible pool; the draw keeps the selected categories evenly
+// mixed (scaled by category weight) and picks uniformly within the chosen category.
+func (s *QuestionService) GetRandomQuestion(ctx context.Context, room *models.Room) (*models.Question, error) {
+	q := Where().Eq("lang_code", room.Language).Select("id,category_id")
+	if len(room.SelectedCategories) > 0 {
+		q = q.In("category_id", ToStringSlice(room.SelectedCategories))
+	}
+
+	var pool []drawCandidate
+	if err := s.BaseService.QueryRecords(ctx, "questions", q, &pool); err != nil {
+		return nil, fmt.Errorf("failed to fetch questions: %w", err)
+	}
+
+	asked, err := s.historyQuestionIDs(ctx, "question_history", Where(WithRoomID(room.ID)))
+	if err != nil {
+		return nil, err
+	}
+
+	seen, err := s.seenQuestionIDs(ctx, room)
+	if err != nil {
+		return nil, err
+	}
+
+	weights, err := s.categoryWeights(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	questionID, ok := drawQuestion(pool, asked, seen, weights, s.intn)
+	if !ok && room.AllowRepeats {
+		questionID, ok = drawQuestion(pool, asked, nil, weights, s.intn)
+	}
+	if !ok {
+		if _, hasUnasked := drawQuestion(pool, asked, nil, weights, s.intn); hasUnasked {
+			return nil, fmt.Errorf("%w: allow repeats in the room settings or reset your question history", models.ErrQuestionsAllSeen)
+		}
+		// Provide more helpful error message
+		if len(room.SelectedCategories) > 0 {
+			return nil, fmt.Errorf("%w for the selected categories and language '%s'. Please add questions to the database or change category selection", models.ErrNoQuestionsAvailable, room.Language)
 		}
+		return nil, fmt.Errorf("%w for language '%s'. Please add questions to the database", models.ErrNoQuestionsAvailable, room.Language)
 	}
 
-	// Build query for random question
-	query := s.client.From("questions").
-		Select("*", "", false).
-		Eq("lang_code", language)
+	return s.GetQuestionByID(ctx, questionID)
+}
 
-	// Filter by categories if provided
-	if len(categoryIDs) > 0 {
-		categoryIDStrings := make([]string, len(categoryIDs))
-		for i, id := range categoryIDs {
-			categoryIDStrings[i] = id.String()
+// seenQuestionIDs returns the questions the room's players saw in earlier sessions
+func (s *QuestionService) seenQuestionIDs(ctx context.Context, room *models.Room) (map[uuid.UUID]bool, error) {
+	if s.historyScope == HistoryScopeUser {
+		players := []uuid.UUID{room.OwnerID}
+		if room.GuestID != nil {
+			players = append(players, *room.GuestID)
 		}
-		query = query.In("category_id", categoryIDStrings)
+		return s.historyQuestionIDs(ctx, "player_question_history", NewQuery().In("user_id", ToStringSlice(players)))
 	}
 
-	// Exclude already asked questions
-	if len(askedQuestionIDs) > 0 {
-		// Use string interpolation format for NOT IN clause
-		// Format: NOT id IN ('uuid1','uuid2',...)
-		query = query.Not("id", "in", "("+strings.Join(askedQuestionIDs, ",")+")")
+	if room.GuestID == nil {
+		return nil, nil
 	}
+	// Rows are written for both players, so one direction covers the pair
+	return s.historyQuestionIDs(ctx, "player_question_history", Where().
+		Eq("user_id", room.OwnerID.String()).
+		Eq("partner_id", room.GuestID.String()))
+}
 
-	// Execute query with limit 1 to get one random question
-	// Note: For true randomness, we'd need a custom SQL function, but for now we'll get the first available
-	data, _, err := query.Limit(1, "").Execute()
+// historyQuestionIDs returns the question ids recorded in a history table
+// The rows are read historyPageSize at a time, since PostgREST caps how many one request returns.
+func (s *QuestionService) historyQuestionIDs(ctx context.Context, table string, q *Query) (map[uuid.UUID]bool, error) {
+	q = q.Select("question_id").OrderBy("id", true)
+	ids := make(map[uuid.UUID]bool)
+	for offset := 0; ; offset += historyPageSize {
+		var history []struct {
+			QuestionID uuid.UUID `json:"question_id"`
+		}
+		if err := s.BaseService.QueryRecords(ctx, table, q.Page(historyPageSize, offset), &history); err != nil {
+			return nil, fmt.Errorf("failed to fetch %s: %w", table, err)
+		}
+		for _, record := range history {
+			ids[record.QuestionID] = true
+		}
+		if len(history) < historyPageSize {
+			return ids, nil
+		}
+	}
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch question: %w", err)
+// categoryWeights returns the weight of every category present in the pool
+func (s *QuestionService) categoryWeights(ctx context.Context, pool []drawCandidate) (map[uuid.UUID]float64, error) {
+	seen := make(map[uuid.UUID]bool)
+	var ids []uuid.UUID
+	for _, candidate := range pool {
+		if !seen[candidate.CategoryID] {
+			seen[candidate.CategoryID] = true
+			ids = append(ids, candidate.CategoryID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
 	}
 
-	var questions []models.Question
-	if err := json.Unmarshal(data, &questions); err != nil {
-		return nil, fmt.Errorf("failed to parse questions: %w", err)
+	var categories []models.Category
+	if err := s.BaseService.QueryRecords(ctx, "categories", NewQuery().Select("id,weight").In("id", ToStringSlice(ids)), &categories); err != nil {
+		return nil, fmt.Errorf("failed to fetch category weights: %w", err)
 	}
 
-	if len(questions) == 0 {
-		// Provide more helpful error message
-		if len(categoryIDs) > 0 {
-			return nil, fmt.Errorf("no questions available for the selected categories and language '%s'. Please add questions to the database or change category selection", language)
+	weights := make(map[uuid.UUID]float64, len(categories))
+	for _, category := range categories {
+		weights[category.ID] = category.Weight
+	}
+	return weights, nil
+}
+
+// drawCandidate is the projection of a question GetRandomQuestion draws from
+type drawCandidate struct {
+	ID         uuid.UUID `json:"id"`
+	CategoryID uuid.UUID `json:"category_id"`
+}
+
+// drawQuestion picks a question from the pool that is neither asked (in this room) nor seen
+// (by the players in earlier sessions).
+// The category drawn from is the one with the fewest asked questions relative to its weight
+// (ties broken at random), so over a game each category gets its weighted share of draws.
+// Returns false when no question is left.
+func drawQuestion(pool []drawCandidate, asked, seen map[uuid.UUID]bool, weights map[uuid.UUID]float64, intn func(int) int) (uuid.UUID, bool) {
+	askedCount := make(map[uuid.UUID]int)
+	unasked := make(map[uuid.UUID][]uuid.UUID)
+	for _, candidate := range pool {
+		switch {
+		case asked[candidate.ID]:
+			askedCount[candidate.CategoryID]++
+		case !seen[candidate.ID]:
+			unasked[candidate.CategoryID] = append(unasked[candidate.CategoryID], candidate.ID)
 		}
-		return nil, fmt.Errorf("no questions available for language '%s'. Please add questions to the database", language)
+	}
+	if len(unasked) == 0 {
+		return uuid.Nil, false
 	}
 
-	return &questions[0], nil
+	// Iterate in a stable order so a seeded intn gives reproducible draws
+	categories := make([]uuid.UUID, 0, len(unasked))
+	for categoryID := range unasked {
+		categories = append(categories, categoryID)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].String() < categories[j].String() })
+
+	var lowest []uuid.UUID
+	lowestScore := math.Inf(1)
+	for _, categoryID := range categories {
+		weight := weights[categoryID]
+		if weight <= 0 {
+			weight = 1
+		}
+		score := float64(askedCount[categoryID]) / weight
+		switch {
+		case score < lowestScore:
+			lowest, lowestScore = []uuid.UUID{categoryID}, score
+		case score == lowestScore:
+			lowest = append(lowest, categoryID)
+		}
+	}
+
+	candidates := unasked[lowest[intn(len(lowest))]]
+	return candidates[intn(len(candidates))], true
 }
 
-// MarkQuestionAsked marks a question as asked in a room to prevent repetition
-func (s *QuestionService) MarkQuestionAsked(ctx context.Context, roomID, questionID uuid.UUID) error {
+// MarkQuestionAsked records a question as asked in a room, and as seen by each of its players,
+// so it isn't drawn again in this room or in the players' later sessions together
+func (s *QuestionService) MarkQuestionAsked(ctx context.Context, room *models.Room, questionID uuid.UUID) error {
+	if room.GuestID != nil {
+		pairs := [][2]uuid.UUID{{room.OwnerID, *room.GuestID}, {*room.GuestID, room.OwnerID}}
+		for _, pair := range pairs {
+			seen := Where().
+				Eq("user_id", pair[0].String()).
+				Eq("partner_id", pair[1].String()).
+				Eq("question_id", questionID.String())
+
+			// Replace rather than insert so a repeated question refreshes asked_at
+			if err := s.store.Delete(ctx, "player_question_history", seen); err != nil {
+				return fmt.Errorf("failed to update player question history: %w", err)
+			}
+			if err := s.BaseService.InsertRecord(ctx, "player_question_history", map[string]interface{}{
+				"user_id":     pair[0].String(),
+				"partner_id":  pair[1].String(),
+				"question_id": questionID.String(),
+				"room_id":     room.ID.String(),
+			}); err != nil {
+				return fmt.Errorf("failed to update player question history: %w", err)
+			}
+		}
+	}
+
 	historyMap := map[string]interface{}{
-		"room_id":     roomID.String(),
+		"room_id":     room.ID.String(),
 		"question_id": questionID.String(),
 	}
 
 	return s.BaseService.InsertRecord(ctx, "question_history", historyMap)
 }
 
+// ResetPairHistory forgets which questions two players have seen together,
+// so their next games can draw those questions again
+func (s *QuestionService) ResetPairHistory(ctx context.Context, userID, partnerID uuid.UUID) error {
+	return s.WithTx(ctx, func(tx *BaseService) error {
+		for _, pair := range [][2]uuid.UUID{{userID, partnerID}, {partnerID, userID}} {
+			if err := tx.store.Delete(ctx, "player_question_history", Where().
+				Eq("user_id", pair[0].String()).
+				Eq("partner_id", pair[1].String())); err != nil {
+				return fmt.Errorf("failed to reset question history: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
 // GetAllQuestions retrieves all questions
 func (s *QuestionService) GetAllQuestions(ctx context.Context) ([]models.Question, error) {
 	var questions []models.Question
@@ -192,21 +354,12 @@ func (s *QuestionService) DeleteQuestion(ctx context.Context, id uuid.UUID) erro
 // GetQuestionCountsByCategory returns the number of questions per category for a given language
 func (s *QuestionService) GetQuestionCountsByCategory(ctx context.Context, language string) (map[string]int, error) {
 	// Query questions grouped by category for the given language
-	data, _, err := s.client.From("questions").
-		Select("category_id", "", false).
-		Eq("lang_code", language).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch question counts: %w", err)
-	}
-
-	// Parse the response - we get all questions with their category_id
+	// We get all questions with their category_id
 	var questions []struct {
 		CategoryID string `json:"category_id"`
 	}
-	if err := json.Unmarshal(data, &questions); err != nil {
-		return nil, fmt.Errorf("failed to parse questions: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "questions", Where().Eq("lang_code", language).Select("category_id"), &questions); err != nil {
+		return nil, fmt.Errorf("failed to fetch question counts: %w", err)
 	}
 
 	// Count questions per category
@@ -219,35 +372,21 @@ func (s *QuestionService) GetQuestionCountsByCategory(ctx context.Context, langu
 }
 
 // GetQuestionTranslationStatus returns the number of translations (0-3) for each question ID
-// This checks how many language versions exist for each question (en, fr, ja)
+// This checks how many language versions exist for each question (at most one per language)
 // Uses base_question_id to link translations together
 func (s *QuestionService) GetQuestionTranslationStatus(ctx context.Context, questionIDs []uuid.UUID) (map[string]int, error) {
 	if len(questionIDs) == 0 {
 		return make(map[string]int), nil
 	}
 
-	// Convert UUIDs to strings for the query
-	idStrings := make([]string, len(questionIDs))
-	for i, id := range questionIDs {
-		idStrings[i] = id.String()
-	}
-
 	// Step 1: Fetch the questions to get their base_question_ids
-	data, _, err := s.client.From("questions").
-		Select("id, base_question_id", "", false).
-		In("id", idStrings).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch questions: %w", err)
-	}
-
 	var questions []struct {
 		ID             string `json:"id"`
 		BaseQuestionID string `json:"base_question_id"`
 	}
-	if err := json.Unmarshal(data, &questions); err != nil {
-		return nil, fmt.Errorf("failed to parse questions: %w", err)
+	query := NewQuery().Select("id,base_question_id").In("id", ToStringSlice(questionIDs))
+	if err := s.BaseService.QueryRecords(ctx, "questions", query, &questions); err != nil {
+		return nil, fmt.Errorf("failed to fetch questions: %w", err)
 	}
 
 	// Step 2: Build map of ID → BaseQuestionID and collect all base question IDs
@@ -265,21 +404,13 @@ func (s *QuestionService) GetQuestionTranslationStatus(ctx context.Context, ques
 	}
 
 	// Step 3: Query all questions with these base_question_ids to count translations
-	data, _, err = s.client.From("questions").
-		Select("base_question_id, lang_code", "", false).
-		In("base_question_id", baseIDStrings).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch translations: %w", err)
-	}
-
 	var allTranslations []struct {
 		BaseQuestionID string `json:"base_question_id"`
 		LangCode       string `json:"lang_code"`
 	}
-	if err := json.Unmarshal(data, &allTranslations); err != nil {
-		return nil, fmt.Errorf("failed to parse translations: %w", err)
+	query = NewQuery().Select("base_question_id,lang_code").In("base_question_id", baseIDStrings)
+	if err := s.BaseService.QueryRecords(ctx, "questions", query, &allTranslations); err != nil {
+		return nil, fmt.Errorf("failed to fetch translations: %w", err)
 	}
 
 	// Step 4: Count distinct languages per base_question_id
@@ -306,60 +437,36 @@ func (s *QuestionService) GetQuestionTranslationStatus(ctx context.Context, ques
 
 // CountQuestionsForCategories counts total questions available for selected categories and language
 func (s *QuestionService) CountQuestionsForCategories(ctx context.Context, language string, categoryIDs []uuid.UUID) (int, error) {
-	query := s.client.From("questions").
-		Select("id", "exact", false).
-		Eq("lang_code", language)
+	q := Where().Eq("lang_code", language)
 
 	// Filter by categories if provided
 	if len(categoryIDs) > 0 {
-		categoryIDStrings := make([]string, len(categoryIDs))
-		for i, id := range categoryIDs {
-			categoryIDStrings[i] = id.String()
-		}
-		query = query.In("category_id", categoryIDStrings)
+		q = q.In("category_id", ToStringSlice(categoryIDs))
 	}
 
-	data, _, err := query.Execute()
+	count, err := s.BaseService.CountQueryRecords(ctx, "questions", q)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count questions: %w", err)
 	}
 
-	// Parse the data array length
-	var questions []map[string]interface{}
-	if err := json.Unmarshal(data, &questions); err != nil {
-		return 0, fmt.Errorf("failed to parse questions: %w", err)
-	}
-
-	return len(questions), nil
+	return count, nil
 }
 
 // ListQuestions retrieves questions with pagination and optional filtering
 func (s *QuestionService) ListQuestions(ctx context.Context, limit, offset int, categoryID *uuid.UUID, langCode *string) ([]models.Question, error) {
-	// Custom query - uses Order and Range with optional filters, not supported by BaseService
-	query := s.client.From("questions").
-		Select("*", "", false).
-		Order("created_at", &postgrest.OrderOpts{Ascending: false})
+	q := NewQuery().OrderBy("created_at", false).Page(limit, offset)
 
 	if categoryID != nil {
-		query = query.Eq("category_id", categoryID.String())
+		q = q.Eq("category_id", categoryID.String())
 	}
 
 	if langCode != nil {
-		query = query.Eq("lang_code", *langCode)
-	}
-
-	if limit > 0 {
-		query = query.Range(offset, offset+limit-1, "")
-	}
-
-	data, _, err := query.Execute()
-	if err != nil {
-		return nil, fmt.Errorf("failed to list questions: %w", err)
+		q = q.Eq("lang_code", *langCode)
 	}
 
 	var questions []models.Question
-	if err := json.Unmarshal(data, &questions); err != nil {
-		return nil, fmt.Errorf("failed to parse questions: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "questions", q, &questions); err != nil {
+		return nil, fmt.Errorf("failed to list questions: %w", err)
 	}
 
 	return questions, nil
@@ -1,57 +1,85 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/hekigan/couples/internal/models"
 )
 
-// TestGetRandomQuestion_CategoryFiltering tests that questions are filtered by category
+// TestGetRandomQuestion_CategoryFiltering tests that questions are filtered by category and language
 func TestGetRandomQuestion_CategoryFiltering(t *testing.T) {
-	// Note: This is an integration test that requires a test Supabase instance
-	// Skip if running unit tests only
 	if testing.Short() {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	questionService := NewQuestionService(store)
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	room := loadTestRoom(t, store, CreateTestRoom(t, store, owner.ID, "en"))
+	selected := CreateTestCategory(t, store, "selected")
+	other := CreateTestCategory(t, store, "other")
+	CreateTestQuestion(t, store, selected, "en", "Selected question")
+	CreateTestQuestion(t, store, selected, "fr", "Question choisie")
+	CreateTestQuestion(t, store, other, "en", "Other question")
+
 	tests := []struct {
 		name        string
-		roomID      uuid.UUID
 		language    string
 		categoryIDs []uuid.UUID
+		wantText    []string
 		wantErr     bool
 		errMsg      string
 	}{
 		{
 			name:        "valid request with categories",
-			roomID:      uuid.New(),
 			language:    "en",
-			categoryIDs: []uuid.UUID{uuid.New(), uuid.New()},
-			wantErr:     false,
+			categoryIDs: []uuid.UUID{selected},
+			wantText:    []string{"Selected question"},
 		},
 		{
 			name:        "valid request without categories",
-			roomID:      uuid.New(),
 			language:    "en",
 			categoryIDs: []uuid.UUID{},
-			wantErr:     false,
+			wantText:    []string{"Selected question", "Other question"},
 		},
 		{
-			name:        "empty language should still work",
-			roomID:      uuid.New(),
+			name:        "language filter",
+			language:    "fr",
+			categoryIDs: []uuid.UUID{selected, other},
+			wantText:    []string{"Question choisie"},
+		},
+		{
+			name:        "empty language has no questions",
 			language:    "",
 			categoryIDs: []uuid.UUID{},
 			wantErr:     true,
-			errMsg:      "failed to fetch question",
+			errMsg:      "no questions available",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// This test requires a real Supabase client
-			// In production, you would inject a mock client here
-			t.Skip("Requires test database setup")
+			room.Language, room.SelectedCategories = tt.language, tt.categoryIDs
+			question, err := questionService.GetRandomQuestion(ctx, room)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("expected error containing %q, got %v", tt.errMsg, err)
+				}
+				return
+			}
+			AssertNoError(t, err, "get random question")
+			if !slices.Contains(tt.wantText, question.Text) {
+				t.Errorf("drew %q, want one of %q", question.Text, tt.wantText)
+			}
 		})
 	}
 }
@@ -62,8 +90,259 @@ func TestGetRandomQuestion_HistoryExclusion(t *testing.T) {
 		t.Skip("Skipping integration test in short mode")
 	}
 
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	questionService := NewQuestionService(store)
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	categoryID := CreateTestCategory(t, store, "history")
+	for i := 0; i < 5; i++ {
+		CreateTestQuestion(t, store, categoryID, "en", fmt.Sprintf("Question %d", i))
+	}
+	room := loadTestRoom(t, store, CreateTestRoom(t, store, owner.ID, "en"))
+	room.SelectedCategories = []uuid.UUID{categoryID}
+
 	t.Run("excludes previously asked questions", func(t *testing.T) {
-		t.Skip("Requires test database with question history")
+		drawn := make(map[uuid.UUID]bool)
+		for i := 0; i < 5; i++ {
+			question, err := questionService.GetRandomQuestion(ctx, room)
+			AssertNoError(t, err, "get random question")
+			if drawn[question.ID] {
+				t.Fatalf("question %s was drawn twice", question.ID)
+			}
+			drawn[question.ID] = true
+			AssertNoError(t, questionService.MarkQuestionAsked(ctx, room, question.ID), "mark question asked")
+		}
+
+		_, err := questionService.GetRandomQuestion(ctx, room)
+		if !errors.Is(err, models.ErrNoQuestionsAvailable) {
+			t.Errorf("expected ErrNoQuestionsAvailable once every question has been asked, got %v", err)
+		}
+	})
+
+	t.Run("history without a partner is per room", func(t *testing.T) {
+		other := loadTestRoom(t, store, CreateTestRoom(t, store, owner.ID, "en"))
+		other.SelectedCategories = room.SelectedCategories
+		_, err := questionService.GetRandomQuestion(ctx, other)
+		AssertNoError(t, err, "get random question in another room")
+	})
+}
+
+// TestHistoryQuestionIDs_Paged tests that history longer than one page is read in full
+func TestHistoryQuestionIDs_Paged(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	questionService := NewQuestionService(store)
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+	categoryID := CreateTestCategory(t, store, "paged")
+	for i := 0; i <= historyPageSize; i++ {
+		questionID := CreateTestQuestion(t, store, categoryID, "en", fmt.Sprintf("Question %d", i))
+		_, err := store.Insert(ctx, "question_history", map[string]interface{}{
+			"room_id":     roomID.String(),
+			"question_id": questionID.String(),
+		})
+		AssertNoError(t, err, "insert question history")
+	}
+
+	asked, err := questionService.historyQuestionIDs(ctx, "question_history", Where(WithRoomID(roomID)))
+	AssertNoError(t, err, "history question ids")
+	AssertEqual(t, historyPageSize+1, len(asked), "asked questions")
+}
+
+// TestGetRandomQuestion_PlayerHistory tests that questions seen in earlier sessions are excluded
+func TestGetRandomQuestion_PlayerHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	questionService := NewQuestionService(store)
+
+	alice := CreateTestUser(t, store, "alice", "Alice", true)
+	bob := CreateTestUser(t, store, "bob", "Bob", true)
+	carol := CreateTestUser(t, store, "carol", "Carol", true)
+	categoryID := CreateTestCategory(t, store, "couples")
+	seenID := CreateTestQuestion(t, store, categoryID, "en", "Seen together")
+	freshID := CreateTestQuestion(t, store, categoryID, "en", "Fresh")
+
+	newRoom := func(owner, guest *UserTestData) *models.Room {
+		room := loadTestRoom(t, store, CreateTestRoom(t, store, owner.ID, "en"))
+		room.GuestID = &guest.ID
+		room.SelectedCategories = []uuid.UUID{categoryID}
+		return room
+	}
+
+	first := newRoom(alice, bob)
+	AssertNoError(t, questionService.MarkQuestionAsked(ctx, first, seenID), "mark question asked")
+
+	t.Run("either partner can own the next room", func(t *testing.T) {
+		for _, room := range []*models.Room{newRoom(alice, bob), newRoom(bob, alice)} {
+			question, err := questionService.GetRandomQuestion(ctx, room)
+			AssertNoError(t, err, "get random question")
+			AssertEqual(t, freshID, question.ID, "drawn question")
+		}
+	})
+
+	t.Run("user scope excludes what either player has seen", func(t *testing.T) {
+		userScoped := NewQuestionService(store)
+		userScoped.SetHistoryScope(HistoryScopeUser)
+		for i := 0; i < 10; i++ {
+			question, err := userScoped.GetRandomQuestion(ctx, newRoom(alice, carol))
+			AssertNoError(t, err, "get random question")
+			AssertEqual(t, freshID, question.ID, "drawn question")
+		}
+	})
+
+	t.Run("pair scope ignores other partners", func(t *testing.T) {
+		room := newRoom(alice, carol)
+		AssertNoError(t, questionService.MarkQuestionAsked(ctx, room, freshID), "mark question asked")
+		question, err := questionService.GetRandomQuestion(ctx, room)
+		AssertNoError(t, err, "a question alice saw with bob should be drawable with carol")
+		AssertEqual(t, seenID, question.ID, "drawn question")
+	})
+
+	t.Run("exhausted pool requires repeats or a reset", func(t *testing.T) {
+		room := newRoom(alice, bob)
+		AssertNoError(t, questionService.MarkQuestionAsked(ctx, room, freshID), "mark question asked")
+
+		next := newRoom(bob, alice)
+		_, err := questionService.GetRandomQuestion(ctx, next)
+		if !errors.Is(err, models.ErrQuestionsAllSeen) {
+			t.Fatalf("expected ErrQuestionsAllSeen, got %v", err)
+		}
+
+		next.AllowRepeats = true
+		_, err = questionService.GetRandomQuestion(ctx, next)
+		AssertNoError(t, err, "get repeated question")
+
+		// Repeats are still never drawn twice in the same room
+		AssertNoError(t, questionService.MarkQuestionAsked(ctx, next, seenID), "mark repeated question asked")
+		AssertNoError(t, questionService.MarkQuestionAsked(ctx, next, freshID), "mark repeated question asked")
+		_, err = questionService.GetRandomQuestion(ctx, next)
+		if !errors.Is(err, models.ErrNoQuestionsAvailable) {
+			t.Errorf("expected ErrNoQuestionsAvailable once the room has asked everything, got %v", err)
+		}
+
+		AssertNoError(t, questionService.ResetPairHistory(ctx, bob.ID, alice.ID), "reset pair history")
+		_, err = questionService.GetRandomQuestion(ctx, newRoom(alice, bob))
+		AssertNoError(t, err, "get random question after reset")
+	})
+}
+
+// TestGetRandomQuestion_IsRandom tests that rooms don't all draw the same first question
+func TestGetRandomQuestion_IsRandom(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	questionService := NewQuestionService(store)
+	questionService.intn = rand.New(rand.NewSource(1)).Intn
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	categoryID := CreateTestCategory(t, store, "random")
+	for i := 0; i < 10; i++ {
+		CreateTestQuestion(t, store, categoryID, "en", fmt.Sprintf("Question %d", i))
+	}
+	room := loadTestRoom(t, store, CreateTestRoom(t, store, owner.ID, "en"))
+	room.SelectedCategories = []uuid.UUID{categoryID}
+
+	first := make(map[uuid.UUID]bool)
+	for i := 0; i < 20; i++ {
+		question, err := questionService.GetRandomQuestion(ctx, room)
+		AssertNoError(t, err, "get random question")
+		first[question.ID] = true
+	}
+	if len(first) < 2 {
+		t.Errorf("20 draws from a fresh room all returned the same question")
+	}
+}
+
+// TestDrawQuestion_CategoryMix tests that draws are spread across categories by weight
+func TestDrawQuestion_CategoryMix(t *testing.T) {
+	categories := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	var pool []drawCandidate
+	for _, categoryID := range categories {
+		for i := 0; i < 20; i++ {
+			pool = append(pool, drawCandidate{ID: uuid.New(), CategoryID: categoryID})
+		}
+	}
+	category := make(map[uuid.UUID]uuid.UUID, len(pool))
+	for _, candidate := range pool {
+		category[candidate.ID] = candidate.CategoryID
+	}
+
+	draw := func(weights map[uuid.UUID]float64, n int) map[uuid.UUID]int {
+		intn := rand.New(rand.NewSource(42)).Intn
+		asked := make(map[uuid.UUID]bool)
+		perCategory := make(map[uuid.UUID]int)
+		for i := 0; i < n; i++ {
+			id, ok := drawQuestion(pool, asked, nil, weights, intn)
+			if !ok {
+				t.Fatalf("pool exhausted after %d draws", i)
+			}
+			if asked[id] {
+				t.Fatalf("question %s drawn twice", id)
+			}
+			asked[id] = true
+			perCategory[category[id]]++
+		}
+		return perCategory
+	}
+
+	t.Run("even mix without weights", func(t *testing.T) {
+		counts := draw(nil, 15)
+		for _, categoryID := range categories {
+			AssertEqual(t, 5, counts[categoryID], "draws per category")
+		}
+	})
+
+	t.Run("weights scale the share of draws", func(t *testing.T) {
+		counts := draw(map[uuid.UUID]float64{categories[0]: 2, categories[1]: 1, categories[2]: 1}, 16)
+		AssertEqual(t, 8, counts[categories[0]], "draws from the double-weight category")
+		AssertEqual(t, 4, counts[categories[1]], "draws from a normal category")
+		AssertEqual(t, 4, counts[categories[2]], "draws from a normal category")
+	})
+
+	t.Run("seen questions are skipped but don't count toward the mix", func(t *testing.T) {
+		seen := map[uuid.UUID]bool{pool[0].ID: true, pool[1].ID: true}
+		intn := rand.New(rand.NewSource(7)).Intn
+		asked := make(map[uuid.UUID]bool)
+		perCategory := make(map[uuid.UUID]int)
+		for i := 0; i < 9; i++ {
+			id, ok := drawQuestion(pool, asked, seen, nil, intn)
+			AssertTrue(t, ok, "a question should remain")
+			AssertFalse(t, seen[id], "seen question drawn")
+			asked[id] = true
+			perCategory[category[id]]++
+		}
+		for _, categoryID := range categories {
+			AssertEqual(t, 3, perCategory[categoryID], "draws per category")
+		}
+	})
+
+	t.Run("exhausted categories fall back to the others", func(t *testing.T) {
+		small := []drawCandidate{
+			{ID: uuid.New(), CategoryID: categories[0]},
+			{ID: uuid.New(), CategoryID: categories[1]},
+			{ID: uuid.New(), CategoryID: categories[1]},
+		}
+		asked := map[uuid.UUID]bool{small[0].ID: true, small[1].ID: true}
+		id, ok := drawQuestion(small, asked, nil, nil, rand.New(rand.NewSource(1)).Intn)
+		AssertTrue(t, ok, "a question should remain")
+		AssertEqual(t, small[2].ID, id, "drawn question")
+
+		asked[small[2].ID] = true
+		_, ok = drawQuestion(small, asked, nil, nil, rand.New(rand.NewSource(1)).Intn)
+		AssertFalse(t, ok, "pool should be exhausted")
 	})
 }
 
@@ -220,3 +499,13 @@ func BenchmarkGetRandomQuestion(b *testing.B) {
 func BenchmarkMarkQuestionAsked(b *testing.B) {
 	b.Skip("Requires test database setup")
 }
+
+// loadTestRoom fetches a room created by CreateTestRoom so tests can adjust its settings
+func loadTestRoom(t *testing.T, store Store, roomID uuid.UUID) *models.Room {
+	t.Helper()
+	room, err := NewRoomService(store, NewRealtimeService()).GetRoomByID(context.Background(), roomID)
+	if err != nil {
+		t.Fatalf("Failed to load test room: %v", err)
+	}
+	return room
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// RealtimeBroker carries realtime events to every server instance
+// RealtimeService publishes each broadcast through it and only delivers to its own clients
+// what comes back, so players connected to different instances see the same events.
+type RealtimeBroker interface {
+	// Publish sends msg to every instance, this one included
+	Publish(ctx context.Context, msg BrokerMessage) error
+	// Start begins calling deliver for every published message
+	Start(deliver func(BrokerMessage)) error
+	// Close stops delivery
+	Close() error
+}
+
+// BrokerMessage is an event on its way to the clients it targets:
+// the room's clients (UserID is uuid.Nil), one user's clients in the room,
+// or every connection of a user (RoomID is uuid.Nil)
+type BrokerMessage struct {
+	RoomID uuid.UUID     `json:"room_id"`
+	UserID uuid.UUID     `json:"user_id"`
+	Event  RealtimeEvent `json:"event"`
+}
+
+// LocalBroker delivers messages within the process, for a single instance
+type LocalBroker struct {
+	mu      sync.RWMutex
+	deliver func(BrokerMessage)
+}
+
+// NewLocalBroker creates an in-process broker
+func NewLocalBroker() *LocalBroker {
+	return &LocalBroker{}
+}
+
+// Publish delivers msg synchronously
+func (b *LocalBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	b.mu.RLock()
+	deliver := b.deliver
+	b.mu.RUnlock()
+
+	if deliver != nil {
+		deliver(msg)
+	}
+	return nil
+}
+
+// Start sets the delivery callback
+func (b *LocalBroker) Start(deliver func(BrokerMessage)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = deliver
+	return nil
+}
+
+// Close stops delivery
+func (b *LocalBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deliver = nil
+	return nil
+}
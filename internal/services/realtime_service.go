@@ -1,19 +1,26 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 // RealtimeEvent represents a real-time event
+// Room events get an increasing ID (sent as the SSE id) so clients can resume after it.
 type RealtimeEvent struct {
+	ID   int64       `json:"id,omitempty"`
 	Type string      `json:"type"`
 	Data interface{} `json:"data"`
 }
 
+// realtimePublishTimeout bounds handing one event to the broker (and persisting it)
+const realtimePublishTimeout = 5 * time.Second
+
 // RealtimeClient represents a connected client
 type RealtimeClient struct {
 	ID      string
@@ -23,23 +30,92 @@ type RealtimeClient struct {
 }
 
 // RealtimeService manages real-time connections
+// Broadcasts go through a RealtimeBroker and are delivered to this instance's clients
+// when the broker hands them back, so every instance serves the same events.
 type RealtimeService struct {
-	clients map[string]*RealtimeClient
-	mu      sync.RWMutex
+	clients  map[string]*RealtimeClient
+	events   *RoomEventLog
+	broker   RealtimeBroker
+	presence PresenceObserver
+	mu       sync.RWMutex
+	closed   bool
 }
 
 // NewRealtimeService creates a new realtime service
+// Room events are kept in memory for replay; use SetEventLog to change the size or persist them.
+// Events are delivered in-process; use SetBroker to share them with other instances.
 func NewRealtimeService() *RealtimeService {
-	return &RealtimeService{
+	s := &RealtimeService{
 		clients: make(map[string]*RealtimeClient),
+		events:  NewRoomEventLog(DefaultEventReplaySize, nil),
+		broker:  NewLocalBroker(),
+	}
+	s.broker.Start(s.deliver)
+	return s
+}
+
+// SetBroker replaces the broker events are published through and starts it
+func (s *RealtimeService) SetBroker(broker RealtimeBroker) error {
+	if err := broker.Start(s.deliver); err != nil {
+		return err
 	}
+
+	s.mu.Lock()
+	previous := s.broker
+	s.broker = broker
+	s.mu.Unlock()
+
+	return previous.Close()
+}
+
+// SetEventLog replaces the room event log used for Last-Event-ID replay
+func (s *RealtimeService) SetEventLog(events *RoomEventLog) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = events
+}
+
+// SetPresenceObserver sets who is told about room clients connecting and disconnecting
+func (s *RealtimeService) SetPresenceObserver(presence PresenceObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.presence = presence
 }
 
 // Subscribe adds a new client
 func (s *RealtimeService) Subscribe(roomID, userID uuid.UUID) *RealtimeClient {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	client := s.subscribe(roomID, userID)
+	s.mu.Unlock()
+
+	s.connected(client)
+	return client
+}
+
+// SubscribeFrom adds a new client that resumes after lastEventID (the SSE Last-Event-ID)
+// It returns the room events the client missed, registered atomically with the
+// subscription so nothing is lost or sent twice in between. complete is false when
+// the missed events are no longer available and the client must reload its state.
+func (s *RealtimeService) SubscribeFrom(ctx context.Context, roomID, userID uuid.UUID, lastEventID int64) (client *RealtimeClient, missed []RealtimeEvent, complete bool) {
+	if lastEventID <= 0 {
+		return s.Subscribe(roomID, userID), nil, true
+	}
 
+	if err := s.events.load(ctx, roomID); err != nil {
+		fmt.Printf("⚠️ Warning: %v\n", err)
+	}
+
+	s.mu.Lock()
+	missed, complete = s.events.since(roomID, userID, lastEventID)
+	client = s.subscribe(roomID, userID)
+	s.mu.Unlock()
+
+	s.connected(client)
+	return client, missed, complete
+}
+
+// subscribe registers a client (s.mu must be held)
+func (s *RealtimeService) subscribe(roomID, userID uuid.UUID) *RealtimeClient {
 	client := &RealtimeClient{
 		ID:      uuid.New().String(),
 		RoomID:  roomID,
@@ -47,45 +123,164 @@ func (s *RealtimeService) Subscribe(roomID, userID uuid.UUID) *RealtimeClient {
 		Channel: make(chan RealtimeEvent, 100), // Increased buffer size to prevent dropped events
 	}
 
+	// After shutdown, hand back an already-closed channel so the stream ends immediately
+	if s.closed {
+		close(client.Channel)
+		return client
+	}
+
 	s.clients[client.ID] = client
 	return client
 }
 
+// connected tells the presence observer about a new room client (s.mu must not be held:
+// the observer may broadcast)
+func (s *RealtimeService) connected(client *RealtimeClient) {
+	s.mu.RLock()
+	presence, closed := s.presence, s.closed
+	s.mu.RUnlock()
+
+	if presence != nil && !closed && client.RoomID != uuid.Nil {
+		presence.ClientConnected(client.RoomID, client.UserID)
+	}
+}
+
 // Unsubscribe removes a client
 func (s *RealtimeService) Unsubscribe(clientID string) {
+	s.mu.Lock()
+	client, exists := s.clients[clientID]
+	if exists {
+		close(client.Channel)
+		delete(s.clients, clientID)
+	}
+	presence := s.presence
+	s.mu.Unlock()
+
+	if exists && presence != nil && client.RoomID != uuid.Nil {
+		presence.ClientDisconnected(client.RoomID, client.UserID)
+	}
+}
+
+// Shutdown stops the broker and closes every client channel so open SSE streams return
+// cleanly. Subsequent subscriptions receive a closed channel. Safe to call more than once.
+func (s *RealtimeService) Shutdown() int {
+	s.mu.RLock()
+	broker := s.broker
+	s.mu.RUnlock()
+	if err := broker.Close(); err != nil {
+		fmt.Printf("⚠️ Warning: failed to close realtime broker: %v\n", err)
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if client, exists := s.clients[clientID]; exists {
+	count := len(s.clients)
+	for id, client := range s.clients {
 		close(client.Channel)
-		delete(s.clients, clientID)
+		delete(s.clients, id)
 	}
+	s.closed = true
+
+	return count
+}
+
+// ClientCount returns the number of connected clients
+func (s *RealtimeService) ClientCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.clients)
 }
 
 // Broadcast sends an event to all clients in a room
 func (s *RealtimeService) Broadcast(roomID uuid.UUID, event RealtimeEvent) {
+	s.publish(roomID, uuid.Nil, event)
+}
+
+// BroadcastToUser sends an event to all connections for a specific user
+// These events are not logged for replay: they are not tied to one room's stream.
+func (s *RealtimeService) BroadcastToUser(userID uuid.UUID, event RealtimeEvent) {
+	s.publish(uuid.Nil, userID, event)
+}
+
+// publish sends an event through the broker to the room's clients (only userID's when
+// set), or to every connection of userID when roomID is uuid.Nil
+// Room events get their ID here and are persisted once, by the publishing instance.
+func (s *RealtimeService) publish(roomID, userID uuid.UUID, event RealtimeEvent) {
 	s.mu.RLock()
-	defer s.mu.RUnlock()
+	events, broker := s.events, s.broker
+	s.mu.RUnlock()
+
+	if roomID != uuid.Nil {
+		event.ID = events.nextID()
+	}
+
+	// Bounded: a broker waiting on a busy pool must not hang the request that broadcasts
+	ctx, cancel := context.WithTimeout(context.Background(), realtimePublishTimeout)
+	defer cancel()
+	if err := broker.Publish(ctx, BrokerMessage{RoomID: roomID, UserID: userID, Event: event}); err != nil {
+		fmt.Printf("⚠️ Warning: %v\n", err)
+		return
+	}
+
+	if roomID != uuid.Nil {
+		if err := events.persist(ctx, roomID, userID, event); err != nil {
+			fmt.Printf("⚠️ Warning: %v\n", err)
+		}
+	}
+}
+
+// deliver hands a message from the broker to this instance's clients
+func (s *RealtimeService) deliver(msg BrokerMessage) {
+	if msg.RoomID == uuid.Nil {
+		s.deliverToUser(msg.UserID, msg.Event)
+		return
+	}
+
+	sent := s.deliverToRoom(msg.RoomID, msg.UserID, msg.Event)
+	if msg.Event.Type == "room_deleted" {
+		// Nobody can reconnect to a deleted room
+		s.events.forget(msg.RoomID)
+	}
+	if sent > 0 {
+		if msg.UserID != uuid.Nil {
+			fmt.Printf("📡 Broadcasted %s event to %d clients for user %s in room %s\n", msg.Event.Type, sent, msg.UserID, msg.RoomID)
+		} else {
+			fmt.Printf("📡 Broadcasted %s event to %d clients in room %s\n", msg.Event.Type, sent, msg.RoomID)
+		}
+	}
+}
 
-	broadcastCount := 0
+// deliverToRoom logs a room event and sends it to the room's clients (only userID's when set)
+// A client whose channel is full is disconnected rather than silently skipped: its
+// browser reconnects with Last-Event-ID and replays what it missed.
+func (s *RealtimeService) deliverToRoom(roomID, userID uuid.UUID, event RealtimeEvent) int {
+	s.mu.RLock()
+	s.events.add(roomID, userID, event)
+
+	sent := 0
+	var lagging []string
 	for _, client := range s.clients {
-		if client.RoomID == roomID {
-			select {
-			case client.Channel <- event:
-				broadcastCount++
-			default:
-				// Channel full, skip this client
-				fmt.Printf("⚠️ Warning: Skipped broadcasting %s event to client %s (channel full)\n", event.Type, client.ID)
-			}
+		if client.RoomID != roomID || (userID != uuid.Nil && client.UserID != userID) {
+			continue
+		}
+		select {
+		case client.Channel <- event:
+			sent++
+		default:
+			fmt.Printf("⚠️ Warning: Client %s fell behind on %s events (channel full), disconnecting it to replay\n", client.ID, event.Type)
+			lagging = append(lagging, client.ID)
 		}
 	}
-	if broadcastCount > 0 {
-		fmt.Printf("📡 Broadcasted %s event to %d clients in room %s\n", event.Type, broadcastCount, roomID)
+	s.mu.RUnlock()
+
+	for _, clientID := range lagging {
+		s.Unsubscribe(clientID)
 	}
+	return sent
 }
 
-// BroadcastToUser sends an event to all connections for a specific user
-func (s *RealtimeService) BroadcastToUser(userID uuid.UUID, event RealtimeEvent) {
+// deliverToUser sends an event to every connection of a user
+func (s *RealtimeService) deliverToUser(userID uuid.UUID, event RealtimeEvent) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -187,6 +382,7 @@ func (s *RealtimeService) BroadcastGameFinished(roomID uuid.UUID, results interf
 }
 
 // BroadcastRoomDeleted broadcasts a room_deleted event
+// Every instance drops the room's replay buffer once delivered (see deliver).
 func (s *RealtimeService) BroadcastRoomDeleted(roomID uuid.UUID) {
 	s.Broadcast(roomID, RealtimeEvent{
 		Type: "room_deleted",
@@ -283,59 +479,22 @@ type HTMLFragmentEvent struct {
 // This is the HTMX-compatible version that sends HTML directly
 // HTMX SSE extension expects the HTML as the data field, not wrapped in JSON
 func (s *RealtimeService) BroadcastHTMLFragment(roomID uuid.UUID, fragment HTMLFragmentEvent) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	broadcastCount := 0
-	for _, client := range s.clients {
-		if client.RoomID == roomID {
-			// HTMX SSE extension expects the HTML directly as a string
-			// The sse-swap attribute in the template handles where it goes
-			event := RealtimeEvent{
-				Type: fragment.Type,
-				Data: fragment.HTML, // Send HTML directly, not wrapped in JSON
-			}
-
-			select {
-			case client.Channel <- event:
-				broadcastCount++
-			default:
-				fmt.Printf("⚠️ Client channel full, skipping\n")
-			}
-		}
-	}
-	if broadcastCount > 0 {
-		fmt.Printf("📡 Broadcasted HTML fragment (%s) to %d clients in room %s\n", fragment.Type, broadcastCount, roomID)
-	}
+	// HTMX SSE extension expects the HTML directly as a string
+	// The sse-swap attribute in the template handles where it goes
+	s.publish(roomID, uuid.Nil, RealtimeEvent{
+		Type: fragment.Type,
+		Data: fragment.HTML, // Send HTML directly, not wrapped in JSON
+	})
 }
 
 // BroadcastHTMLFragmentToUser sends HTML fragment to a specific user in a room
 // Only clients matching both roomID AND userID will receive the fragment
 func (s *RealtimeService) BroadcastHTMLFragmentToUser(roomID uuid.UUID, userID uuid.UUID, fragment HTMLFragmentEvent) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	broadcastCount := 0
-	for _, client := range s.clients {
-		// Filter by BOTH roomID and userID
-		if client.RoomID == roomID && client.UserID == userID {
-			event := RealtimeEvent{
-				Type: fragment.Type,
-				Data: fragment.HTML,
-			}
-
-			select {
-			case client.Channel <- event:
-				broadcastCount++
-			default:
-				fmt.Printf("⚠️ Client channel full, skipping\n")
-			}
-		}
-	}
-	if broadcastCount > 0 {
-		fmt.Printf("📡 Broadcasted HTML fragment (%s) to %d clients for user %s in room %s\n",
-			fragment.Type, broadcastCount, userID, roomID)
-	}
+	// Filter by BOTH roomID and userID (replay also only goes to that user)
+	s.publish(roomID, userID, RealtimeEvent{
+		Type: fragment.Type,
+		Data: fragment.HTML,
+	})
 }
 
 // HTMLFragmentToSSE converts an HTML fragment event to SSE format
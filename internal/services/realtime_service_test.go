@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/google/uuid"
 )
 
 // TestHTMLFragmentToSSE tests SSE format conversion
@@ -83,3 +87,209 @@ func TestHTMLFragmentEvent_AllSwapMethods(t *testing.T) {
 		})
 	}
 }
+
+// TestRealtimeService_Shutdown tests that shutdown closes every open client stream
+func TestRealtimeService_Shutdown(t *testing.T) {
+	service := NewRealtimeService()
+	roomID := uuid.New()
+
+	first := service.Subscribe(roomID, uuid.New())
+	second := service.Subscribe(roomID, uuid.New())
+	AssertEqual(t, 2, service.ClientCount(), "Both clients should be registered")
+
+	closed := service.Shutdown()
+	AssertEqual(t, 2, closed, "Shutdown should report closed clients")
+	AssertEqual(t, 0, service.ClientCount(), "No clients should remain")
+
+	_, ok := <-first.Channel
+	AssertTrue(t, !ok, "First client channel should be closed")
+	_, ok = <-second.Channel
+	AssertTrue(t, !ok, "Second client channel should be closed")
+
+	// Unsubscribing after shutdown must not double-close
+	service.Unsubscribe(first.ID)
+
+	// Late subscribers get an already-closed channel
+	late := service.Subscribe(roomID, uuid.New())
+	_, ok = <-late.Channel
+	AssertTrue(t, !ok, "Subscriptions after shutdown should be closed immediately")
+	AssertEqual(t, 0, service.ClientCount(), "Late subscribers should not be tracked")
+
+	// Broadcasting after shutdown is a no-op
+	service.Broadcast(roomID, RealtimeEvent{Type: "noop"})
+}
+
+// TestRealtimeService_SubscribeFrom tests replaying the events a reconnecting client missed
+func TestRealtimeService_SubscribeFrom(t *testing.T) {
+	ctx := context.Background()
+	service := NewRealtimeService()
+	roomID := uuid.New()
+	userID := uuid.New()
+	otherID := uuid.New()
+
+	first := service.Subscribe(roomID, userID)
+	service.Broadcast(roomID, RealtimeEvent{Type: "room_update"})
+	seen := <-first.Channel
+	AssertTrue(t, seen.ID != 0, "Logged events should carry an ID")
+	service.Unsubscribe(first.ID)
+
+	// While disconnected: one event for the room, one for each player
+	service.Broadcast(roomID, RealtimeEvent{Type: "question_drawn"})
+	service.BroadcastHTMLFragmentToUser(roomID, userID, HTMLFragmentEvent{Type: "mine", Target: "#a", HTML: "<p>a</p>"})
+	service.BroadcastHTMLFragmentToUser(roomID, otherID, HTMLFragmentEvent{Type: "theirs", Target: "#b", HTML: "<p>b</p>"})
+
+	t.Run("replays missed events for the user", func(t *testing.T) {
+		client, missed, complete := service.SubscribeFrom(ctx, roomID, userID, seen.ID)
+		defer service.Unsubscribe(client.ID)
+
+		AssertTrue(t, complete, "Replay should be complete")
+		AssertEqual(t, 2, len(missed), "Room event and own event should be replayed")
+		AssertEqual(t, "question_drawn", missed[0].Type, "Events should be replayed in order")
+		AssertEqual(t, "mine", missed[1].Type, "Events for other players should be skipped")
+		AssertTrue(t, missed[0].ID > seen.ID && missed[1].ID > missed[0].ID, "IDs should increase")
+	})
+
+	t.Run("up to date client gets nothing", func(t *testing.T) {
+		client, missed, complete := service.SubscribeFrom(ctx, roomID, otherID, 1<<62)
+		defer service.Unsubscribe(client.ID)
+
+		AssertTrue(t, complete, "Replay should be complete")
+		AssertEqual(t, 0, len(missed), "Nothing should be replayed")
+	})
+
+	t.Run("unknown id asks for a resync", func(t *testing.T) {
+		client, missed, complete := service.SubscribeFrom(ctx, roomID, userID, seen.ID-1)
+		defer service.Unsubscribe(client.ID)
+
+		AssertFalse(t, complete, "Replay from an unknown event should be incomplete")
+		AssertEqual(t, 0, len(missed), "Nothing should be replayed")
+	})
+
+	t.Run("evicted events ask for a resync", func(t *testing.T) {
+		small := NewRealtimeService()
+		small.SetEventLog(NewRoomEventLog(2, nil))
+
+		client := small.Subscribe(roomID, userID)
+		small.Broadcast(roomID, RealtimeEvent{Type: "first"})
+		last := <-client.Channel
+		small.Unsubscribe(client.ID)
+
+		small.Broadcast(roomID, RealtimeEvent{Type: "second"})
+		small.Broadcast(roomID, RealtimeEvent{Type: "third"})
+
+		client, _, complete := small.SubscribeFrom(ctx, roomID, userID, last.ID)
+		defer small.Unsubscribe(client.ID)
+		AssertFalse(t, complete, "Replay past the ring size should be incomplete")
+	})
+}
+
+// TestRealtimeService_LaggingClientDisconnected tests that a full channel disconnects the client
+func TestRealtimeService_LaggingClientDisconnected(t *testing.T) {
+	service := NewRealtimeService()
+	roomID := uuid.New()
+
+	client := service.Subscribe(roomID, uuid.New())
+	for i := 0; i <= cap(client.Channel); i++ {
+		service.Broadcast(roomID, RealtimeEvent{Type: "room_update"})
+	}
+
+	AssertEqual(t, 0, service.ClientCount(), "Lagging client should be disconnected")
+	received := 0
+	for range client.Channel {
+		received++
+	}
+	AssertEqual(t, cap(client.Channel), received, "Buffered events should still be delivered before the channel closes")
+}
+
+// TestRoomEventLog_Persisted tests that persisted events are replayed by a fresh log
+func TestRoomEventLog_Persisted(t *testing.T) {
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	owner := CreateTestUser(t, store, "event_owner", "Event Owner", false)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	before := NewRealtimeService()
+	before.SetEventLog(NewRoomEventLog(2, store))
+	client := before.Subscribe(roomID, owner.ID)
+	before.Broadcast(roomID, RealtimeEvent{Type: "first", Data: map[string]interface{}{"n": 1}})
+	first := <-client.Channel
+	before.Broadcast(roomID, RealtimeEvent{Type: "second", Data: map[string]interface{}{"n": 2}})
+	before.Broadcast(roomID, RealtimeEvent{Type: "third", Data: map[string]interface{}{"n": 3}})
+	second := <-client.Channel
+
+	count, err := store.Count(ctx, "room_events", Where(WithRoomID(roomID)))
+	AssertNoError(t, err, "count room events")
+	AssertEqual(t, 2, count, "Persisted events should be pruned to the ring size")
+
+	// A restarted server loads the room's events from the database
+	after := NewRealtimeService()
+	after.SetEventLog(NewRoomEventLog(2, store))
+	replayed, missed, complete := after.SubscribeFrom(ctx, roomID, owner.ID, second.ID)
+	defer after.Unsubscribe(replayed.ID)
+
+	AssertTrue(t, complete, "Replay should be complete after a restart")
+	AssertEqual(t, 1, len(missed), "The last event should be replayed")
+	AssertEqual(t, "third", missed[0].Type, "The last event should be replayed")
+
+	_, _, complete = after.SubscribeFrom(ctx, roomID, owner.ID, first.ID)
+	AssertFalse(t, complete, "Pruned events should ask for a resync")
+}
+
+// TestRealtimeService_SharedBroker tests that instances sharing a broker serve each other's events
+func TestRealtimeService_SharedBroker(t *testing.T) {
+	ctx := context.Background()
+	bus := &testBus{}
+	first := NewRealtimeService()
+	second := NewRealtimeService()
+	AssertNoError(t, first.SetBroker(bus.broker()), "set first broker")
+	AssertNoError(t, second.SetBroker(bus.broker()), "set second broker")
+
+	roomID := uuid.New()
+	userID := uuid.New()
+	remote := second.Subscribe(roomID, userID)
+
+	first.Broadcast(roomID, RealtimeEvent{Type: "room_update"})
+	first.BroadcastHTMLFragment(roomID, HTMLFragmentEvent{Type: "question_drawn", HTML: "<p>q</p>"})
+	first.BroadcastToUser(userID, RealtimeEvent{Type: "notification"})
+
+	got := []RealtimeEvent{<-remote.Channel, <-remote.Channel, <-remote.Channel}
+	AssertEqual(t, "room_update", got[0].Type, "Room events should reach the other instance")
+	AssertEqual(t, "<p>q</p>", got[1].Data, "HTML fragments should reach the other instance")
+	AssertEqual(t, "notification", got[2].Type, "User events should reach the other instance")
+
+	// The other instance can replay what the first one published
+	second.Unsubscribe(remote.ID)
+	client, missed, complete := second.SubscribeFrom(ctx, roomID, userID, got[0].ID)
+	defer second.Unsubscribe(client.ID)
+	AssertTrue(t, complete, "Replay should be complete")
+	AssertEqual(t, 1, len(missed), "Events after the last one seen should be replayed")
+	AssertEqual(t, "question_drawn", missed[0].Type, "The fragment should be replayed")
+}
+
+// testBus connects RealtimeServices like separate instances behind one broker
+type testBus struct {
+	mu       sync.RWMutex
+	delivers []func(BrokerMessage)
+}
+
+func (b *testBus) broker() RealtimeBroker { return &testBusBroker{bus: b} }
+
+type testBusBroker struct{ bus *testBus }
+
+func (b *testBusBroker) Publish(ctx context.Context, msg BrokerMessage) error {
+	b.bus.mu.RLock()
+	defer b.bus.mu.RUnlock()
+	for _, deliver := range b.bus.delivers {
+		deliver(msg)
+	}
+	return nil
+}
+
+func (b *testBusBroker) Start(deliver func(BrokerMessage)) error {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+	b.bus.delivers = append(b.bus.delivers, deliver)
+	return nil
+}
+
+func (b *testBusBroker) Close() error { return nil }
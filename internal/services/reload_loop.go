@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// reloadInterval is how often cached admin-edited tables (translations, languages) are re-read,
+// so edits made on another instance show up
+const reloadInterval = time.Minute
+
+// reloadLoop re-reads a cached table every reloadInterval between start and stop
+type reloadLoop struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// start runs reload every reloadInterval; a second start is a no-op
+func (l *reloadLoop) start(logger *ServiceLogger, reload func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	l.cancel = cancel
+	l.done = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+
+		ticker := time.NewTicker(reloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := reload(ctx); err != nil && ctx.Err() == nil {
+					logger.Warn("Failed to reload: %v", err)
+				}
+			}
+		}
+	}(l.done)
+}
+
+// stop ends the loop and waits for a reload in progress
+func (l *reloadLoop) stop() {
+	l.mu.Lock()
+	cancel, done := l.cancel, l.done
+	l.cancel = nil
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
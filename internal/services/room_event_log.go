@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultEventReplaySize is how many events per room are kept for replay by default
+const DefaultEventReplaySize = 200
+
+// eventRetention is how long a room without new events keeps its ring in memory
+const eventRetention = time.Hour
+
+// RoomEventLog keeps the latest events of every room so a reconnecting SSE client can
+// replay what it missed (see RealtimeService.SubscribeFrom).
+//
+// Event IDs are microsecond timestamps forced to increase, so they keep increasing across
+// restarts and match the SSE Last-Event-ID a browser sends back. With a Store, events are
+// also written to room_events (bounded to the same size) so replay survives a restart.
+type RoomEventLog struct {
+	size  int
+	store Store
+
+	mu        sync.Mutex
+	lastID    int64
+	lastSweep time.Time
+	rooms     map[uuid.UUID]*eventRing
+}
+
+// loggedEvent is a room event with the user it was sent to (uuid.Nil for everyone in the room)
+type loggedEvent struct {
+	event  RealtimeEvent
+	userID uuid.UUID
+}
+
+// eventRing is a bounded, ID-ordered buffer of one room's events
+type eventRing struct {
+	events []loggedEvent
+	loaded bool // room_events has been read into the ring
+}
+
+// roomEventRow is a room_events row
+type roomEventRow struct {
+	ID     int64      `json:"id"`
+	RoomID uuid.UUID  `json:"room_id"`
+	UserID *uuid.UUID `json:"user_id"`
+	Type   string     `json:"type"`
+	Data   string     `json:"data"`
+}
+
+// NewRoomEventLog creates a log keeping size events per room; store may be nil to keep
+// events in memory only
+func NewRoomEventLog(size int, store Store) *RoomEventLog {
+	if size < 1 {
+		size = DefaultEventReplaySize
+	}
+	return &RoomEventLog{
+		size:  size,
+		store: store,
+		rooms: make(map[uuid.UUID]*eventRing),
+	}
+}
+
+// nextID returns an ID greater than every event seen so far
+func (l *RoomEventLog) nextID() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	id := time.Now().UnixMicro()
+	if id <= l.lastID {
+		id = l.lastID + 1
+	}
+	l.lastID = id
+	return id
+}
+
+// add puts an event (already given its ID) into the room's ring, in ID order
+// Events published by other instances can arrive slightly out of order; one already
+// in the ring is ignored.
+func (l *RoomEventLog) add(roomID, userID uuid.UUID, event RealtimeEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if event.ID > l.lastID {
+		l.lastID = event.ID
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > eventRetention {
+		l.sweep(now)
+	}
+
+	ring := l.ring(roomID)
+	at := sort.Search(len(ring.events), func(i int) bool { return ring.events[i].event.ID >= event.ID })
+	if at < len(ring.events) && ring.events[at].event.ID == event.ID {
+		return
+	}
+	ring.events = append(ring.events, loggedEvent{})
+	copy(ring.events[at+1:], ring.events[at:])
+	ring.events[at] = loggedEvent{event: event, userID: userID}
+
+	if len(ring.events) > l.size {
+		ring.events = append([]loggedEvent(nil), ring.events[len(ring.events)-l.size:]...)
+	}
+}
+
+// since returns the events userID may see that came after lastID
+// complete is false when events after lastID have already been dropped (or were never
+// known to this process), in which case the client has to reload its state.
+func (l *RoomEventLog) since(roomID, userID uuid.UUID, lastID int64) (events []RealtimeEvent, complete bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ring := l.rooms[roomID]
+	if ring == nil || len(ring.events) == 0 {
+		return nil, false
+	}
+
+	// lastID is an event the client received, so it is either still in the ring or
+	// the client is already up to date
+	newest := ring.events[len(ring.events)-1].event.ID
+	if lastID >= newest {
+		return nil, true
+	}
+
+	start := sort.Search(len(ring.events), func(i int) bool { return ring.events[i].event.ID >= lastID })
+	if start == len(ring.events) || ring.events[start].event.ID != lastID {
+		return nil, false
+	}
+
+	for _, logged := range ring.events[start+1:] {
+		if logged.userID == uuid.Nil || logged.userID == userID {
+			events = append(events, logged.event)
+		}
+	}
+	return events, true
+}
+
+// ring returns the room's ring, creating it if needed (l.mu must be held)
+func (l *RoomEventLog) ring(roomID uuid.UUID) *eventRing {
+	ring, ok := l.rooms[roomID]
+	if !ok {
+		ring = &eventRing{}
+		l.rooms[roomID] = ring
+	}
+	return ring
+}
+
+// sweep drops the rings of rooms idle for longer than eventRetention (l.mu must be held)
+// IDs are microsecond timestamps, so a ring's age is its newest ID.
+func (l *RoomEventLog) sweep(now time.Time) {
+	cutoff := now.Add(-eventRetention).UnixMicro()
+	for roomID, ring := range l.rooms {
+		if len(ring.events) == 0 || ring.events[len(ring.events)-1].event.ID < cutoff {
+			delete(l.rooms, roomID)
+		}
+	}
+	l.lastSweep = now
+}
+
+// forget drops a room's events (e.g. once the room is deleted)
+func (l *RoomEventLog) forget(roomID uuid.UUID) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.rooms, roomID)
+}
+
+// load reads a room's persisted events into its ring, once per process
+// Called before replaying so events from before a restart can be found.
+func (l *RoomEventLog) load(ctx context.Context, roomID uuid.UUID) error {
+	if l.store == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	loaded := l.ring(roomID).loaded
+	l.mu.Unlock()
+	if loaded {
+		return nil
+	}
+
+	data, err := l.store.Select(ctx, "room_events", Where(WithRoomID(roomID)).OrderBy("id", false).Page(l.size, 0))
+	if err != nil {
+		return fmt.Errorf("failed to load room events: %w", err)
+	}
+	var rows []roomEventRow
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return fmt.Errorf("failed to parse room events: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ring := l.ring(roomID)
+	if ring.loaded {
+		return nil
+	}
+
+	known := make(map[int64]bool, len(ring.events))
+	for _, logged := range ring.events {
+		known[logged.event.ID] = true
+	}
+	for _, row := range rows {
+		if known[row.ID] {
+			continue
+		}
+		var payload interface{}
+		if err := json.Unmarshal([]byte(row.Data), &payload); err != nil {
+			continue
+		}
+		userID := uuid.Nil
+		if row.UserID != nil {
+			userID = *row.UserID
+		}
+		ring.events = append(ring.events, loggedEvent{
+			event:  RealtimeEvent{ID: row.ID, Type: row.Type, Data: payload},
+			userID: userID,
+		})
+		if row.ID > l.lastID {
+			l.lastID = row.ID
+		}
+	}
+
+	sort.Slice(ring.events, func(i, j int) bool { return ring.events[i].event.ID < ring.events[j].event.ID })
+	if len(ring.events) > l.size {
+		ring.events = ring.events[len(ring.events)-l.size:]
+	}
+	ring.loaded = true
+	return nil
+}
+
+// persist writes an event to room_events and prunes the room's rows down to what the
+// ring still holds
+func (l *RoomEventLog) persist(ctx context.Context, roomID, userID uuid.UUID, event RealtimeEvent) error {
+	if l.store == nil {
+		return nil
+	}
+
+	payload, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode room event: %w", err)
+	}
+
+	row := map[string]interface{}{
+		"id":      event.ID,
+		"room_id": roomID.String(),
+		"type":    event.Type,
+		"data":    string(payload),
+	}
+	if userID != uuid.Nil {
+		row["user_id"] = userID.String()
+	}
+	if _, err := l.store.Insert(ctx, "room_events", row); err != nil {
+		return fmt.Errorf("failed to persist room event: %w", err)
+	}
+
+	// The event itself is always kept: with a broker in between it may not be in the
+	// ring yet
+	l.mu.Lock()
+	ring := l.rooms[roomID]
+	full := ring != nil && len(ring.events) == l.size
+	var kept []string
+	if full {
+		kept = make([]string, 0, len(ring.events)+1)
+		for _, logged := range ring.events {
+			kept = append(kept, strconv.FormatInt(logged.event.ID, 10))
+		}
+		kept = append(kept, strconv.FormatInt(event.ID, 10))
+	}
+	l.mu.Unlock()
+
+	if !full {
+		return nil
+	}
+	if err := l.store.Delete(ctx, "room_events", Where(WithRoomID(roomID)).NotIn("id", kept)); err != nil {
+		return fmt.Errorf("failed to prune room events: %w", err)
+	}
+	return nil
+}
@@ -3,54 +3,50 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/hekigan/couples/internal/models"
-	"github.com/supabase-community/supabase-go"
 )
 
 // RoomService handles room-related operations
 type RoomService struct {
-	client          *supabase.Client
+	*BaseService
 	realtimeService *RealtimeService
 }
 
 // NewRoomService creates a new room service
-func NewRoomService(client *supabase.Client, realtimeService *RealtimeService) *RoomService {
+func NewRoomService(store Store, realtimeService *RealtimeService) *RoomService {
 	return &RoomService{
-		client:          client,
+		BaseService:     NewBaseService(store, "RoomService"),
 		realtimeService: realtimeService,
 	}
 }
 
+// withStore returns a copy of the service bound to another store (e.g. an open transaction)
+func (s *RoomService) withStore(store Store) *RoomService {
+	return &RoomService{
+		BaseService:     s.BaseService.withStore(store),
+		realtimeService: s.realtimeService,
+	}
+}
+
 // GetRealtimeService returns the realtime service
 func (s *RoomService) GetRealtimeService() *RealtimeService {
 	return s.realtimeService
 }
 
-// GetRoomByID retrieves a room by ID from Supabase
+// GetRoomByID retrieves a room by ID
 func (s *RoomService) GetRoomByID(ctx context.Context, id uuid.UUID) (*models.Room, error) {
-	// Query Supabase for the room
-	data, _, err := s.client.From("rooms").
-		Select("*", "", false).
-		Eq("id", id.String()).
-		Single().
-		Execute()
-
-	if err != nil {
+	// Query the store for the room
+	var room models.Room
+	if err := s.BaseService.QuerySingleRecord(ctx, "rooms", Where().Eq("id", id.String()), &room); err != nil {
 		fmt.Printf("ERROR: Failed to fetch room %s: %v\n", id.String(), err)
 		return nil, fmt.Errorf("room not found: %w", err)
 	}
 
-	// Parse the response
-	var room models.Room
-	if err := json.Unmarshal(data, &room); err != nil {
-		fmt.Printf("ERROR: Failed to parse room data: %v\n", err)
-		return nil, fmt.Errorf("failed to parse room: %w", err)
-	}
-
 	fmt.Printf("DEBUG: Room found: %s (owner: %s, status: %s)\n", room.ID, room.OwnerID, room.Status)
 	return &room, nil
 }
@@ -59,24 +55,12 @@ func (s *RoomService) GetRoomByID(ctx context.Context, id uuid.UUID) (*models.Ro
 // This eliminates N+1 queries (3 queries → 1 query)
 func (s *RoomService) GetRoomWithPlayers(ctx context.Context, id uuid.UUID) (*models.RoomWithPlayers, error) {
 	// Query the rooms_with_players view - single query includes all player info
-	data, _, err := s.client.From("rooms_with_players").
-		Select("*", "", false).
-		Eq("id", id.String()).
-		Single().
-		Execute()
-
-	if err != nil {
+	var roomWithPlayers models.RoomWithPlayers
+	if err := s.BaseService.QuerySingleRecord(ctx, "rooms_with_players", Where().Eq("id", id.String()), &roomWithPlayers); err != nil {
 		fmt.Printf("❌ Failed to fetch room with players %s: %v\n", id.String(), err)
 		return nil, fmt.Errorf("room not found: %w", err)
 	}
 
-	// Parse the response
-	var roomWithPlayers models.RoomWithPlayers
-	if err := json.Unmarshal(data, &roomWithPlayers); err != nil {
-		fmt.Printf("❌ Failed to parse room with players data: %v\n", err)
-		return nil, fmt.Errorf("failed to parse room: %w", err)
-	}
-
 	fmt.Printf("📊 Room with players found: %s (owner: %s, guest: %s, status: %s) - single query via view\n",
 		roomWithPlayers.ID,
 		safeString(roomWithPlayers.OwnerUsername),
@@ -97,24 +81,12 @@ func safeString(s *string) string {
 // This eliminates multiple queries (room + owner + guest + question + category → 1 query)
 func (s *RoomService) GetActiveGame(ctx context.Context, id uuid.UUID) (*models.ActiveGame, error) {
 	// Query the active_games view - single query includes all game state
-	data, _, err := s.client.From("active_games").
-		Select("*", "", false).
-		Eq("id", id.String()).
-		Single().
-		Execute()
-
-	if err != nil {
+	var activeGame models.ActiveGame
+	if err := s.BaseService.QuerySingleRecord(ctx, "active_games", Where().Eq("id", id.String()), &activeGame); err != nil {
 		fmt.Printf("❌ Failed to fetch active game %s: %v\n", id.String(), err)
 		return nil, fmt.Errorf("active game not found: %w", err)
 	}
 
-	// Parse the response
-	var activeGame models.ActiveGame
-	if err := json.Unmarshal(data, &activeGame); err != nil {
-		fmt.Printf("❌ Failed to parse active game data: %v\n", err)
-		return nil, fmt.Errorf("failed to parse active game: %w", err)
-	}
-
 	fmt.Printf("📊 Active game found: %s (owner: %s, guest: %s, question: %s) - single query via view\n",
 		activeGame.ID,
 		safeString(activeGame.OwnerUsername),
@@ -123,7 +95,7 @@ func (s *RoomService) GetActiveGame(ctx context.Context, id uuid.UUID) (*models.
 	return &activeGame, nil
 }
 
-// CreateRoom creates a new room in Supabase
+// CreateRoom creates a new room
 func (s *RoomService) CreateRoom(ctx context.Context, room *models.Room) error {
 	// Set timestamps
 	now := time.Now()
@@ -132,13 +104,14 @@ func (s *RoomService) CreateRoom(ctx context.Context, room *models.Room) error {
 
 	// Prepare data for insert
 	data := map[string]interface{}{
-		"id":         room.ID.String(),
-		"owner_id":   room.OwnerID.String(),
-		"name":       room.Name,
-		"status":     room.Status,
-		"is_private": room.IsPrivate,
-		"created_at": room.CreatedAt,
-		"updated_at": room.UpdatedAt,
+		"id":            room.ID.String(),
+		"owner_id":      room.OwnerID.String(),
+		"name":          room.Name,
+		"status":        room.Status,
+		"is_private":    room.IsPrivate,
+		"allow_repeats": room.AllowRepeats,
+		"created_at":    room.CreatedAt,
+		"updated_at":    room.UpdatedAt,
 	}
 
 	// Add optional fields if present
@@ -148,24 +121,38 @@ func (s *RoomService) CreateRoom(ctx context.Context, room *models.Room) error {
 
 	fmt.Printf("DEBUG: Creating room in database: %+v\n", data)
 
-	// Insert into Supabase
-	responseData, count, err := s.client.From("rooms").Insert(data, false, "", "", "").Execute()
+	responseData, err := s.store.Insert(ctx, "rooms", data)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to create room in Supabase: %v\n", err)
+		fmt.Printf("ERROR: Failed to create room: %v\n", err)
 		return fmt.Errorf("failed to create room: %w", err)
 	}
 
-	fmt.Printf("DEBUG: Room created successfully. Response: %s, Count: %d\n", string(responseData), count)
+	fmt.Printf("DEBUG: Room created successfully. Response: %s\n", string(responseData))
 	return nil
 }
 
-// UpdateRoom updates a room in Supabase
+// UpdateRoom updates a room
+// The status is not written: state changes go through Transition.
+// The write only applies if the room is still at room.Version; otherwise it returns
+// models.ErrConflict and the caller should re-read the room (see RetryOnConflict).
 func (s *RoomService) UpdateRoom(ctx context.Context, room *models.Room) error {
-	room.UpdatedAt = time.Now()
+	if err := s.saveRoom(ctx, room); err != nil {
+		return err
+	}
+
+	// Broadcast to realtime service
+	s.realtimeService.BroadcastRoomUpdate(room.ID, room)
+	return nil
+}
+
+// saveRoom writes room like UpdateRoom without broadcasting it, for callers inside a
+// transaction that broadcast once it has committed
+func (s *RoomService) saveRoom(ctx context.Context, room *models.Room) error {
+	updatedAt := time.Now()
 
 	data := map[string]interface{}{
-		"status":              room.Status,
-		"updated_at":          room.UpdatedAt,
+		"version":             room.Version + 1,
+		"updated_at":          updatedAt,
 		"selected_categories": room.SelectedCategories,
 		"guest_ready":         room.GuestReady,
 		"current_question":    room.CurrentQuestion,
@@ -193,23 +180,111 @@ func (s *RoomService) UpdateRoom(ctx context.Context, room *models.Room) error {
 		data["current_player_id"] = nil
 	}
 
-	fmt.Printf("DEBUG: Updating room %s with data: %+v\n", room.ID, data)
-
-	_, _, err := s.client.From("rooms").
-		Update(data, "", "").
-		Eq("id", room.ID.String()).
-		Execute()
+	s.logger.Debug("Updating room %s with data: %+v", room.ID, data)
 
+	updated, err := s.store.Update(ctx, "rooms", Where().Eq("id", room.ID.String()).Eq("version", room.Version), data)
 	if err != nil {
-		fmt.Printf("ERROR: Failed to update room in Supabase: %v\n", err)
+		s.logger.Error("Failed to update room %s: %v", room.ID, err)
 		return fmt.Errorf("failed to update room: %w", err)
 	}
+	var rooms []models.Room
+	if err := json.Unmarshal(updated, &rooms); err != nil {
+		return fmt.Errorf("failed to parse room: %w", err)
+	}
+	if len(rooms) == 0 {
+		s.logger.Warn("Room %s changed since version %d was read", room.ID, room.Version)
+		return models.ErrConflict
+	}
+	room.Version++
+	room.UpdatedAt = updatedAt
 
-	fmt.Printf("DEBUG: Room %s updated successfully in database\n", room.ID)
+	s.logger.Debug("Room %s updated successfully in database", room.ID)
+	return nil
+}
+
+// Transition moves a room through its state machine (see models.RoomState)
+// Illegal moves return a *models.RoomTransitionError; every accepted move is logged
+// in room_state_transitions.
+func (s *RoomService) Transition(ctx context.Context, roomID uuid.UUID, event models.RoomEvent) (*models.Room, error) {
+	return s.TransitionWith(ctx, roomID, event, nil)
+}
+
+// TransitionWith is Transition plus column changes saved atomically with the new state
+// (e.g. the guest_id of a joining player)
+func (s *RoomService) TransitionWith(ctx context.Context, roomID uuid.UUID, event models.RoomEvent, changes map[string]interface{}) (*models.Room, error) {
+	var room *models.Room
+	err := RetryOnConflict(ctx, func() error {
+		return s.WithTx(ctx, func(tx *BaseService) error {
+			var err error
+			room, err = transitionRoom(ctx, tx, roomID, event, changes)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	// Broadcast to realtime service
 	s.realtimeService.BroadcastRoomUpdate(room.ID, room)
-	return nil
+	return room, nil
+}
+
+// transitionRoom applies event to a room through tx and logs the move
+// It is shared by every service that changes a room's state; callers run it inside WithTx.
+func transitionRoom(ctx context.Context, tx *BaseService, roomID uuid.UUID, event models.RoomEvent, changes map[string]interface{}) (*models.Room, error) {
+	var current models.Room
+	if err := tx.QuerySingleRecord(ctx, "rooms", Where().Eq("id", roomID.String()), &current); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, models.ErrRoomNotFound
+		}
+		return nil, fmt.Errorf("failed to fetch room: %w", err)
+	}
+
+	next, err := current.Status.Next(event)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]interface{}, len(changes)+3)
+	for column, value := range changes {
+		data[column] = value
+	}
+	data["status"] = string(next)
+	data["version"] = current.Version + 1
+	data["updated_at"] = time.Now()
+
+	// Only update the room if nobody changed it since the event was checked
+	updated, err := tx.store.Update(ctx, "rooms", Where().Eq("id", roomID.String()).Eq("version", current.Version), data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update room state: %w", err)
+	}
+	var rooms []models.Room
+	if err := json.Unmarshal(updated, &rooms); err != nil {
+		return nil, fmt.Errorf("failed to parse room: %w", err)
+	}
+	if len(rooms) == 0 {
+		return nil, models.ErrConflict
+	}
+
+	if _, err := tx.store.Insert(ctx, "room_state_transitions", map[string]interface{}{
+		"room_id":    roomID.String(),
+		"from_state": string(current.Status),
+		"to_state":   string(next),
+		"event":      string(event),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to log room transition: %w", err)
+	}
+
+	tx.logger.Debug("Room %s %s --%s--> %s", roomID, current.Status, event, next)
+	return &rooms[0], nil
+}
+
+// GetTransitions returns the state changes of a room, oldest first
+func (s *RoomService) GetTransitions(ctx context.Context, roomID uuid.UUID) ([]models.RoomTransition, error) {
+	var transitions []models.RoomTransition
+	if err := s.BaseService.QueryRecords(ctx, "room_state_transitions", Where(WithRoomID(roomID)).OrderBy("created_at", true), &transitions); err != nil {
+		return nil, fmt.Errorf("failed to fetch room transitions: %w", err)
+	}
+	return transitions, nil
 }
 
 // BroadcastRoomUpdate broadcasts a room update event to all connected clients
@@ -261,18 +336,12 @@ func (s *RoomService) BroadcastRequestRejectedToGuest(guestUserID, roomID uuid.U
 	}
 }
 
-// DeleteRoom deletes a room from Supabase
+// DeleteRoom deletes a room
 func (s *RoomService) DeleteRoom(ctx context.Context, id uuid.UUID) error {
 	fmt.Printf("DEBUG: Deleting room %s from database\n", id)
 
-	// Delete from Supabase
-	_, _, err := s.client.From("rooms").
-		Delete("", "").
-		Eq("id", id.String()).
-		Execute()
-
-	if err != nil {
-		fmt.Printf("ERROR: Failed to delete room from Supabase: %v\n", err)
+	if err := s.store.Delete(ctx, "rooms", Where().Eq("id", id.String())); err != nil {
+		fmt.Printf("ERROR: Failed to delete room: %v\n", err)
 		return fmt.Errorf("failed to delete room: %w", err)
 	}
 
@@ -289,31 +358,17 @@ func (s *RoomService) CountUserRooms(ctx context.Context, userID uuid.UUID) (int
 	return 0, nil
 }
 
-// GetRoomsByUserID gets all rooms for a user from Supabase (where user is owner OR guest)
+// GetRoomsByUserID gets all rooms for a user (where user is owner OR guest)
 func (s *RoomService) GetRoomsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Room, error) {
 	userIDStr := userID.String()
 
 	// Query for rooms where user is owner
-	ownerData, _, err := s.client.From("rooms").
-		Select("*", "", false).
-		Eq("owner_id", userIDStr).
-		Execute()
-
 	var ownerRooms []models.Room
-	if err == nil {
-		json.Unmarshal(ownerData, &ownerRooms)
-	}
+	_ = s.BaseService.QueryRecords(ctx, "rooms", Where().Eq("owner_id", userIDStr), &ownerRooms)
 
 	// Query for rooms where user is guest
-	guestData, _, err := s.client.From("rooms").
-		Select("*", "", false).
-		Eq("guest_id", userIDStr).
-		Execute()
-
 	var guestRooms []models.Room
-	if err == nil {
-		json.Unmarshal(guestData, &guestRooms)
-	}
+	_ = s.BaseService.QueryRecords(ctx, "rooms", Where().Eq("guest_id", userIDStr), &guestRooms)
 
 	// Combine both lists
 	allRooms := append(ownerRooms, guestRooms...)
@@ -331,26 +386,12 @@ func (s *RoomService) GetRoomsByUserIDWithPlayers(ctx context.Context, userID uu
 	userIDStr := userID.String()
 
 	// Query for rooms where user is owner (using view)
-	ownerData, _, err := s.client.From("rooms_with_players").
-		Select("*", "", false).
-		Eq("owner_id", userIDStr).
-		Execute()
-
 	var ownerRooms []models.RoomWithPlayers
-	if err == nil {
-		json.Unmarshal(ownerData, &ownerRooms)
-	}
+	_ = s.BaseService.QueryRecords(ctx, "rooms_with_players", Where().Eq("owner_id", userIDStr), &ownerRooms)
 
 	// Query for rooms where user is guest (using view)
-	guestData, _, err := s.client.From("rooms_with_players").
-		Select("*", "", false).
-		Eq("guest_id", userIDStr).
-		Execute()
-
 	var guestRooms []models.RoomWithPlayers
-	if err == nil {
-		json.Unmarshal(guestData, &guestRooms)
-	}
+	_ = s.BaseService.QueryRecords(ctx, "rooms_with_players", Where().Eq("guest_id", userIDStr), &guestRooms)
 
 	// Combine both lists
 	allRooms := append(ownerRooms, guestRooms...)
@@ -361,14 +402,23 @@ func (s *RoomService) GetRoomsByUserIDWithPlayers(ctx context.Context, userID uu
 	return allRooms, nil
 }
 
-// CreateJoinRequest creates a new join request in Supabase
+// GetRoomsByStatus gets every room in the given state
+func (s *RoomService) GetRoomsByStatus(ctx context.Context, status models.RoomState) ([]models.Room, error) {
+	var rooms []models.Room
+	if err := s.BaseService.QueryRecords(ctx, "rooms", Where().Eq("status", string(status)), &rooms); err != nil {
+		return nil, fmt.Errorf("failed to get %s rooms: %w", status, err)
+	}
+	return rooms, nil
+}
+
+// CreateJoinRequest creates a new join request
 func (s *RoomService) CreateJoinRequest(ctx context.Context, request *models.RoomJoinRequest) error {
 	// Set timestamps
 	now := time.Now()
 	request.CreatedAt = now
 	request.UpdatedAt = now
 
-	// Prepare data for insert (without 'message' field - not in schema)
+	// Prepare data for insert
 	data := map[string]interface{}{
 		"id":         request.ID.String(),
 		"room_id":    request.RoomID.String(),
@@ -377,37 +427,27 @@ func (s *RoomService) CreateJoinRequest(ctx context.Context, request *models.Roo
 		"created_at": request.CreatedAt,
 		"updated_at": request.UpdatedAt,
 	}
+	if request.Message != nil {
+		data["message"] = *request.Message
+	}
 
 	fmt.Printf("DEBUG: Attempting to insert join request: %+v\n", data)
 
-	// Insert into Supabase
-	responseData, count, err := s.client.From("room_join_requests").Insert(data, false, "", "", "").Execute()
+	responseData, err := s.store.Insert(ctx, "room_join_requests", data)
 	if err != nil {
-		fmt.Printf("ERROR: Supabase insert failed: %v\n", err)
+		fmt.Printf("ERROR: Join request insert failed: %v\n", err)
 		return fmt.Errorf("failed to create join request: %w", err)
 	}
 
-	fmt.Printf("DEBUG: Insert successful. Response data: %s, Count: %d\n", string(responseData), count)
+	fmt.Printf("DEBUG: Insert successful. Response data: %s\n", string(responseData))
 	return nil
 }
 
-// GetJoinRequestsByRoom gets pending join requests for a room from Supabase
+// GetJoinRequestsByRoom gets pending join requests for a room
 func (s *RoomService) GetJoinRequestsByRoom(ctx context.Context, roomID uuid.UUID) ([]models.RoomJoinRequest, error) {
-	// Query Supabase for join requests
-	data, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
-		Eq("status", "pending").
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
-	}
-
-	// Parse the response
 	var requests []models.RoomJoinRequest
-	if err := json.Unmarshal(data, &requests); err != nil {
-		return nil, fmt.Errorf("failed to parse join requests: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "room_join_requests", Where(WithRoomID(roomID), WithStatus("pending")), &requests); err != nil {
+		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
 	}
 
 	return requests, nil
@@ -415,20 +455,9 @@ func (s *RoomService) GetJoinRequestsByRoom(ctx context.Context, roomID uuid.UUI
 
 // GetAllJoinRequestsByRoom gets ALL join requests (including rejected) for a room
 func (s *RoomService) GetAllJoinRequestsByRoom(ctx context.Context, roomID uuid.UUID) ([]models.RoomJoinRequest, error) {
-	// Query Supabase for ALL join requests
-	data, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
-	}
-
-	// Parse the response
 	var requests []models.RoomJoinRequest
-	if err := json.Unmarshal(data, &requests); err != nil {
-		return nil, fmt.Errorf("failed to parse join requests: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "room_join_requests", Where(WithRoomID(roomID)), &requests); err != nil {
+		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
 	}
 
 	return requests, nil
@@ -436,34 +465,35 @@ func (s *RoomService) GetAllJoinRequestsByRoom(ctx context.Context, roomID uuid.
 
 // CancelJoinRequest cancels (deletes) a user's join request
 func (s *RoomService) CancelJoinRequest(ctx context.Context, roomID, userID uuid.UUID) error {
-	_, _, err := s.client.From("room_join_requests").
-		Delete("", "").
-		Eq("room_id", roomID.String()).
-		Eq("user_id", userID.String()).
-		Execute()
-
-	if err != nil {
+	if err := s.store.Delete(ctx, "room_join_requests", Where(WithRoomID(roomID), WithUserID(userID))); err != nil {
 		return fmt.Errorf("failed to cancel join request: %w", err)
 	}
 
 	return nil
 }
 
-// GetAcceptedRequestsByUser gets all accepted join requests for a user
-func (s *RoomService) GetAcceptedRequestsByUser(ctx context.Context, userID uuid.UUID) ([]models.RoomJoinRequest, error) {
-	data, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("user_id", userID.String()).
-		Eq("status", "accepted").
-		Execute()
-
+// DeleteStaleJoinRequests deletes join requests still pending after olderThan
+func (s *RoomService) DeleteStaleJoinRequests(ctx context.Context, olderThan time.Duration) (int, error) {
+	q := Where().Eq("status", "pending").Lt("created_at", time.Now().Add(-olderThan))
+	count, err := s.store.Count(ctx, "room_join_requests", q)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch accepted requests: %w", err)
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if err := s.store.Delete(ctx, "room_join_requests", q); err != nil {
+		return 0, fmt.Errorf("failed to delete stale join requests: %w", err)
 	}
+	return count, nil
+}
 
+// GetAcceptedRequestsByUser gets all accepted join requests for a user
+func (s *RoomService) GetAcceptedRequestsByUser(ctx context.Context, userID uuid.UUID) ([]models.RoomJoinRequest, error) {
 	var requests []models.RoomJoinRequest
-	if err := json.Unmarshal(data, &requests); err != nil {
-		return nil, fmt.Errorf("failed to parse accepted requests: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "room_join_requests", Where(WithUserID(userID), WithStatus("accepted")), &requests); err != nil {
+		return nil, fmt.Errorf("failed to fetch accepted requests: %w", err)
 	}
 
 	return requests, nil
@@ -471,18 +501,9 @@ func (s *RoomService) GetAcceptedRequestsByUser(ctx context.Context, userID uuid
 
 // GetJoinRequestsByUser gets ALL join requests (any status) for a specific user
 func (s *RoomService) GetJoinRequestsByUser(ctx context.Context, userID uuid.UUID) ([]models.RoomJoinRequest, error) {
-	data, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("user_id", userID.String()).
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch user's join requests: %w", err)
-	}
-
 	var requests []models.RoomJoinRequest
-	if err := json.Unmarshal(data, &requests); err != nil {
-		return nil, fmt.Errorf("failed to parse user's join requests: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "room_join_requests", Where(WithUserID(userID)), &requests); err != nil {
+		return nil, fmt.Errorf("failed to fetch user's join requests: %w", err)
 	}
 
 	return requests, nil
@@ -501,19 +522,9 @@ type JoinRequestWithUserInfo struct {
 
 // GetJoinRequestByID gets a specific join request by ID
 func (s *RoomService) GetJoinRequestByID(ctx context.Context, requestID uuid.UUID) (*models.RoomJoinRequest, error) {
-	data, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("id", requestID.String()).
-		Single().
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("join request not found: %w", err)
-	}
-
 	var request models.RoomJoinRequest
-	if err := json.Unmarshal(data, &request); err != nil {
-		return nil, fmt.Errorf("failed to parse join request: %w", err)
+	if err := s.BaseService.QuerySingleRecord(ctx, "room_join_requests", Where().Eq("id", requestID.String()), &request); err != nil {
+		return nil, fmt.Errorf("join request not found: %w", err)
 	}
 
 	return &request, nil
@@ -525,98 +536,56 @@ func (s *RoomService) GetJoinRequestByID(ctx context.Context, requestID uuid.UUI
 // After: 1 query (view includes JOIN)
 func (s *RoomService) GetJoinRequestsWithUserInfo(ctx context.Context, roomID uuid.UUID) ([]JoinRequestWithUserInfo, error) {
 	// Query the database view - single query with user info already joined
-	data, _, err := s.client.From("join_requests_with_users").
-		Select("*", "", false).
-		Eq("room_id", roomID.String()).
-		Eq("status", "pending").
-		Execute()
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
-	}
-
-	// Parse the response
 	var result []JoinRequestWithUserInfo
-	if err := json.Unmarshal(data, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse join requests: %w", err)
+	if err := s.BaseService.QueryRecords(ctx, "join_requests_with_users", Where(WithRoomID(roomID), WithStatus("pending")), &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch join requests: %w", err)
 	}
 
 	fmt.Printf("📊 Fetched %d join requests with user info (single query via view)\n", len(result))
 	return result, nil
 }
 
-// AcceptJoinRequest accepts a join request in Supabase
+// AcceptJoinRequest accepts a join request
+// The request and the room are updated in one transaction so a room never ends up
+// with an accepted request but no guest (or the reverse)
 func (s *RoomService) AcceptJoinRequest(ctx context.Context, requestID uuid.UUID) error {
 	now := time.Now()
 
-	// First, get the join request to find the user and room
-	requestData, _, err := s.client.From("room_join_requests").
-		Select("*", "", false).
-		Eq("id", requestID.String()).
-		Single().
-		Execute()
-
-	if err != nil {
-		return fmt.Errorf("failed to find join request: %w", err)
-	}
-
-	var joinRequest models.RoomJoinRequest
-	if err := json.Unmarshal(requestData, &joinRequest); err != nil {
-		return fmt.Errorf("failed to parse join request: %w", err)
-	}
-
-	// Update the join request status to accepted
-	data := map[string]interface{}{
-		"status":     "accepted",
-		"updated_at": now,
-	}
-
-	_, _, err = s.client.From("room_join_requests").
-		Update(data, "", "").
-		Eq("id", requestID.String()).
-		Execute()
-
-	if err != nil {
-		return fmt.Errorf("failed to accept join request: %w", err)
-	}
-
-	// CRITICAL: Update the room with the guest_id and change status to "ready"
-	roomUpdateData := map[string]interface{}{
-		"guest_id":   joinRequest.UserID.String(),
-		"status":     "ready",
-		"updated_at": now,
-	}
-
-	fmt.Printf("DEBUG: Updating room %s with guest_id=%s, status=ready\n",
-		joinRequest.RoomID, joinRequest.UserID)
-
-	responseData, count, err := s.client.From("rooms").
-		Update(roomUpdateData, "", "").
-		Eq("id", joinRequest.RoomID.String()).
-		Execute()
-
-	if err != nil {
-		fmt.Printf("ERROR: Failed to update room: %v\n", err)
-		return fmt.Errorf("failed to update room with guest: %w", err)
-	}
-
-	fmt.Printf("DEBUG: Room update response: %s, Count: %d\n", string(responseData), count)
-	fmt.Printf("DEBUG: Room %s successfully updated with guest\n", joinRequest.RoomID)
-
-	// Verify the update by fetching the room
-	verifyData, _, verifyErr := s.client.From("rooms").
-		Select("*", "", false).
-		Eq("id", joinRequest.RoomID.String()).
-		Single().
-		Execute()
-	if verifyErr == nil {
-		fmt.Printf("DEBUG: Room verification: %s\n", string(verifyData))
-	}
-
-	return nil
+	// The room may change between reading and seating the guest: retry the whole acceptance
+	return RetryOnConflict(ctx, func() error {
+		return s.WithTx(ctx, func(tx *BaseService) error {
+			// First, get the join request to find the user and room
+			var joinRequest models.RoomJoinRequest
+			if err := tx.QuerySingleRecord(ctx, "room_join_requests", Where().Eq("id", requestID.String()), &joinRequest); err != nil {
+				return fmt.Errorf("failed to find join request: %w", err)
+			}
+
+			// Update the join request status to accepted
+			data := map[string]interface{}{
+				"status":     "accepted",
+				"updated_at": now,
+			}
+
+			if _, err := tx.store.Update(ctx, "room_join_requests", Where().Eq("id", requestID.String()), data); err != nil {
+				return fmt.Errorf("failed to accept join request: %w", err)
+			}
+
+			// CRITICAL: Set the room's guest_id; the room moves from waiting to ready
+			fmt.Printf("DEBUG: Updating room %s with guest_id=%s\n", joinRequest.RoomID, joinRequest.UserID)
+
+			if _, err := transitionRoom(ctx, tx, joinRequest.RoomID, models.RoomEventGuestJoined, map[string]interface{}{
+				"guest_id": joinRequest.UserID.String(),
+			}); err != nil {
+				fmt.Printf("ERROR: Failed to update room: %v\n", err)
+				return fmt.Errorf("failed to update room with guest: %w", err)
+			}
+			fmt.Printf("DEBUG: Room %s successfully updated with guest\n", joinRequest.RoomID)
+			return nil
+		})
+	})
 }
 
-// RejectJoinRequest rejects a join request in Supabase
+// RejectJoinRequest rejects a join request
 func (s *RoomService) RejectJoinRequest(ctx context.Context, requestID uuid.UUID) error {
 	now := time.Now()
 
@@ -625,12 +594,7 @@ func (s *RoomService) RejectJoinRequest(ctx context.Context, requestID uuid.UUID
 		"updated_at": now,
 	}
 
-	_, _, err := s.client.From("room_join_requests").
-		Update(data, "", "").
-		Eq("id", requestID.String()).
-		Execute()
-
-	if err != nil {
+	if _, err := s.store.Update(ctx, "room_join_requests", Where().Eq("id", requestID.String()), data); err != nil {
 		return fmt.Errorf("failed to reject join request: %w", err)
 	}
 
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// TestAcceptJoinRequest tests that accepting a request seats the guest in the room
+func TestAcceptJoinRequest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	roomService := NewRoomService(store, NewRealtimeService())
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	guest := CreateTestUser(t, store, "guest", "Guest", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	request := &models.RoomJoinRequest{
+		ID:     uuid.New(),
+		RoomID: roomID,
+		UserID: guest.ID,
+		Status: "pending",
+	}
+	AssertNoError(t, roomService.CreateJoinRequest(ctx, request), "create join request")
+
+	t.Run("duplicate request is rejected", func(t *testing.T) {
+		duplicate := &models.RoomJoinRequest{ID: uuid.New(), RoomID: roomID, UserID: guest.ID, Status: "pending"}
+		if err := roomService.CreateJoinRequest(ctx, duplicate); err == nil {
+			t.Error("expected unique violation for a second request from the same user")
+		}
+	})
+
+	t.Run("accepting sets guest and ready status", func(t *testing.T) {
+		AssertNoError(t, roomService.AcceptJoinRequest(ctx, request.ID), "accept join request")
+
+		room, err := roomService.GetRoomWithPlayers(ctx, roomID)
+		AssertNoError(t, err, "get room")
+		if room.GuestID == nil || *room.GuestID != guest.ID {
+			t.Errorf("guest not seated: %+v", room.GuestID)
+		}
+		if room.Status != models.RoomReady {
+			t.Errorf("status = %q, want ready", room.Status)
+		}
+		if room.GuestUsername == nil || *room.GuestUsername != guest.Username {
+			t.Errorf("guest username = %v, want %s", room.GuestUsername, guest.Username)
+		}
+
+		pending, err := roomService.GetJoinRequestsByRoom(ctx, roomID)
+		AssertNoError(t, err, "get pending requests")
+		if len(pending) != 0 {
+			t.Errorf("expected no pending requests, got %d", len(pending))
+		}
+	})
+}
+
+// TestRoomStateMachine tests which events each room state accepts
+func TestRoomStateMachine(t *testing.T) {
+	tests := []struct {
+		from  models.RoomState
+		event models.RoomEvent
+		want  models.RoomState
+		legal bool
+	}{
+		{models.RoomWaiting, models.RoomEventGuestJoined, models.RoomReady, true},
+		{models.RoomWaiting, models.RoomEventStart, "", false},
+		{models.RoomReady, models.RoomEventGuestLeft, models.RoomWaiting, true},
+		{models.RoomReady, models.RoomEventStart, models.RoomPlaying, true},
+		{models.RoomReady, models.RoomEventFinish, "", false},
+		{models.RoomPlaying, models.RoomEventPause, models.RoomPaused, true},
+		{models.RoomPlaying, models.RoomEventGuestLeft, "", false},
+		{models.RoomPaused, models.RoomEventResume, models.RoomPlaying, true},
+		{models.RoomPaused, models.RoomEventFinish, models.RoomFinished, true},
+		{models.RoomPaused, models.RoomEventAbandon, models.RoomAbandoned, true},
+		{models.RoomFinished, models.RoomEventAbandon, "", false},
+		{models.RoomAbandoned, models.RoomEventResume, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"/"+string(tt.event), func(t *testing.T) {
+			next, err := tt.from.Next(tt.event)
+			if !tt.legal {
+				if !errors.Is(err, models.ErrIllegalRoomTransition) {
+					t.Errorf("expected ErrIllegalRoomTransition, got %v", err)
+				}
+				return
+			}
+			AssertNoError(t, err, "next state")
+			if next != tt.want {
+				t.Errorf("next = %q, want %q", next, tt.want)
+			}
+		})
+	}
+
+	for _, state := range []models.RoomState{models.RoomFinished, models.RoomAbandoned} {
+		if !state.IsTerminal() {
+			t.Errorf("%s should be terminal", state)
+		}
+	}
+}
+
+// TestRoomTransition tests that transitions are applied, guarded and logged
+func TestRoomTransition(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	roomService := NewRoomService(store, NewRealtimeService())
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	guest := CreateTestUser(t, store, "guest", "Guest", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	if _, err := roomService.Transition(ctx, roomID, models.RoomEventStart); !errors.Is(err, models.ErrIllegalRoomTransition) {
+		t.Fatalf("starting a room without a guest: expected ErrIllegalRoomTransition, got %v", err)
+	}
+
+	room, err := roomService.TransitionWith(ctx, roomID, models.RoomEventGuestJoined, map[string]interface{}{
+		"guest_id": guest.ID.String(),
+	})
+	AssertNoError(t, err, "guest joined")
+	if room.Status != models.RoomReady || room.GuestID == nil || *room.GuestID != guest.ID {
+		t.Errorf("room after join = %s with guest %v, want ready with %s", room.Status, room.GuestID, guest.ID)
+	}
+
+	for _, event := range []models.RoomEvent{models.RoomEventStart, models.RoomEventPause, models.RoomEventResume, models.RoomEventFinish} {
+		_, err := roomService.Transition(ctx, roomID, event)
+		AssertNoError(t, err, string(event))
+	}
+
+	if _, err := roomService.Transition(ctx, roomID, models.RoomEventAbandon); !errors.Is(err, models.ErrIllegalRoomTransition) {
+		t.Errorf("abandoning a finished room: expected ErrIllegalRoomTransition, got %v", err)
+	}
+
+	transitions, err := roomService.GetTransitions(ctx, roomID)
+	AssertNoError(t, err, "get transitions")
+	want := []models.RoomState{models.RoomReady, models.RoomPlaying, models.RoomPaused, models.RoomPlaying, models.RoomFinished}
+	if len(transitions) != len(want) {
+		t.Fatalf("logged %d transitions, want %d", len(transitions), len(want))
+	}
+	from := models.RoomWaiting
+	for i, transition := range transitions {
+		if transition.From != from || transition.To != want[i] {
+			t.Errorf("transition %d = %s -> %s, want %s -> %s", i, transition.From, transition.To, from, want[i])
+		}
+		from = transition.To
+	}
+}
+
+// TestUpdateRoom_Conflict tests that a write based on a stale read is rejected
+func TestUpdateRoom_Conflict(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	store := SetupTestStore(t)
+	roomService := NewRoomService(store, NewRealtimeService())
+
+	owner := CreateTestUser(t, store, "owner", "Owner", true)
+	roomID := CreateTestRoom(t, store, owner.ID, "en")
+
+	first := loadTestRoom(t, store, roomID)
+	stale := loadTestRoom(t, store, roomID)
+
+	first.CurrentQuestion = 1
+	AssertNoError(t, roomService.UpdateRoom(ctx, first), "first update")
+	if first.Version != stale.Version+1 {
+		t.Errorf("version = %d, want %d", first.Version, stale.Version+1)
+	}
+
+	stale.CurrentQuestion = 5
+	if err := roomService.UpdateRoom(ctx, stale); !errors.Is(err, models.ErrConflict) {
+		t.Fatalf("expected ErrConflict for a stale update, got %v", err)
+	}
+	if room := loadTestRoom(t, store, roomID); room.CurrentQuestion != 1 {
+		t.Errorf("stale update was applied: current question = %d, want 1", room.CurrentQuestion)
+	}
+
+	// A transition bumps the version too, so it also invalidates earlier reads
+	_, err := roomService.Transition(ctx, roomID, models.RoomEventAbandon)
+	AssertNoError(t, err, "abandon")
+	first.CurrentQuestion = 2
+	if err := roomService.UpdateRoom(ctx, first); !errors.Is(err, models.ErrConflict) {
+		t.Errorf("expected ErrConflict after a transition, got %v", err)
+	}
+}
+
+// TestRetryOnConflict tests that conflicts are retried a bounded number of times
+func TestRetryOnConflict(t *testing.T) {
+	ctx := context.Background()
+
+	calls := 0
+	err := RetryOnConflict(ctx, func() error {
+		calls++
+		if calls < 2 {
+			return models.ErrConflict
+		}
+		return nil
+	})
+	AssertNoError(t, err, "retry")
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	calls = 0
+	err = RetryOnConflict(ctx, func() error {
+		calls++
+		return models.ErrConflict
+	})
+	if !errors.Is(err, models.ErrConflict) || calls != conflictAttempts {
+		t.Errorf("got %v after %d calls, want ErrConflict after %d", err, calls, conflictAttempts)
+	}
+
+	failure := errors.New("boom")
+	calls = 0
+	if err := RetryOnConflict(ctx, func() error { calls++; return failure }); !errors.Is(err, failure) || calls != 1 {
+		t.Errorf("other errors must not be retried: got %v after %d calls", err, calls)
+	}
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// jobRunsKept is how many runs of each job stay in job_runs
+const jobRunsKept = 100
+
+// idleWait is how long the scheduler sleeps when no job has a next run
+const idleWait = time.Hour
+
+// JobFunc runs a maintenance job and describes what it did ("deleted 3 users")
+type JobFunc func(ctx context.Context) (string, error)
+
+// JobStatus describes a registered job for the admin page
+type JobStatus struct {
+	Name    string
+	Spec    string
+	NextRun time.Time
+	Running bool
+	// LastRun is nil when the job has not run yet
+	LastRun *models.JobRun
+	// LastFailure is the latest failed run among the kept history, if any
+	LastFailure *models.JobRun
+}
+
+// scheduledJob is a registered job and its timing
+type scheduledJob struct {
+	name     string
+	spec     string
+	schedule Schedule
+	run      JobFunc
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs maintenance jobs in process on cron-like schedules
+// With several instances, only the one the LeaderElector elects runs the jobs. Every run is
+// recorded in job_runs. A job still running when it is due again is not started twice.
+type Scheduler struct {
+	*BaseService
+	leader   LeaderElector
+	instance string
+	now      func() time.Time
+
+	mu     sync.Mutex
+	jobs   []*scheduledJob
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler recording its runs in store
+func NewScheduler(store Store, leader LeaderElector) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{
+		BaseService: NewBaseService(store, "Scheduler"),
+		leader:      leader,
+		instance:    fmt.Sprintf("%s:%d", host, os.Getpid()),
+		now:         time.Now,
+		ctx:         context.Background(),
+	}
+}
+
+// Register adds a job; spec is parsed by ParseSchedule
+func (s *Scheduler) Register(name, spec string, run JobFunc) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.jobs {
+		if job.name == name {
+			return fmt.Errorf("job %s is already registered", name)
+		}
+	}
+	s.jobs = append(s.jobs, &scheduledJob{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		run:      run,
+		next:     schedule.Next(s.now()),
+	})
+	return nil
+}
+
+// Start runs due jobs in the background until Stop
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cancel != nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.done = make(chan struct{})
+	go s.run(s.ctx)
+}
+
+// Stop cancels running jobs, waits for them and gives up leadership
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	s.wg.Wait()
+
+	if err := s.leader.Close(); err != nil {
+		fmt.Printf("⚠️ Warning: %v\n", err)
+	}
+}
+
+// run sleeps until the next job is due and starts it
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	for {
+		timer := time.NewTimer(s.untilNext())
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.runDue(ctx)
+	}
+}
+
+// untilNext returns how long until the earliest next run
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var earliest time.Time
+	for _, job := range s.jobs {
+		if !job.next.IsZero() && (earliest.IsZero() || job.next.Before(earliest)) {
+			earliest = job.next
+		}
+	}
+	if earliest.IsZero() {
+		return idleWait
+	}
+	return max(earliest.Sub(s.now()), 0)
+}
+
+// runDue starts the jobs whose time has come, when this instance leads
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := s.now()
+
+	s.mu.Lock()
+	var due []*scheduledJob
+	for _, job := range s.jobs {
+		if !job.next.IsZero() && !job.next.After(now) {
+			due = append(due, job)
+			job.next = job.schedule.Next(now)
+		}
+	}
+	s.mu.Unlock()
+
+	if len(due) == 0 || !s.leader.IsLeader(ctx) {
+		return
+	}
+	for _, job := range due {
+		s.launch(ctx, job)
+	}
+}
+
+// RunNow starts a job immediately on this instance, whatever its schedule
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	ctx := s.ctx
+	var found *scheduledJob
+	for _, job := range s.jobs {
+		if job.name == name {
+			found = job
+		}
+	}
+	s.mu.Unlock()
+
+	if found == nil {
+		return fmt.Errorf("job %s: %w", name, ErrNotFound)
+	}
+	if !s.launch(ctx, found) {
+		return fmt.Errorf("job %s is already running", name)
+	}
+	return nil
+}
+
+// launch runs a job in the background unless it is still running
+func (s *Scheduler) launch(ctx context.Context, job *scheduledJob) bool {
+	s.mu.Lock()
+	if job.running {
+		s.mu.Unlock()
+		fmt.Printf("⏭️ Skipping job %s: previous run still in progress\n", job.name)
+		return false
+	}
+	job.running = true
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.execute(ctx, job)
+
+		s.mu.Lock()
+		job.running = false
+		s.mu.Unlock()
+	}()
+	return true
+}
+
+// execute runs a job and records the run
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob) {
+	started := s.now()
+	result, err := runJob(ctx, job.run)
+
+	run := models.JobRun{
+		ID:         uuid.New(),
+		JobName:    job.name,
+		StartedAt:  started,
+		DurationMs: s.now().Sub(started).Milliseconds(),
+		Status:     models.JobSucceeded,
+		Result:     result,
+		Instance:   s.instance,
+	}
+	if err != nil {
+		run.Status = models.JobFailed
+		run.Error = err.Error()
+		fmt.Printf("❌ Job %s failed after %s: %v\n", job.name, run.Duration(), err)
+	} else {
+		fmt.Printf("⏰ Job %s done in %s: %s\n", job.name, run.Duration(), result)
+	}
+
+	// Record the run even when the job was cancelled by a shutdown
+	recordCtx := context.WithoutCancel(ctx)
+	if err := s.record(recordCtx, run); err != nil {
+		fmt.Printf("⚠️ Warning: failed to record run of job %s: %v\n", job.name, err)
+	}
+}
+
+// runJob calls fn, turning a panic into an error
+func runJob(ctx context.Context, fn JobFunc) (result string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
+
+// record stores a run and drops the job's runs beyond jobRunsKept
+func (s *Scheduler) record(ctx context.Context, run models.JobRun) error {
+	data := map[string]interface{}{
+		"id":          run.ID.String(),
+		"job_name":    run.JobName,
+		"started_at":  run.StartedAt,
+		"duration_ms": run.DurationMs,
+		"status":      run.Status,
+		"result":      run.Result,
+		"instance":    run.Instance,
+	}
+	if run.Error != "" {
+		data["error"] = run.Error
+	}
+	if err := s.InsertRecord(ctx, "job_runs", data); err != nil {
+		return err
+	}
+
+	var old []struct {
+		ID string `json:"id"`
+	}
+	q := Where().Eq("job_name", run.JobName).Select("id").OrderBy("started_at", false).Page(jobRunsKept, jobRunsKept)
+	if err := s.QueryRecords(ctx, "job_runs", q, &old); err != nil {
+		return err
+	}
+	if len(old) == 0 {
+		return nil
+	}
+	ids := make([]string, len(old))
+	for i, row := range old {
+		ids[i] = row.ID
+	}
+	return s.Store().Delete(ctx, "job_runs", NewQuery().In("id", ids))
+}
+
+// GetJobRuns returns a job's latest runs, newest first
+func (s *Scheduler) GetJobRuns(ctx context.Context, name string, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	q := Where().Eq("job_name", name).OrderBy("started_at", false).Page(limit, 0)
+	if err := s.QueryRecords(ctx, "job_runs", q, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
+
+// Jobs describes every registered job with its latest runs, sorted by name
+func (s *Scheduler) Jobs(ctx context.Context) ([]JobStatus, error) {
+	s.mu.Lock()
+	statuses := make([]JobStatus, len(s.jobs))
+	for i, job := range s.jobs {
+		statuses[i] = JobStatus{Name: job.name, Spec: job.spec, NextRun: job.next, Running: job.running}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+
+	var errs []error
+	for i := range statuses {
+		runs, err := s.GetJobRuns(ctx, statuses[i].Name, jobRunsKept)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(runs) > 0 {
+			statuses[i].LastRun = &runs[0]
+		}
+		for j := range runs {
+			if runs[j].Status == models.JobFailed {
+				statuses[i].LastFailure = &runs[j]
+				break
+			}
+		}
+	}
+	return statuses, errors.Join(errs...)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// follower is a LeaderElector that never leads
+type follower struct{}
+
+func (follower) IsLeader(ctx context.Context) bool { return false }
+func (follower) Close() error                      { return nil }
+
+// newTestScheduler creates a scheduler on a memory store whose clock starts at start
+func newTestScheduler(t *testing.T, leader LeaderElector, start time.Time) (*Scheduler, *time.Time) {
+	t.Helper()
+
+	now := start
+	scheduler := NewScheduler(NewMemoryStore(), leader)
+	scheduler.now = func() time.Time { return now }
+	t.Cleanup(scheduler.Stop)
+	return scheduler, &now
+}
+
+// TestScheduler_RecordsRuns tests that successes, errors and panics are all recorded
+func TestScheduler_RecordsRuns(t *testing.T) {
+	scheduler, _ := newTestScheduler(t, LocalLeader{}, time.Now())
+	ctx := context.Background()
+
+	AssertNoError(t, scheduler.Register("ok", "@daily", func(ctx context.Context) (string, error) {
+		return "deleted 2 users", nil
+	}), "register ok")
+	AssertNoError(t, scheduler.Register("failing", "@daily", func(ctx context.Context) (string, error) {
+		return "", errors.New("database unavailable")
+	}), "register failing")
+	AssertNoError(t, scheduler.Register("panicking", "@daily", func(ctx context.Context) (string, error) {
+		panic("nil map")
+	}), "register panicking")
+
+	for _, name := range []string{"ok", "failing", "panicking"} {
+		AssertNoError(t, scheduler.RunNow(name), "run "+name)
+	}
+	scheduler.wg.Wait()
+
+	jobs, err := scheduler.Jobs(ctx)
+	AssertNoError(t, err, "jobs")
+	AssertEqual(t, 3, len(jobs), "job count")
+
+	byName := map[string]JobStatus{}
+	for _, job := range jobs {
+		if job.LastRun == nil {
+			t.Fatalf("job %s has no recorded run", job.Name)
+		}
+		byName[job.Name] = job
+	}
+	AssertEqual(t, models.JobSucceeded, byName["ok"].LastRun.Status, "ok status")
+	AssertEqual(t, "deleted 2 users", byName["ok"].LastRun.Result, "ok result")
+	if byName["ok"].LastFailure != nil {
+		t.Error("successful job has a failure")
+	}
+	AssertEqual(t, "database unavailable", byName["failing"].LastFailure.Error, "failing error")
+	AssertEqual(t, "panic: nil map", byName["panicking"].LastFailure.Error, "panic error")
+}
+
+// TestScheduler_RunsDueJobsOnlyWhenLeading tests that due jobs run on the leader alone
+func TestScheduler_RunsDueJobsOnlyWhenLeading(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 30, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name   string
+		leader LeaderElector
+		want   int
+	}{
+		{"leader", LocalLeader{}, 1},
+		{"follower", follower{}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			scheduler, now := newTestScheduler(t, tt.leader, start)
+			ctx := context.Background()
+
+			var mu sync.Mutex
+			runs := 0
+			AssertNoError(t, scheduler.Register("every-minute", "* * * * *", func(ctx context.Context) (string, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				runs++
+				return "", nil
+			}), "register")
+
+			// Not due yet
+			scheduler.runDue(ctx)
+			scheduler.wg.Wait()
+			AssertEqual(t, 0, runs, "runs before the due time")
+
+			*now = start.Add(30 * time.Second)
+			scheduler.runDue(ctx)
+			scheduler.wg.Wait()
+			AssertEqual(t, tt.want, runs, "runs at the due time")
+
+			jobs, err := scheduler.Jobs(ctx)
+			AssertNoError(t, err, "jobs")
+			AssertEqual(t, start.Add(90*time.Second), jobs[0].NextRun, "next run")
+		})
+	}
+}
+
+// TestScheduler_RunNowUnknownJob tests that running an unregistered job is not found
+func TestScheduler_RunNowUnknownJob(t *testing.T) {
+	scheduler, _ := newTestScheduler(t, LocalLeader{}, time.Now())
+
+	if err := scheduler.RunNow("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("RunNow error = %v, want ErrNotFound", err)
+	}
+	AssertError(t, scheduler.Register("bad", "every day", nil), "invalid spec")
+}
+
+// TestScheduler_PrunesHistory tests that only the latest jobRunsKept runs are kept
+func TestScheduler_PrunesHistory(t *testing.T) {
+	scheduler, _ := newTestScheduler(t, LocalLeader{}, time.Now())
+	ctx := context.Background()
+
+	start := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < jobRunsKept+5; i++ {
+		AssertNoError(t, scheduler.record(ctx, models.JobRun{
+			ID:        uuid.New(),
+			JobName:   "busy",
+			StartedAt: start.Add(time.Duration(i) * time.Minute),
+			Status:    models.JobSucceeded,
+			Result:    fmt.Sprintf("run %d", i),
+		}), "record")
+	}
+
+	runs, err := scheduler.GetJobRuns(ctx, "busy", 1000)
+	AssertNoError(t, err, "get runs")
+	AssertEqual(t, jobRunsKept, len(runs), "kept runs")
+	AssertEqual(t, fmt.Sprintf("run %d", jobRunsKept+4), runs[0].Result, "newest run")
+}
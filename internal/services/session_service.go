@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// sessionTouchInterval is how stale last_seen_at may get before a request refreshes it
+const sessionTouchInterval = 5 * time.Minute
+
+// sessionListColumns are the columns shown on the devices page (everything but the values)
+const sessionListColumns = "id,token_hash,user_id,user_agent,ip_address,created_at,last_seen_at,expires_at"
+
+// SessionService keeps the server-side sessions in the sessions table
+// It is the backend of middleware.DBStore; only a hash of each cookie token is stored.
+type SessionService struct {
+	*BaseService
+	now func() time.Time
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(store Store) *SessionService {
+	return &SessionService{
+		BaseService: NewBaseService(store, "SessionService"),
+		now:         time.Now,
+	}
+}
+
+// LoadSession returns the encoded values of the unexpired session with token
+func (s *SessionService) LoadSession(ctx context.Context, token string) (string, bool, error) {
+	var session models.Session
+	err := s.QuerySingleRecord(ctx, "sessions", Where().Eq("token_hash", hashToken(token)), &session)
+	if errors.Is(err, ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	now := s.now()
+	if !now.Before(session.ExpiresAt) {
+		return "", false, nil
+	}
+
+	// Keep "last seen" roughly current without a write on every request
+	if now.Sub(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.UpdateRecord(ctx, "sessions", session.ID, map[string]interface{}{"last_seen_at": now.UTC()}); err != nil {
+			s.logger.Warn("Failed to update last seen of session %s: %v", session.ID.String(), err)
+		}
+	}
+	return session.Data, true, nil
+}
+
+// SaveSession creates or updates the session with token
+func (s *SessionService) SaveSession(ctx context.Context, token, userID, data, userAgent, ipAddress string, expiresAt time.Time) error {
+	values := map[string]interface{}{
+		"user_id":      nil,
+		"data":         data,
+		"user_agent":   userAgent,
+		"ip_address":   ipAddress,
+		"last_seen_at": s.now().UTC(),
+		"expires_at":   expiresAt.UTC(),
+	}
+	if id, err := uuid.Parse(userID); err == nil {
+		values["user_id"] = id.String()
+	}
+
+	tokenHash := hashToken(token)
+	result, err := s.store.Update(ctx, "sessions", Where().Eq("token_hash", tokenHash), values)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	var updated []json.RawMessage
+	if err := json.Unmarshal(result, &updated); err != nil {
+		return fmt.Errorf("failed to parse sessions data: %w", err)
+	}
+	if len(updated) > 0 {
+		return nil
+	}
+
+	values["id"] = uuid.New().String()
+	values["token_hash"] = tokenHash
+	return s.InsertRecord(ctx, "sessions", values)
+}
+
+// DeleteSession ends the session with token
+func (s *SessionService) DeleteSession(ctx context.Context, token string) error {
+	return s.DeleteRecordsWithFilter(ctx, "sessions", map[string]interface{}{"token_hash": hashToken(token)})
+}
+
+// ListUserSessions returns the user's unexpired sessions, most recently used first
+// The session whose token is currentToken is marked Current.
+func (s *SessionService) ListUserSessions(ctx context.Context, userID uuid.UUID, currentToken string) ([]models.Session, error) {
+	var sessions []models.Session
+	q := Where(WithUserID(userID)).Select(sessionListColumns).OrderBy("last_seen_at", false)
+	if err := s.QueryRecords(ctx, "sessions", q, &sessions); err != nil {
+		return nil, err
+	}
+
+	currentHash := hashToken(currentToken)
+	active := sessions[:0]
+	for _, session := range sessions {
+		if s.now().Before(session.ExpiresAt) {
+			session.Current = currentToken != "" && session.TokenHash == currentHash
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession ends one of the user's sessions
+func (s *SessionService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if err := s.store.Delete(ctx, "sessions", Where(WithUserID(userID)).Eq("id", sessionID.String())); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	s.logger.Info("Revoked session %s of user_id=%s", sessionID.String(), userID.String())
+	return nil
+}
+
+// RevokeOtherSessions ends every session of the user but the one with currentToken
+func (s *SessionService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentToken string) error {
+	q := Where(WithUserID(userID)).NotIn("token_hash", []string{hashToken(currentToken)})
+	if err := s.store.Delete(ctx, "sessions", q); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	s.logger.Info("Revoked the other sessions of user_id=%s", userID.String())
+	return nil
+}
+
+// DeleteExpiredSessions deletes the sessions that have expired
+func (s *SessionService) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	q := NewQuery().Lt("expires_at", s.now())
+	count, err := s.store.Count(ctx, "sessions", q)
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return 0, nil
+	}
+
+	if err := s.store.Delete(ctx, "sessions", q); err != nil {
+		return 0, fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return count, nil
+}
+
+// revokeUserSessions ends every session of the user, e.g. when an admin changes their rights
+func revokeUserSessions(ctx context.Context, b *BaseService, userID uuid.UUID) error {
+	if err := b.DeleteRecordsWithFilter(ctx, "sessions", WithUserID(userID)); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hekigan/couples/internal/middleware"
+)
+
+// saveTestSession saves a session for userID through the middleware store and returns the
+// request carrying its cookie
+func saveTestSession(t *testing.T, userID, userAgent string) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", userAgent)
+	session, err := middleware.Store.New(req, middleware.SessionCookieName)
+	AssertNoError(t, err, "new session")
+	session.Values["user_id"] = userID
+	session.Values["pending_since"] = int64(42)
+
+	rec := httptest.NewRecorder()
+	AssertNoError(t, session.Save(req, rec), "save session")
+
+	next := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range rec.Result().Cookies() {
+		next.AddCookie(cookie)
+	}
+	return next
+}
+
+// TestSessionService_StoreRoundTrip tests that session values survive a request through the
+// database, and that a revoked session comes back empty
+func TestSessionService_StoreRoundTrip(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewSessionService(store)
+	middleware.InitSessionStore(service, "current-secret")
+	user := CreateTestUser(t, store, "alice", "alice", false)
+
+	req := saveTestSession(t, user.ID.String(), "Firefox")
+	session, err := middleware.Store.New(req, middleware.SessionCookieName)
+	AssertNoError(t, err, "load session")
+	AssertTrue(t, !session.IsNew, "session found")
+	AssertEqual(t, user.ID.String(), session.Values["user_id"], "user_id")
+	AssertEqual(t, int64(42), session.Values["pending_since"], "int64 value keeps its type")
+
+	// A rotated secret still accepts the old cookies
+	middleware.InitSessionStore(service, "new-secret", "current-secret")
+	session, _ = middleware.Store.New(req, middleware.SessionCookieName)
+	AssertTrue(t, !session.IsNew, "session found after rotation")
+	middleware.InitSessionStore(service, "new-secret")
+	session, _ = middleware.Store.New(req, middleware.SessionCookieName)
+	AssertTrue(t, session.IsNew, "retired secret refused")
+
+	middleware.InitSessionStore(service, "current-secret")
+	AssertNoError(t, revokeUserSessions(context.Background(), service.BaseService, user.ID), "revoke")
+	session, _ = middleware.Store.New(req, middleware.SessionCookieName)
+	AssertTrue(t, session.IsNew, "revoked session is gone")
+	AssertEqual(t, 0, len(session.Values), "revoked session is empty")
+}
+
+// TestSessionService_Renew tests that a renewed session keeps its values under a new token and
+// that the old token no longer works
+func TestSessionService_Renew(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewSessionService(store)
+	middleware.InitSessionStore(service, "current-secret")
+	user := CreateTestUser(t, store, "frank", "frank", false)
+
+	req := saveTestSession(t, user.ID.String(), "Firefox")
+	session, err := middleware.Store.New(req, middleware.SessionCookieName)
+	AssertNoError(t, err, "load session")
+	oldToken := session.ID
+
+	AssertNoError(t, middleware.Store.Renew(req, session), "renew")
+	rec := httptest.NewRecorder()
+	AssertNoError(t, session.Save(req, rec), "save renewed session")
+	AssertTrue(t, session.ID != "" && session.ID != oldToken, "new token issued")
+
+	_, found, err := service.LoadSession(context.Background(), oldToken)
+	AssertNoError(t, err, "load old token")
+	AssertFalse(t, found, "old token revoked")
+
+	next := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, cookie := range rec.Result().Cookies() {
+		next.AddCookie(cookie)
+	}
+	renewed, err := middleware.Store.New(next, middleware.SessionCookieName)
+	AssertNoError(t, err, "load renewed session")
+	AssertFalse(t, renewed.IsNew, "renewed session found")
+	AssertEqual(t, user.ID.String(), renewed.Values["user_id"], "values kept")
+}
+
+// TestSessionService_Devices tests listing and revoking a user's sessions
+func TestSessionService_Devices(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewSessionService(store)
+	middleware.InitSessionStore(service, "current-secret")
+	ctx := context.Background()
+	user := CreateTestUser(t, store, "bob", "bob", false)
+	other := CreateTestUser(t, store, "carol", "carol", false)
+
+	phone := saveTestSession(t, user.ID.String(), "Phone")
+	saveTestSession(t, user.ID.String(), "Laptop")
+	tablet := saveTestSession(t, user.ID.String(), "Tablet")
+	saveTestSession(t, other.ID.String(), "Other")
+
+	current, _ := middleware.Store.New(phone, middleware.SessionCookieName)
+	sessions, err := service.ListUserSessions(ctx, user.ID, current.ID)
+	AssertNoError(t, err, "list")
+	AssertEqual(t, 3, len(sessions), "sessions of the user")
+	currentCount := 0
+	for _, session := range sessions {
+		if session.Current {
+			currentCount++
+			AssertEqual(t, "Phone", session.UserAgent, "current session")
+		}
+	}
+	AssertEqual(t, 1, currentCount, "one current session")
+
+	// Another user's session cannot be revoked
+	others, _ := service.ListUserSessions(ctx, other.ID, "")
+	AssertNoError(t, service.RevokeSession(ctx, user.ID, others[0].ID), "revoke someone else's")
+	others, _ = service.ListUserSessions(ctx, other.ID, "")
+	AssertEqual(t, 1, len(others), "other user's session kept")
+
+	tabletSession, _ := middleware.Store.New(tablet, middleware.SessionCookieName)
+	for _, session := range sessions {
+		if session.UserAgent == "Tablet" {
+			AssertNoError(t, service.RevokeSession(ctx, user.ID, session.ID), "revoke tablet")
+		}
+	}
+	_, found, err := service.LoadSession(ctx, tabletSession.ID)
+	AssertNoError(t, err, "load revoked")
+	AssertTrue(t, !found, "tablet signed out")
+
+	AssertNoError(t, service.RevokeOtherSessions(ctx, user.ID, current.ID), "revoke others")
+	sessions, _ = service.ListUserSessions(ctx, user.ID, current.ID)
+	AssertEqual(t, 1, len(sessions), "only this device left")
+	AssertTrue(t, sessions[0].Current, "this device kept")
+}
+
+// TestSessionService_Expiry tests that expired sessions are ignored and then deleted
+func TestSessionService_Expiry(t *testing.T) {
+	store := SetupTestStore(t)
+	service := NewSessionService(store)
+	ctx := context.Background()
+	user := CreateTestUser(t, store, "dave", "dave", false)
+
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	AssertNoError(t, service.SaveSession(ctx, "short", user.ID.String(), "", "", "", now.Add(time.Hour)), "save short")
+	AssertNoError(t, service.SaveSession(ctx, "long", user.ID.String(), "", "", "", now.Add(48*time.Hour)), "save long")
+	AssertNoError(t, service.SaveSession(ctx, "guest", "", "", "", "", now.Add(time.Hour)), "save without user")
+
+	now = now.Add(2 * time.Hour)
+	_, found, err := service.LoadSession(ctx, "short")
+	AssertNoError(t, err, "load expired")
+	AssertTrue(t, !found, "expired session ignored")
+
+	deleted, err := service.DeleteExpiredSessions(ctx)
+	AssertNoError(t, err, "delete expired")
+	AssertEqual(t, 2, deleted, "expired sessions deleted")
+	_, found, _ = service.LoadSession(ctx, "long")
+	AssertTrue(t, found, "unexpired session kept")
+}
+
+// TestAdminService_EndsSessionsOnRightsChange tests that demoting or deleting a user signs them out
+func TestAdminService_EndsSessionsOnRightsChange(t *testing.T) {
+	store := SetupTestStore(t)
+	sessions := NewSessionService(store)
+	admin := NewAdminService(store)
+	ctx := context.Background()
+	user := CreateTestUser(t, store, "erin", "erin", false)
+	expires := time.Now().Add(time.Hour)
+
+	AssertNoError(t, sessions.SaveSession(ctx, "erin-1", user.ID.String(), "", "", "", expires), "save")
+	AssertNoError(t, admin.ToggleUserAdmin(ctx, user.ID), "promote")
+	_, found, _ := sessions.LoadSession(ctx, "erin-1")
+	AssertTrue(t, !found, "session ended on promotion")
+
+	AssertNoError(t, sessions.SaveSession(ctx, "erin-2", user.ID.String(), "", "", "", expires), "save")
+	AssertNoError(t, admin.UpdateUser(ctx, user.ID, "erin2", "", true, false), "rename")
+	_, found, _ = sessions.LoadSession(ctx, "erin-2")
+	AssertTrue(t, found, "session kept when the admin flag is unchanged")
+
+	AssertNoError(t, admin.DeleteUser(ctx, user.ID), "delete")
+	_, found, _ = sessions.LoadSession(ctx, "erin-2")
+	AssertTrue(t, !found, "session ended on deletion")
+}
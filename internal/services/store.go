@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned (wrapped) when a single-record lookup matches no rows
+var ErrNotFound = errors.New("record not found")
+
+// Store is the persistence backend behind BaseService
+// Rows travel as JSON so services keep unmarshaling into their models exactly as before:
+//   - SupabaseStore talks to PostgREST (production default)
+//   - PostgresStore talks to PostgreSQL directly through a connection pool
+//   - MemoryStore keeps every table in process (tests, offline development)
+type Store interface {
+	// Select returns the rows of a table or view matching q as a JSON array
+	Select(ctx context.Context, table string, q *Query) ([]byte, error)
+
+	// Count returns the number of rows matching q (ordering and paging are ignored)
+	Count(ctx context.Context, table string, q *Query) (int, error)
+
+	// Insert inserts one row and returns the stored rows as a JSON array
+	Insert(ctx context.Context, table string, data map[string]interface{}) ([]byte, error)
+
+	// Update applies data to every row matching q and returns the updated rows as a JSON array
+	Update(ctx context.Context, table string, q *Query, data map[string]interface{}) ([]byte, error)
+
+	// Delete removes every row matching q
+	Delete(ctx context.Context, table string, q *Query) error
+
+	// WithTx runs fn in a transaction: every write made through tx is committed when fn
+	// returns nil and rolled back when it returns an error. Calling WithTx on tx opens a
+	// savepoint in the open transaction, so a failed nested call only undoes its own writes
+	// and the outer fn may carry on. Backends without transactions run fn directly (see SupabaseStore).
+	WithTx(ctx context.Context, fn func(tx Store) error) error
+}
+
+// Query selects the rows a Store operation applies to
+// A nil *Query matches every row. Build one with NewQuery or Where and chain refinements:
+//
+//	q := Where(WithRoomID(roomID)).In("status", statuses).OrderBy("created_at", false).Page(25, 0)
+type Query struct {
+	// Columns is a PostgREST-style column list ("id,username"); empty means "*"
+	Columns string
+
+	// Filters are equality filters; a nil value matches NULL
+	Filters map[string]interface{}
+
+	// InFilters / NotInFilters match (or exclude) rows whose column is one of the values
+	InFilters    map[string][]string
+	NotInFilters map[string][]string
+
+	// LtFilters keep rows whose column is below the value; NULL never matches
+	LtFilters map[string]interface{}
+
+	// Search matches rows where any of the columns contains the term (case-insensitive)
+	Search *SearchFilter
+
+	// Orders are applied in sequence
+	Orders []Order
+
+	// Limit (0 = no limit) and Offset page through the result
+	Limit  int
+	Offset int
+}
+
+// SearchFilter is a case-insensitive substring match across several columns (ILIKE ... OR ...)
+type SearchFilter struct {
+	Term    string
+	Columns []string
+}
+
+// Order sorts the result by a column
+type Order struct {
+	Column     string
+	Ascending  bool
+	NullsFirst bool
+}
+
+// NewQuery creates an empty query (matches every row)
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where creates a query from equality filters (see BuildFilter and the With* helpers)
+func Where(filters ...map[string]interface{}) *Query {
+	return &Query{Filters: BuildFilter(filters...)}
+}
+
+// Select restricts the returned columns
+func (q *Query) Select(columns string) *Query {
+	q.Columns = columns
+	return q
+}
+
+// Eq adds an equality filter; a nil value matches NULL
+func (q *Query) Eq(column string, value interface{}) *Query {
+	if q.Filters == nil {
+		q.Filters = make(map[string]interface{})
+	}
+	q.Filters[column] = value
+	return q
+}
+
+// In keeps rows whose column is one of values
+// An empty list matches nothing, like SQL's "IN ()"
+func (q *Query) In(column string, values []string) *Query {
+	if q.InFilters == nil {
+		q.InFilters = make(map[string][]string)
+	}
+	q.InFilters[column] = values
+	return q
+}
+
+// NotIn drops rows whose column is one of values; an empty list drops nothing
+func (q *Query) NotIn(column string, values []string) *Query {
+	if len(values) == 0 {
+		return q
+	}
+	if q.NotInFilters == nil {
+		q.NotInFilters = make(map[string][]string)
+	}
+	q.NotInFilters[column] = values
+	return q
+}
+
+// Lt keeps rows whose column is less than value (numbers, timestamps, text)
+func (q *Query) Lt(column string, value interface{}) *Query {
+	if q.LtFilters == nil {
+		q.LtFilters = make(map[string]interface{})
+	}
+	q.LtFilters[column] = value
+	return q
+}
+
+// SearchIn keeps rows where any of columns contains term (case-insensitive); an empty term is ignored
+func (q *Query) SearchIn(term string, columns ...string) *Query {
+	if term == "" || len(columns) == 0 {
+		return q
+	}
+	q.Search = &SearchFilter{Term: term, Columns: columns}
+	return q
+}
+
+// OrderBy appends a sort column (NULLs sort last)
+func (q *Query) OrderBy(column string, ascending bool) *Query {
+	q.Orders = append(q.Orders, Order{Column: column, Ascending: ascending})
+	return q
+}
+
+// OrderByNulls appends a sort column with explicit NULL placement
+func (q *Query) OrderByNulls(column string, ascending, nullsFirst bool) *Query {
+	q.Orders = append(q.Orders, Order{Column: column, Ascending: ascending, NullsFirst: nullsFirst})
+	return q
+}
+
+// Page limits the result to limit rows starting at offset (limit <= 0 means no limit)
+func (q *Query) Page(limit, offset int) *Query {
+	q.Limit = limit
+	q.Offset = offset
+	return q
+}
+
+// filterValue converts a filter value to the string form used in PostgREST filters
+func filterValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
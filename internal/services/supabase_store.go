@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/supabase-community/postgrest-go"
+	supabase "github.com/supabase-community/supabase-go"
+)
+
+// SupabaseStore implements Store on top of the Supabase PostgREST client
+type SupabaseStore struct {
+	client *supabase.Client
+}
+
+// NewSupabaseStore wraps a Supabase client
+func NewSupabaseStore(client *supabase.Client) *SupabaseStore {
+	return &SupabaseStore{client: client}
+}
+
+// Client returns the underlying Supabase client
+func (s *SupabaseStore) Client() *supabase.Client {
+	return s.client
+}
+
+// Select returns matching rows as a JSON array
+func (s *SupabaseStore) Select(ctx context.Context, table string, q *Query) ([]byte, error) {
+	columns := "*"
+	if q != nil && q.Columns != "" {
+		columns = q.Columns
+	}
+
+	query := applyFilters(s.client.From(table).Select(columns, "", false), q)
+
+	if q != nil {
+		for _, order := range q.Orders {
+			query = query.Order(order.Column, &postgrest.OrderOpts{
+				Ascending:  order.Ascending,
+				NullsFirst: order.NullsFirst,
+			})
+		}
+		if q.Limit > 0 {
+			query = query.Range(q.Offset, q.Offset+q.Limit-1, "")
+		}
+	}
+
+	data, _, err := query.Execute()
+	return data, err
+}
+
+// Count returns the exact number of matching rows (HEAD request, no rows transferred)
+func (s *SupabaseStore) Count(ctx context.Context, table string, q *Query) (int, error) {
+	_, count, err := applyFilters(s.client.From(table).Select("*", "exact", true), q).Execute()
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Insert inserts one row and returns the stored representation
+func (s *SupabaseStore) Insert(ctx context.Context, table string, data map[string]interface{}) ([]byte, error) {
+	response, _, err := s.client.From(table).
+		Insert(data, false, "", "", "").
+		Execute()
+	return response, err
+}
+
+// Update updates every matching row and returns the updated representation
+func (s *SupabaseStore) Update(ctx context.Context, table string, q *Query, data map[string]interface{}) ([]byte, error) {
+	response, _, err := applyFilters(s.client.From(table).Update(data, "", ""), q).Execute()
+	return response, err
+}
+
+// Delete removes every matching row
+func (s *SupabaseStore) Delete(ctx context.Context, table string, q *Query) error {
+	_, _, err := applyFilters(s.client.From(table).Delete("", ""), q).Execute()
+	return err
+}
+
+// WithTx runs fn directly: PostgREST has no multi-request transactions, so writes made
+// before a failure are not rolled back. Use PostgresStore where atomicity matters.
+func (s *SupabaseStore) WithTx(ctx context.Context, fn func(tx Store) error) error {
+	return fn(s)
+}
+
+// applyFilters translates the query filters into PostgREST filter parameters
+func applyFilters(query *postgrest.FilterBuilder, q *Query) *postgrest.FilterBuilder {
+	if q == nil {
+		return query
+	}
+
+	for key, value := range q.Filters {
+		if value == nil {
+			query = query.Is(key, "null")
+			continue
+		}
+		query = query.Eq(key, filterValue(value))
+	}
+
+	for key, values := range q.InFilters {
+		query = query.In(key, values)
+	}
+
+	for key, values := range q.NotInFilters {
+		query = query.Not(key, "in", "("+strings.Join(values, ",")+")")
+	}
+
+	for key, value := range q.LtFilters {
+		query = query.Lt(key, filterValue(value))
+	}
+
+	if q.Search != nil {
+		conditions := make([]string, len(q.Search.Columns))
+		for i, column := range q.Search.Columns {
+			conditions[i] = fmt.Sprintf("%s.ilike.%%%s%%", column, q.Search.Term)
+		}
+		query = query.Or(strings.Join(conditions, ","), "")
+	}
+
+	return query
+}
@@ -171,6 +171,13 @@ type TurnIndicatorData struct {
 	OtherPlayerName string
 }
 
+// PresenceIndicatorData represents data for presence indicator partial
+type PresenceIndicatorData struct {
+	OtherPlayerName   string
+	OtherPlayerOnline bool
+	Paused            bool
+}
+
 // QuestionCardData represents data for question card partial
 type QuestionCardData struct {
 	QuestionText string
@@ -209,7 +216,7 @@ type BadgeUpdateData struct {
 
 // RoomStatusBadgeData represents data for room status badge partial
 type RoomStatusBadgeData struct {
-	Status string // "waiting", "ready", "playing"
+	Status string // models.RoomState
 }
 
 // ProgressCounterData represents data for progress counter partial
@@ -230,6 +237,8 @@ type AdminUserInfo struct {
 	UserType  string // "registered", "guest", "admin"
 	IsAdmin   bool
 	CreatedAt string
+	// LastActive is the last visit (or creation), shown by the cleanup preview
+	LastActive string
 }
 
 // UsersListData represents data for admin users list partial
@@ -250,13 +259,19 @@ type UsersListData struct {
 	ItemName        string // Name of items for display
 }
 
+// ExpiredAnonymousUsersData represents the dry run of the anonymous user cleanup
+type ExpiredAnonymousUsersData struct {
+	OlderThanHours int
+	Users          []AdminUserInfo
+}
+
 // AdminQuestionInfo represents a question in the admin list
 type AdminQuestionInfo struct {
 	ID               string
 	Text             string
 	CategoryLabel    string // Combined icon + label
 	LanguageCode     string
-	TranslationCount int // Number of translations (0-3) for this question
+	TranslationCount int // Number of language versions of this question
 }
 
 // AdminCategoryOption represents a category option for dropdowns
@@ -277,6 +292,7 @@ type QuestionsListData struct {
 	TotalPages               int // Total number of pages
 	ItemsPerPage             int // Number of items per page
 	MissingTranslationsCount int // Total number of incomplete translations
+	LanguageCount            int // Number of enabled languages (a complete question has one version each)
 	// Pagination template fields
 	BaseURL         string // API URL for fetching data (e.g., "/admin/api/questions/list")
 	PageURL         string // Page URL for browser history (e.g., "/admin/questions")
@@ -286,20 +302,8 @@ type QuestionsListData struct {
 	ItemName        string // Name of items for display (e.g., "questions")
 }
 
-// QuestionEditFormData represents data for question edit form partial
-type QuestionEditFormData struct {
-	QuestionID     string
-	BaseQuestionID string
-	QuestionText   string // English question text
-	TranslationFR  string // French translation
-	TranslationJA  string // Japanese translation
-	Categories     []AdminCategoryOption
-	LangEN         bool
-	LangFR         bool
-	LangJA         bool
-	SelectedLang   string // Currently selected language code
-	Page           int    // Current page number for pagination
-}
+// QuestionEditFormData is an alias for backwards compatibility
+type QuestionEditFormData = QuestionFormData
 
 // AdminCategoryInfo represents a category in the admin list
 type AdminCategoryInfo struct {
@@ -327,9 +331,10 @@ type CategoriesListData struct {
 
 // CategoryFormData represents data for category create/edit form (shared template)
 type CategoryFormData struct {
-	ID    string // Empty for create mode, populated for edit mode
-	Key   string
-	Label string
+	ID     string // Empty for create mode, populated for edit mode
+	Key    string
+	Label  string
+	Weight float64
 }
 
 // CategoryEditFormData is an alias for backwards compatibility
@@ -346,16 +351,20 @@ type UserFormData struct {
 
 // QuestionFormData represents data for question create/edit form (shared template)
 type QuestionFormData struct {
-	QuestionID     string                 // Empty for create mode, populated for edit mode
-	BaseQuestionID string                 // Base question ID (for translations)
-	Categories     []AdminCategoryOption  // Available categories
-	QuestionText   string                 // English question text
-	TranslationFR  string                 // French translation
-	TranslationJA  string                 // Japanese translation
-	SelectedLang   string                 // Currently selected language (en, fr, ja)
-	LangEN         bool                   // True if English is selected
-	LangFR         bool                   // True if French is selected
-	LangJA         bool                   // True if Japanese is selected
+	QuestionID     string                  // Empty for create mode, populated for edit mode
+	BaseQuestionID string                  // Base question ID (for translations)
+	Categories     []AdminCategoryOption   // Available categories
+	BaseLanguage   QuestionLanguageField   // The base question's language (English) and text
+	Translations   []QuestionLanguageField // The other languages, with the existing translations
+	SelectedLang   string                  // Currently selected language code
+}
+
+// QuestionLanguageField is one language version of a question in the question form
+type QuestionLanguageField struct {
+	Code     string
+	Name     string // Native name from the language registry
+	Text     string // Empty when the question has no version in this language
+	Selected bool
 }
 
 // RoomDetailsData represents data for room details view (read-only)
@@ -421,6 +430,37 @@ type DashboardStatsData struct {
 	TotalCategories int
 }
 
+// AdminJobInfo represents a scheduled job in the admin jobs list
+type AdminJobInfo struct {
+	Name       string
+	Spec       string
+	NextRun    string // Empty when the schedule never fires again
+	Running    bool
+	LastRun    string // Empty when the job has not run yet
+	Duration   string
+	LastStatus string // "succeeded", "failed" or empty
+	LastResult string
+	// Latest failure among the kept runs
+	LastError   string
+	LastErrorAt string
+}
+
+// JobsListData represents data for the admin jobs list partial
+type JobsListData struct {
+	Jobs []AdminJobInfo
+	// Message reports the outcome of a "run now" request
+	Message string
+}
+
+// TranslationsListData represents data for the admin translations list partial
+type TranslationsListData struct {
+	Languages    []string
+	Registry     []models.Language // Every language with its settings
+	SelectedLang string
+	Entries      []TranslationEntry
+	MissingKeys  map[string][]string // Keys each language lacks
+}
+
 // ============================================================================
 // Pagination Interface Implementation
 // ============================================================================
@@ -12,10 +12,38 @@ import (
 	"github.com/hekigan/couples/internal/models"
 )
 
+// SetupTestStore returns the store service tests run against:
+// a migrated PostgreSQL database when TEST_DATABASE_URL is set, the Supabase test
+// database when TEST_SUPABASE_URL/TEST_SUPABASE_KEY are configured,
+// otherwise a fresh MemoryStore so the suite also runs offline
+func SetupTestStore(t *testing.T) Store {
+	_ = godotenv.Load("../../.env.test")
+
+	if databaseURL := os.Getenv("TEST_DATABASE_URL"); databaseURL != "" {
+		store, err := NewPostgresStore(context.Background(), databaseURL, PostgresPoolConfig{MaxConns: 4})
+		if err != nil {
+			t.Fatalf("Failed to connect to TEST_DATABASE_URL: %v", err)
+		}
+		t.Cleanup(func() {
+			CleanupTestData(t, store)
+			store.Close()
+		})
+		return store
+	}
+
+	if os.Getenv("TEST_SUPABASE_URL") == "" || os.Getenv("TEST_SUPABASE_KEY") == "" {
+		return NewMemoryStore()
+	}
+
+	store := SetupTestDatabase(t)
+	t.Cleanup(func() { CleanupTestData(t, store) })
+	return store
+}
+
 // SetupTestDatabase initializes a test database connection
 // It looks for TEST_SUPABASE_URL and TEST_SUPABASE_KEY environment variables
 // If not found, it attempts to load from .env.test file
-func SetupTestDatabase(t *testing.T) *supabase.Client {
+func SetupTestDatabase(t *testing.T) Store {
 	// Try to load test environment file (ignore errors if not present)
 	_ = godotenv.Load("../../.env.test")
 
@@ -33,39 +61,47 @@ func SetupTestDatabase(t *testing.T) *supabase.Client {
 		t.Fatalf("Failed to create test database client: %v\nCheck your TEST_SUPABASE_URL and TEST_SUPABASE_KEY", err)
 	}
 
-	return client
+	return NewSupabaseStore(client)
 }
 
 // CleanupTestData cleans up all test data from the database
 // This should be called with defer after SetupTestDatabase
 // Example:
 //
-//	client := SetupTestDatabase(t)
-//	defer CleanupTestData(t, client)
-func CleanupTestData(t *testing.T, client *supabase.Client) {
+//	store := SetupTestDatabase(t)
+//	defer CleanupTestData(t, store)
+func CleanupTestData(t *testing.T, store Store) {
 	// Delete in reverse dependency order to avoid foreign key constraints
 	tables := []string{
-		"question_history",   // References: questions, rooms
-		"answers",            // References: questions, rooms, users
-		"room_join_requests", // References: rooms, users
-		"room_invitations",   // References: rooms, users
-		"notifications",      // References: users
-		"friends",            // References: users
-		"rooms",              // References: users
-		"users",              // No dependencies
+		"question_history",        // References: questions, rooms
+		"player_question_history", // References: questions, users
+		"room_state_transitions",  // References: rooms
+		"room_events",             // References: rooms, users
+		"room_presence",           // References: rooms, users
+		"answers",                 // References: questions, rooms, users
+		"room_join_requests",      // References: rooms, users
+		"room_invitations",        // References: rooms, users
+		"notifications",           // References: users
+		"friends",                 // References: users
+		"rooms",                   // References: users
+		"account_tokens",          // References: users
+		"two_factor_recovery_codes", // References: users
+		"user_two_factor",         // References: users
+		"sessions",                // References: users
+		"api_tokens",              // References: users
+		"users",                   // No dependencies
 	}
 
 	for _, table := range tables {
-		_, _, err := client.From(table).Delete("", "").Execute()
-		if err != nil {
+		if err := store.Delete(context.Background(), table, nil); err != nil {
 			t.Logf("Warning: Failed to clean table %s: %v (this may be expected if table is empty)", table, err)
 		}
 	}
 }
 
 // CreateTestUser creates a test user and returns the user object
-func CreateTestUser(t *testing.T, client *supabase.Client, username, name string, isAnonymous bool) *UserTestData {
-	userService := NewUserService(client)
+func CreateTestUser(t *testing.T, store Store, username, name string, isAnonymous bool) *UserTestData {
+	userService := NewUserService(store)
 
 	var user *UserTestData
 	if isAnonymous {
@@ -79,9 +115,20 @@ func CreateTestUser(t *testing.T, client *supabase.Client, username, name string
 			IsAnonymous: true,
 		}
 	} else {
-		// For non-anonymous users, we'll need to use direct insert
-		// since there's no CreateUser method for non-anonymous
-		t.Skip("Non-anonymous user creation not yet implemented in test helpers")
+		// Non-anonymous users are inserted directly (auth normally creates them)
+		userID := uuid.New()
+		if err := NewBaseService(store, "TestHelpers").InsertRecord(context.Background(), "users", map[string]interface{}{
+			"id":           userID.String(),
+			"username":     username,
+			"name":         name,
+			"is_anonymous": false,
+		}); err != nil {
+			t.Fatalf("Failed to create test user: %v", err)
+		}
+		user = &UserTestData{
+			ID:       userID,
+			Username: username,
+		}
 	}
 
 	return user
@@ -95,14 +142,14 @@ type UserTestData struct {
 }
 
 // CreateTestRoom creates a test room and returns the room ID
-func CreateTestRoom(t *testing.T, client *supabase.Client, ownerID uuid.UUID, language string) uuid.UUID {
-	roomService := NewRoomService(client, nil) // nil realtimeService for tests
+func CreateTestRoom(t *testing.T, store Store, ownerID uuid.UUID, language string) uuid.UUID {
+	roomService := NewRoomService(store, NewRealtimeService())
 
 	room := &models.Room{
 		ID:       uuid.New(),
 		OwnerID:  ownerID,
 		Language: language,
-		Status:   "waiting",
+		Status:   models.RoomWaiting,
 	}
 
 	err := roomService.CreateRoom(context.Background(), room)
@@ -114,13 +161,14 @@ func CreateTestRoom(t *testing.T, client *supabase.Client, ownerID uuid.UUID, la
 }
 
 // CreateTestCategory creates a test category and returns the category ID
-func CreateTestCategory(t *testing.T, client *supabase.Client, name string) uuid.UUID {
+func CreateTestCategory(t *testing.T, store Store, name string) uuid.UUID {
 	categoryData := map[string]interface{}{
-		"id":   uuid.New().String(),
-		"name": name,
+		"id":    uuid.New().String(),
+		"key":   name,
+		"label": name,
 	}
 
-	data, _, err := client.From("categories").Insert(categoryData, false, "", "", "").Execute()
+	data, err := store.Insert(context.Background(), "categories", categoryData)
 	if err != nil {
 		t.Fatalf("Failed to create test category: %v", err)
 	}
@@ -137,15 +185,17 @@ func CreateTestCategory(t *testing.T, client *supabase.Client, name string) uuid
 }
 
 // CreateTestQuestion creates a test question and returns the question ID
-func CreateTestQuestion(t *testing.T, client *supabase.Client, categoryID uuid.UUID, langCode, text string) uuid.UUID {
+func CreateTestQuestion(t *testing.T, store Store, categoryID uuid.UUID, langCode, text string) uuid.UUID {
+	questionID := uuid.New().String()
 	questionData := map[string]interface{}{
-		"id":            uuid.New().String(),
-		"category_id":   categoryID.String(),
-		"lang_code":     langCode,
-		"question_text": text,
+		"id":               questionID,
+		"category_id":      categoryID.String(),
+		"lang_code":        langCode,
+		"question_text":    text,
+		"base_question_id": questionID, // base questions reference themselves
 	}
 
-	data, _, err := client.From("questions").Insert(questionData, false, "", "", "").Execute()
+	data, err := store.Insert(context.Background(), "questions", questionData)
 	if err != nil {
 		t.Fatalf("Failed to create test question: %v", err)
 	}
@@ -157,8 +207,8 @@ func CreateTestQuestion(t *testing.T, client *supabase.Client, categoryID uuid.U
 		t.Fatalf("Failed to parse question response: %v", err)
 	}
 
-	questionID, _ := uuid.Parse(questions[0].ID)
-	return questionID
+	parsedID, _ := uuid.Parse(questions[0].ID)
+	return parsedID
 }
 
 // AssertNoError fails the test if err is not nil
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTOTPCode tests the RFC 6238 SHA-1 test vectors (truncated to 6 digits)
+func TestTOTPCode(t *testing.T) {
+	key := []byte("12345678901234567890")
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		if got := totpCode(key, totpStep(time.Unix(tt.unix, 0))); got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+// TestVerifyTOTP tests clock drift and replay refusal
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	AssertNoError(t, err, "secret")
+	key, err := totpEncoding.DecodeString(secret)
+	AssertNoError(t, err, "decode secret")
+
+	now := time.Unix(1700000000, 0)
+	step := totpStep(now)
+
+	got, ok := verifyTOTP(secret, totpCode(key, step-1), now, 0)
+	AssertTrue(t, ok, "previous step accepted")
+	AssertEqual(t, step-1, got, "matched step")
+
+	_, ok = verifyTOTP(secret, totpCode(key, step-2), now, 0)
+	AssertTrue(t, !ok, "two steps ago refused")
+
+	_, ok = verifyTOTP(secret, totpCode(key, step), now, step)
+	AssertTrue(t, !ok, "used step refused")
+
+	code := totpCode(key, step)
+	_, ok = verifyTOTP(secret, code[:3]+" "+code[3:], now, 0)
+	AssertTrue(t, ok, "spaces ignored")
+}
+
+// TestTOTPURI tests the otpauth URI authenticator apps scan
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("Couple Card Game", "alice@example.com", "ABCDEF")
+	AssertTrue(t, strings.HasPrefix(uri, "otpauth://totp/Couple%20Card%20Game:alice@example.com?"), "label")
+
+	parsed, err := url.Parse(uri)
+	AssertNoError(t, err, "parse")
+	AssertEqual(t, "ABCDEF", parsed.Query().Get("secret"), "secret")
+	AssertEqual(t, "Couple Card Game", parsed.Query().Get("issuer"), "issuer")
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// Two-factor limits
+const (
+	// twoFactorMaxAttempts wrong codes in a row lock the second factor for twoFactorLockDuration
+	twoFactorMaxAttempts  = 5
+	twoFactorLockDuration = 5 * time.Minute
+	// RecoveryCodeCount is how many recovery codes are issued at a time
+	RecoveryCodeCount = 10
+)
+
+// TwoFactorEnrollment is what a user needs to add the account to an authenticator app
+type TwoFactorEnrollment struct {
+	Secret string // base32, for manual entry
+	URI    string // otpauth:// URI, opened by authenticator apps (or encoded as a QR code)
+}
+
+// TwoFactorService manages TOTP two-factor authentication and recovery codes
+type TwoFactorService struct {
+	*BaseService
+	issuer string
+	now    func() time.Time
+}
+
+// NewTwoFactorService creates a new two-factor service
+// issuer is the name authenticator apps show next to the codes.
+func NewTwoFactorService(store Store, issuer string) *TwoFactorService {
+	return &TwoFactorService{
+		BaseService: NewBaseService(store, "TwoFactorService"),
+		issuer:      issuer,
+		now:         time.Now,
+	}
+}
+
+// IsEnabled reports whether the user has confirmed two-factor enrollment
+func (s *TwoFactorService) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	record, err := s.getRecord(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return record.Enabled(), nil
+}
+
+// BeginEnrollment returns the secret to add to an authenticator app
+// The secret is kept across calls until enrollment is confirmed, so reloading the page
+// does not invalidate an app that already scanned it.
+func (s *TwoFactorService) BeginEnrollment(ctx context.Context, user *models.User) (*TwoFactorEnrollment, error) {
+	if user.IsAnonymous {
+		return nil, models.ErrEmailRequired
+	}
+
+	account := user.Username
+	if user.Email != nil && *user.Email != "" {
+		account = *user.Email
+	}
+
+	record, err := s.getRecord(ctx, user.ID)
+	switch {
+	case err == nil && record.Enabled():
+		return nil, models.ErrTwoFactorEnabled
+	case err == nil:
+		return &TwoFactorEnrollment{Secret: record.Secret, URI: TOTPURI(s.issuer, account, record.Secret)}, nil
+	case !errors.Is(err, ErrNotFound):
+		return nil, err
+	}
+
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.InsertRecord(ctx, "user_two_factor", map[string]interface{}{
+		"id":      uuid.New().String(),
+		"user_id": user.ID.String(),
+		"secret":  secret,
+	}); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Started two-factor enrollment for user_id=%s", user.ID.String())
+	return &TwoFactorEnrollment{Secret: secret, URI: TOTPURI(s.issuer, account, secret)}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication once the user enters a code from the
+// app, and returns the recovery codes (only shown this once)
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	record, err := s.getRecord(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return nil, models.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if record.Enabled() {
+		return nil, models.ErrTwoFactorEnabled
+	}
+
+	step, ok := verifyTOTP(record.Secret, code, s.now(), record.LastUsedStep)
+	if !ok {
+		return nil, models.ErrInvalidTwoFactorCode
+	}
+	if err := s.UpdateRecord(ctx, "user_two_factor", record.ID, map[string]interface{}{
+		"enabled_at":      s.now().UTC(),
+		"last_used_step":  step,
+		"failed_attempts": 0,
+	}); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Success("Enabled two-factor authentication for user_id=%s", userID.String())
+	return codes, nil
+}
+
+// Verify checks a TOTP code or an unused recovery code
+// After twoFactorMaxAttempts wrong codes in a row, every code is refused with
+// models.ErrTwoFactorLocked for twoFactorLockDuration.
+func (s *TwoFactorService) Verify(ctx context.Context, userID uuid.UUID, code string) error {
+	record, err := s.getRecord(ctx, userID)
+	if errors.Is(err, ErrNotFound) {
+		return models.ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if !record.Enabled() {
+		return models.ErrTwoFactorNotEnabled
+	}
+	if record.LockedUntil != nil && s.now().Before(*record.LockedUntil) {
+		return models.ErrTwoFactorLocked
+	}
+
+	if step, ok := verifyTOTP(record.Secret, code, s.now(), record.LastUsedStep); ok {
+		// Claim the step so the same code cannot be used twice, even concurrently
+		claimed, err := s.updateRecord(ctx, Where().Eq("id", record.ID.String()).Eq("last_used_step", record.LastUsedStep),
+			map[string]interface{}{"last_used_step": step, "failed_attempts": 0, "locked_until": nil})
+		if err != nil {
+			return err
+		}
+		if claimed {
+			return nil
+		}
+	} else {
+		used, err := s.useRecoveryCode(ctx, userID, code)
+		if err != nil {
+			return err
+		}
+		if used {
+			s.logger.Info("Recovery code used by user_id=%s", userID.String())
+			_, err := s.updateRecord(ctx, Where().Eq("id", record.ID.String()),
+				map[string]interface{}{"failed_attempts": 0, "locked_until": nil})
+			return err
+		}
+	}
+
+	return s.registerFailure(ctx, record)
+}
+
+// Disable turns two-factor authentication off after checking a code
+// Admins cannot: the admin pages require a second factor.
+func (s *TwoFactorService) Disable(ctx context.Context, user *models.User, code string) error {
+	if user.IsAdmin {
+		return models.ErrTwoFactorRequired
+	}
+	if err := s.Verify(ctx, user.ID, code); err != nil {
+		return err
+	}
+
+	err := s.WithTx(ctx, func(tx *BaseService) error {
+		if err := tx.DeleteRecordsWithFilter(ctx, "two_factor_recovery_codes", WithUserID(user.ID)); err != nil {
+			return err
+		}
+		return tx.DeleteRecordsWithFilter(ctx, "user_two_factor", WithUserID(user.ID))
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Success("Disabled two-factor authentication for user_id=%s", user.ID.String())
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes after checking a code
+func (s *TwoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(ctx, userID)
+}
+
+// RemainingRecoveryCodes counts the user's unused recovery codes
+func (s *TwoFactorService) RemainingRecoveryCodes(ctx context.Context, userID uuid.UUID) (int, error) {
+	return s.CountQueryRecords(ctx, "two_factor_recovery_codes", Where(WithUserID(userID)).Eq("used_at", nil))
+}
+
+// getRecord returns the user's two-factor record, wrapping ErrNotFound when there is none
+func (s *TwoFactorService) getRecord(ctx context.Context, userID uuid.UUID) (*models.UserTwoFactor, error) {
+	var record models.UserTwoFactor
+	if err := s.QuerySingleRecord(ctx, "user_two_factor", Where(WithUserID(userID)), &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// updateRecord applies data to the user_two_factor rows matching q and reports whether any matched
+func (s *TwoFactorService) updateRecord(ctx context.Context, q *Query, data map[string]interface{}) (bool, error) {
+	result, err := s.store.Update(ctx, "user_two_factor", q, data)
+	if err != nil {
+		return false, fmt.Errorf("failed to update user_two_factor: %w", err)
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(result, &rows); err != nil {
+		return false, fmt.Errorf("failed to parse user_two_factor data: %w", err)
+	}
+	return len(rows) > 0, nil
+}
+
+// registerFailure counts a wrong code, locking the second factor at twoFactorMaxAttempts.
+// The count is only written if it has not moved since it was read, so parallel guesses
+// cannot overwrite each other's increments.
+func (s *TwoFactorService) registerFailure(ctx context.Context, record *models.UserTwoFactor) error {
+	for {
+		if record.LockedUntil != nil && s.now().Before(*record.LockedUntil) {
+			return models.ErrTwoFactorLocked
+		}
+
+		attempts := record.FailedAttempts + 1
+		data := map[string]interface{}{"failed_attempts": attempts}
+		locked := attempts >= twoFactorMaxAttempts
+		if locked {
+			data["failed_attempts"] = 0
+			data["locked_until"] = s.now().Add(twoFactorLockDuration).UTC()
+		}
+		counted, err := s.updateRecord(ctx, Where().Eq("id", record.ID.String()).Eq("failed_attempts", record.FailedAttempts), data)
+		if err != nil {
+			return err
+		}
+		if !counted {
+			// Another request changed the count first: start again from the stored one
+			if record, err = s.getRecord(ctx, record.UserID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if locked {
+			s.logger.Warn("Locked two-factor for user_id=%s after %d wrong codes", record.UserID.String(), attempts)
+			return models.ErrTwoFactorLocked
+		}
+		return models.ErrInvalidTwoFactorCode
+	}
+}
+
+// useRecoveryCode marks an unused recovery code as used and reports whether code was one
+func (s *TwoFactorService) useRecoveryCode(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	result, err := s.store.Update(ctx, "two_factor_recovery_codes",
+		Where(WithUserID(userID)).Eq("code_hash", hashToken(normalized)).Eq("used_at", nil),
+		map[string]interface{}{"used_at": s.now().UTC()})
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	var rows []json.RawMessage
+	if err := json.Unmarshal(result, &rows); err != nil {
+		return false, fmt.Errorf("failed to parse two_factor_recovery_codes data: %w", err)
+	}
+	return len(rows) > 0, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and issues RecoveryCodeCount new ones
+func (s *TwoFactorService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		code := strings.ToLower(totpEncoding.EncodeToString(raw)) // 12 characters
+		codes[i] = code[:6] + "-" + code[6:]
+	}
+
+	err := s.WithTx(ctx, func(tx *BaseService) error {
+		if err := tx.DeleteRecordsWithFilter(ctx, "two_factor_recovery_codes", WithUserID(userID)); err != nil {
+			return err
+		}
+		for _, code := range codes {
+			if err := tx.InsertRecord(ctx, "two_factor_recovery_codes", map[string]interface{}{
+				"id":        uuid.New().String(),
+				"user_id":   userID.String(),
+				"code_hash": hashToken(normalizeRecoveryCode(code)),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// normalizeRecoveryCode lower-cases a recovery code and drops separators
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hekigan/couples/internal/models"
+)
+
+// newTestTwoFactorService creates a two-factor service whose clock starts now
+func newTestTwoFactorService(t *testing.T) (*TwoFactorService, Store, *time.Time) {
+	t.Helper()
+
+	store := SetupTestStore(t)
+	service := NewTwoFactorService(store, "Couple Card Game")
+
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	return service, store, &now
+}
+
+// enrollTestUser enables two-factor authentication for a new user and returns its secret and
+// recovery codes
+func enrollTestUser(t *testing.T, service *TwoFactorService, store Store, username string, isAdmin bool) (*models.User, []byte, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	created := CreateTestUser(t, store, username, username, false)
+	user := &models.User{ID: created.ID, Username: username, IsAdmin: isAdmin}
+
+	enrollment, err := service.BeginEnrollment(ctx, user)
+	AssertNoError(t, err, "begin enrollment")
+	key, err := totpEncoding.DecodeString(enrollment.Secret)
+	AssertNoError(t, err, "decode secret")
+
+	codes, err := service.ConfirmEnrollment(ctx, user.ID, totpCode(key, totpStep(service.now())))
+	AssertNoError(t, err, "confirm enrollment")
+	return user, key, codes
+}
+
+// TestTwoFactorService_Enrollment tests that enrollment takes effect once confirmed
+func TestTwoFactorService_Enrollment(t *testing.T) {
+	service, store, now := newTestTwoFactorService(t)
+	ctx := context.Background()
+
+	created := CreateTestUser(t, store, "alice", "alice", false)
+	user := &models.User{ID: created.ID, Username: "alice"}
+
+	first, err := service.BeginEnrollment(ctx, user)
+	AssertNoError(t, err, "begin enrollment")
+	again, err := service.BeginEnrollment(ctx, user)
+	AssertNoError(t, err, "begin again")
+	AssertEqual(t, first.Secret, again.Secret, "pending secret kept")
+
+	enabled, err := service.IsEnabled(ctx, user.ID)
+	AssertNoError(t, err, "is enabled")
+	AssertTrue(t, !enabled, "not enabled before confirmation")
+
+	if _, err := service.ConfirmEnrollment(ctx, user.ID, "not-a-code"); !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+		t.Errorf("wrong code: error = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	key, _ := totpEncoding.DecodeString(first.Secret)
+	codes, err := service.ConfirmEnrollment(ctx, user.ID, totpCode(key, totpStep(*now)))
+	AssertNoError(t, err, "confirm")
+	AssertEqual(t, RecoveryCodeCount, len(codes), "recovery codes")
+
+	enabled, _ = service.IsEnabled(ctx, user.ID)
+	AssertTrue(t, enabled, "enabled after confirmation")
+	if _, err := service.BeginEnrollment(ctx, user); !errors.Is(err, models.ErrTwoFactorEnabled) {
+		t.Errorf("enrolling twice: error = %v, want ErrTwoFactorEnabled", err)
+	}
+
+	var stored []models.RecoveryCode
+	AssertNoError(t, service.QueryRecords(ctx, "two_factor_recovery_codes", Where(WithUserID(user.ID)), &stored), "stored codes")
+	AssertEqual(t, RecoveryCodeCount, len(stored), "stored codes")
+	found := false
+	for _, code := range stored {
+		found = found || code.CodeHash == hashToken(normalizeRecoveryCode(codes[0]))
+	}
+	AssertTrue(t, found, "recovery codes are stored hashed")
+}
+
+// TestTwoFactorService_CodesAreSingleUse tests that TOTP and recovery codes cannot be replayed
+func TestTwoFactorService_CodesAreSingleUse(t *testing.T) {
+	service, store, now := newTestTwoFactorService(t)
+	ctx := context.Background()
+	user, key, codes := enrollTestUser(t, service, store, "bob", false)
+
+	// The confirmation code's step is used up
+	if err := service.Verify(ctx, user.ID, totpCode(key, totpStep(*now))); !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+		t.Errorf("replayed code: error = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	*now = now.Add(totpPeriod * time.Second)
+	AssertNoError(t, service.Verify(ctx, user.ID, totpCode(key, totpStep(*now))), "next code")
+
+	AssertNoError(t, service.Verify(ctx, user.ID, " "+codes[3]+" "), "recovery code")
+	if err := service.Verify(ctx, user.ID, codes[3]); !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+		t.Errorf("reused recovery code: error = %v, want ErrInvalidTwoFactorCode", err)
+	}
+
+	remaining, err := service.RemainingRecoveryCodes(ctx, user.ID)
+	AssertNoError(t, err, "remaining")
+	AssertEqual(t, RecoveryCodeCount-1, remaining, "remaining codes")
+
+	if err := service.Verify(ctx, uuid.New(), "123456"); !errors.Is(err, models.ErrTwoFactorNotEnabled) {
+		t.Errorf("user without two-factor: error = %v, want ErrTwoFactorNotEnabled", err)
+	}
+}
+
+// TestTwoFactorService_Lockout tests that repeated wrong codes lock the second factor for a while
+func TestTwoFactorService_Lockout(t *testing.T) {
+	service, store, now := newTestTwoFactorService(t)
+	ctx := context.Background()
+	user, key, codes := enrollTestUser(t, service, store, "carol", false)
+
+	for i := 1; i < twoFactorMaxAttempts; i++ {
+		if err := service.Verify(ctx, user.ID, "not-a-code"); !errors.Is(err, models.ErrInvalidTwoFactorCode) {
+			t.Fatalf("wrong code %d: error = %v, want ErrInvalidTwoFactorCode", i, err)
+		}
+	}
+	if err := service.Verify(ctx, user.ID, "not-a-code"); !errors.Is(err, models.ErrTwoFactorLocked) {
+		t.Fatalf("last wrong code: error = %v, want ErrTwoFactorLocked", err)
+	}
+
+	*now = now.Add(totpPeriod * time.Second)
+	if err := service.Verify(ctx, user.ID, codes[0]); !errors.Is(err, models.ErrTwoFactorLocked) {
+		t.Errorf("valid code while locked: error = %v, want ErrTwoFactorLocked", err)
+	}
+
+	*now = now.Add(twoFactorLockDuration)
+	AssertNoError(t, service.Verify(ctx, user.ID, totpCode(key, totpStep(*now))), "code after the lock")
+}
+
+// TestTwoFactorService_ConcurrentLockout tests that wrong codes sent in parallel are all counted
+func TestTwoFactorService_ConcurrentLockout(t *testing.T) {
+	service, store, _ := newTestTwoFactorService(t)
+	ctx := context.Background()
+	user, _, _ := enrollTestUser(t, service, store, "dave", false)
+
+	guesses := 2 * twoFactorMaxAttempts
+	results := make(chan error, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results <- service.Verify(ctx, user.ID, "not-a-code")
+		}()
+	}
+	wg.Wait()
+	close(results)
+
+	invalid := 0
+	for err := range results {
+		switch {
+		case errors.Is(err, models.ErrInvalidTwoFactorCode):
+			invalid++
+		case !errors.Is(err, models.ErrTwoFactorLocked):
+			t.Fatalf("parallel wrong code: error = %v", err)
+		}
+	}
+	AssertEqual(t, twoFactorMaxAttempts-1, invalid, "wrong codes answered before the lock")
+
+	if err := service.Verify(ctx, user.ID, "not-a-code"); !errors.Is(err, models.ErrTwoFactorLocked) {
+		t.Errorf("wrong code after the parallel guesses: error = %v, want ErrTwoFactorLocked", err)
+	}
+}
+
+// TestTwoFactorService_Disable tests that users can turn two-factor off but admins cannot
+func TestTwoFactorService_Disable(t *testing.T) {
+	service, store, _ := newTestTwoFactorService(t)
+	ctx := context.Background()
+
+	admin, _, adminCodes := enrollTestUser(t, service, store, "admin", true)
+	if err := service.Disable(ctx, admin, adminCodes[0]); !errors.Is(err, models.ErrTwoFactorRequired) {
+		t.Errorf("admin disable: error = %v, want ErrTwoFactorRequired", err)
+	}
+
+	user, _, codes := enrollTestUser(t, service, store, "dave", false)
+	AssertNoError(t, service.Disable(ctx, user, codes[0]), "disable")
+
+	enabled, err := service.IsEnabled(ctx, user.ID)
+	AssertNoError(t, err, "is enabled")
+	AssertTrue(t, !enabled, "disabled")
+	remaining, _ := service.RemainingRecoveryCodes(ctx, user.ID)
+	AssertEqual(t, 0, remaining, "recovery codes deleted")
+}
@@ -3,29 +3,137 @@ package services
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
-	"github.com/supabase-community/supabase-go"
 	"github.com/hekigan/couples/internal/models"
 )
 
+// DefaultAnonymousUserTTLHours is how long an anonymous user is kept after their last visit
+const DefaultAnonymousUserTTLHours = 24
+
+// lastSeenInterval is the least time between two last_seen_at writes for one user
+const lastSeenInterval = 5 * time.Minute
+
+// languageCacheTTL is how long the i18n middleware trusts a cached UI language; a change made
+// on another instance applies within it
+const languageCacheTTL = time.Minute
+
+// userPageSize is how many rows the anonymous user cleanups read at a time
+const userPageSize = 1000
+
 // UserService handles user-related operations
 type UserService struct {
 	*BaseService
-	client *supabase.Client
+
+	// lastSeen remembers the last last_seen_at write per user, to throttle them
+	seenMu   sync.Mutex
+	lastSeen map[uuid.UUID]time.Time
+
+	// uiLanguages caches language_preference per user for LanguagePreference
+	languageMu  sync.Mutex
+	uiLanguages map[uuid.UUID]cachedLanguage
+}
+
+// cachedLanguage is a user's UI language ("" for none) and when it was read
+type cachedLanguage struct {
+	code   string
+	readAt time.Time
 }
 
 // NewUserService creates a new user service
-func NewUserService(client *supabase.Client) *UserService {
+func NewUserService(store Store) *UserService {
 	return &UserService{
-		BaseService: NewBaseService(client, "UserService"),
-		client:      client,
+		BaseService: NewBaseService(store, "UserService"),
+		lastSeen:    make(map[uuid.UUID]time.Time),
+		uiLanguages: make(map[uuid.UUID]cachedLanguage),
+	}
+}
+
+// LanguagePreference returns a user's saved UI language, or "" when they have none
+// Reads are cached for languageCacheTTL; a failed read is only logged.
+func (s *UserService) LanguagePreference(ctx context.Context, userID uuid.UUID) string {
+	now := time.Now()
+
+	s.languageMu.Lock()
+	cached, ok := s.uiLanguages[userID]
+	s.languageMu.Unlock()
+	if ok && now.Sub(cached.readAt) < languageCacheTTL {
+		return cached.code
+	}
+
+	var users []models.User
+	if err := s.QueryRecords(ctx, "users", Where().Eq("id", userID.String()).Select("language_preference"), &users); err != nil {
+		s.logger.Warn("Failed to read the language of user_id=%s: %v", userID.String(), err)
+		return cached.code
+	}
+	code := ""
+	if len(users) > 0 {
+		code = users[0].LanguagePreference
 	}
+	s.cacheLanguage(userID, code, now)
+	return code
 }
 
-// GetSupabaseClient returns the underlying Supabase client
-func (s *UserService) GetSupabaseClient() *supabase.Client {
-	return s.client
+// UpdateLanguagePreferences saves a user's UI and question languages; "" clears one, so the
+// language detected from the request applies
+func (s *UserService) UpdateLanguagePreferences(ctx context.Context, userID uuid.UUID, uiLanguage, questionLanguage string) error {
+	data := map[string]interface{}{"language_preference": nil, "question_language": nil}
+	if uiLanguage != "" {
+		data["language_preference"] = uiLanguage
+	}
+	if questionLanguage != "" {
+		data["question_language"] = questionLanguage
+	}
+	if err := s.UpdateRecord(ctx, "users", userID, data); err != nil {
+		return err
+	}
+
+	s.cacheLanguage(userID, uiLanguage, time.Now())
+	return nil
+}
+
+// cacheLanguage remembers a user's UI language for LanguagePreference
+func (s *UserService) cacheLanguage(userID uuid.UUID, code string, readAt time.Time) {
+	s.languageMu.Lock()
+	defer s.languageMu.Unlock()
+
+	s.uiLanguages[userID] = cachedLanguage{code: code, readAt: readAt}
+	// Forget stale entries so the map stays small
+	if len(s.uiLanguages) > 10000 {
+		for id, cached := range s.uiLanguages {
+			if readAt.Sub(cached.readAt) >= languageCacheTTL {
+				delete(s.uiLanguages, id)
+			}
+		}
+	}
+}
+
+// TouchLastSeen records that a user is active now
+// Writes are throttled to one per lastSeenInterval per user; a failed write is only logged.
+func (s *UserService) TouchLastSeen(ctx context.Context, userID uuid.UUID) {
+	now := time.Now()
+
+	s.seenMu.Lock()
+	if last, ok := s.lastSeen[userID]; ok && now.Sub(last) < lastSeenInterval {
+		s.seenMu.Unlock()
+		return
+	}
+	s.lastSeen[userID] = now
+	// Forget users not seen for a while so the map stays small
+	if len(s.lastSeen) > 10000 {
+		for id, last := range s.lastSeen {
+			if now.Sub(last) >= lastSeenInterval {
+				delete(s.lastSeen, id)
+			}
+		}
+	}
+	s.seenMu.Unlock()
+
+	if err := s.UpdateRecord(ctx, "users", userID, map[string]interface{}{"last_seen_at": now}); err != nil {
+		s.logger.Warn("Failed to record last seen user_id=%s: %v", userID.String(), err)
+	}
 }
 
 // CreateAnonymousUser creates a new anonymous user
@@ -88,114 +196,329 @@ func (s *UserService) UpdateUser(ctx context.Context, user *models.User) error {
 func (s *UserService) DeleteUser(ctx context.Context, userID uuid.UUID) error {
 	s.logger.Debug("Starting cascade delete for user_id=%s", userID.String())
 
-	// 1. Delete user's rooms (as owner)
-	s.logger.Debug("Deleting rooms where user is owner...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "rooms", map[string]interface{}{
-		"owner_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete rooms: %v", err)
-	}
+	// All steps run in one transaction: if any of them fails nothing is deleted,
+	// instead of leaving a half-deleted user behind
+	err := s.WithTx(ctx, func(tx *BaseService) error {
+		// 1. Delete user's rooms (as owner)
+		s.logger.Debug("Deleting rooms where user is owner...")
+		if err := tx.DeleteRecordsWithFilter(ctx, "rooms", map[string]interface{}{
+			"owner_id": userID.String(),
+		}); err != nil {
+			return fmt.Errorf("failed to delete rooms: %w", err)
+		}
+
+		// 2. Remove user as guest: a ready room goes back to waiting, a game in progress
+		// is abandoned and a closed room just loses its guest
+		s.logger.Debug("Removing user as guest from rooms...")
+		var guestRooms []models.Room
+		if err := tx.QueryRecords(ctx, "rooms", Where().Eq("guest_id", userID.String()), &guestRooms); err != nil {
+			return fmt.Errorf("failed to fetch guest rooms: %w", err)
+		}
+		for _, room := range guestRooms {
+			clearGuest := map[string]interface{}{"guest_id": nil, "guest_ready": false}
+			var err error
+			switch {
+			case room.Status.Can(models.RoomEventGuestLeft):
+				_, err = transitionRoom(ctx, tx, room.ID, models.RoomEventGuestLeft, clearGuest)
+			case room.Status.IsLive():
+				_, err = transitionRoom(ctx, tx, room.ID, models.RoomEventAbandon, clearGuest)
+			default:
+				_, err = tx.store.Update(ctx, "rooms", Where().Eq("id", room.ID.String()), clearGuest)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update rooms: %w", err)
+			}
+		}
+
+		// 3-9. Delete everything else that references the user
+		dependents := []struct {
+			table  string
+			column string
+		}{
+			{"answers", "user_id"},
+			{"player_question_history", "user_id"},
+			{"player_question_history", "partner_id"},
+			{"room_join_requests", "user_id"},
+			{"friends", "user_id"},
+			{"friends", "friend_id"},
+			{"room_invitations", "inviter_id"},
+			{"room_invitations", "invitee_id"},
+			{"notifications", "user_id"},
+		}
+		for _, dependent := range dependents {
+			s.logger.Debug("Deleting %s by %s...", dependent.table, dependent.column)
+			if err := tx.DeleteRecordsWithFilter(ctx, dependent.table, map[string]interface{}{
+				dependent.column: userID.String(),
+			}); err != nil {
+				return fmt.Errorf("failed to delete %s (%s): %w", dependent.table, dependent.column, err)
+			}
+		}
 
-	// 2. Update rooms where user is guest (set guest_id to NULL)
-	s.logger.Debug("Removing user as guest from rooms...")
-	if err := s.BaseService.UpdateRecordsWithFilter(ctx, "rooms",
-		map[string]interface{}{"guest_id": userID.String()},
-		map[string]interface{}{"guest_id": nil, "status": "waiting"},
-	); err != nil {
-		s.logger.Warn("Failed to update rooms: %v", err)
+		// 10. Finally, delete the user
+		s.logger.Debug("Deleting user record...")
+		if err := tx.DeleteRecord(ctx, "users", userID); err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("User deletion rolled back user_id=%s: %v", userID.String(), err)
+		return err
 	}
 
-	// 3. Delete user's answers
-	s.logger.Debug("Deleting user's answers...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "answers", map[string]interface{}{
-		"user_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete answers: %v", err)
+	s.logger.Success("User successfully deleted with all related data user_id=%s", userID.String())
+	return nil
+}
+
+// userReference is a column pointing at users, moved when accounts are merged
+type userReference struct {
+	table  string
+	column string
+	// unique lists the other columns of a UNIQUE constraint including column: a row that
+	// would duplicate one the target user already has is dropped instead
+	unique []string
+	// other is a second user column: a row that would point at the target user twice
+	// (befriending or inviting oneself) is dropped instead
+	other string
+	// clear is the nullable column emptied, instead of dropping the row, when it would point at
+	// the target user twice: a room the account played against its own guest keeps its answers
+	clear string
+}
+
+// userReferences lists every column referencing users.id
+var userReferences = []userReference{
+	{table: "rooms", column: "owner_id", other: "guest_id", clear: "guest_id"},
+	{table: "rooms", column: "guest_id", other: "owner_id", clear: "guest_id"},
+	{table: "rooms", column: "current_player_id"},
+	{table: "rooms", column: "disconnected_user"},
+	{table: "room_join_requests", column: "user_id", unique: []string{"room_id"}},
+	{table: "room_invitations", column: "inviter_id", other: "invitee_id"},
+	{table: "room_invitations", column: "invitee_id", unique: []string{"room_id"}, other: "inviter_id"},
+	{table: "friends", column: "user_id", unique: []string{"friend_id"}, other: "friend_id"},
+	{table: "friends", column: "friend_id", unique: []string{"user_id"}, other: "user_id"},
+	{table: "answers", column: "user_id"},
+	{table: "notifications", column: "user_id"},
+	{table: "player_question_history", column: "user_id", unique: []string{"partner_id", "question_id"}, other: "partner_id"},
+	{table: "player_question_history", column: "partner_id", unique: []string{"user_id", "question_id"}, other: "user_id"},
+	{table: "room_events", column: "user_id"},
+}
+
+// MergeAnonymousUser moves everything an anonymous user owns (rooms, answers, friends,
+// question history, notifications...) to a registered user, then deletes the anonymous user
+// Rows that would duplicate the target's own are dropped; rooms where the target played the
+// guest lose their guest, other rows relating the target to itself are dropped.
+// Everything runs in one transaction. Without one (SupabaseStore) every step can be run
+// again: the guest is marked with merged_into first, and ResumeUserMerges finishes a merge
+// that failed halfway.
+func (s *UserService) MergeAnonymousUser(ctx context.Context, anonymousID, userID uuid.UUID) error {
+	if anonymousID == userID {
+		return nil
 	}
 
-	// 4. Delete user's join requests
-	s.logger.Debug("Deleting user's join requests...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "room_join_requests", map[string]interface{}{
-		"user_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete join requests: %v", err)
+	anonymous, err := s.GetUserByID(ctx, anonymousID)
+	if err != nil {
+		return err
+	}
+	if !anonymous.IsAnonymous {
+		return fmt.Errorf("user %s: %w", anonymousID, models.ErrNotAnonymous)
+	}
+	if anonymous.MergedInto != nil && *anonymous.MergedInto != userID {
+		return fmt.Errorf("user %s into %s: %w", anonymousID, *anonymous.MergedInto, models.ErrMergePending)
+	}
+	if _, err := s.GetUserByID(ctx, userID); err != nil {
+		return err
 	}
 
-	// 5. Delete friendships (both as user_id and friend_id)
-	s.logger.Debug("Deleting friendships...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "friends", map[string]interface{}{
-		"user_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete friends (as user): %v", err)
+	s.logger.Debug("Merging anonymous user_id=%s into user_id=%s", anonymousID.String(), userID.String())
+
+	if anonymous.MergedInto == nil {
+		if err := s.UpdateRecord(ctx, "users", anonymousID, map[string]interface{}{"merged_into": userID.String()}); err != nil {
+			return fmt.Errorf("failed to start merge: %w", err)
+		}
 	}
 
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "friends", map[string]interface{}{
-		"friend_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete friends (as friend): %v", err)
+	err = s.WithTx(ctx, func(tx *BaseService) error {
+		for _, ref := range userReferences {
+			if err := moveUserReference(ctx, tx, ref, anonymousID, userID); err != nil {
+				return fmt.Errorf("failed to move %s (%s): %w", ref.table, ref.column, err)
+			}
+		}
+		if err := tx.DeleteRecord(ctx, "users", anonymousID); err != nil {
+			return fmt.Errorf("failed to delete anonymous user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Merge rolled back anonymous user_id=%s: %v", anonymousID.String(), err)
+		return err
 	}
 
-	// 6. Delete room invitations
-	s.logger.Debug("Deleting room invitations...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "room_invitations", map[string]interface{}{
-		"inviter_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete invitations (as inviter): %v", err)
+	s.logger.Success("Merged anonymous user_id=%s into user_id=%s", anonymousID.String(), userID.String())
+	return nil
+}
+
+// moveUserReference points ref's rows from one user to another
+func moveUserReference(ctx context.Context, tx *BaseService, ref userReference, fromID, toID uuid.UUID) error {
+	from, to := fromID.String(), toID.String()
+
+	if ref.other == "" && len(ref.unique) == 0 {
+		_, err := tx.store.Update(ctx, ref.table, Where().Eq(ref.column, from), map[string]interface{}{ref.column: to})
+		return err
 	}
 
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "room_invitations", map[string]interface{}{
-		"invitee_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete invitations (as invitee): %v", err)
+	var rows []map[string]interface{}
+	if err := tx.QueryRecords(ctx, ref.table, Where().Eq(ref.column, from), &rows); err != nil {
+		return err
 	}
+	for _, row := range rows {
+		byID := Where().Eq("id", row["id"])
+
+		data := map[string]interface{}{ref.column: to}
+		self := ref.other != "" && row[ref.other] == to
+		if self && ref.clear != "" {
+			data[ref.clear] = nil
+			self = false
+		}
+
+		drop := self
+		if !drop && len(ref.unique) > 0 {
+			q := Where().Eq(ref.column, to)
+			for _, column := range ref.unique {
+				q.Eq(column, row[column])
+			}
+			count, err := tx.store.Count(ctx, ref.table, q)
+			if err != nil {
+				return err
+			}
+			drop = count > 0
+		}
 
-	// 7. Delete notifications
-	s.logger.Debug("Deleting notifications...")
-	if err := s.BaseService.DeleteRecordsWithFilter(ctx, "notifications", map[string]interface{}{
-		"user_id": userID.String(),
-	}); err != nil {
-		s.logger.Warn("Failed to delete notifications: %v", err)
+		if drop {
+			if err := tx.store.Delete(ctx, ref.table, byID); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := tx.store.Update(ctx, ref.table, byID, data); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	// 8. Finally, delete the user
-	s.logger.Debug("Deleting user record...")
-	if err := s.BaseService.DeleteRecord(ctx, "users", userID); err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+// ResumeUserMerges finishes the merges that failed halfway (see MergeAnonymousUser)
+// It returns how many were completed.
+func (s *UserService) ResumeUserMerges(ctx context.Context) (int, error) {
+	var guests []models.User
+	if err := s.QueryRecords(ctx, "users", Where().Eq("is_anonymous", true).Select("id,merged_into"), &guests); err != nil {
+		return 0, err
 	}
 
-	s.logger.Success("User successfully deleted with all related data user_id=%s", userID.String())
-	return nil
+	resumed := 0
+	for _, guest := range guests {
+		if guest.MergedInto == nil {
+			continue
+		}
+		if err := s.MergeAnonymousUser(ctx, guest.ID, *guest.MergedInto); err != nil {
+			s.logger.Warn("Failed to resume merge of user_id=%s: %v", guest.ID.String(), err)
+			continue
+		}
+		resumed++
+	}
+	return resumed, nil
 }
 
-// CleanupExpiredAnonymousUsers deletes anonymous users older than the specified duration
-func (s *UserService) CleanupExpiredAnonymousUsers(ctx context.Context, olderThanHours int) (int, error) {
-	s.logger.Debug("Starting cleanup of anonymous users older_than_hours=%d", olderThanHours)
+// FindExpiredAnonymousUsers lists the anonymous users CleanupExpiredAnonymousUsers would delete:
+// not seen for olderThanHours (or created that long ago and never seen) and not in an open room
+func (s *UserService) FindExpiredAnonymousUsers(ctx context.Context, olderThanHours int) ([]models.User, error) {
+	users, err := s.inactiveAnonymousUsers(ctx, time.Now().Add(-time.Duration(olderThanHours)*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	inOpenRoom, err := s.openRoomPlayers(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	// Query for expired anonymous users
-	filters := map[string]interface{}{
-		"is_anonymous": "true",
+	var expired []models.User
+	for i := range users {
+		if !inOpenRoom[users[i].ID] {
+			expired = append(expired, users[i])
+		}
+	}
+	return expired, nil
+}
+
+// inactiveAnonymousUsers returns the anonymous users last active before cutoff
+// A guest half-merged into an account is ResumeUserMerges' to finish, so it is left out. The
+// rows are read userPageSize at a time, since PostgREST caps how many one request returns.
+func (s *UserService) inactiveAnonymousUsers(ctx context.Context, cutoff time.Time) ([]models.User, error) {
+	// Never seen, or last seen before the cutoff (see models.User.LastActiveAt)
+	queries := []*Query{
+		NewQuery().Eq("last_seen_at", nil),
+		NewQuery().Lt("last_seen_at", cutoff),
 	}
 
 	var users []models.User
-	if err := s.BaseService.GetRecords(ctx, "users", filters, &users); err != nil {
-		return 0, err
+	for _, q := range queries {
+		q = q.Eq("is_anonymous", true).Eq("merged_into", nil).Lt("created_at", cutoff).OrderBy("id", true)
+		for offset := 0; ; offset += userPageSize {
+			var page []models.User
+			if err := s.QueryRecords(ctx, "users", q.Page(userPageSize, offset), &page); err != nil {
+				return nil, err
+			}
+			users = append(users, page...)
+			if len(page) < userPageSize {
+				break
+			}
+		}
 	}
+	return users, nil
+}
 
-	// Filter users by age manually (since Supabase Go client doesn't support lt() for dates easily)
-	// TODO: Implement proper time-based filtering using olderThanHours parameter
-	// For now, this returns all anonymous users for manual cleanup
-	var expiredUsers []uuid.UUID
-	for _, user := range users {
-		// Simple check: if created_at is not recent, consider it expired
-		// In a real implementation, you'd parse created_at and compare with current time
-		expiredUsers = append(expiredUsers, user.ID)
+// openRoomPlayers returns the players of rooms still open (waiting to mid-game), which are
+// kept whatever their age
+func (s *UserService) openRoomPlayers(ctx context.Context) (map[uuid.UUID]bool, error) {
+	var openStates []string
+	for _, name := range models.RoomStateNames() {
+		if !models.RoomState(name).IsTerminal() {
+			openStates = append(openStates, name)
+		}
+	}
+
+	q := Where().In("status", openStates).Select("id,owner_id,guest_id").OrderBy("id", true)
+	players := make(map[uuid.UUID]bool)
+	for offset := 0; ; offset += userPageSize {
+		var rooms []models.Room
+		if err := s.QueryRecords(ctx, "rooms", q.Page(userPageSize, offset), &rooms); err != nil {
+			return nil, err
+		}
+		for _, room := range rooms {
+			players[room.OwnerID] = true
+			if room.GuestID != nil {
+				players[*room.GuestID] = true
+			}
+		}
+		if len(rooms) < userPageSize {
+			return players, nil
+		}
+	}
+}
+
+// CleanupExpiredAnonymousUsers deletes the anonymous users found by FindExpiredAnonymousUsers
+func (s *UserService) CleanupExpiredAnonymousUsers(ctx context.Context, olderThanHours int) (int, error) {
+	s.logger.Debug("Starting cleanup of anonymous users older_than_hours=%d", olderThanHours)
+
+	expiredUsers, err := s.FindExpiredAnonymousUsers(ctx, olderThanHours)
+	if err != nil {
+		return 0, err
 	}
 
 	// Delete each expired user
 	deletedCount := 0
-	for _, userID := range expiredUsers {
-		if err := s.DeleteUser(ctx, userID); err != nil {
-			s.logger.Warn("Failed to delete expired user user_id=%s: %v", userID.String(), err)
+	for _, user := range expiredUsers {
+		if err := s.DeleteUser(ctx, user.ID); err != nil {
+			s.logger.Warn("Failed to delete expired user user_id=%s: %v", user.ID.String(), err)
 			continue
 		}
 		deletedCount++
@@ -214,37 +537,26 @@ func (s *UserService) GetAnonymousUserCount(ctx context.Context) (int, error) {
 	return s.BaseService.CountRecords(ctx, "users", filters)
 }
 
-// CleanupInactiveAnonymousUsers deletes anonymous users who have no active rooms or recent activity
-func (s *UserService) CleanupInactiveAnonymousUsers(ctx context.Context) (int, error) {
-	s.logger.Debug("Starting cleanup of inactive anonymous users")
+// CleanupInactiveAnonymousUsers deletes anonymous users not seen for the specified duration who
+// have no rooms at all (users seen more recently may be about to create or join one)
+func (s *UserService) CleanupInactiveAnonymousUsers(ctx context.Context, olderThanHours int) (int, error) {
+	s.logger.Debug("Starting cleanup of inactive anonymous users older_than_hours=%d", olderThanHours)
 
-	// Get all anonymous users
-	filters := map[string]interface{}{
-		"is_anonymous": "true",
-	}
-
-	var users []models.User
-	if err := s.BaseService.GetRecords(ctx, "users", filters, &users); err != nil {
+	cutoff := time.Now().Add(-time.Duration(olderThanHours) * time.Hour)
+	users, err := s.inactiveAnonymousUsers(ctx, cutoff)
+	if err != nil {
 		return 0, err
 	}
 
 	deletedCount := 0
 	for _, user := range users {
-		// Check if user has any active rooms
-		roomData, _, err := s.client.From("rooms").
-			Select("id", "exact", false).
-			Eq("owner_id", user.ID.String()).
-			Execute()
-
-		hasRooms := err == nil && len(roomData) > 0
+		// Check if user has any active rooms (a failed count keeps the user)
+		ownedRooms, err := s.BaseService.CountRecords(ctx, "rooms", WithOwnerID(user.ID))
+		hasRooms := err != nil || ownedRooms > 0
 
 		// Check if user is a guest in any room
-		guestData, _, err := s.client.From("rooms").
-			Select("id", "exact", false).
-			Eq("guest_id", user.ID.String()).
-			Execute()
-
-		isGuest := err == nil && len(guestData) > 0
+		guestRooms, err := s.BaseService.CountRecords(ctx, "rooms", map[string]interface{}{"guest_id": user.ID.String()})
+		isGuest := err != nil || guestRooms > 0
 
 		// If user has no active involvement, delete them
 		if !hasRooms && !isGuest {
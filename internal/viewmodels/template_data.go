@@ -1,5 +1,7 @@
 package viewmodels
 
+import "github.com/hekigan/couples/internal/models"
+
 // TemplateData represents common data passed to page templates
 type TemplateData struct {
 	Title             string
@@ -48,3 +50,34 @@ type AnswerSubmittedData struct {
 	IsMyTurn              bool   // Is it now my turn to draw next question?
 	CurrentPlayerUsername string
 }
+
+// TwoFactorData represents data for the two-factor settings page
+type TwoFactorData struct {
+	Enabled        bool     // Enrollment confirmed
+	Verified       bool     // This session completed the second factor
+	Required       bool     // Admins cannot turn it off
+	Secret         string   // Pending enrollment: base32 secret for manual entry
+	URI            string   // Pending enrollment: otpauth:// URI
+	RecoveryCodes  []string // Freshly issued codes, shown once
+	RemainingCodes int      // Unused recovery codes
+}
+
+// APITokensData represents data for the API tokens page
+type APITokensData struct {
+	Tokens      []models.APIToken
+	NewToken    string   // Freshly created token, shown once
+	Scopes      []string // Scopes the user may grant
+	AdminLocked bool     // An admin whose session has not completed the second factor
+}
+
+// CreateRoomData represents data for the create room page
+type CreateRoomData struct {
+	Languages        []models.Language // Enabled languages, for the question language picker
+	SelectedLanguage string
+}
+
+// ProfileData represents data for the profile page
+type ProfileData struct {
+	User      *models.User
+	Languages []models.Language // Enabled languages, for the language preferences
+}
@@ -243,20 +243,43 @@ func RoomDetails(data *services.RoomDetailsData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" hx-target=\"#rooms-list\" hx-swap=\"outerHTML\" hx-confirm=\"Are you sure you want to force close this room?\" hx-indicator=\"#rooms-list-loading\" data-close-modal=\"view-modal\" class=\"btn btn-danger\">Force Close Room</button> <button hx-delete=\"")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\" hx-target=\"#rooms-list\" hx-swap=\"outerHTML\" hx-confirm=\"Are you sure you want to force close this room?\" hx-indicator=\"#rooms-list-loading\" data-close-modal=\"view-modal\" class=\"btn btn-danger\">Force Close Room</button> ")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		var templ_7745c5c3_Var14 string
-		templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("/admin/api/v1/rooms/%s", data.ID))
+		if data.GuestUsername != "" {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<button hx-post=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var14 string
+			templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("/admin/api/v1/rooms/%s/reset-question-history", data.ID))
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/fragments/admin/room_details.templ`, Line: 84, Col: 84}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "\" hx-target=\"#rooms-list\" hx-swap=\"outerHTML\" hx-confirm=\"Reset the questions these two players have seen together? They may be drawn again in future games.\" hx-indicator=\"#rooms-list-loading\" data-close-modal=\"view-modal\" class=\"btn\">Reset Question History</button> ")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<button hx-delete=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var15 string
+		templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(fmt.Sprintf("/admin/api/v1/rooms/%s", data.ID))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/fragments/admin/room_details.templ`, Line: 83, Col: 62}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/fragments/admin/room_details.templ`, Line: 96, Col: 62}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "\" hx-target=\"#rooms-list\" hx-swap=\"outerHTML\" hx-confirm=\"Are you sure you want to DELETE this room? This action cannot be undone!\" hx-indicator=\"#rooms-list-loading\" data-close-modal=\"view-modal\" class=\"btn btn-danger\">Delete Room</button></div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "\" hx-target=\"#rooms-list\" hx-swap=\"outerHTML\" hx-confirm=\"Are you sure you want to DELETE this room? This action cannot be undone!\" hx-indicator=\"#rooms-list-loading\" data-close-modal=\"view-modal\" class=\"btn btn-danger\">Delete Room</button></div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -285,12 +308,12 @@ func RoomDetailsStyles() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var15 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var15 == nil {
-			templ_7745c5c3_Var15 = templ.NopComponent
+		templ_7745c5c3_Var16 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var16 == nil {
+			templ_7745c5c3_Var16 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<style>\n\t.detail-section {\n\t\tmargin-bottom: 1.5rem;\n\t\tpadding: 1rem;\n\t\tbackground: var(--pico-card-sectioning-background-color);\n\t\tborder-radius: var(--pico-border-radius);\n\t}\n\n\t.detail-section h4 {\n\t\tmargin-top: 0;\n\t\tmargin-bottom: 1rem;\n\t\tborder-bottom: 1px solid var(--pico-muted-border-color);\n\t\tpadding-bottom: 0.5rem;\n\t}\n\n\t.detail-section dl {\n\t\tdisplay: grid;\n\t\tgrid-template-columns: 140px 1fr;\n\t\tgap: 0.5rem;\n\t\tmargin: 0;\n\t}\n\n\t.detail-section dt {\n\t\tfont-weight: 600;\n\t\tcolor: var(--pico-primary);\n\t}\n\n\t.detail-section dd {\n\t\tmargin: 0;\n\t}\n\n\t.detail-section code {\n\t\tfont-size: 0.875rem;\n\t}\n\n\t.category-list {\n\t\tmargin: 0;\n\t\tpadding-left: 1.25rem;\n\t\tlist-style-type: disc;\n\t}\n\n\t.category-list li {\n\t\tmargin-bottom: 0.25rem;\n\t}\n\n\t.detail-actions {\n\t\tmargin-top: 1.5rem;\n\t\tpadding-top: 1rem;\n\t\tborder-top: 1px solid var(--pico-muted-border-color);\n\t\ttext-align: right;\n\t}\n\n\t.detail-actions .btn {\n\t\tmargin-left: 0.5rem;\n\t}\n\t</style>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "<style>\n\t.detail-section {\n\t\tmargin-bottom: 1.5rem;\n\t\tpadding: 1rem;\n\t\tbackground: var(--pico-card-sectioning-background-color);\n\t\tborder-radius: var(--pico-border-radius);\n\t}\n\n\t.detail-section h4 {\n\t\tmargin-top: 0;\n\t\tmargin-bottom: 1rem;\n\t\tborder-bottom: 1px solid var(--pico-muted-border-color);\n\t\tpadding-bottom: 0.5rem;\n\t}\n\n\t.detail-section dl {\n\t\tdisplay: grid;\n\t\tgrid-template-columns: 140px 1fr;\n\t\tgap: 0.5rem;\n\t\tmargin: 0;\n\t}\n\n\t.detail-section dt {\n\t\tfont-weight: 600;\n\t\tcolor: var(--pico-primary);\n\t}\n\n\t.detail-section dd {\n\t\tmargin: 0;\n\t}\n\n\t.detail-section code {\n\t\tfont-size: 0.875rem;\n\t}\n\n\t.category-list {\n\t\tmargin: 0;\n\t\tpadding-left: 1.25rem;\n\t\tlist-style-type: disc;\n\t}\n\n\t.category-list li {\n\t\tmargin-bottom: 0.25rem;\n\t}\n\n\t.detail-actions {\n\t\tmargin-top: 1.5rem;\n\t\tpadding-top: 1rem;\n\t\tborder-top: 1px solid var(--pico-muted-border-color);\n\t\ttext-align: right;\n\t}\n\n\t.detail-actions .btn {\n\t\tmargin-left: 0.5rem;\n\t}\n\t</style>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -85,7 +85,7 @@ func HeadCommon(data *viewmodels.TemplateData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		} else {
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<!-- Development: Load individual files for easier debugging --> <script src=\"/static/js/htmx.min.js\"></script> <script src=\"/static/js/sse.js\"></script> <script src=\"/static/js/ui-utils.js\"></script> <script src=\"/static/js/notifications-realtime.js\" defer></script> <script src=\"/static/js/modal.js\" defer></script>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<!-- Development: Load individual files for easier debugging --> <script src=\"/static/js/htmx.min.js\"></script> <script src=\"/static/js/sse.js\"></script> <script src=\"/static/js/room-socket.js\"></script> <script src=\"/static/js/ui-utils.js\"></script> <script src=\"/static/js/notifications-realtime.js\" defer></script> <script src=\"/static/js/modal.js\" defer></script>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
@@ -67,7 +67,7 @@ func QuestionsContent(templateData *viewmodels.TemplateData) templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"admin-container\"><h1>Question Management</h1><div class=\"admin-actions-header\"><div class=\"admin-filters-bar\"><a href=\"/admin/api/questions/export-csv\" class=\"btn\">Export CSV</a> <a href=\"/admin/api/questions/template-csv\" class=\"btn\">Download Template</a></div><button data-target=\"create-modal\" onclick=\"toggleModal(event)\" class=\"btn-add\">Add Question</button></div><div class=\"admin-table\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"admin-container\"><h1>Question Management</h1><div class=\"admin-actions-header\"><div class=\"admin-filters-bar\"><a href=\"/admin/api/v1/csv/questions/export\" class=\"btn\">Export CSV</a> <a href=\"/admin/api/v1/csv/questions/template\" class=\"btn\">Download Template</a> <button data-target=\"import-modal\" onclick=\"toggleModal(event)\" class=\"secondary\">Import</button></div><button data-target=\"create-modal\" onclick=\"toggleModal(event)\" class=\"btn-add\">Add Question</button></div><div class=\"admin-table\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -82,7 +82,7 @@ func QuestionsContent(templateData *viewmodels.TemplateData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div></div><!-- Create Question Modal - Using PicoCSS dialog element --><dialog id=\"create-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"create-modal\" onclick=\"toggleModal(event)\"></button><h3>Create Question</h3></header><div id=\"create-modal-content\" class=\"modal-content\" hx-get=\"/admin/api/v1/questions/new\" hx-trigger=\"load once\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"create-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" class=\"success\" onclick=\"submitModalForm(event)\">Create</button></footer></article></dialog><!-- Edit Question Modal --><dialog id=\"edit-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\"></button><h3>Edit Question</h3></header><div id=\"edit-modal-content\" class=\"modal-content\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" class=\"success\" onclick=\"submitModalForm(event)\">Save</button></footer></article></dialog>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div></div><!-- Create Question Modal - Using PicoCSS dialog element --><dialog id=\"create-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"create-modal\" onclick=\"toggleModal(event)\"></button><h3>Create Question</h3></header><div id=\"create-modal-content\" class=\"modal-content\" hx-get=\"/admin/api/v1/questions/new\" hx-trigger=\"load once\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"create-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" class=\"success\" onclick=\"submitModalForm(event)\">Create</button></footer></article></dialog><!-- Import Questions Modal --><dialog id=\"import-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"import-modal\" onclick=\"toggleModal(event)\"></button><h3>Import Questions</h3></header><form id=\"import-form\"><p>Upload a CSV or JSON file with the columns of the export. Rows with an id update that question; rows without one are added. Translations point to their English question through base_question_id.</p><input type=\"file\" name=\"file\" accept=\".csv,.json\" required aria-label=\"Import file\"></form><div id=\"import-report\"></div><footer><button type=\"button\" class=\"secondary\" onclick=\"importQuestions(true)\">Preview</button> <button type=\"button\" id=\"import-commit\" class=\"success\" onclick=\"importQuestions(false)\" disabled>Import</button></footer></article></dialog><!-- Edit Question Modal --><dialog id=\"edit-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\"></button><h3>Edit Question</h3></header><div id=\"edit-modal-content\" class=\"modal-content\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" class=\"success\" onclick=\"submitModalForm(event)\">Save</button></footer></article></dialog>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
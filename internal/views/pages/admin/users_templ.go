@@ -82,7 +82,7 @@ func UsersContent(templateData *viewmodels.TemplateData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div></div><!-- Create User Modal - Using PicoCSS dialog element --><dialog id=\"create-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"create-modal\" onclick=\"toggleModal(event)\"></button><h3>Create User</h3></header><div id=\"create-modal-content\" class=\"modal-content\" hx-get=\"/admin/api/v1/users/new\" hx-trigger=\"load once\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"create-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" onclick=\"submitModalForm(event)\">Create</button></footer></article></dialog><!-- Edit User Modal --><dialog id=\"edit-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\"></button><h3>Edit User</h3></header><div id=\"edit-modal-content\" class=\"modal-content\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" onclick=\"submitModalForm(event)\">Save</button></footer></article></dialog>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 3, "</div></div><!-- Create User Modal - Using PicoCSS dialog element --><dialog id=\"create-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"create-modal\" onclick=\"toggleModal(event)\"></button><h3>Create User</h3></header><div id=\"create-modal-content\" class=\"modal-content\" hx-get=\"/admin/api/v1/users/new\" hx-trigger=\"load once\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"create-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" onclick=\"submitModalForm(event)\">Create</button></footer></article></dialog><!-- Anonymous User Cleanup Preview Modal --><dialog id=\"cleanup-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"cleanup-modal\" onclick=\"toggleModal(event)\"></button><h3>Guest Cleanup Preview</h3></header><div id=\"cleanup-modal-content\" class=\"modal-content\"><!-- Dry run loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"cleanup-modal\" onclick=\"toggleModal(event)\">Close</button></footer></article></dialog><!-- Edit User Modal --><dialog id=\"edit-modal\" class=\"modal-wide\"><article><header><button aria-label=\"Close\" rel=\"prev\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\"></button><h3>Edit User</h3></header><div id=\"edit-modal-content\" class=\"modal-content\"><!-- Form will be loaded here via HTMX --></div><footer><button type=\"button\" class=\"secondary\" data-target=\"edit-modal\" onclick=\"toggleModal(event)\">Cancel</button> <button type=\"button\" onclick=\"submitModalForm(event)\">Save</button></footer></article></dialog>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -88,7 +88,7 @@ func LoginContent(data *viewmodels.TemplateData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<input type=\"email\" name=\"email\" placeholder=\"Email\" required autocomplete=\"email\" aria-label=\"Email address\"> <input type=\"password\" name=\"password\" placeholder=\"Password\" required autocomplete=\"current-password\" aria-label=\"Password\"> <button type=\"submit\" class=\"secondary\"><span id=\"login-loading\" class=\"htmx-indicator\" style=\"display:none;\">⏳ </span> Login</button></form><p class=\"help-text\" style=\"text-align: center; margin-top: 1rem;\">Don't have an account? <a href=\"/signup\" style=\"color: var(--primary);\">Sign up</a></p></section></div></div></div><script>\n\t\tfunction handleLoginResponse(event) {\n\t\t\tconst xhr = event.detail.xhr;\n\n\t\t\tif (xhr.status === 200) {\n\t\t\t\t// Success - HTMX handles HX-Redirect automatically\n\t\t\t\tToast.success('Login successful! Redirecting...');\n\t\t\t} else {\n\t\t\t\t// Error - parse JSON and show toast\n\t\t\t\ttry {\n\t\t\t\t\tconst response = JSON.parse(xhr.responseText);\n\t\t\t\t\tToast.error(response.error || 'Login failed. Please try again.');\n\t\t\t\t} catch (e) {\n\t\t\t\t\tToast.error('Login failed. Please check your credentials.');\n\t\t\t\t}\n\n\t\t\t\t// Clear password field on error\n\t\t\t\tevent.target.querySelector('input[name=\"password\"]').value = '';\n\t\t\t}\n\t\t}\n\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<input type=\"email\" name=\"email\" placeholder=\"Email\" required autocomplete=\"email\" aria-label=\"Email address\"> <input type=\"password\" name=\"password\" placeholder=\"Password\" required autocomplete=\"current-password\" aria-label=\"Password\"> <button type=\"submit\" class=\"secondary\"><span id=\"login-loading\" class=\"htmx-indicator\" style=\"display:none;\">⏳ </span> Login</button></form><p class=\"help-text\" style=\"text-align: center; margin-top: 1rem;\"><a href=\"/forgot-password\" style=\"color: var(--primary);\">Forgot your password?</a></p><p class=\"help-text\" style=\"text-align: center; margin-top: 1rem;\">Don't have an account? <a href=\"/signup\" style=\"color: var(--primary);\">Sign up</a></p></section></div></div></div><script>\n\t\tfunction handleLoginResponse(event) {\n\t\t\tconst xhr = event.detail.xhr;\n\n\t\t\tif (xhr.status === 200) {\n\t\t\t\t// Success - HTMX handles HX-Redirect automatically\n\t\t\t\tToast.success('Login successful! Redirecting...');\n\t\t\t} else {\n\t\t\t\t// Error - parse JSON and show toast\n\t\t\t\ttry {\n\t\t\t\t\tconst response = JSON.parse(xhr.responseText);\n\t\t\t\t\tToast.error(response.error || 'Login failed. Please try again.');\n\t\t\t\t} catch (e) {\n\t\t\t\t\tToast.error('Login failed. Please check your credentials.');\n\t\t\t\t}\n\n\t\t\t\t// Clear password field on error\n\t\t\t\tevent.target.querySelector('input[name=\"password\"]').value = '';\n\t\t\t}\n\t\t}\n\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
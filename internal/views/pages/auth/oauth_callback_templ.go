@@ -65,7 +65,7 @@ func OAuthCallbackContent() templ.Component {
 			templ_7745c5c3_Var2 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"auth-callback-container\"><article class=\"auth-callback-card\"><header><h2>Completing Login...</h2></header><div class=\"loading-spinner\"><div class=\"spinner\"></div></div><p>Please wait while we complete your authentication.</p></article></div><script>\n\t\t(function() {\n\t\t\t// Extract tokens from URL fragment\n\t\t\tconst hash = window.location.hash.substring(1);\n\t\t\tconst params = new URLSearchParams(hash);\n\n\t\t\tconst accessToken = params.get('access_token');\n\t\t\tconst refreshToken = params.get('refresh_token');\n\t\t\tconst error = params.get('error');\n\t\t\tconst errorDescription = params.get('error_description');\n\n\t\t\tif (error) {\n\t\t\t\talert('Authentication error: ' + (errorDescription || error));\n\t\t\t\twindow.location.href = '/login';\n\t\t\t\treturn;\n\t\t\t}\n\n\t\t\tif (accessToken) {\n\t\t\t\t// Send tokens to server\n\t\t\t\tfetch('/auth/oauth/token', {\n\t\t\t\t\tmethod: 'POST',\n\t\t\t\t\theaders: {\n\t\t\t\t\t\t'Content-Type': 'application/json',\n\t\t\t\t\t},\n\t\t\t\t\tbody: JSON.stringify({\n\t\t\t\t\t\taccess_token: accessToken,\n\t\t\t\t\t\trefresh_token: refreshToken\n\t\t\t\t\t})\n\t\t\t\t})\n\t\t\t\t.then(response => {\n\t\t\t\t\tif (response.ok) {\n\t\t\t\t\t\twindow.location.href = '/';\n\t\t\t\t\t} else {\n\t\t\t\t\t\tthrow new Error('Failed to process authentication');\n\t\t\t\t\t}\n\t\t\t\t})\n\t\t\t\t.catch(error => {\n\t\t\t\t\tconsole.error('Error:', error);\n\t\t\t\t\talert('Failed to complete authentication. Please try again.');\n\t\t\t\t\twindow.location.href = '/login';\n\t\t\t\t});\n\t\t\t} else {\n\t\t\t\t// No token in fragment, might be PKCE flow with query params\n\t\t\t\t// Redirect to let server handle it\n\t\t\t\twindow.location.href = window.location.pathname + window.location.search;\n\t\t\t}\n\t\t})();\n\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"auth-callback-container\"><article class=\"auth-callback-card\"><header><h2>Completing Login...</h2></header><div class=\"loading-spinner\"><div class=\"spinner\"></div></div><p>Please wait while we complete your authentication.</p></article></div><script>\n\t\t(function() {\n\t\t\t// Extract tokens from URL fragment\n\t\t\tconst hash = window.location.hash.substring(1);\n\t\t\tconst params = new URLSearchParams(hash);\n\n\t\t\tconst accessToken = params.get('access_token');\n\t\t\tconst refreshToken = params.get('refresh_token');\n\t\t\tconst error = params.get('error');\n\t\t\tconst errorDescription = params.get('error_description');\n\n\t\t\tif (error) {\n\t\t\t\talert('Authentication error: ' + (errorDescription || error));\n\t\t\t\twindow.location.href = '/login';\n\t\t\t\treturn;\n\t\t\t}\n\n\t\t\tif (accessToken) {\n\t\t\t\t// Send tokens to server\n\t\t\t\tfetch('/auth/oauth/token', {\n\t\t\t\t\tmethod: 'POST',\n\t\t\t\t\theaders: {\n\t\t\t\t\t\t'Content-Type': 'application/json',\n\t\t\t\t\t},\n\t\t\t\t\tbody: JSON.stringify({\n\t\t\t\t\t\taccess_token: accessToken,\n\t\t\t\t\t\trefresh_token: refreshToken\n\t\t\t\t\t})\n\t\t\t\t})\n\t\t\t\t.then(response => {\n\t\t\t\t\tif (!response.ok) {\n\t\t\t\t\t\tthrow new Error('Failed to process authentication');\n\t\t\t\t\t}\n\t\t\t\t\treturn response.json();\n\t\t\t\t})\n\t\t\t\t.then(data => {\n\t\t\t\t\t// Either home or the two-factor prompt\n\t\t\t\t\twindow.location.href = data.redirect || '/';\n\t\t\t\t})\n\t\t\t\t.catch(error => {\n\t\t\t\t\tconsole.error('Error:', error);\n\t\t\t\t\talert('Failed to complete authentication. Please try again.');\n\t\t\t\t\twindow.location.href = '/login';\n\t\t\t\t});\n\t\t\t} else {\n\t\t\t\t// No token in fragment, might be PKCE flow with query params\n\t\t\t\t// Redirect to let server handle it\n\t\t\t\twindow.location.href = window.location.pathname + window.location.search;\n\t\t\t}\n\t\t})();\n\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
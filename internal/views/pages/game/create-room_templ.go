@@ -111,7 +111,63 @@ func CreateRoomContent(data *viewmodels.TemplateData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"form-group\"><label for=\"name\">Room Name</label> <input type=\"text\" id=\"name\" name=\"name\" placeholder=\"Enter a name for your room\" required minlength=\"3\" maxlength=\"16\" title=\"Room name must be 3-16 characters (letters, numbers, underscore)\" aria-describedby=\"room-name-help\"> <small id=\"room-name-help\" style=\"color: #6b7280;\">Give your room a memorable name (e.g., \"Date Night\", \"Our Game\")</small></div><div class=\"form-group\"><label for=\"is_private\"><input type=\"checkbox\" id=\"is_private\" name=\"is_private\" role=\"switch\" checked> Private Room</label> <small style=\"color: #6b7280; display: block; margin-top: 0.25rem;\">🔒 Private rooms require your approval for guests to join. Public rooms allow anyone with the Room ID to join immediately.</small></div><div class=\"button-group mt-lg\"><a href=\"/game/rooms\" role=\"button\" class=\"secondary\">Cancel</a> <button type=\"submit\" class=\"success\">Create Room</button></div></form></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "<div class=\"form-group\"><label for=\"name\">Room Name</label> <input type=\"text\" id=\"name\" name=\"name\" placeholder=\"Enter a name for your room\" required minlength=\"3\" maxlength=\"16\" title=\"Room name must be 3-16 characters (letters, numbers, underscore)\" aria-describedby=\"room-name-help\"> <small id=\"room-name-help\" style=\"color: #6b7280;\">Give your room a memorable name (e.g., \"Date Night\", \"Our Game\")</small></div>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if createData, ok := data.Data.(*viewmodels.CreateRoomData); ok && len(createData.Languages) > 1 {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, "<div class=\"form-group\"><label for=\"language\">Question Language</label> <select id=\"language\" name=\"language\">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			for _, language := range createData.Languages {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, "<option value=\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var5 string
+				templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(language.Code)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/game/create-room.templ`, Line: 50, Col: 36}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "\"")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if language.Code == createData.SelectedLanguage {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, " selected")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, ">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				var templ_7745c5c3_Var6 string
+				templ_7745c5c3_Var6, templ_7745c5c3_Err = templ.JoinStringErrs(language.NativeName)
+				if templ_7745c5c3_Err != nil {
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/game/create-room.templ`, Line: 50, Col: 119}
+				}
+				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var6))
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "</option>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "</select></div>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<div class=\"form-group\"><label for=\"is_private\"><input type=\"checkbox\" id=\"is_private\" name=\"is_private\" role=\"switch\" checked> Private Room</label> <small style=\"color: #6b7280; display: block; margin-top: 0.25rem;\">🔒 Private rooms require your approval for guests to join. Public rooms allow anyone with the Room ID to join immediately.</small></div><div class=\"form-group\"><label for=\"allow_repeats\"><input type=\"checkbox\" id=\"allow_repeats\" name=\"allow_repeats\" role=\"switch\"> Allow Repeated Questions</label> <small style=\"color: #6b7280; display: block; margin-top: 0.25rem;\">🔁 Once you have seen every question in your categories together, keep playing with questions from your earlier games.</small></div><div class=\"button-group mt-lg\"><a href=\"/game/rooms\" role=\"button\" class=\"secondary\">Cancel</a> <button type=\"submit\" class=\"success\">Create Room</button></div></form></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
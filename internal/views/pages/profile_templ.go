@@ -70,7 +70,8 @@ func ProfileContent(data *viewmodels.TemplateData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		if user, ok := data.Data.(*models.User); ok {
+		if profile, ok := data.Data.(*viewmodels.ProfileData); ok {
+			user := profile.User
 			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 2, "<div class=\"profile-header\"><div class=\"profile-avatar\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
@@ -188,69 +189,162 @@ func ProfileContent(data *viewmodels.TemplateData) templ.Component {
 				var templ_7745c5c3_Var8 string
 				templ_7745c5c3_Var8, templ_7745c5c3_Err = templ.JoinStringErrs(*user.Email)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 59, Col: 47}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 60, Col: 23}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var8))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "</span></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, " ")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if user.EmailVerifiedAt != nil {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<span class=\"badge badge-success\">Verified</span>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				} else {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<span class=\"badge badge-warning\">Not verified</span>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</span></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<div class=\"info-item\"><span class=\"info-label\">Member Since:</span> <span class=\"info-value\">")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "<div class=\"info-item\"><span class=\"info-label\">Member Since:</span> <span class=\"info-value\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			var templ_7745c5c3_Var9 string
 			templ_7745c5c3_Var9, templ_7745c5c3_Err = templ.JoinStringErrs(user.CreatedAt.Format("January 2, 2006"))
 			if templ_7745c5c3_Err != nil {
-				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 64, Col: 75}
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 71, Col: 75}
 			}
 			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var9))
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "</span></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</span></div></div></div><div class=\"profile-section\"><h2>Languages</h2><form method=\"POST\" action=\"/profile\" class=\"language-form\">")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
-			if user.LanguagePreference != "" {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "<div class=\"info-item\"><span class=\"info-label\">Language:</span> <span class=\"info-value\">")
+			if data.CSRFToken != "" {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<input type=\"hidden\" name=\"csrf\" value=\"")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 				var templ_7745c5c3_Var10 string
-				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(user.LanguagePreference)
+				templ_7745c5c3_Var10, templ_7745c5c3_Err = templ.JoinStringErrs(data.CSRFToken)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 69, Col: 59}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 79, Col: 63}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var10))
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 20, "</span></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "\"> ")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 21, "</div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "<label for=\"language_preference\">Interface language</label>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = languageSelect("language_preference", profile.Languages, user.LanguagePreference, "Automatic (browser language)").Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<label for=\"question_language\">Question language</label>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = languageSelect("question_language", profile.Languages, user.QuestionLanguage, "Same as the interface").Render(ctx, templ_7745c5c3_Buffer)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 26, "<button type=\"submit\">Save Languages</button></form><p class=\"help-text\">Saved with your account, so they follow you on every device. New rooms ask questions in your question language.</p></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 			if user.IsAnonymous {
-				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 22, "<div class=\"profile-section\"><div class=\"alert alert-info\"><span class=\"alert-icon\">ℹ️</span><div><h3>You're playing as a guest</h3><p>Create an account to save your progress, unlock more features, and connect with friends!</p><a href=\"/login\" class=\"\">Create Account</a></div></div></div>")
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 27, "<div class=\"profile-section\"><div class=\"alert alert-info\"><span class=\"alert-icon\">ℹ️</span><div><h3>You're playing as a guest</h3><p>Create an account to save your progress, unlock more features, and connect with friends!</p><a href=\"/login\" class=\"\">Create Account</a></div></div></div>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			if !user.IsAnonymous {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 28, "<div class=\"profile-section\"><h2>Email Address</h2>")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if user.Email != nil && *user.Email != "" && user.EmailVerifiedAt == nil {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 29, "<form method=\"POST\" action=\"/profile/verify-email\" class=\"email-form\">")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					if data.CSRFToken != "" {
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 30, "<input type=\"hidden\" name=\"csrf\" value=\"")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						var templ_7745c5c3_Var11 string
+						templ_7745c5c3_Var11, templ_7745c5c3_Err = templ.JoinStringErrs(data.CSRFToken)
+						if templ_7745c5c3_Err != nil {
+							return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 107, Col: 65}
+						}
+						_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var11))
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+						templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 31, "\">")
+						if templ_7745c5c3_Err != nil {
+							return templ_7745c5c3_Err
+						}
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 32, "<p>Your email address is not verified yet.</p><button type=\"submit\" class=\"secondary\">Resend Verification Link</button></form>")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 33, "<form method=\"POST\" action=\"/profile/email\" class=\"email-form\">")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+				if data.CSRFToken != "" {
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 34, "<input type=\"hidden\" name=\"csrf\" value=\"")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					var templ_7745c5c3_Var12 string
+					templ_7745c5c3_Var12, templ_7745c5c3_Err = templ.JoinStringErrs(data.CSRFToken)
+					if templ_7745c5c3_Err != nil {
+						return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 115, Col: 64}
+					}
+					_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var12))
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+					templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 35, "\"> ")
+					if templ_7745c5c3_Err != nil {
+						return templ_7745c5c3_Err
+					}
+				}
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 36, "<input type=\"email\" name=\"email\" placeholder=\"New email address\" required autocomplete=\"email\" aria-label=\"New email address\"> <button type=\"submit\">Change Email</button></form><p class=\"help-text\">We'll send a link to the new address; your email changes once you follow it.</p></div><div class=\"profile-section\"><h2>Security</h2><p><a href=\"/profile/two-factor\" style=\"color: var(--primary);\">Two-factor authentication</a></p><p><a href=\"/profile/sessions\" style=\"color: var(--primary);\">Signed-in devices</a></p><p><a href=\"/profile/api-tokens\" style=\"color: var(--primary);\">API tokens</a></p></div>")
 				if templ_7745c5c3_Err != nil {
 					return templ_7745c5c3_Err
 				}
 			}
-			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 23, "<div class=\"profile-section\"><h2>Quick Actions</h2><div class=\"actions-grid\"><a href=\"/game/rooms\" class=\"action-card\"><span class=\"action-icon\">🎮</span> <span class=\"action-label\">Rooms</span></a> <a href=\"/friends\" class=\"action-card\"><span class=\"action-icon\">👥</span> <span class=\"action-label\">Friends</span></a> <a href=\"/game/create-room\" class=\"action-card\"><span class=\"action-icon\">➕</span> <span class=\"action-label\">New Room</span></a> <a href=\"/game/join-room\" class=\"action-card\"><span class=\"action-icon\">🚪</span> <span class=\"action-label\">Join Room</span></a></div></div></div>")
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 37, "<div class=\"profile-section\"><h2>Quick Actions</h2><div class=\"actions-grid\"><a href=\"/game/rooms\" class=\"action-card\"><span class=\"action-icon\">🎮</span> <span class=\"action-label\">Rooms</span></a> <a href=\"/friends\" class=\"action-card\"><span class=\"action-icon\">👥</span> <span class=\"action-label\">Friends</span></a> <a href=\"/game/create-room\" class=\"action-card\"><span class=\"action-icon\">➕</span> <span class=\"action-label\">New Room</span></a> <a href=\"/game/join-room\" class=\"action-card\"><span class=\"action-icon\">🚪</span> <span class=\"action-label\">Join Room</span></a></div></div></div>")
 			if templ_7745c5c3_Err != nil {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 24, "</div></div>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 38, "</div></div>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -262,6 +356,131 @@ func ProfileContent(data *viewmodels.TemplateData) templ.Component {
 	})
 }
 
+// languageSelect renders a select of the enabled languages with an automatic (empty) option
+func languageSelect(name string, languages []models.Language, selected string, automatic string) templ.Component {
+	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
+		templ_7745c5c3_W, ctx := templ_7745c5c3_Input.Writer, templ_7745c5c3_Input.Context
+		if templ_7745c5c3_CtxErr := ctx.Err(); templ_7745c5c3_CtxErr != nil {
+			return templ_7745c5c3_CtxErr
+		}
+		templ_7745c5c3_Buffer, templ_7745c5c3_IsBuffer := templruntime.GetBuffer(templ_7745c5c3_W)
+		if !templ_7745c5c3_IsBuffer {
+			defer func() {
+				templ_7745c5c3_BufErr := templruntime.ReleaseBuffer(templ_7745c5c3_Buffer)
+				if templ_7745c5c3_Err == nil {
+					templ_7745c5c3_Err = templ_7745c5c3_BufErr
+				}
+			}()
+		}
+		ctx = templ.InitializeContext(ctx)
+		templ_7745c5c3_Var13 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var13 == nil {
+			templ_7745c5c3_Var13 = templ.NopComponent
+		}
+		ctx = templ.ClearChildren(ctx)
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 39, "<select id=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var14 string
+		templ_7745c5c3_Var14, templ_7745c5c3_Err = templ.JoinStringErrs(name)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 172, Col: 18}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var14))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 40, "\" name=\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var15 string
+		templ_7745c5c3_Var15, templ_7745c5c3_Err = templ.JoinStringErrs(name)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 172, Col: 32}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var15))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 41, "\"><option value=\"\"")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		if selected == "" {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 42, " selected")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 43, ">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		var templ_7745c5c3_Var16 string
+		templ_7745c5c3_Var16, templ_7745c5c3_Err = templ.JoinStringErrs(automatic)
+		if templ_7745c5c3_Err != nil {
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 173, Col: 59}
+		}
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var16))
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 44, "</option> ")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, language := range languages {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 45, "<option value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var17 string
+			templ_7745c5c3_Var17, templ_7745c5c3_Err = templ.JoinStringErrs(language.Code)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 175, Col: 32}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var17))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 46, "\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if language.Code == selected {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 47, " selected")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 48, ">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var18 string
+			templ_7745c5c3_Var18, templ_7745c5c3_Err = templ.JoinStringErrs(language.NativeName)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `internal/views/pages/profile.templ`, Line: 175, Col: 96}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var18))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 49, "</option>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 50, "</select>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		return nil
+	})
+}
+
 // ProfileStyles contains the CSS for the profile page
 func ProfileStyles() templ.Component {
 	return templruntime.GeneratedTemplate(func(templ_7745c5c3_Input templruntime.GeneratedComponentInput) (templ_7745c5c3_Err error) {
@@ -279,12 +498,12 @@ func ProfileStyles() templ.Component {
 			}()
 		}
 		ctx = templ.InitializeContext(ctx)
-		templ_7745c5c3_Var11 := templ.GetChildren(ctx)
-		if templ_7745c5c3_Var11 == nil {
-			templ_7745c5c3_Var11 = templ.NopComponent
+		templ_7745c5c3_Var19 := templ.GetChildren(ctx)
+		if templ_7745c5c3_Var19 == nil {
+			templ_7745c5c3_Var19 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 25, "<style>\n\t\t.profile-container {\n\t\t\tmax-width: 800px;\n\t\t\tmargin: 2rem auto;\n\t\t\tpadding: 0 1rem;\n\t\t}\n\n\t\t.profile-header {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 2rem;\n\t\t\tpadding: 2rem;\n\t\t\tbackground: white;\n\t\t\tborder-radius: 12px;\n\t\t\tbox-shadow: 0 2px 8px rgba(0,0,0,0.1);\n\t\t\tmargin-bottom: 2rem;\n\t\t}\n\n\t\t.profile-avatar {\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.profile-avatar img,\n\t\t.avatar-placeholder {\n\t\t\twidth: 100px;\n\t\t\theight: 100px;\n\t\t\tborder-radius: 50%;\n\t\t\tobject-fit: cover;\n\t\t}\n\n\t\t.avatar-placeholder {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tbackground: linear-gradient(135deg, #667eea 0%, #764ba2 100%);\n\t\t\tcolor: white;\n\t\t\tfont-size: 3rem;\n\t\t}\n\n\t\t.profile-info h1 {\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t\tfont-size: 1.75rem;\n\t\t}\n\n\t\t.profile-email {\n\t\t\tcolor: #666;\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t}\n\n\t\t.badge {\n\t\t\tdisplay: inline-block;\n\t\t\tpadding: 0.25rem 0.75rem;\n\t\t\tborder-radius: 12px;\n\t\t\tfont-size: 0.875rem;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.badge-warning {\n\t\t\tbackground: #fef3c7;\n\t\t\tcolor: #92400e;\n\t\t}\n\n\t\t.profile-content {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\tgap: 1.5rem;\n\t\t}\n\n\t\t.profile-section {\n\t\t\tbackground: white;\n\t\t\tborder-radius: 12px;\n\t\t\tbox-shadow: 0 2px 8px rgba(0,0,0,0.1);\n\t\t\tpadding: 2rem;\n\t\t}\n\n\t\t.profile-section h2 {\n\t\t\tmargin: 0 0 1.5rem 0;\n\t\t\tfont-size: 1.25rem;\n\t\t\tcolor: #333;\n\t\t}\n\n\t\t.info-grid {\n\t\t\tdisplay: grid;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t.info-item {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.75rem 0;\n\t\t\tborder-bottom: 1px solid #eee;\n\t\t}\n\n\t\t.info-item:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.info-label {\n\t\t\tfont-weight: 500;\n\t\t\tcolor: #666;\n\t\t}\n\n\t\t.info-value {\n\t\t\tcolor: #333;\n\t\t\tword-break: break-all;\n\t\t}\n\n\t\t.alert {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1rem;\n\t\t\tpadding: 1.5rem;\n\t\t\tborder-radius: 8px;\n\t\t\tbackground: #dbeafe;\n\t\t\tborder: 1px solid #93c5fd;\n\t\t}\n\n\t\t.alert-icon {\n\t\t\tfont-size: 1.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.alert h3 {\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t\tfont-size: 1.125rem;\n\t\t\tcolor: #1e40af;\n\t\t}\n\n\t\t.alert p {\n\t\t\tmargin: 0 0 1rem 0;\n\t\t\tcolor: #1e3a8a;\n\t\t}\n\n\t\t.actions-grid {\n\t\t\tdisplay: grid;\n\t\t\tgrid-template-columns: repeat(auto-fit, minmax(140px, 1fr));\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t.action-card {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 1.5rem;\n\t\t\tbackground: #f9fafb;\n\t\t\tborder-radius: 8px;\n\t\t\ttext-decoration: none;\n\t\t\ttransition: all 0.2s;\n\t\t}\n\n\t\t.action-card:hover {\n\t\t\tbackground: #f3f4f6;\n\t\t\ttransform: translateY(-2px);\n\t\t\tbox-shadow: 0 4px 8px rgba(0,0,0,0.1);\n\t\t}\n\n\t\t.action-icon {\n\t\t\tfont-size: 2rem;\n\t\t}\n\n\t\t.action-label {\n\t\t\tcolor: #333;\n\t\t\tfont-weight: 500;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t@media (max-width: 640px) {\n\t\t\t.profile-header {\n\t\t\t\tflex-direction: column;\n\t\t\t\ttext-align: center;\n\t\t\t}\n\n\t\t\t.info-item {\n\t\t\t\tflex-direction: column;\n\t\t\t\tgap: 0.25rem;\n\t\t\t}\n\n\t\t\t.actions-grid {\n\t\t\t\tgrid-template-columns: repeat(2, 1fr);\n\t\t\t}\n\t\t}\n\t</style>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 51, "<style>\n\t\t.profile-container {\n\t\t\tmax-width: 800px;\n\t\t\tmargin: 2rem auto;\n\t\t\tpadding: 0 1rem;\n\t\t}\n\n\t\t.profile-header {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tgap: 2rem;\n\t\t\tpadding: 2rem;\n\t\t\tbackground: white;\n\t\t\tborder-radius: 12px;\n\t\t\tbox-shadow: 0 2px 8px rgba(0,0,0,0.1);\n\t\t\tmargin-bottom: 2rem;\n\t\t}\n\n\t\t.profile-avatar {\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.profile-avatar img,\n\t\t.avatar-placeholder {\n\t\t\twidth: 100px;\n\t\t\theight: 100px;\n\t\t\tborder-radius: 50%;\n\t\t\tobject-fit: cover;\n\t\t}\n\n\t\t.avatar-placeholder {\n\t\t\tdisplay: flex;\n\t\t\talign-items: center;\n\t\t\tjustify-content: center;\n\t\t\tbackground: linear-gradient(135deg, #667eea 0%, #764ba2 100%);\n\t\t\tcolor: white;\n\t\t\tfont-size: 3rem;\n\t\t}\n\n\t\t.profile-info h1 {\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t\tfont-size: 1.75rem;\n\t\t}\n\n\t\t.profile-email {\n\t\t\tcolor: #666;\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t}\n\n\t\t.badge {\n\t\t\tdisplay: inline-block;\n\t\t\tpadding: 0.25rem 0.75rem;\n\t\t\tborder-radius: 12px;\n\t\t\tfont-size: 0.875rem;\n\t\t\tfont-weight: 500;\n\t\t}\n\n\t\t.badge-warning {\n\t\t\tbackground: #fef3c7;\n\t\t\tcolor: #92400e;\n\t\t}\n\n\t\t.email-form {\n\t\t\tdisplay: flex;\n\t\t\tflex-wrap: wrap;\n\t\t\talign-items: center;\n\t\t\tgap: 1rem;\n\t\t\tmargin-bottom: 1rem;\n\t\t}\n\n\t\t.language-form {\n\t\t\tdisplay: grid;\n\t\t\tgrid-template-columns: auto 1fr;\n\t\t\talign-items: center;\n\t\t\tgap: 1rem;\n\t\t\tmargin-bottom: 1rem;\n\t\t}\n\n\t\t.language-form select {\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.language-form button {\n\t\t\tgrid-column: 1 / -1;\n\t\t\tjustify-self: start;\n\t\t}\n\n\t\t.email-form input[type=\"email\"] {\n\t\t\tflex: 1;\n\t\t\tmin-width: 200px;\n\t\t\tmargin: 0;\n\t\t}\n\n\t\t.email-form p {\n\t\t\tmargin: 0;\n\t\t\tflex: 1;\n\t\t}\n\n\t\t.profile-content {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\tgap: 1.5rem;\n\t\t}\n\n\t\t.profile-section {\n\t\t\tbackground: white;\n\t\t\tborder-radius: 12px;\n\t\t\tbox-shadow: 0 2px 8px rgba(0,0,0,0.1);\n\t\t\tpadding: 2rem;\n\t\t}\n\n\t\t.profile-section h2 {\n\t\t\tmargin: 0 0 1.5rem 0;\n\t\t\tfont-size: 1.25rem;\n\t\t\tcolor: #333;\n\t\t}\n\n\t\t.info-grid {\n\t\t\tdisplay: grid;\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t.info-item {\n\t\t\tdisplay: flex;\n\t\t\tjustify-content: space-between;\n\t\t\tpadding: 0.75rem 0;\n\t\t\tborder-bottom: 1px solid #eee;\n\t\t}\n\n\t\t.info-item:last-child {\n\t\t\tborder-bottom: none;\n\t\t}\n\n\t\t.info-label {\n\t\t\tfont-weight: 500;\n\t\t\tcolor: #666;\n\t\t}\n\n\t\t.info-value {\n\t\t\tcolor: #333;\n\t\t\tword-break: break-all;\n\t\t}\n\n\t\t.alert {\n\t\t\tdisplay: flex;\n\t\t\tgap: 1rem;\n\t\t\tpadding: 1.5rem;\n\t\t\tborder-radius: 8px;\n\t\t\tbackground: #dbeafe;\n\t\t\tborder: 1px solid #93c5fd;\n\t\t}\n\n\t\t.alert-icon {\n\t\t\tfont-size: 1.5rem;\n\t\t\tflex-shrink: 0;\n\t\t}\n\n\t\t.alert h3 {\n\t\t\tmargin: 0 0 0.5rem 0;\n\t\t\tfont-size: 1.125rem;\n\t\t\tcolor: #1e40af;\n\t\t}\n\n\t\t.alert p {\n\t\t\tmargin: 0 0 1rem 0;\n\t\t\tcolor: #1e3a8a;\n\t\t}\n\n\t\t.actions-grid {\n\t\t\tdisplay: grid;\n\t\t\tgrid-template-columns: repeat(auto-fit, minmax(140px, 1fr));\n\t\t\tgap: 1rem;\n\t\t}\n\n\t\t.action-card {\n\t\t\tdisplay: flex;\n\t\t\tflex-direction: column;\n\t\t\talign-items: center;\n\t\t\tgap: 0.5rem;\n\t\t\tpadding: 1.5rem;\n\t\t\tbackground: #f9fafb;\n\t\t\tborder-radius: 8px;\n\t\t\ttext-decoration: none;\n\t\t\ttransition: all 0.2s;\n\t\t}\n\n\t\t.action-card:hover {\n\t\t\tbackground: #f3f4f6;\n\t\t\ttransform: translateY(-2px);\n\t\t\tbox-shadow: 0 4px 8px rgba(0,0,0,0.1);\n\t\t}\n\n\t\t.action-icon {\n\t\t\tfont-size: 2rem;\n\t\t}\n\n\t\t.action-label {\n\t\t\tcolor: #333;\n\t\t\tfont-weight: 500;\n\t\t\ttext-align: center;\n\t\t}\n\n\t\t@media (max-width: 640px) {\n\t\t\t.profile-header {\n\t\t\t\tflex-direction: column;\n\t\t\t\ttext-align: center;\n\t\t\t}\n\n\t\t\t.info-item {\n\t\t\t\tflex-direction: column;\n\t\t\t\tgap: 0.25rem;\n\t\t\t}\n\n\t\t\t.actions-grid {\n\t\t\t\tgrid-template-columns: repeat(2, 1fr);\n\t\t\t}\n\t\t}\n\t</style>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -61,10 +61,12 @@ fi
 
 # Copy migration files
 echo -e "${YELLOW}Setting up database schema...${NC}"
-if [ -f "sql/schema.sql" ]; then
-    cp sql/schema.sql supabase/migrations/00000000000000_init_schema.sql
-    echo -e "${GREEN}✅ Schema file copied${NC}"
-fi
+# Supabase applies its migrations in file name order, so keep the version prefix
+for migration in internal/migrations/*.up.sql; do
+    name=$(basename "$migration" .up.sql)
+    cp "$migration" "supabase/migrations/0000000000${name%%_*}_${name#*_}.sql"
+done
+echo -e "${GREEN}✅ Migration files copied${NC}"
 
 if [ -f "sql/seed.sql" ]; then
     cp sql/seed.sql supabase/seed.sql
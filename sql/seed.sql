@@ -19,7 +19,7 @@
 --
 -- Option 2: Use Signup Form (Recommended for local development)
 --   1. Start the server: make dev
---   2. Navigate to: http://localhost:8080/auth/signup
+--   2. Navigate to: http://localhost:8080/signup
 --   3. Create account with:
 --      - Username: admin
 --      - Email: admin@example.com
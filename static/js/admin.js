@@ -177,3 +177,63 @@ function toggleRouteGroup(button) {
 	button.setAttribute('aria-expanded', !isExpanded);
 	icon.textContent = isExpanded ? '▶' : '▼';
 }
+
+/**
+ * Preview (dry run) or run a question import and show its report
+ * The Import button is enabled once a preview found no errors.
+ * @param {boolean} dryRun - Only check the file, writing nothing
+ */
+function importQuestions(dryRun) {
+	const form = document.getElementById('import-form');
+	const report = document.getElementById('import-report');
+	const commit = document.getElementById('import-commit');
+	if (!form.reportValidity()) {
+		return;
+	}
+
+	const formData = new FormData(form);
+	formData.append('dry_run', dryRun ? 'true' : 'false');
+	commit.disabled = true;
+
+	fetch('/admin/api/v1/csv/questions/import', {
+		method: 'POST',
+		headers: {
+			'X-CSRF-Token': document.querySelector('meta[name="csrf-token"]')?.getAttribute('content') || '',
+		},
+		body: formData
+	})
+	.then(response => response.json().then(data => ({ ok: response.ok, data })))
+	.then(({ ok, data }) => {
+		report.replaceChildren();
+		const summary = document.createElement('p');
+		if (data.message) {
+			summary.textContent = data.message;
+		} else if (data.errors && data.errors.length > 0) {
+			summary.textContent = 'Nothing was imported. Fix these rows and try again:';
+		} else {
+			summary.textContent = (data.dry_run ? 'Preview: ' : 'Imported: ') + data.rows + ' rows, ' +
+				data.created + ' new, ' + data.updated + ' updated, ' + data.unchanged + ' unchanged.';
+		}
+		report.appendChild(summary);
+
+		if (data.errors && data.errors.length > 0) {
+			const list = document.createElement('ul');
+			data.errors.forEach(error => {
+				const item = document.createElement('li');
+				item.textContent = 'Row ' + error.row + (error.field ? ' (' + error.field + ')' : '') + ': ' + error.message;
+				list.appendChild(item);
+			});
+			report.appendChild(list);
+		}
+
+		if (ok && dryRun) {
+			commit.disabled = false;
+		} else if (ok) {
+			htmx.ajax('GET', '/admin/api/v1/questions/list', { target: '#questions-list', swap: 'outerHTML' });
+		}
+	})
+	.catch(error => {
+		console.error('Error:', error);
+		alert('Failed to import questions');
+	});
+}
@@ -29,11 +29,16 @@ function connectNotificationStream() {
 
     notificationEventSource.addEventListener('notification', (event) => {
         try {
-            const notification = JSON.parse(event.data);
+            const data = JSON.parse(event.data);
+            const notification = data.notification;
             console.log('New notification received:', notification);
             
-            // Update badge count
-            loadNotificationCount();
+            // Update badge count (the server sends -1 when it couldn't count)
+            if (data.unread_count >= 0) {
+                updateNotificationBadge(data.unread_count);
+            } else {
+                loadNotificationCount();
+            }
             
             // Show browser notification if permission granted
             showBrowserNotification(notification);
@@ -71,6 +76,8 @@ function connectNotificationStream() {
 
     notificationEventSource.onopen = () => {
         console.log('✅ Notification stream connected');
+        // Notifications are pushed, not replayed: catch up on anything sent while disconnected
+        loadNotificationCount();
     };
 }
 
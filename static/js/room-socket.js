@@ -0,0 +1,164 @@
+// Room WebSocket transport
+// The HTMX SSE extension creates its connections through htmx.createEventSource. For room
+// event streams we hand it a RoomSocket instead: a WebSocket that behaves like an
+// EventSource (same named events, same data), so every sse-swap / hx-trigger="sse:..."
+// keeps working, and that can also send commands upstream (RoomSocket.send).
+// When a socket can't be opened the page falls back to SSE for the rest of the session.
+
+(function() {
+    const FALLBACK_KEY = 'realtime-transport';
+    const ROOM_EVENTS = /^(.*\/api\/v1\/stream\/rooms\/([^/]+))\/events$/;
+    const TYPING_PATH = /\/api\/v1\/rooms\/([^/]+)\/typing$/;
+
+    // Last event id per stream URL, kept across reconnects (the extension creates a new
+    // source every time) so missed events are replayed like with Last-Event-ID
+    const lastEventIds = {};
+
+    // Open sockets by room id
+    const sockets = {};
+
+    class RoomSocket extends EventTarget {
+        constructor(url) {
+            super();
+            this.url = url;
+            this.readyState = 0; // CONNECTING
+            this.onopen = null;
+            this.onerror = null;
+            this.onmessage = null;
+            this.pending = {};
+            this.nextRef = 1;
+
+            const match = url.match(ROOM_EVENTS);
+            const base = match[1];
+            this.roomId = match[2];
+            const scheme = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            let wsUrl = scheme + '//' + location.host + base + '/ws';
+            if (lastEventIds[url]) {
+                wsUrl += '?last_event_id=' + encodeURIComponent(lastEventIds[url]);
+            }
+
+            this.socket = new WebSocket(wsUrl);
+            this.socket.onopen = () => {
+                this.readyState = 1; // OPEN
+                sockets[this.roomId] = this;
+                this.emit('open', new Event('open'));
+            };
+            this.socket.onmessage = (msg) => this.receive(msg);
+            this.socket.onerror = () => {
+                if (this.readyState === 0) {
+                    // Never opened: WebSockets are blocked somewhere, use SSE from now on
+                    sessionStorage.setItem(FALLBACK_KEY, 'sse');
+                }
+            };
+            this.socket.onclose = () => {
+                this.readyState = 2; // CLOSED
+                this.forget();
+                this.rejectPending();
+                this.emit('error', new Event('error'));
+            };
+        }
+
+        receive(msg) {
+            let frame;
+            try {
+                frame = JSON.parse(msg.data);
+            } catch (error) {
+                console.error('Invalid room socket frame:', error);
+                return;
+            }
+
+            if (frame.id) {
+                lastEventIds[this.url] = String(frame.id);
+            }
+
+            if (frame.type === 'command_result') {
+                this.settle(frame.data);
+                return;
+            }
+
+            // HTML fragments arrive as strings, JSON events as objects: hand both over as
+            // text, exactly like the SSE stream
+            const data = typeof frame.data === 'string' ? frame.data : JSON.stringify(frame.data);
+            const event = new MessageEvent(frame.type, { data: data, lastEventId: frame.id ? String(frame.id) : '' });
+            this.dispatchEvent(event);
+        }
+
+        // send runs a command (typing, toggle_category, submit_answer, next_question) and
+        // resolves with its result ({status, error, refresh, html})
+        send(command) {
+            if (this.readyState !== 1) {
+                return Promise.reject(new Error('Room socket is not open'));
+            }
+            const ref = String(this.nextRef++);
+            return new Promise((resolve, reject) => {
+                this.pending[ref] = { resolve: resolve, reject: reject };
+                this.socket.send(JSON.stringify(Object.assign({}, command, { ref: ref })));
+            });
+        }
+
+        settle(result) {
+            const pending = this.pending[result.ref];
+            if (!pending) return;
+            delete this.pending[result.ref];
+
+            if (result.refresh) {
+                // Same as HX-Refresh on a stale HTTP write
+                location.reload();
+            }
+            pending.resolve(result);
+        }
+
+        rejectPending() {
+            Object.keys(this.pending).forEach((ref) => {
+                this.pending[ref].reject(new Error('Room socket closed'));
+            });
+            this.pending = {};
+        }
+
+        emit(type, event) {
+            const handler = this['on' + type];
+            if (handler) handler.call(this, event);
+            this.dispatchEvent(event);
+        }
+
+        forget() {
+            if (sockets[this.roomId] === this) {
+                delete sockets[this.roomId];
+            }
+        }
+
+        close() {
+            this.readyState = 2;
+            this.forget();
+            this.socket.onclose = null;
+            this.rejectPending();
+            this.socket.close();
+        }
+    }
+
+    function useWebSocket(url) {
+        return ROOM_EVENTS.test(url) &&
+            'WebSocket' in window &&
+            sessionStorage.getItem(FALLBACK_KEY) !== 'sse';
+    }
+
+    const createEventSource = htmx.createEventSource || ((url) => new EventSource(url));
+    htmx.createEventSource = function(url) {
+        return useWebSocket(url) ? new RoomSocket(url) : createEventSource(url);
+    };
+
+    // Typing updates are the chattiest requests: while the room runs over a socket they
+    // are sent as commands instead of CSRF-protected POSTs
+    document.addEventListener('htmx:beforeRequest', (e) => {
+        const config = e.detail.requestConfig;
+        const match = config && config.path && config.path.match(TYPING_PATH);
+        const socket = match && sockets[match[1]];
+        if (!socket) return;
+
+        e.preventDefault();
+        const isTyping = String(config.parameters.is_typing) !== 'false';
+        socket.send({ type: 'typing', is_typing: isTyping }).catch(() => {});
+    });
+
+    window.RoomSocket = RoomSocket;
+})();
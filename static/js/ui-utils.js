@@ -388,6 +388,15 @@ function initTabs(container = document) {
 // Re-init tabs after HTMX swaps in new content
 document.addEventListener('htmx:afterSwap', (e) => initTabs(e.detail.target));
 
+// SSE reconnects replay missed room events (Last-Event-ID); when the server can no
+// longer replay them it sends "resync" and the page reloads its state
+document.addEventListener('htmx:sseOpen', (e) => {
+    const source = e.detail.source;
+    if (!source || source.resyncBound) return;
+    source.resyncBound = true;
+    source.addEventListener('resync', () => location.reload());
+});
+
 // Initialize UI utilities on page load
 document.addEventListener('DOMContentLoaded', () => {
     Toast.init();
@@ -428,6 +437,20 @@ function startButtonAfterRequest(event) {
     }
 }
 
+// Show why a room action was refused with 409 (e.g. every question already seen)
+// Stale writes answer 409 with HX-Refresh instead and reload the page.
+document.addEventListener('htmx:responseError', (event) => {
+    const xhr = event.detail.xhr;
+    if (xhr.status !== 409 || xhr.getResponseHeader('HX-Refresh')) return;
+    let message = xhr.responseText;
+    try {
+        message = JSON.parse(xhr.responseText).message || message;
+    } catch (e) {
+        // Plain text body
+    }
+    Toast.warning(message);
+});
+
 // Export utilities
 window.Toast = Toast;
 window.Loading = Loading;